@@ -21,6 +21,7 @@ import (
 	"context"
 	"os"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 
 	"github.com/apache/skywalking-banyandb/banyand/discovery"
@@ -32,6 +33,7 @@ import (
 	"github.com/apache/skywalking-banyandb/banyand/stream"
 	"github.com/apache/skywalking-banyandb/pkg/config"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
 	"github.com/apache/skywalking-banyandb/pkg/run"
 	"github.com/apache/skywalking-banyandb/pkg/signal"
 	"github.com/apache/skywalking-banyandb/pkg/version"
@@ -44,7 +46,8 @@ var (
 func newStandaloneCmd() *cobra.Command {
 	_ = logger.Bootstrap()
 	l := logger.GetLogger("bootstrap")
-	ctx := context.Background()
+	// the metrics are exported by the pprof server
+	ctx := context.WithValue(context.Background(), meter.ContextKey, meter.NewPrometheusObserver(prometheus.DefaultRegisterer))
 	repo, err := discovery.NewServiceRepo(ctx)
 	if err != nil {
 		l.Fatal().Err(err).Msg("failed to initiate service repository")
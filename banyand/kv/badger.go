@@ -198,6 +198,20 @@ func (b *badgerDB) NewIterator(opt ScanOpts) Iterator {
 	}
 }
 
+func (b *badgerDB) Stats() (s Stats) {
+	tables := b.db.Tables()
+	s.TableCount = len(tables)
+	for _, t := range tables {
+		s.KeyCount += uint64(t.KeyCount)
+		s.Size += int64(t.OnDiskSize)
+	}
+	return s
+}
+
+func (b *badgerDB) Sync() error {
+	return b.db.Sync()
+}
+
 func (b *badgerDB) Close() error {
 	if b.db != nil && !b.db.IsClosed() {
 		return b.db.Close()
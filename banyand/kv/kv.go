@@ -60,11 +60,29 @@ type Reader interface {
 	Scan(prefix []byte, opt ScanOpts, f ScanFunc) error
 }
 
+// Stats is the statistics of the on-disk tables of a store
+type Stats struct {
+	// TableCount is the number of on-disk tables
+	TableCount int
+	// KeyCount is the number of keys in on-disk tables
+	KeyCount uint64
+	// Size is the bytes of on-disk tables
+	Size int64
+}
+
+// Syncer makes the written data durable
+type Syncer interface {
+	// Sync fsyncs the data which is only in the page cache, so that it survives a crash of the OS
+	Sync() error
+}
+
 // Store is a common kv storage with auto-generated key
 type Store interface {
 	io.Closer
 	Writer
 	Reader
+	Syncer
+	Stats() Stats
 }
 
 type TimeSeriesWriter interface {
@@ -135,6 +153,8 @@ type IndexStore interface {
 	Iterable
 	Reader
 	Handover(iterator Iterator) error
+	Syncer
+	Stats() Stats
 	Close() error
 }
 
@@ -66,7 +66,7 @@ var _ = Describe("Registry", func() {
 			Metadata: meta,
 		})
 		errStatus, _ := status.FromError(err)
-		Expect(errStatus.Message()).To(Equal(schema.ErrEntityNotFound.Error()))
+		Expect(errStatus.Message()).To(HaveSuffix(schema.ErrEntityNotFound.Error()))
 		By("Creating a new stream")
 		_, err = client.Create(context.TODO(), &databasev1.StreamRegistryServiceCreateRequest{Stream: getResp.GetStream()})
 		Expect(err).ShouldNot(HaveOccurred())
@@ -96,7 +96,7 @@ var _ = Describe("Registry", func() {
 			Metadata: meta,
 		})
 		errStatus, _ := status.FromError(err)
-		Expect(errStatus.Message()).To(Equal(schema.ErrEntityNotFound.Error()))
+		Expect(errStatus.Message()).To(HaveSuffix(schema.ErrEntityNotFound.Error()))
 		By("Creating a new index-rule-binding")
 		_, err = client.Create(context.TODO(), &databasev1.IndexRuleBindingRegistryServiceCreateRequest{IndexRuleBinding: getResp.GetIndexRuleBinding()})
 		Expect(err).ShouldNot(HaveOccurred())
@@ -126,7 +126,7 @@ var _ = Describe("Registry", func() {
 			Metadata: meta,
 		})
 		errStatus, _ := status.FromError(err)
-		Expect(errStatus.Message()).To(Equal(schema.ErrEntityNotFound.Error()))
+		Expect(errStatus.Message()).To(HaveSuffix(schema.ErrEntityNotFound.Error()))
 		By("Creating a new index-rule")
 		_, err = client.Create(context.TODO(), &databasev1.IndexRuleRegistryServiceCreateRequest{IndexRule: getResp.GetIndexRule()})
 		Expect(err).ShouldNot(HaveOccurred())
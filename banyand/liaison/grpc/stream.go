@@ -22,6 +22,8 @@ import (
 	"io"
 	"time"
 
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	"github.com/apache/skywalking-banyandb/api/data"
 	streamv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/stream/v1"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
@@ -42,6 +44,10 @@ func (s *streamService) Write(stream streamv1.StreamService_WriteServer) error {
 		if err != nil {
 			return err
 		}
+		if writeEntity.GetElement() != nil && writeEntity.GetElement().GetTimestamp() == nil {
+			// stamp the element on receipt
+			writeEntity.Element.Timestamp = timestamppb.Now()
+		}
 		entity, shardID, err := s.navigate(writeEntity.GetMetadata(), writeEntity.GetElement().GetTagFamilies())
 		if err != nil {
 			s.log.Error().Err(err).Msg("failed to navigate to the write target")
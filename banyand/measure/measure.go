@@ -19,18 +19,27 @@ package measure
 
 import (
 	"context"
+	"sync"
+	"time"
 
+	"github.com/apache/skywalking-banyandb/api/common"
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb/index"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
 	"github.com/apache/skywalking-banyandb/pkg/partition"
 	pbv1 "github.com/apache/skywalking-banyandb/pkg/pb/v1"
 )
 
-// a chunk is 1MB
-const chunkSize = 1 << 20
+const (
+	// a chunk is 1MB
+	chunkSize = 1 << 20
+
+	versionLockNum = 64
+)
 
 type measure struct {
 	name     string
@@ -44,6 +53,17 @@ type measure struct {
 	entityLocator          partition.EntityLocator
 	indexRules             []*databasev1.IndexRule
 	indexWriter            *index.Writer
+	// strictIndexing rejects the data which fails to be indexed
+	strictIndexing bool
+	utf8Policy     commonv1.ResourceOpts_UTF8Policy
+	// versionLocks make the version check, the write and the indexing of a data point of a versioned measure atomic.
+	// A lock is picked by the series and timestamp, so only the writes to the same data point contend for it.
+	versionLocks [versionLockNum]sync.Mutex
+}
+
+func (s *measure) versionLock(seriesID common.SeriesID, t time.Time) *sync.Mutex {
+	key := append(convert.Uint64ToBytes(uint64(seriesID)), convert.Uint64ToBytes(uint64(t.UnixNano()))...)
+	return &s.versionLocks[convert.Hash(key)%versionLockNum]
 }
 
 func (s *measure) GetSchema() *databasev1.Measure {
@@ -77,26 +97,33 @@ func (s *measure) parseSpec() {
 }
 
 type measureSpec struct {
-	schema     *databasev1.Measure
-	indexRules []*databasev1.IndexRule
+	schema         *databasev1.Measure
+	indexRules     []*databasev1.IndexRule
+	strictIndexing bool
+	utf8Policy     commonv1.ResourceOpts_UTF8Policy
+	observer       meter.MetricsObserver
 }
 
 func openMeasure(shardNum uint32, db tsdb.Supplier, spec measureSpec, l *logger.Logger) (*measure, error) {
 	sm := &measure{
-		shardNum:   shardNum,
-		schema:     spec.schema,
-		indexRules: spec.indexRules,
-		l:          l,
+		shardNum:       shardNum,
+		schema:         spec.schema,
+		indexRules:     spec.indexRules,
+		strictIndexing: spec.strictIndexing,
+		utf8Policy:     spec.utf8Policy,
+		l:              l,
 	}
 	sm.parseSpec()
 	ctx := context.WithValue(context.Background(), logger.ContextKey, l)
 
 	sm.db = db
 	sm.indexWriter = index.NewWriter(ctx, index.WriterOptions{
-		DB:         db,
-		ShardNum:   shardNum,
-		Families:   spec.schema.TagFamilies,
-		IndexRules: spec.indexRules,
+		DB:              db,
+		ShardNum:        shardNum,
+		Families:        spec.schema.TagFamilies,
+		IndexRules:      spec.indexRules,
+		Subject:         spec.schema.GetMetadata(),
+		MetricsObserver: spec.observer,
 	})
 	return sm, nil
 }
@@ -50,10 +50,17 @@ type Measure interface {
 	ParseTagFamily(family string, item tsdb.Item) (*modelv1.TagFamily, error)
 	ParseField(name string, item tsdb.Item) (*measurev1.DataPoint_Field, error)
 	GetSchema() *databasev1.Measure
+	// SamplingRates returns the rates of the rules indexing a fraction of the values, keyed by their names.
+	// A lookup by such a rule is best-effort.
+	SamplingRates() map[string]float64
 }
 
 var _ Measure = (*measure)(nil)
 
+func (s *measure) SamplingRates() map[string]float64 {
+	return s.indexWriter.SamplingRates()
+}
+
 func (s *measure) Shards(entity tsdb.Entity) ([]tsdb.Shard, error) {
 	wrap := func(shards []tsdb.Shard) []tsdb.Shard {
 		result := make([]tsdb.Shard, len(shards))
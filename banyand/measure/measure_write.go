@@ -19,6 +19,7 @@ package measure
 
 import (
 	"bytes"
+	"math"
 
 	"github.com/pkg/errors"
 	"google.golang.org/protobuf/proto"
@@ -27,6 +28,7 @@ import (
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
 	measurev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/measure/v1"
 	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/banyand/kv"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb/index"
 	"github.com/apache/skywalking-banyandb/pkg/bus"
@@ -44,6 +46,9 @@ const (
 	TagFlag byte = iota
 )
 
+// versionFamily holds the version of a data point. Its flag is out of the range of the field flags.
+var versionFamily = familyIdentity("_version", math.MaxUint8)
+
 func (s *measure) Write(value *measurev1.DataPointValue) error {
 	entity, shardID, err := s.entityLocator.Locate(s.name, value.GetTagFamilies(), s.shardNum)
 	if err != nil {
@@ -67,8 +72,22 @@ func (s *measure) write(shardID common.ShardID, seriesHashKey []byte, value *mea
 	if fLen < 1 {
 		return errors.Wrap(ErrMalformedElement, "no tag family")
 	}
-	if fLen > len(sm.TagFamilies) {
-		return errors.Wrap(ErrMalformedElement, "tag family number is more than expected")
+	if err := pbv1.CheckTagFamilyCount(value.GetTagFamilies(), sm.GetTagFamilies()); err != nil {
+		return err
+	}
+	if err := pbv1.ApplyUTF8Policy(value.GetTagFamilies(), s.utf8Policy); err != nil {
+		return err
+	}
+	if value.GetVersion() != 0 && !sm.GetVersioned() {
+		return errors.Wrap(ErrMalformedElement, "version is set, but the measure isn't versioned")
+	}
+	if s.strictIndexing {
+		if err := s.indexWriter.Check(index.Value{
+			TagFamilies: value.GetTagFamilies(),
+			Timestamp:   value.GetTimestamp().AsTime(),
+		}); err != nil {
+			return err
+		}
 	}
 	shard, err := s.db.SupplyTSDB().Shard(shardID)
 	if err != nil {
@@ -86,6 +105,14 @@ func (s *measure) write(shardID common.ShardID, seriesHashKey []byte, value *mea
 		}
 		return err
 	}
+	versioned := sm.GetVersioned()
+	if versioned {
+		// the data point is checked, written and indexed under the lock, so the overwritten one is indexed before
+		versionLock := s.versionLock(series.ID(), t)
+		versionLock.Lock()
+		defer versionLock.Unlock()
+	}
+	var overwritten *index.Value
 	writeFn := func() (tsdb.Writer, error) {
 		builder := wp.WriterBuilder().Time(t)
 		for fi, family := range value.GetTagFamilies() {
@@ -109,28 +136,64 @@ func (s *measure) write(shardID common.ShardID, seriesHashKey []byte, value *mea
 			}
 			builder.Family(familyIdentity(sm.GetTagFamilies()[fi].GetName(), TagFlag), bb)
 		}
+		if versioned {
+			// clear the families left out, which the overwritten data point might carry
+			for _, familySpec := range sm.GetTagFamilies()[len(value.GetTagFamilies()):] {
+				builder.Family(familyIdentity(familySpec.GetName(), TagFlag), []byte{})
+			}
+		}
 		if len(value.GetFields()) > len(sm.GetFields()) {
 			return nil, errors.Wrap(ErrMalformedElement, "fields number is more than expected")
 		}
-		for fi, fieldValue := range value.GetFields() {
-			fieldSpec := sm.GetFields()[fi]
-			fType, isNull := pbv1.FieldValueTypeConv(fieldValue)
-			if isNull {
-				continue
-			}
-			if fType != fieldSpec.GetFieldType() {
-				return nil, errors.Wrapf(ErrMalformedElement, "field %s type is unexpected", fieldSpec.GetName())
+		for fi, fieldSpec := range sm.GetFields() {
+			var data []byte
+			if fi < len(value.GetFields()) {
+				fieldValue := value.GetFields()[fi]
+				fType, isNull := pbv1.FieldValueTypeConv(fieldValue)
+				if !isNull {
+					if fType != fieldSpec.GetFieldType() {
+						return nil, errors.Wrapf(ErrMalformedElement, "field %s type is unexpected", fieldSpec.GetName())
+					}
+					data = encodeFieldValue(fieldValue)
+				}
 			}
-			data := encodeFieldValue(fieldValue)
 			if data == nil {
-				continue
+				if !versioned {
+					continue
+				}
+				// clear the field left out or null, which the overwritten data point might carry
+				data = []byte{}
 			}
-			builder.Family(familyIdentity(sm.GetFields()[fi].GetName(), encoderFieldFlag(fieldSpec)), data)
+			builder.Family(familyIdentity(fieldSpec.GetName(), encoderFieldFlag(fieldSpec)), data)
+		}
+		if value.GetVersion() != 0 {
+			builder.Family(versionFamily, convert.Int64ToBytes(value.GetVersion()))
 		}
 		writer, errWrite := builder.Build()
 		if errWrite != nil {
 			return nil, errWrite
 		}
+		// an unversioned measure keeps the latest written data point, which needs no check
+		if versioned {
+			stored, errStored := s.loadStored(series, writer.ItemID())
+			if errStored != nil {
+				return nil, errStored
+			}
+			if value.GetVersion() < stored.version {
+				s.l.Debug().
+					Time("ts", t).
+					Int64("version", value.GetVersion()).
+					Uint64("series_id", uint64(series.ID())).
+					Msg("drop the stale measure")
+				return nil, nil
+			}
+			if stored.tagFamilies != nil {
+				overwritten = &index.Value{
+					TagFamilies: stored.tagFamilies,
+					Timestamp:   t,
+				}
+			}
+		}
 		_, errWrite = writer.Write()
 		s.l.Debug().
 			Time("ts", t).
@@ -147,15 +210,34 @@ func (s *measure) write(shardID common.ShardID, seriesHashKey []byte, value *mea
 		_ = wp.Close()
 		return err
 	}
+	if writer == nil {
+		// the stale data point is dropped
+		_ = wp.Close()
+		if cb != nil {
+			cb()
+		}
+		return nil
+	}
 	m := index.Message{
 		LocalWriter: writer,
 		Value: index.Value{
 			TagFamilies: value.GetTagFamilies(),
 			Timestamp:   value.GetTimestamp().AsTime(),
 		},
+		Overwritten: overwritten,
 		BlockCloser: wp,
 		Cb:          cb,
 	}
+	if s.indexWriter.StoresPayload() {
+		if m.Value.Payload, err = proto.Marshal(value); err != nil {
+			_ = wp.Close()
+			return err
+		}
+	}
+	if versioned {
+		s.indexWriter.WriteSync(m)
+		return nil
+	}
 	s.indexWriter.Write(m)
 	return err
 }
@@ -180,18 +262,70 @@ func (w *writeCallback) Rev(message bus.Message) (resp bus.Message) {
 		return
 	}
 
-	stm, ok := w.schemaRepo.loadMeasure(writeEvent.GetRequest().GetMetadata())
-	if !ok {
-		w.l.Warn().Msg("cannot find measure definition")
+	stm, err := w.schemaRepo.fetchMeasure(writeEvent.GetRequest().GetMetadata())
+	if err != nil {
+		w.l.Warn().Err(err).Msg("cannot find measure definition")
 		return
 	}
-	err := stm.write(common.ShardID(writeEvent.GetShardId()), writeEvent.GetSeriesHash(), writeEvent.GetRequest().GetDataPoint(), nil)
+	err = stm.write(common.ShardID(writeEvent.GetShardId()), writeEvent.GetSeriesHash(), writeEvent.GetRequest().GetDataPoint(), nil)
 	if err != nil {
 		w.l.Debug().Err(err).Msg("fail to write entity")
 	}
 	return
 }
 
+// storedPoint is the data point stored at the series and timestamp of a written one
+type storedPoint struct {
+	// version is zero if the data point doesn't have a version
+	version int64
+	// tagFamilies are nil if no data point is stored
+	tagFamilies []*modelv1.TagFamilyForWrite
+}
+
+// loadStored reads the version and the tag families of the stored data point of the item
+func (s *measure) loadStored(series tsdb.Series, id tsdb.GlobalItemID) (stored storedPoint, err error) {
+	item, closer, err := series.Get(id)
+	if err != nil {
+		return stored, err
+	}
+	defer func() {
+		_ = closer.Close()
+	}()
+	// an absent family reads as empty
+	family := func(name []byte) ([]byte, error) {
+		raw, errFamily := item.Family(string(name))
+		if errors.Is(errFamily, kv.ErrKeyNotFound) {
+			return nil, nil
+		}
+		return raw, errFamily
+	}
+	raw, err := family(versionFamily)
+	if err != nil {
+		return stored, err
+	}
+	if len(raw) == 8 {
+		stored.version = convert.BytesToInt64(raw)
+	}
+	tagFamilies := make([]*modelv1.TagFamilyForWrite, 0, len(s.schema.GetTagFamilies()))
+	var found bool
+	for _, familySpec := range s.schema.GetTagFamilies() {
+		raw, err = family(familyIdentity(familySpec.GetName(), TagFlag))
+		if err != nil {
+			return stored, err
+		}
+		tagFamily := &modelv1.TagFamilyForWrite{}
+		if err = proto.Unmarshal(raw, tagFamily); err != nil {
+			return stored, err
+		}
+		found = found || len(raw) > 0
+		tagFamilies = append(tagFamilies, tagFamily)
+	}
+	if found {
+		stored.tagFamilies = tagFamilies
+	}
+	return stored, nil
+}
+
 func familyIdentity(name string, flag byte) []byte {
 	return bytes.Join([][]byte{[]byte(name), {flag}}, nil)
 }
@@ -204,11 +338,18 @@ func encodeFieldValue(fieldValue *modelv1.FieldValue) []byte {
 		return []byte(fieldValue.GetStr().Value)
 	case *modelv1.FieldValue_BinaryData:
 		return fieldValue.GetBinaryData()
+	case *modelv1.FieldValue_Float:
+		return convert.Float64ToBytes(fieldValue.GetFloat().GetValue())
 	}
 	return nil
 }
 
 func decodeFieldValue(fieldValue []byte, fieldSpec *databasev1.FieldSpec) *modelv1.FieldValue {
+	if len(fieldValue) == 0 && fieldSpec.GetFieldType() != databasev1.FieldType_FIELD_TYPE_STRING &&
+		fieldSpec.GetFieldType() != databasev1.FieldType_FIELD_TYPE_DATA_BINARY {
+		// a numeric field is cleared by a newer version
+		return &modelv1.FieldValue{Value: &modelv1.FieldValue_Null{}}
+	}
 	switch fieldSpec.GetFieldType() {
 	case databasev1.FieldType_FIELD_TYPE_STRING:
 		return &modelv1.FieldValue{Value: &modelv1.FieldValue_Str{Str: &modelv1.Str{Value: string(fieldValue)}}}
@@ -216,6 +357,8 @@ func decodeFieldValue(fieldValue []byte, fieldSpec *databasev1.FieldSpec) *model
 		return &modelv1.FieldValue{Value: &modelv1.FieldValue_Int{Int: &modelv1.Int{Value: convert.BytesToInt64(fieldValue)}}}
 	case databasev1.FieldType_FIELD_TYPE_DATA_BINARY:
 		return &modelv1.FieldValue{Value: &modelv1.FieldValue_BinaryData{BinaryData: fieldValue}}
+	case databasev1.FieldType_FIELD_TYPE_FLOAT:
+		return &modelv1.FieldValue{Value: &modelv1.FieldValue_Float{Float: &modelv1.Float{Value: convert.BytesToFloat64(fieldValue)}}}
 	}
 	return &modelv1.FieldValue{Value: &modelv1.FieldValue_Null{}}
 }
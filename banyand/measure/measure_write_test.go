@@ -20,6 +20,7 @@ package measure_test
 import (
 	"embed"
 	"encoding/json"
+	"sync"
 	"time"
 
 	"github.com/golang/protobuf/jsonpb"
@@ -28,8 +29,14 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
 	measurev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/measure/v1"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
 	"github.com/apache/skywalking-banyandb/banyand/measure"
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+	pbv1 "github.com/apache/skywalking-banyandb/pkg/pb/v1"
+	"github.com/apache/skywalking-banyandb/pkg/timestamp"
 )
 
 var _ = Describe("Write", func() {
@@ -52,8 +59,132 @@ var _ = Describe("Write", func() {
 	It("writes data", func() {
 		writeData("write_data.json", measure)
 	})
+	It("keeps the data point with the highest version", func() {
+		ts := time.Now()
+		write := func(version, value int64) {
+			req, err := pbv1.NewMeasureWriteRequestBuilder().
+				Timestamp(ts).
+				TagFamily("1", "minute").
+				Fields(value, value, value).
+				Version(version).
+				Build()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(measure.Write(req.GetDataPoint())).Should(Succeed())
+		}
+		write(2, 200)
+		write(1, 100)
+		write(0, 50)
+		Expect(queryValue(measure, ts)).To(Equal(int64(200)))
+		write(3, 300)
+		Expect(queryValue(measure, ts)).To(Equal(int64(300)))
+		write(3, 301)
+		Expect(queryValue(measure, ts)).To(Equal(int64(301)))
+
+		// the concurrent writes to the same data point still keep the highest version
+		var wg sync.WaitGroup
+		for v := int64(4); v < 20; v++ {
+			wg.Add(1)
+			go func(version int64) {
+				defer wg.Done()
+				defer GinkgoRecover()
+				write(version, version*100)
+			}(v)
+		}
+		wg.Wait()
+		Expect(queryValue(measure, ts)).To(Equal(int64(1900)))
+	})
+	It("clears the fields and tags a newer version leaves out", func() {
+		ts := time.Now()
+		req, err := pbv1.NewMeasureWriteRequestBuilder().
+			Timestamp(ts).
+			TagFamily("1", "minute").
+			Fields(100, 100, 100).
+			Version(1).
+			Build()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(measure.Write(req.GetDataPoint())).Should(Succeed())
+		Expect(queryByScope(measure, ts, "minute")).To(HaveLen(1))
+
+		req, err = pbv1.NewMeasureWriteRequestBuilder().
+			Timestamp(ts).
+			TagFamily("1", "hour").
+			Fields(200, 200, nil).
+			Version(2).
+			Build()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(measure.Write(req.GetDataPoint())).Should(Succeed())
+		Expect(queryField(measure, ts).GetNull()).NotTo(BeNil())
+		Expect(queryByScope(measure, ts, "minute")).To(BeEmpty())
+		Expect(queryByScope(measure, ts, "hour")).To(HaveLen(1))
+	})
 })
 
+func queryValue(measure measure.Measure, ts time.Time) int64 {
+	return queryField(measure, ts).GetInt().GetValue()
+}
+
+// queryByScope returns the values of the data points whose scope is the given one
+func queryByScope(measure measure.Measure, ts time.Time, scope string) []*modelv1.FieldValue {
+	rule := &databasev1.IndexRule{
+		Metadata: &commonv1.Metadata{
+			Name:  "scope",
+			Group: "default",
+			Id:    1,
+		},
+		Tags:     []string{"scope"},
+		Type:     databasev1.IndexRule_TYPE_INVERTED,
+		Location: databasev1.IndexRule_LOCATION_SERIES,
+	}
+	return queryValues(measure, ts, func(builder tsdb.SeekerBuilder) {
+		builder.Filter(rule, tsdb.Condition{
+			"scope": []index.ConditionValue{
+				{
+					Op:     modelv1.Condition_BINARY_OP_EQ,
+					Values: [][]byte{[]byte(scope)},
+				},
+			},
+		})
+	})
+}
+
+func queryField(measure measure.Measure, ts time.Time) *modelv1.FieldValue {
+	values := queryValues(measure, ts, nil)
+	Expect(values).To(HaveLen(1))
+	return values[0]
+}
+
+// queryValues returns the field "value" of the data points of the series "1" from ts on
+func queryValues(measure measure.Measure, ts time.Time, filter func(tsdb.SeekerBuilder)) (values []*modelv1.FieldValue) {
+	entity := tsdb.Entity{tsdb.Entry("1")}
+	shards, err := measure.Shards(entity)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(shards).To(HaveLen(1))
+	series, err := shards[0].Series().Get(entity)
+	Expect(err).ShouldNot(HaveOccurred())
+	seriesSpan, err := series.Span(timestamp.NewInclusiveTimeRangeDuration(ts, time.Hour))
+	Expect(err).ShouldNot(HaveOccurred())
+	defer func() {
+		_ = seriesSpan.Close()
+	}()
+	builder := seriesSpan.SeekerBuilder().OrderByTime(modelv1.Sort_SORT_DESC)
+	if filter != nil {
+		filter(builder)
+	}
+	seeker, err := builder.Build()
+	Expect(err).ShouldNot(HaveOccurred())
+	iter, err := seeker.Seek()
+	Expect(err).ShouldNot(HaveOccurred())
+	for _, it := range iter {
+		for it.Next() {
+			value, errParse := measure.ParseField("value", it.Val())
+			Expect(errParse).ShouldNot(HaveOccurred())
+			values = append(values, value.GetValue())
+		}
+		_ = it.Close()
+	}
+	return values
+}
+
 //go:embed testdata/*.json
 var dataFS embed.FS
 
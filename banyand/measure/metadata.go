@@ -21,6 +21,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/apache/skywalking-banyandb/api/event"
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
@@ -30,6 +32,7 @@ import (
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/pkg/encoding"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
 	resourceSchema "github.com/apache/skywalking-banyandb/pkg/schema"
 )
 
@@ -39,7 +42,7 @@ type schemaRepo struct {
 	metadata metadata.Repo
 }
 
-func newSchemaRepo(path string, metadata metadata.Repo, repo discovery.ServiceRepo, l *logger.Logger) schemaRepo {
+func newSchemaRepo(path string, metadata metadata.Repo, repo discovery.ServiceRepo, observer meter.MetricsObserver, l *logger.Logger) schemaRepo {
 	return schemaRepo{
 		l:        l,
 		metadata: metadata,
@@ -47,7 +50,8 @@ func newSchemaRepo(path string, metadata metadata.Repo, repo discovery.ServiceRe
 			metadata,
 			repo,
 			l,
-			newSupplier(path, metadata, l),
+			newSupplier(path, metadata, observer, l),
+			commonv1.Catalog_CATALOG_MEASURE,
 			event.MeasureTopicShardEvent,
 			event.MeasureTopicEntityEvent,
 		),
@@ -155,6 +159,23 @@ func (sr *schemaRepo) OnDelete(m schema.Metadata) {
 	}
 }
 
+// fetchMeasure fetches the measure from the registry if it's missing in the repository,
+// whose registration might not be handled yet
+func (sr *schemaRepo) fetchMeasure(metadata *commonv1.Metadata) (*measure, error) {
+	if m, ok := sr.loadMeasure(metadata); ok {
+		return m, nil
+	}
+	r, err := sr.FetchResource(metadata)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := r.(*measure)
+	if !ok {
+		return nil, errors.Errorf("%s/%s isn't a measure", metadata.GetGroup(), metadata.GetName())
+	}
+	return m, nil
+}
+
 func (sr *schemaRepo) loadMeasure(metadata *commonv1.Metadata) (*measure, bool) {
 	r, ok := sr.LoadResource(metadata)
 	if !ok {
@@ -169,13 +190,15 @@ var _ resourceSchema.ResourceSupplier = (*supplier)(nil)
 type supplier struct {
 	path     string
 	metadata metadata.Repo
+	observer meter.MetricsObserver
 	l        *logger.Logger
 }
 
-func newSupplier(path string, metadata metadata.Repo, l *logger.Logger) *supplier {
+func newSupplier(path string, metadata metadata.Repo, observer meter.MetricsObserver, l *logger.Logger) *supplier {
 	return &supplier{
 		path:     path,
 		metadata: metadata,
+		observer: observer,
 		l:        l,
 	}
 }
@@ -183,8 +206,11 @@ func newSupplier(path string, metadata metadata.Repo, l *logger.Logger) *supplie
 func (s *supplier) OpenResource(shardNum uint32, db tsdb.Supplier, spec resourceSchema.ResourceSpec) (resourceSchema.Resource, error) {
 	measureSchema := spec.Schema.(*databasev1.Measure)
 	return openMeasure(shardNum, db, measureSpec{
-		schema:     measureSchema,
-		indexRules: spec.IndexRules,
+		schema:         measureSchema,
+		indexRules:     spec.IndexRules,
+		strictIndexing: spec.StrictIndexing,
+		utf8Policy:     spec.UTF8Policy,
+		observer:       s.observer,
 	}, s.l)
 }
 func (s *supplier) ResourceSchema(repo metadata.Repo, md *commonv1.Metadata) (resourceSchema.ResourceSchema, error) {
@@ -193,6 +219,20 @@ func (s *supplier) ResourceSchema(repo metadata.Repo, md *commonv1.Metadata) (re
 	return s.metadata.MeasureRegistry().GetMeasure(ctx, md)
 }
 
+func (s *supplier) ResourceSchemas(repo metadata.Repo, group string) ([]resourceSchema.ResourceSchema, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	schemas, err := s.metadata.MeasureRegistry().ListMeasure(ctx, schema.ListOpt{Group: group})
+	if err != nil {
+		return nil, err
+	}
+	rr := make([]resourceSchema.ResourceSchema, 0, len(schemas))
+	for _, sm := range schemas {
+		rr = append(rr, sm)
+	}
+	return rr, nil
+}
+
 func (s *supplier) OpenDB(groupSchema *commonv1.Group) (tsdb.Database, error) {
 	return tsdb.OpenDatabase(
 		context.TODO(),
@@ -203,5 +243,6 @@ func (s *supplier) OpenDB(groupSchema *commonv1.Group) (tsdb.Database, error) {
 				EncoderPool: encoding.NewPlainEncoderPool(chunkSize),
 				DecoderPool: encoding.NewPlainDecoderPool(chunkSize),
 			},
+			MetricsObserver: s.observer,
 		})
 }
@@ -31,6 +31,7 @@ import (
 	"github.com/apache/skywalking-banyandb/banyand/metadata/schema"
 	"github.com/apache/skywalking-banyandb/banyand/queue"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
 	"github.com/apache/skywalking-banyandb/pkg/run"
 	resourceSchema "github.com/apache/skywalking-banyandb/pkg/schema"
 )
@@ -57,6 +58,8 @@ type service struct {
 	root          string
 	pipeline      queue.Queue
 	repo          discovery.ServiceRepo
+	// observer receives the statistics of the databases if it's present
+	observer meter.MetricsObserver
 	// stop channel for the service
 	stopCh chan struct{}
 }
@@ -97,7 +100,7 @@ func (s *service) PreRun() error {
 	if err != nil {
 		return err
 	}
-	s.schemaRepo = newSchemaRepo(path.Join(s.root, s.Name()), s.metadata, s.repo, s.l)
+	s.schemaRepo = newSchemaRepo(path.Join(s.root, s.Name()), s.metadata, s.repo, s.observer, s.l)
 	for _, g := range groups {
 		if g.Catalog != commonv1.Catalog_CATALOG_MEASURE {
 			continue
@@ -148,8 +151,9 @@ func (s *service) GracefulStop() {
 }
 
 // NewService returns a new service
-func NewService(_ context.Context, metadata metadata.Repo, repo discovery.ServiceRepo, pipeline queue.Queue) (Service, error) {
+func NewService(ctx context.Context, metadata metadata.Repo, repo discovery.ServiceRepo, pipeline queue.Queue) (Service, error) {
 	return &service{
+		observer: meter.Fetch(ctx),
 		metadata: metadata,
 		repo:     repo,
 		pipeline: pipeline,
@@ -32,18 +32,30 @@ import (
 
 // IndexFilter provides methods to find a specific index related objects and vice versa
 type IndexFilter interface {
-	// IndexRules fetches v1.IndexRule by subject defined in IndexRuleBinding
+	// IndexRules fetches v1.IndexRule by subject defined in IndexRuleBinding, whose analyzer is the effective one
 	IndexRules(ctx context.Context, subject *commonv1.Metadata) ([]*databasev1.IndexRule, error)
 	// Subjects fetches Subject(s) by index rule
 	Subjects(ctx context.Context, indexRule *databasev1.IndexRule, catalog commonv1.Catalog) ([]schema.Spec, error)
 }
 
+// MeasureDescription is the effective schema of a measure
+type MeasureDescription struct {
+	Measure *databasev1.Measure
+	// IndexRuleBindings are the active bindings whose subject is the measure
+	IndexRuleBindings []*databasev1.IndexRuleBinding
+	// IndexRules are the rules referenced by IndexRuleBindings without duplicates
+	IndexRules []*databasev1.IndexRule
+}
+
 type Repo interface {
 	IndexFilter
+	// DescribeMeasure fetches a measure along with the index rules and bindings applying to it
+	DescribeMeasure(ctx context.Context, metadata *commonv1.Metadata) (MeasureDescription, error)
 	StreamRegistry() schema.Stream
 	IndexRuleRegistry() schema.IndexRule
 	IndexRuleBindingRegistry() schema.IndexRuleBinding
 	MeasureRegistry() schema.Measure
+	DownsamplingRuleRegistry() schema.DownsamplingRule
 	GroupRegistry() schema.Group
 }
 
@@ -55,6 +67,9 @@ type Service interface {
 	SchemaRegistry() schema.Registry
 }
 
+// shutdownTimeout bounds the time to drain the in-flight schema writes
+const shutdownTimeout = 10 * time.Second
+
 type service struct {
 	schemaRegistry schema.Registry
 	rootDir        string
@@ -89,7 +104,9 @@ func (s *service) Serve() run.StopNotify {
 }
 
 func (s *service) GracefulStop() {
-	_ = s.schemaRegistry.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	_ = s.schemaRegistry.Shutdown(ctx)
 	<-s.schemaRegistry.StopNotify()
 }
 
@@ -117,6 +134,10 @@ func (s *service) MeasureRegistry() schema.Measure {
 	return s.schemaRegistry
 }
 
+func (s *service) DownsamplingRuleRegistry() schema.DownsamplingRule {
+	return s.schemaRegistry
+}
+
 func (s *service) GroupRegistry() schema.Group {
 	return s.schemaRegistry
 }
@@ -154,6 +175,13 @@ func (s *service) IndexRules(ctx context.Context, subject *commonv1.Metadata) ([
 			indexRuleErr = multierr.Append(indexRuleErr, err)
 			continue
 		}
+		// the rules serve the indexers, so they carry the effective analyzer instead of the stored one
+		analyzer, analyzerErr := s.schemaRegistry.EffectiveAnalyzer(ctx, r)
+		if analyzerErr != nil {
+			indexRuleErr = multierr.Append(indexRuleErr, analyzerErr)
+			continue
+		}
+		r.Analyzer = analyzer
 		result = append(result, r)
 
 	}
@@ -208,6 +236,49 @@ func (s *service) Subjects(ctx context.Context, indexRule *databasev1.IndexRule,
 	return foundSubjects, subjectErr
 }
 
+func (s *service) DescribeMeasure(ctx context.Context, metadata *commonv1.Metadata) (MeasureDescription, error) {
+	var desc MeasureDescription
+	measure, err := s.schemaRegistry.GetMeasure(ctx, metadata)
+	if err != nil {
+		return desc, err
+	}
+	desc.Measure = measure
+	bindings, err := s.schemaRegistry.ListIndexRuleBinding(ctx, schema.ListOpt{Group: metadata.GetGroup()})
+	if err != nil {
+		return desc, err
+	}
+	now := time.Now()
+	seen := make(map[string]struct{})
+	var ruleErr error
+	for _, binding := range bindings {
+		if binding.GetBeginAt().AsTime().After(now) ||
+			binding.GetExpireAt().AsTime().Before(now) {
+			continue
+		}
+		sub := binding.GetSubject()
+		if sub.GetCatalog() != commonv1.Catalog_CATALOG_MEASURE || sub.GetName() != metadata.GetName() {
+			continue
+		}
+		desc.IndexRuleBindings = append(desc.IndexRuleBindings, binding)
+		for _, rule := range binding.GetRules() {
+			if _, ok := seen[rule]; ok {
+				continue
+			}
+			seen[rule] = struct{}{}
+			r, getErr := s.schemaRegistry.GetIndexRule(ctx, &commonv1.Metadata{
+				Name:  rule,
+				Group: metadata.GetGroup(),
+			})
+			if getErr != nil {
+				ruleErr = multierr.Append(ruleErr, getErr)
+				continue
+			}
+			desc.IndexRules = append(desc.IndexRules, r)
+		}
+	}
+	return desc, ruleErr
+}
+
 func contains(s []string, e string) bool {
 	for _, a := range s {
 		if a == e {
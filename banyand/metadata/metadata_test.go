@@ -26,6 +26,7 @@ import (
 
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	"github.com/apache/skywalking-banyandb/pkg/test/measure"
 	test "github.com/apache/skywalking-banyandb/pkg/test/stream"
 )
 
@@ -117,3 +118,28 @@ func createSubject(name, group string) *commonv1.Metadata {
 		Name:  name,
 	}
 }
+
+func Test_service_DescribeMeasure(t *testing.T) {
+	is := assert.New(t)
+	ctx := context.TODO()
+	s, _ := NewService(ctx)
+	is.NotNil(s)
+	rootDir := measure.RandomTempDir()
+	is.NoError(s.FlagSet().Parse([]string{"--metadata-root-path=" + rootDir}))
+	is.NoError(s.PreRun())
+	defer func() {
+		_ = os.RemoveAll(rootDir)
+	}()
+	is.NoError(measure.PreloadSchema(s.SchemaRegistry()))
+
+	desc, err := s.DescribeMeasure(ctx, createSubject("cpm", "default"))
+	is.NoError(err)
+	is.Equal("cpm", desc.Measure.GetMetadata().GetName())
+	is.Len(desc.IndexRuleBindings, 1)
+	is.Equal("cpm-index-rule-binding", desc.IndexRuleBindings[0].GetMetadata().GetName())
+	is.Len(desc.IndexRules, 1)
+	is.Equal("scope", desc.IndexRules[0].GetMetadata().GetName())
+
+	_, err = s.DescribeMeasure(ctx, createSubject("absent", "default"))
+	is.Error(err)
+}
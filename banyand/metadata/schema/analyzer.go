@@ -0,0 +1,90 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+var ErrUnknownAnalyzer = errors.New("the analyzer is unknown")
+
+// defaultAnalyzer returns the analyzer which the index rules of the group inherit
+func defaultAnalyzer(group *commonv1.Group) (databasev1.IndexRule_Analyzer, error) {
+	name := group.GetResourceOpts().GetDefaultAnalyzer()
+	if name == "" {
+		return databasev1.IndexRule_ANALYZER_UNSPECIFIED, nil
+	}
+	analyzer, ok := databasev1.IndexRule_Analyzer_value[name]
+	if !ok {
+		return databasev1.IndexRule_ANALYZER_UNSPECIFIED,
+			errors.Wrapf(ErrUnknownAnalyzer, "%s of group %s", name, group.GetMetadata().GetName())
+	}
+	return databasev1.IndexRule_Analyzer(analyzer), nil
+}
+
+// EffectiveAnalyzer returns the analyzer of the rule, or the default analyzer of its group if the rule leaves it unspecified.
+// The stored rule is never changed, so that it keeps following the default of the group.
+func (e *etcdSchemaRegistry) EffectiveAnalyzer(ctx context.Context, rule *databasev1.IndexRule) (databasev1.IndexRule_Analyzer, error) {
+	if rule.GetAnalyzer() != databasev1.IndexRule_ANALYZER_UNSPECIFIED {
+		return rule.GetAnalyzer(), nil
+	}
+	group, err := e.GetGroup(ctx, rule.GetMetadata().GetGroup())
+	switch {
+	case errors.Is(err, ErrEntityNotFound):
+		// the group is being deleted along with the rule
+		return databasev1.IndexRule_ANALYZER_UNSPECIFIED, nil
+	case err != nil:
+		return databasev1.IndexRule_ANALYZER_UNSPECIFIED, err
+	}
+	return defaultAnalyzer(group)
+}
+
+// notifyInheritingIndexRules emits the update events of the rules which inherit the default analyzer of the group,
+// so that the indexers rebuild them once the default changes. The events carry the stored rules as they are.
+func (e *etcdSchemaRegistry) notifyInheritingIndexRules(ctx context.Context, group string) error {
+	messages, revision, err := e.listWithPrefixSince(ctx, e.keyLayout.listPrefixesForEntity(group, e.keyLayout.IndexRuleKeyPrefix), 0,
+		func() proto.Message {
+			return &databasev1.IndexRule{}
+		})
+	if err != nil {
+		return err
+	}
+	var notifyErr error
+	for _, message := range messages {
+		rule := message.(*databasev1.IndexRule)
+		if rule.GetAnalyzer() != databasev1.IndexRule_ANALYZER_UNSPECIFIED {
+			continue
+		}
+		notifyErr = multierr.Append(notifyErr, e.notifyUpdate(Metadata{
+			TypeMeta: TypeMeta{
+				Kind:  KindIndexRule,
+				Name:  rule.GetMetadata().GetName(),
+				Group: group,
+			},
+			Spec: rule,
+		}, revision))
+	}
+	return notifyErr
+}
@@ -0,0 +1,143 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+var _ EventHandler = (*analyzerRecorder)(nil)
+
+type analyzerRecorder struct {
+	mu        sync.Mutex
+	analyzers map[string]databasev1.IndexRule_Analyzer
+}
+
+func (r *analyzerRecorder) OnAddOrUpdate(metadata Metadata) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.analyzers[metadata.Name] = metadata.Spec.(*databasev1.IndexRule).GetAnalyzer()
+}
+
+func (r *analyzerRecorder) OnDelete(Metadata) {}
+
+func updateGroupWithAnalyzer(registry Registry, name, analyzer string) error {
+	return registry.UpdateGroup(context.TODO(), &commonv1.Group{
+		Metadata: &commonv1.Metadata{Name: name},
+		Catalog:  commonv1.Catalog_CATALOG_STREAM,
+		ResourceOpts: &commonv1.ResourceOpts{
+			ShardNum:        2,
+			DefaultAnalyzer: analyzer,
+		},
+	})
+}
+
+func Test_Etcd_DefaultAnalyzer(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	ctx := context.TODO()
+
+	req.True(errors.Is(updateGroupWithAnalyzer(registry, "g1", "ANALYZER_UNKNOWN"), ErrUnknownAnalyzer))
+	req.NoError(updateGroupWithAnalyzer(registry, "g1", "ANALYZER_STANDARD"))
+	for name, analyzer := range map[string]databasev1.IndexRule_Analyzer{
+		"inherited": databasev1.IndexRule_ANALYZER_UNSPECIFIED,
+		"explicit":  databasev1.IndexRule_ANALYZER_WHITESPACE,
+	} {
+		req.NoError(registry.UpdateIndexRule(ctx, &databasev1.IndexRule{
+			Metadata: &commonv1.Metadata{Name: name, Group: "g1"},
+			Tags:     []string{name},
+			Type:     databasev1.IndexRule_TYPE_INVERTED,
+			Location: databasev1.IndexRule_LOCATION_SERIES,
+			Analyzer: analyzer,
+		}))
+	}
+	// the rules are read as they are stored
+	rule, err := registry.GetIndexRule(ctx, &commonv1.Metadata{Name: "inherited", Group: "g1"})
+	req.NoError(err)
+	req.Equal(databasev1.IndexRule_ANALYZER_UNSPECIFIED, rule.GetAnalyzer())
+	rules, err := registry.ListIndexRule(ctx, ListOpt{Group: "g1"})
+	req.NoError(err)
+	req.Len(rules, 2)
+	for _, r := range rules {
+		analyzer, innerErr := registry.EffectiveAnalyzer(ctx, r)
+		req.NoError(innerErr)
+		if r.GetMetadata().GetName() == "explicit" {
+			req.Equal(databasev1.IndexRule_ANALYZER_WHITESPACE, analyzer)
+		} else {
+			req.Equal(databasev1.IndexRule_ANALYZER_UNSPECIFIED, r.GetAnalyzer())
+			req.Equal(databasev1.IndexRule_ANALYZER_STANDARD, analyzer)
+		}
+	}
+
+	// changing the default rebuilds the rules inheriting it only
+	recorder := &analyzerRecorder{analyzers: make(map[string]databasev1.IndexRule_Analyzer)}
+	registry.RegisterHandler(KindIndexRule, recorder)
+	req.NoError(updateGroupWithAnalyzer(registry, "g1", "ANALYZER_KEYWORD"))
+	req.Equal(map[string]databasev1.IndexRule_Analyzer{
+		"inherited": databasev1.IndexRule_ANALYZER_UNSPECIFIED,
+	}, recorder.analyzers)
+	rule, err = registry.GetIndexRule(ctx, &commonv1.Metadata{Name: "inherited", Group: "g1"})
+	req.NoError(err)
+	analyzer, err := registry.EffectiveAnalyzer(ctx, rule)
+	req.NoError(err)
+	req.Equal(databasev1.IndexRule_ANALYZER_KEYWORD, analyzer)
+}
+
+func Test_Etcd_DefaultAnalyzerRoundTrip(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	ctx := context.TODO()
+
+	req.NoError(updateGroupWithAnalyzer(registry, "g1", "ANALYZER_STANDARD"))
+	md := &commonv1.Metadata{Name: "inherited", Group: "g1"}
+	req.NoError(registry.UpdateIndexRule(ctx, &databasev1.IndexRule{
+		Metadata: md,
+		Tags:     []string{"inherited"},
+		Type:     databasev1.IndexRule_TYPE_INVERTED,
+		Location: databasev1.IndexRule_LOCATION_SERIES,
+	}))
+	// a read-modify-write keeps the rule inheriting the default
+	rule, err := registry.GetIndexRule(ctx, md)
+	req.NoError(err)
+	rule.StorePayload = true
+	req.NoError(registry.UpdateIndexRule(ctx, rule))
+
+	recorder := &analyzerRecorder{analyzers: make(map[string]databasev1.IndexRule_Analyzer)}
+	registry.RegisterHandler(KindIndexRule, recorder)
+	req.NoError(updateGroupWithAnalyzer(registry, "g1", "ANALYZER_WHITESPACE"))
+	req.Contains(recorder.analyzers, "inherited")
+	rule, err = registry.GetIndexRule(ctx, md)
+	req.NoError(err)
+	req.True(rule.GetStorePayload())
+	req.Equal(databasev1.IndexRule_ANALYZER_UNSPECIFIED, rule.GetAnalyzer())
+	analyzer, err := registry.EffectiveAnalyzer(ctx, rule)
+	req.NoError(err)
+	req.Equal(databasev1.IndexRule_ANALYZER_WHITESPACE, analyzer)
+}
@@ -0,0 +1,280 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+var (
+	ErrInvalidBatch = errors.New("the batch is invalid")
+	ErrSpecMismatch = errors.New("the spec doesn't match the kind")
+)
+
+// Batch creates or updates a set of entities all at once
+type Batch interface {
+	// ApplyBatch validates the whole batch before committing it in a single transaction.
+	// The references are resolved against the batch first, so a binding could refer to the rules created in the same batch.
+	// The returned error combines all the violations. Nothing is applied if any of them exists.
+	ApplyBatch(ctx context.Context, entities []Metadata) error
+}
+
+type batchEntry struct {
+	Metadata
+	key string
+	val []byte
+	// modRevision is zero if the entity is absent
+	modRevision int64
+}
+
+func (e *etcdSchemaRegistry) ApplyBatch(ctx context.Context, entities []Metadata) error {
+	if err := e.gate.enter(); err != nil {
+		return err
+	}
+	defer e.gate.leave()
+	if len(entities) < 1 {
+		return nil
+	}
+	entries, err := e.loadBatch(ctx, entities)
+	if err != nil {
+		return err
+	}
+	refs, err := e.validateBatch(ctx, entries)
+	if err != nil {
+		return err
+	}
+	cmps := make([]clientv3.Cmp, 0, len(entries)+len(refs))
+	ops := make([]clientv3.Op, 0, len(entries))
+	for _, entry := range entries {
+		// the entities are expected to stay as they were validated
+		cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(entry.key), "=", entry.modRevision))
+		ops = append(ops, clientv3.OpPut(entry.key, string(entry.val)))
+	}
+	for _, ref := range refs {
+		// the referenced entities out of the batch must not be deleted before committing
+		cmps = append(cmps, clientv3.Compare(clientv3.CreateRevision(ref), ">", 0))
+	}
+	resp, err := e.kv.Txn(ctx).If(cmps...).Then(ops...).Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return ErrConcurrentModification
+	}
+	var notifyErr error
+	for _, entry := range entries {
+		notifyErr = multierr.Append(notifyErr, e.notifyUpdate(entry.Metadata, resp.Header.GetRevision()))
+	}
+	return notifyErr
+}
+
+func (e *etcdSchemaRegistry) loadBatch(ctx context.Context, entities []Metadata) ([]*batchEntry, error) {
+	entries := make([]*batchEntry, 0, len(entities))
+	var errs []error
+	seen := make(map[string]struct{}, len(entities))
+	now := time.Now()
+	for _, md := range entities {
+		key, err := e.keyLayout.Key(md)
+		if err != nil {
+			errs = append(errs, errors.WithMessagef(err, "%s/%s", md.Group, md.Name))
+			continue
+		}
+		if err = checkSpec(md); err != nil {
+			errs = append(errs, errors.WithMessagef(err, "key %s", key))
+			continue
+		}
+		if md.Kind == KindIndexRule {
+			if err = checkIndexRule(md.Spec.(*databasev1.IndexRule)); err != nil {
+				errs = append(errs, errors.WithMessagef(err, "key %s", key))
+				continue
+			}
+		}
+		// etcd rejects a transaction with duplicated keys
+		if _, ok := seen[key]; ok {
+			errs = append(errs, errors.Wrapf(ErrInvalidBatch, "duplicated key %s", key))
+			continue
+		}
+		seen[key] = struct{}{}
+		entry := &batchEntry{key: key}
+		getResp, err := e.kv.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		var existing proto.Message
+		if getResp.Count > 0 {
+			entry.modRevision = getResp.Kvs[0].ModRevision
+			existingRaw, innerErr := verifyChecksum(getResp.Kvs[0].Key, getResp.Kvs[0].Value)
+			if innerErr != nil {
+				return nil, innerErr
+			}
+			existing, innerErr = md.Unmarshal(existingRaw)
+			if innerErr != nil {
+				return nil, innerErr
+			}
+			assignRevisions(existing, getResp.Kvs[0])
+			if innerErr = e.checkCompatibility(ctx, md, existing); innerErr != nil {
+				errs = append(errs, errors.WithMessagef(innerErr, "key %s", key))
+				continue
+			}
+			md.PrevSpec = existing
+		}
+		md.Spec = stampTime(md.Spec.(proto.Message), existing, now)
+		entry.Metadata = md
+		if entry.val, err = e.marshal(md.Spec.(proto.Message)); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if len(errs) > 0 {
+		return nil, multierr.Combine(errs...)
+	}
+	return entries, nil
+}
+
+func checkSpec(md Metadata) error {
+	spec, ok := md.Spec.(proto.Message)
+	if !ok {
+		return ErrSpecMismatch
+	}
+	want, err := md.TypeMeta.Unmarshal(nil)
+	if err != nil {
+		return err
+	}
+	if spec.ProtoReflect().Descriptor().FullName() != want.ProtoReflect().Descriptor().FullName() {
+		return errors.Wrapf(ErrSpecMismatch, "%s is not %s", spec.ProtoReflect().Descriptor().FullName(),
+			want.ProtoReflect().Descriptor().FullName())
+	}
+	return nil
+}
+
+// validateBatch resolves the references of the entities. It returns the keys of the referenced entities out of the batch.
+func (e *etcdSchemaRegistry) validateBatch(ctx context.Context, entries []*batchEntry) ([]string, error) {
+	inBatch := make(map[string]proto.Message, len(entries))
+	for _, entry := range entries {
+		inBatch[entry.key] = entry.Spec.(proto.Message)
+	}
+	resolved := make(map[string]bool)
+	var refs []string
+	var errs []error
+	resolve := func(key string) (bool, error) {
+		if _, ok := inBatch[key]; ok {
+			return true, nil
+		}
+		if found, ok := resolved[key]; ok {
+			return found, nil
+		}
+		resp, err := e.kv.Get(ctx, key, clientv3.WithCountOnly())
+		if err != nil {
+			return false, err
+		}
+		found := resp.Count > 0
+		resolved[key] = found
+		if found {
+			refs = append(refs, key)
+		}
+		return found, nil
+	}
+	for _, entry := range entries {
+		deps, err := e.dependencies(entry)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if entry.Kind == KindIndexRuleBinding {
+			if err = e.checkSubjectKind(ctx, entry.Spec.(*databasev1.IndexRuleBinding), inBatch); err != nil {
+				errs = append(errs, errors.WithMessagef(err, "key %s", entry.key))
+				continue
+			}
+		}
+		for _, dep := range deps {
+			found, err := resolve(dep.key)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				errs = append(errs, dep.err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, multierr.Combine(errs...)
+	}
+	return refs, nil
+}
+
+type dependency struct {
+	key string
+	// err is reported if the dependency is absent
+	err error
+}
+
+func (e *etcdSchemaRegistry) dependencies(entry *batchEntry) ([]dependency, error) {
+	switch entry.Kind {
+	case KindRetentionPolicy:
+		return nil, nil
+	case KindGroupAlias:
+		return nil, errors.Wrapf(ErrInvalidBatch, "alias %s has to be created by CreateGroupAlias", entry.key)
+	case KindGroup:
+		policy := entry.Spec.(*commonv1.Group).GetResourceOpts().GetRetentionPolicy()
+		if policy == "" {
+			return nil, nil
+		}
+		return []dependency{{
+			key: e.keyLayout.formatRetentionPolicyKey(policy),
+			err: errors.Wrapf(ErrEntityNotFound, "retention policy %s of %s", policy, entry.key),
+		}}, nil
+	}
+	deps := []dependency{{
+		key: e.keyLayout.formatGroupKey(entry.Group),
+		err: errors.Wrapf(ErrGroupAbsent, "group %s of %s", entry.Group, entry.key),
+	}}
+	if entry.Kind != KindIndexRuleBinding {
+		return deps, nil
+	}
+	binding := entry.Spec.(*databasev1.IndexRuleBinding)
+	subject := &commonv1.Metadata{Name: binding.GetSubject().GetName(), Group: entry.Group}
+	var subjectKey string
+	switch binding.GetSubject().GetCatalog() {
+	case commonv1.Catalog_CATALOG_STREAM:
+		subjectKey = e.keyLayout.formatStreamKey(subject)
+	case commonv1.Catalog_CATALOG_MEASURE:
+		subjectKey = e.keyLayout.formatMeasureKey(subject)
+	default:
+		return nil, errors.Wrapf(ErrInvalidBatch, "unknown catalog of the subject of %s", entry.key)
+	}
+	deps = append(deps, dependency{
+		key: subjectKey,
+		err: errors.Wrapf(ErrEntityNotFound, "subject %s of %s", subject.GetName(), entry.key),
+	})
+	for _, rule := range binding.GetRules() {
+		deps = append(deps, dependency{
+			key: e.keyLayout.formatIndexRuleKey(&commonv1.Metadata{Name: rule, Group: entry.Group}),
+			err: errors.Wrapf(ErrEntityNotFound, "index rule %s of %s", rule, entry.key),
+		})
+	}
+	return deps, nil
+}
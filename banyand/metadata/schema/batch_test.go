@@ -0,0 +1,93 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+func Test_Etcd_ApplyBatch(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	rule := func(group, name string) Metadata {
+		return Metadata{
+			TypeMeta: TypeMeta{Kind: KindIndexRule, Group: group, Name: name},
+			Spec: &databasev1.IndexRule{
+				Metadata: &commonv1.Metadata{Group: group, Name: name},
+				Tags:     []string{"endpoint_id"},
+				Type:     databasev1.IndexRule_TYPE_INVERTED,
+				Location: databasev1.IndexRule_LOCATION_SERIES,
+			},
+		}
+	}
+	binding := func(name, subject string, rules ...string) Metadata {
+		return Metadata{
+			TypeMeta: TypeMeta{Kind: KindIndexRuleBinding, Group: "default", Name: name},
+			Spec: &databasev1.IndexRuleBinding{
+				Metadata: &commonv1.Metadata{Group: "default", Name: name},
+				Rules:    rules,
+				Subject: &databasev1.Subject{
+					Catalog: commonv1.Catalog_CATALOG_STREAM,
+					Name:    subject,
+				},
+			},
+		}
+	}
+
+	err = registry.ApplyBatch(context.TODO(), []Metadata{
+		rule("default", "endpoint_v2"),
+		rule("absent", "endpoint_v2"),
+		binding("batch-binding", "absent", "endpoint_v2", "trace_id", "absent"),
+	})
+	req.ErrorIs(err, ErrGroupAbsent)
+	req.ErrorIs(err, ErrEntityNotFound)
+	req.Len(multierr.Errors(err), 3)
+	_, err = registry.GetIndexRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "endpoint_v2"})
+	req.ErrorIs(err, ErrEntityNotFound)
+
+	req.ErrorIs(registry.ApplyBatch(context.TODO(), []Metadata{
+		rule("default", "endpoint_v2"),
+		rule("default", "endpoint_v2"),
+	}), ErrInvalidBatch)
+	mismatched := rule("default", "endpoint_v2")
+	mismatched.Kind = KindStream
+	req.ErrorIs(registry.ApplyBatch(context.TODO(), []Metadata{mismatched}), ErrSpecMismatch)
+
+	req.NoError(registry.ApplyBatch(context.TODO(), []Metadata{
+		binding("batch-binding", "sw", "endpoint_v2", "trace_id"),
+		rule("default", "endpoint_v2"),
+	}))
+	r, err := registry.GetIndexRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "endpoint_v2"})
+	req.NoError(err)
+	req.Equal([]string{"endpoint_id"}, r.GetTags())
+	b, err := registry.GetIndexRuleBinding(context.TODO(), &commonv1.Metadata{Group: "default", Name: "batch-binding"})
+	req.NoError(err)
+	req.Equal([]string{"endpoint_v2", "trace_id"}, b.GetRules())
+}
@@ -0,0 +1,209 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+var ErrBoltFileInUse = errors.New("the BoltDB file is in use")
+
+// NewBoltSchemaRegistry serves the registry from a single BoltDB file instead of an embedded etcd,
+// which suits a single-node deployment where raft is overkill. The file is created if it's absent.
+// The semantics of the reads, the writes and the events are the same as the etcd one's.
+// The watches only see the writes committed after they start, since there is no history.
+// The options apply as well, except the ones of etcd, e.g. RootDir, the listeners and the storage options,
+// which are ignored.
+// The file is locked while the registry is open, so another registry fails with ErrBoltFileInUse.
+func NewBoltSchemaRegistry(path string, options ...RegistryOption) (Registry, error) {
+	registryConfig, err := newRegistryConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	kv, err := openBoltKV(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &boltBackend{
+		kv:       kv,
+		stopping: make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	return newRegistry(registryConfig, b, kv, kv)
+}
+
+var _ registryBackend = (*boltBackend)(nil)
+
+// boltBackend is ready once the file is open, and there is nothing to wait for when it stops
+type boltBackend struct {
+	kv        *boltKV
+	closeOnce sync.Once
+	stopping  chan struct{}
+	stopped   chan struct{}
+}
+
+var readyCh = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+func (b *boltBackend) readyNotify() <-chan struct{} {
+	return readyCh
+}
+
+func (b *boltBackend) stopNotify() <-chan struct{} {
+	return b.stopped
+}
+
+func (b *boltBackend) stoppingNotify() <-chan struct{} {
+	return b.stopping
+}
+
+func (b *boltBackend) close() {
+	b.closeOnce.Do(func() {
+		close(b.stopping)
+		_ = b.kv.close()
+		close(b.stopped)
+	})
+}
+
+func (b *boltBackend) version(context.Context) (string, error) {
+	return "", nil
+}
+
+func (b *boltBackend) watch(ctx context.Context, key string, opts ...clientv3.OpOption) (clientv3.WatchChan, error) {
+	return b.kv.watch(ctx, b.stopping, key, opts...)
+}
+
+func (b *boltBackend) transact(ctx context.Context, apply func(stm txnStore) error) (*clientv3.TxnResponse, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s := &kvSTM{
+			ctx:  ctx,
+			kv:   b.kv,
+			rset: make(map[string]*clientv3.GetResponse),
+			wset: make(map[string]clientv3.Op),
+		}
+		resp, err := s.attempt(apply)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Succeeded {
+			return resp, nil
+		}
+	}
+}
+
+// stmError passes the error of a read out of the apply function, which is unable to return it
+type stmError struct {
+	err error
+}
+
+// kvSTM is a software transactional memory over a kv, which works like the serializable one of etcd's concurrency package.
+// The reads are recorded, and the writes are buffered until the commit, which fails if any of the read keys is modified.
+type kvSTM struct {
+	ctx  context.Context
+	kv   clientv3.KV
+	rset map[string]*clientv3.GetResponse
+	wset map[string]clientv3.Op
+	// keys keeps the order the keys are written first, so the ops are committed in order
+	keys []string
+}
+
+func (s *kvSTM) attempt(apply func(stm txnStore) error) (resp *clientv3.TxnResponse, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(stmError)
+			if !ok {
+				panic(r)
+			}
+			err = e.err
+		}
+	}()
+	if err = apply(s); err != nil {
+		return nil, err
+	}
+	return s.commit()
+}
+
+func (s *kvSTM) Get(keys ...string) string {
+	for _, key := range keys {
+		if op, ok := s.wset[key]; ok {
+			return string(op.ValueBytes())
+		}
+	}
+	for _, key := range keys {
+		if resp, ok := s.rset[key]; ok {
+			return respValue(resp)
+		}
+	}
+	resp, err := s.kv.Get(s.ctx, keys[0])
+	if err != nil {
+		panic(stmError{err: err})
+	}
+	s.rset[keys[0]] = resp
+	return respValue(resp)
+}
+
+func (s *kvSTM) Put(key, val string, opts ...clientv3.OpOption) {
+	s.write(key, clientv3.OpPut(key, val, opts...))
+}
+
+func (s *kvSTM) Del(key string) {
+	s.write(key, clientv3.OpDelete(key))
+}
+
+func (s *kvSTM) Rev(key string) int64 {
+	if resp, ok := s.rset[key]; ok && len(resp.Kvs) > 0 {
+		return resp.Kvs[0].ModRevision
+	}
+	return 0
+}
+
+func (s *kvSTM) write(key string, op clientv3.Op) {
+	if _, ok := s.wset[key]; !ok {
+		s.keys = append(s.keys, key)
+	}
+	s.wset[key] = op
+}
+
+func (s *kvSTM) commit() (*clientv3.TxnResponse, error) {
+	cmps := make([]clientv3.Cmp, 0, len(s.rset))
+	for key, resp := range s.rset {
+		cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(key), "<", resp.Header.GetRevision()+1))
+	}
+	ops := make([]clientv3.Op, 0, len(s.keys))
+	for _, key := range s.keys {
+		ops = append(ops, s.wset[key])
+	}
+	return s.kv.Txn(s.ctx).If(cmps...).Then(ops...).Commit()
+}
+
+func respValue(resp *clientv3.GetResponse) string {
+	if len(resp.Kvs) < 1 {
+		return ""
+	}
+	return string(resp.Kvs[0].Value)
+}
@@ -0,0 +1,636 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+var ErrUnsupportedOp = errors.New("the op is not supported by the BoltDB registry")
+
+var (
+	boltKVBucket        = []byte("kv")
+	boltLeaseBucket     = []byte("leases")
+	boltLeaseKeysBucket = []byte("lease-keys")
+	boltMetaBucket      = []byte("meta")
+	boltRevisionKey     = []byte("revision")
+	boltLeaseIDKey      = []byte("lease-id")
+	// boltLeasedValue leads the value of a put built by opPutWithLease, which is followed by the lease.
+	// None of the stored values starts with a zero byte, e.g., a marshaled proto, a key or a counter.
+	boltLeasedValue = []byte("\x00lease\x00")
+)
+
+var (
+	_ clientv3.KV  = (*boltKV)(nil)
+	_ leaseGranter = (*boltKV)(nil)
+)
+
+// boltKV mimics the kv of etcd over a BoltDB file without the history.
+// A write transaction bumps the revision stored along with the keys, which backs the comparisons on the revisions.
+// The keys attached to an expired lease are hidden, and they're purged when a lease is granted.
+// A key is attached to a lease by the put of opPutWithLease only, since clientv3.Op doesn't expose the lease of WithLease.
+//
+// The sort, the limit and the reads of a past revision aren't supported,
+// and a delete always returns the deleted key-values.
+type boltKV struct {
+	db       *bolt.DB
+	now      func() time.Time
+	watchMu  sync.Mutex
+	watchers map[*boltWatcher]struct{}
+}
+
+func openBoltKV(path string) (*boltKV, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: lockTimeout})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, errors.Wrapf(ErrBoltFileInUse, "file %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltKVBucket, boltLeaseBucket, boltLeaseKeysBucket, boltMetaBucket} {
+			if _, innerErr := tx.CreateBucketIfNotExists(name); innerErr != nil {
+				return innerErr
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &boltKV{db: db, now: time.Now, watchers: make(map[*boltWatcher]struct{})}, nil
+}
+
+func (k *boltKV) close() error {
+	return k.db.Close()
+}
+
+func (k *boltKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	resp, err := k.Do(ctx, clientv3.OpPut(key, val, opts...))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Put(), nil
+}
+
+func (k *boltKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	resp, err := k.Do(ctx, clientv3.OpGet(key, opts...))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Get(), nil
+}
+
+func (k *boltKV) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	resp, err := k.Do(ctx, clientv3.OpDelete(key, opts...))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Del(), nil
+}
+
+// Compact does nothing since there is no history
+func (k *boltKV) Compact(ctx context.Context, _ int64, _ ...clientv3.CompactOption) (*clientv3.CompactResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var rev int64
+	err := k.db.View(func(tx *bolt.Tx) error {
+		rev = readRevision(tx)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &clientv3.CompactResponse{Header: &pb.ResponseHeader{Revision: rev}}, nil
+}
+
+func (k *boltKV) Do(ctx context.Context, op clientv3.Op) (clientv3.OpResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return clientv3.OpResponse{}, err
+	}
+	if op.Rev() > 0 {
+		return clientv3.OpResponse{}, errors.Wrap(ErrUnsupportedOp, "read a past revision")
+	}
+	if op.IsGet() {
+		var resp *clientv3.GetResponse
+		err := k.db.View(func(tx *bolt.Tx) (err error) {
+			r := &boltReader{tx: tx, now: k.now()}
+			resp, err = r.rangeOp(op, &pb.ResponseHeader{Revision: r.revision()})
+			return err
+		})
+		if err != nil {
+			return clientv3.OpResponse{}, err
+		}
+		return resp.OpResponse(), nil
+	}
+	var resp clientv3.OpResponse
+	var w *boltWriter
+	err := k.db.Update(func(tx *bolt.Tx) (err error) {
+		w = newBoltWriter(tx, k.now())
+		switch {
+		case op.IsPut():
+			var put *clientv3.PutResponse
+			if put, err = w.put(op); err == nil {
+				resp = put.OpResponse()
+			}
+		case op.IsDelete():
+			var del *clientv3.DeleteResponse
+			if del, err = w.delete(op); err == nil {
+				resp = del.OpResponse()
+			}
+		case op.IsTxn():
+			var txn *clientv3.TxnResponse
+			if txn, err = w.txn(op); err == nil {
+				resp = txn.OpResponse()
+			}
+		default:
+			return errors.Wrap(ErrUnsupportedOp, "unknown op")
+		}
+		if err != nil {
+			return err
+		}
+		return w.finish()
+	})
+	if err != nil {
+		return clientv3.OpResponse{}, err
+	}
+	k.publish(w.rev, w.events)
+	return resp, nil
+}
+
+func (k *boltKV) Txn(ctx context.Context) clientv3.Txn {
+	return &boltTxn{kv: k, ctx: ctx}
+}
+
+// Grant purges the keys of the expired leases before granting a new one
+func (k *boltKV) Grant(ctx context.Context, ttl int64) (*clientv3.LeaseGrantResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	resp := &clientv3.LeaseGrantResponse{ResponseHeader: &pb.ResponseHeader{}, TTL: ttl}
+	var w *boltWriter
+	err := k.db.Update(func(tx *bolt.Tx) error {
+		w = newBoltWriter(tx, k.now())
+		if err := w.purgeExpiredLeases(); err != nil {
+			return err
+		}
+		meta := tx.Bucket(boltMetaBucket)
+		id := readInt64(meta.Get(boltLeaseIDKey)) + 1
+		if err := meta.Put(boltLeaseIDKey, int64ToBytes(id)); err != nil {
+			return err
+		}
+		expiry := w.now.Add(time.Duration(ttl) * time.Second).UnixNano()
+		if err := tx.Bucket(boltLeaseBucket).Put(int64ToBytes(id), int64ToBytes(expiry)); err != nil {
+			return err
+		}
+		resp.ID = clientv3.LeaseID(id)
+		return w.finish()
+	})
+	if err != nil {
+		return nil, err
+	}
+	k.publish(w.rev, w.events)
+	resp.ResponseHeader.Revision = k.revision()
+	return resp, nil
+}
+
+func (k *boltKV) revision() (rev int64) {
+	_ = k.db.View(func(tx *bolt.Tx) error {
+		rev = readRevision(tx)
+		return nil
+	})
+	return rev
+}
+
+type boltTxn struct {
+	kv      *boltKV
+	ctx     context.Context
+	cmps    []clientv3.Cmp
+	thenOps []clientv3.Op
+	elseOps []clientv3.Op
+}
+
+func (t *boltTxn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	t.cmps = append(t.cmps, cs...)
+	return t
+}
+
+func (t *boltTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	t.thenOps = append(t.thenOps, ops...)
+	return t
+}
+
+func (t *boltTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	t.elseOps = append(t.elseOps, ops...)
+	return t
+}
+
+func (t *boltTxn) Commit() (*clientv3.TxnResponse, error) {
+	resp, err := t.kv.Do(t.ctx, clientv3.OpTxn(t.cmps, t.thenOps, t.elseOps))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Txn(), nil
+}
+
+// boltReader reads the live key-values, which are the ones not attached to an expired lease
+type boltReader struct {
+	tx  *bolt.Tx
+	now time.Time
+}
+
+func (r *boltReader) revision() int64 {
+	return readRevision(r.tx)
+}
+
+func (r *boltReader) load(key []byte) (*mvccpb.KeyValue, error) {
+	raw := r.tx.Bucket(boltKVBucket).Get(key)
+	if raw == nil {
+		return nil, nil
+	}
+	return r.decode(raw)
+}
+
+func (r *boltReader) decode(raw []byte) (*mvccpb.KeyValue, error) {
+	kv := &mvccpb.KeyValue{}
+	if err := kv.Unmarshal(raw); err != nil {
+		return nil, err
+	}
+	if kv.Lease != 0 && !r.leaseAlive(kv.Lease) {
+		return nil, nil
+	}
+	return kv, nil
+}
+
+func (r *boltReader) leaseAlive(id int64) bool {
+	expiry := r.tx.Bucket(boltLeaseBucket).Get(int64ToBytes(id))
+	return expiry != nil && r.now.UnixNano() < readInt64(expiry)
+}
+
+// scan loads the key-values in [key, end) like a range request of etcd.
+// An empty end stands for the key only, and "\x00" stands for all the keys from the key.
+func (r *boltReader) scan(key, end []byte) ([]*mvccpb.KeyValue, error) {
+	if len(end) == 0 {
+		kv, err := r.load(key)
+		if err != nil || kv == nil {
+			return nil, err
+		}
+		return []*mvccpb.KeyValue{kv}, nil
+	}
+	fromKey := bytes.Equal(end, []byte{0})
+	var kvs []*mvccpb.KeyValue
+	c := r.tx.Bucket(boltKVBucket).Cursor()
+	for k, v := c.Seek(key); k != nil && (fromKey || bytes.Compare(k, end) < 0); k, v = c.Next() {
+		kv, err := r.decode(v)
+		if err != nil {
+			return nil, err
+		}
+		if kv != nil {
+			kvs = append(kvs, kv)
+		}
+	}
+	return kvs, nil
+}
+
+func (r *boltReader) rangeOp(op clientv3.Op, header *pb.ResponseHeader) (*clientv3.GetResponse, error) {
+	kvs, err := r.scan(op.KeyBytes(), op.RangeBytes())
+	if err != nil {
+		return nil, err
+	}
+	filtered := kvs[:0]
+	for _, kv := range kvs {
+		if (op.MinModRev() > 0 && kv.ModRevision < op.MinModRev()) ||
+			(op.MaxModRev() > 0 && kv.ModRevision > op.MaxModRev()) ||
+			(op.MinCreateRev() > 0 && kv.CreateRevision < op.MinCreateRev()) ||
+			(op.MaxCreateRev() > 0 && kv.CreateRevision > op.MaxCreateRev()) {
+			continue
+		}
+		if op.IsKeysOnly() {
+			kv.Value = nil
+		}
+		filtered = append(filtered, kv)
+	}
+	resp := &clientv3.GetResponse{Header: header, Count: int64(len(filtered))}
+	if !op.IsCountOnly() {
+		resp.Kvs = filtered
+	}
+	return resp, nil
+}
+
+// boltWriter bumps the revision once for all the changes made through it
+type boltWriter struct {
+	boltReader
+	rev     int64
+	changed bool
+	// header is shared by all the responses, whose revision is settled once the writer finishes
+	header *pb.ResponseHeader
+	// events are published to the watchers once the write is committed
+	events []*clientv3.Event
+}
+
+func newBoltWriter(tx *bolt.Tx, now time.Time) *boltWriter {
+	w := &boltWriter{
+		boltReader: boltReader{tx: tx, now: now},
+		header:     &pb.ResponseHeader{},
+	}
+	w.rev = w.revision()
+	return w
+}
+
+func (w *boltWriter) nextRevision() int64 {
+	if !w.changed {
+		w.changed = true
+		w.rev++
+	}
+	return w.rev
+}
+
+// deleted bumps the revision and records the deletion of kv
+func (w *boltWriter) deleted(kv *mvccpb.KeyValue) {
+	rev := w.nextRevision()
+	w.events = append(w.events, &clientv3.Event{
+		Type:   mvccpb.DELETE,
+		Kv:     &mvccpb.KeyValue{Key: kv.Key, ModRevision: rev},
+		PrevKv: kv,
+	})
+}
+
+func (w *boltWriter) finish() error {
+	w.header.Revision = w.rev
+	if !w.changed {
+		return nil
+	}
+	return w.tx.Bucket(boltMetaBucket).Put(boltRevisionKey, int64ToBytes(w.rev))
+}
+
+func (w *boltWriter) put(op clientv3.Op) (*clientv3.PutResponse, error) {
+	key := op.KeyBytes()
+	value, lease := unwrapLeasedValue(op.ValueBytes())
+	if lease != 0 && !w.leaseAlive(lease) {
+		return nil, rpctypes.ErrLeaseNotFound
+	}
+	prev, err := w.load(key)
+	if err != nil {
+		return nil, err
+	}
+	rev := w.nextRevision()
+	kv := &mvccpb.KeyValue{
+		Key:            key,
+		Value:          value,
+		CreateRevision: rev,
+		ModRevision:    rev,
+		Version:        1,
+		Lease:          lease,
+	}
+	if prev != nil {
+		kv.CreateRevision = prev.CreateRevision
+		kv.Version = prev.Version + 1
+	}
+	raw, err := kv.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if err = w.tx.Bucket(boltKVBucket).Put(key, raw); err != nil {
+		return nil, err
+	}
+	if lease != 0 {
+		if err = w.tx.Bucket(boltLeaseKeysBucket).Put(append(int64ToBytes(lease), key...), nil); err != nil {
+			return nil, err
+		}
+	}
+	w.events = append(w.events, &clientv3.Event{Type: mvccpb.PUT, Kv: kv, PrevKv: prev})
+	return &clientv3.PutResponse{Header: w.header}, nil
+}
+
+func (w *boltWriter) delete(op clientv3.Op) (*clientv3.DeleteResponse, error) {
+	kvs, err := w.scan(op.KeyBytes(), op.RangeBytes())
+	if err != nil {
+		return nil, err
+	}
+	bucket := w.tx.Bucket(boltKVBucket)
+	for _, kv := range kvs {
+		if err = bucket.Delete(kv.Key); err != nil {
+			return nil, err
+		}
+		w.deleted(kv)
+	}
+	return &clientv3.DeleteResponse{Header: w.header, Deleted: int64(len(kvs)), PrevKvs: kvs}, nil
+}
+
+func (w *boltWriter) txn(op clientv3.Op) (*clientv3.TxnResponse, error) {
+	cmps, thenOps, elseOps := op.Txn()
+	succeeded := true
+	for _, cmp := range cmps {
+		ok, err := w.compare(cmp)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			succeeded = false
+			break
+		}
+	}
+	ops := thenOps
+	if !succeeded {
+		ops = elseOps
+	}
+	resp := &clientv3.TxnResponse{Header: w.header, Succeeded: succeeded}
+	for _, o := range ops {
+		var respOp *pb.ResponseOp
+		switch {
+		case o.IsGet():
+			r, err := w.rangeOp(o, w.header)
+			if err != nil {
+				return nil, err
+			}
+			respOp = &pb.ResponseOp{Response: &pb.ResponseOp_ResponseRange{ResponseRange: (*pb.RangeResponse)(r)}}
+		case o.IsPut():
+			r, err := w.put(o)
+			if err != nil {
+				return nil, err
+			}
+			respOp = &pb.ResponseOp{Response: &pb.ResponseOp_ResponsePut{ResponsePut: (*pb.PutResponse)(r)}}
+		case o.IsDelete():
+			r, err := w.delete(o)
+			if err != nil {
+				return nil, err
+			}
+			respOp = &pb.ResponseOp{Response: &pb.ResponseOp_ResponseDeleteRange{ResponseDeleteRange: (*pb.DeleteRangeResponse)(r)}}
+		case o.IsTxn():
+			r, err := w.txn(o)
+			if err != nil {
+				return nil, err
+			}
+			respOp = &pb.ResponseOp{Response: &pb.ResponseOp_ResponseTxn{ResponseTxn: (*pb.TxnResponse)(r)}}
+		default:
+			return nil, errors.Wrap(ErrUnsupportedOp, "unknown op")
+		}
+		resp.Responses = append(resp.Responses, respOp)
+	}
+	return resp, nil
+}
+
+// compare follows etcd, where an absent key has zero revisions and version but no value
+func (w *boltWriter) compare(cmp clientv3.Cmp) (bool, error) {
+	kvs, err := w.scan(cmp.KeyBytes(), cmp.RangeEnd)
+	if err != nil {
+		return false, err
+	}
+	if len(kvs) == 0 {
+		if cmp.Target == pb.Compare_VALUE {
+			return false, nil
+		}
+		return compareKV(cmp, &mvccpb.KeyValue{}), nil
+	}
+	for _, kv := range kvs {
+		if !compareKV(cmp, kv) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func compareKV(cmp clientv3.Cmp, kv *mvccpb.KeyValue) bool {
+	target := pb.Compare(cmp)
+	var result int
+	switch cmp.Target {
+	case pb.Compare_VALUE:
+		result = bytes.Compare(kv.Value, cmp.ValueBytes())
+	case pb.Compare_CREATE:
+		result = compareInt64(kv.CreateRevision, target.GetCreateRevision())
+	case pb.Compare_MOD:
+		result = compareInt64(kv.ModRevision, target.GetModRevision())
+	case pb.Compare_VERSION:
+		result = compareInt64(kv.Version, target.GetVersion())
+	case pb.Compare_LEASE:
+		result = compareInt64(kv.Lease, target.GetLease())
+	}
+	switch cmp.Result {
+	case pb.Compare_EQUAL:
+		return result == 0
+	case pb.Compare_NOT_EQUAL:
+		return result != 0
+	case pb.Compare_GREATER:
+		return result > 0
+	case pb.Compare_LESS:
+		return result < 0
+	}
+	return false
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// purgeExpiredLeases deletes the expired leases along with the keys still attached to them
+func (w *boltWriter) purgeExpiredLeases() error {
+	var expired [][]byte
+	leases := w.tx.Bucket(boltLeaseBucket)
+	if err := leases.ForEach(func(id, expiry []byte) error {
+		if w.now.UnixNano() >= readInt64(expiry) {
+			expired = append(expired, append([]byte(nil), id...))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	kvBucket := w.tx.Bucket(boltKVBucket)
+	leaseKeys := w.tx.Bucket(boltLeaseKeysBucket)
+	for _, id := range expired {
+		var attached [][]byte
+		c := leaseKeys.Cursor()
+		for k, _ := c.Seek(id); k != nil && bytes.HasPrefix(k, id); k, _ = c.Next() {
+			attached = append(attached, append([]byte(nil), k...))
+		}
+		for _, k := range attached {
+			key := k[len(id):]
+			// the key might be attached to another lease or none since then
+			if raw := kvBucket.Get(key); raw != nil {
+				kv := &mvccpb.KeyValue{}
+				if err := kv.Unmarshal(raw); err != nil {
+					return err
+				}
+				if kv.Lease == readInt64(id) {
+					if err := kvBucket.Delete(key); err != nil {
+						return err
+					}
+					w.deleted(kv)
+				}
+			}
+			if err := leaseKeys.Delete(k); err != nil {
+				return err
+			}
+		}
+		if err := leases.Delete(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// opPutWithLease carries the lease in the value, which the put unwraps before storing it
+func (k *boltKV) opPutWithLease(key, val string, lease clientv3.LeaseID) clientv3.Op {
+	value := make([]byte, 0, len(boltLeasedValue)+8+len(val))
+	value = append(value, boltLeasedValue...)
+	value = append(value, int64ToBytes(int64(lease))...)
+	return clientv3.OpPut(key, string(append(value, val...)))
+}
+
+// unwrapLeasedValue is the inverse of opPutWithLease. The lease is zero if the value isn't wrapped.
+func unwrapLeasedValue(value []byte) ([]byte, int64) {
+	if !bytes.HasPrefix(value, boltLeasedValue) || len(value) < len(boltLeasedValue)+8 {
+		return value, 0
+	}
+	return value[len(boltLeasedValue)+8:], readInt64(value[len(boltLeasedValue):])
+}
+
+func readRevision(tx *bolt.Tx) int64 {
+	return readInt64(tx.Bucket(boltMetaBucket).Get(boltRevisionKey))
+}
+
+func readInt64(b []byte) int64 {
+	if len(b) < 8 {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+func int64ToBytes(v int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(v))
+	return b
+}
@@ -0,0 +1,153 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+func Test_Bolt_Registry(t *testing.T) {
+	req := require.New(t)
+	path := filepath.Join(t.TempDir(), "schema.db")
+	registry, err := NewBoltSchemaRegistry(path)
+	req.NoError(err)
+	<-registry.ReadyNotify()
+	req.NoError(preloadSchema(registry))
+	handler := &mockedEventHandler{}
+	handler.On("OnAddOrUpdate", mock.Anything).Return()
+	handler.On("OnDelete", mock.Anything).Return()
+	registry.RegisterHandler(KindStream|KindIndexRule, handler)
+
+	md := &commonv1.Metadata{Name: "sw", Group: "default"}
+	s, err := registry.GetStream(context.TODO(), md)
+	req.NoError(err)
+	req.Greater(s.GetMetadata().GetModRevision(), int64(0))
+	rules, err := registry.ListIndexRule(context.TODO(), ListOpt{Group: "default"})
+	req.NoError(err)
+	req.NotEmpty(rules)
+
+	updated := proto.Clone(s).(*databasev1.Stream)
+	updated.Entity.TagNames = append(updated.Entity.TagNames, "trace_id")
+	req.NoError(registry.UpdateStream(context.TODO(), updated))
+	deleted, err := registry.DeleteIndexRule(context.TODO(), rules[0].GetMetadata())
+	req.NoError(err)
+	req.True(deleted)
+	req.Len(handler.Calls, 2)
+	prev := handler.Calls[0].Arguments.Get(0).(Metadata).PrevSpec.(*databasev1.Stream)
+	req.Equal(s.GetEntity().GetTagNames(), prev.GetEntity().GetTagNames())
+	req.Equal("OnDelete", handler.Calls[1].Method)
+
+	// the file is locked by the open registry
+	_, err = NewBoltSchemaRegistry(path)
+	req.ErrorIs(err, ErrBoltFileInUse)
+	req.NoError(registry.Close())
+	<-registry.StopNotify()
+
+	registry, err = NewBoltSchemaRegistry(path)
+	req.NoError(err)
+	defer registry.Close()
+	s, err = registry.GetStream(context.TODO(), md)
+	req.NoError(err)
+	req.Equal(updated.GetEntity().GetTagNames(), s.GetEntity().GetTagNames())
+	_, err = registry.GetIndexRule(context.TODO(), rules[0].GetMetadata())
+	req.ErrorIs(err, ErrEntityNotFound)
+}
+
+func Test_Bolt_Transaction(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewBoltSchemaRegistry(filepath.Join(t.TempDir(), "schema.db"))
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	ruleMeta := Metadata{TypeMeta: TypeMeta{Kind: KindIndexRule, Group: "default", Name: "endpoint_id"}}
+	var attempts int
+	req.NoError(registry.Transaction(context.TODO(), func(tx RegistryTxn) error {
+		attempts++
+		spec, innerErr := tx.Get(ruleMeta)
+		if innerErr != nil {
+			return innerErr
+		}
+		if attempts == 1 {
+			// modify the rule read by the transaction to make it retry
+			rule := proto.Clone(spec).(*databasev1.IndexRule)
+			rule.Tags = []string{"endpoint_id", "service_id"}
+			if innerErr = registry.UpdateIndexRule(context.TODO(), rule); innerErr != nil {
+				return innerErr
+			}
+		}
+		_, innerErr = tx.Delete(ruleMeta)
+		return innerErr
+	}))
+	req.Equal(2, attempts)
+	_, err = registry.GetIndexRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "endpoint_id"})
+	req.ErrorIs(err, ErrEntityNotFound)
+}
+
+func Test_Bolt_WatchGroups(t *testing.T) {
+	registry, err := NewBoltSchemaRegistry(filepath.Join(t.TempDir(), "schema.db"))
+	require.NoError(t, err)
+	defer registry.Close()
+	testWatchGroups(t, registry)
+	// there is no history to watch
+	_, err = registry.(*etcdSchemaRegistry).backend.watch(context.TODO(), GroupMetadataKeyPrefix,
+		clientv3.WithPrefix(), clientv3.WithRev(1))
+	require.ErrorIs(t, err, ErrUnsupportedOp)
+}
+
+func Test_Bolt_IdempotencyKeyExpiry(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewBoltSchemaRegistry(filepath.Join(t.TempDir(), "schema.db"), IdempotencyKeyTTL(time.Minute))
+	req.NoError(err)
+	defer registry.Close()
+	e := registry.(*etcdSchemaRegistry)
+	kv := e.backend.(*boltBackend).kv
+	now := time.Now()
+	kv.now = func() time.Time { return now }
+
+	policy := func(name string) *commonv1.RetentionPolicy {
+		return &commonv1.RetentionPolicy{
+			Metadata: &commonv1.Metadata{Name: name},
+			Ttl:      &commonv1.Duration{Val: 7, Unit: commonv1.Duration_DURATION_UNIT_DAY},
+		}
+	}
+	req.NoError(registry.UpdateRetentionPolicy(context.TODO(), policy("week"), IdempotencyKey("k1")))
+	req.ErrorIs(registry.UpdateRetentionPolicy(context.TODO(), policy("month"), IdempotencyKey("k1")), ErrIdempotencyKeyReused)
+
+	// the key is forgotten once its lease expires, and purged when another lease is granted
+	now = now.Add(2 * time.Minute)
+	req.NoError(registry.UpdateRetentionPolicy(context.TODO(), policy("month"), IdempotencyKey("k2")))
+	resp, err := kv.Get(context.TODO(), IdempotencyKeyPrefix, clientv3.WithPrefix())
+	req.NoError(err)
+	req.Len(resp.Kvs, 1)
+	req.Equal(IdempotencyKeyPrefix+"k2", string(resp.Kvs[0].Key))
+	req.Equal(RetentionPolicyKeyPrefix+"month", string(resp.Kvs[0].Value))
+	req.NotZero(resp.Kvs[0].Lease)
+	req.NoError(registry.UpdateRetentionPolicy(context.TODO(), policy("month"), IdempotencyKey("k1")))
+}
@@ -0,0 +1,150 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// boltWatcher receives the events of the keys in [key, end) committed after it's registered.
+// The events are queued, so a slow receiver never holds the writers back.
+type boltWatcher struct {
+	ctx    context.Context
+	key    []byte
+	end    []byte
+	prevKV bool
+	ch     chan clientv3.WatchResponse
+	// notify wakes run once pending grows
+	notify  chan struct{}
+	mu      sync.Mutex
+	pending []clientv3.WatchResponse
+}
+
+// watch mimics the watch of etcd from the current revision. The range options apply, and WithPrevKV as well.
+// There is no history to watch from a past revision.
+func (k *boltKV) watch(ctx context.Context, stopping <-chan struct{}, key string, opts ...clientv3.OpOption) (clientv3.WatchChan, error) {
+	op := clientv3.OpGet(key, opts...)
+	if op.Rev() > 0 {
+		return nil, errors.Wrap(ErrUnsupportedOp, "watch from a past revision")
+	}
+	w := &boltWatcher{
+		ctx:    ctx,
+		key:    op.KeyBytes(),
+		end:    op.RangeBytes(),
+		prevKV: reflect.ValueOf(op).FieldByName("prevKV").Bool(),
+		ch:     make(chan clientv3.WatchResponse),
+		notify: make(chan struct{}, 1),
+	}
+	k.watchMu.Lock()
+	k.watchers[w] = struct{}{}
+	k.watchMu.Unlock()
+	go func() {
+		defer func() {
+			k.watchMu.Lock()
+			delete(k.watchers, w)
+			k.watchMu.Unlock()
+		}()
+		w.run(stopping)
+	}()
+	return w.ch, nil
+}
+
+// publish hands the events of a committed write to the watchers
+func (k *boltKV) publish(rev int64, events []*clientv3.Event) {
+	if len(events) < 1 {
+		return
+	}
+	k.watchMu.Lock()
+	defer k.watchMu.Unlock()
+	for w := range k.watchers {
+		w.enqueue(rev, events)
+	}
+}
+
+func (w *boltWatcher) enqueue(rev int64, events []*clientv3.Event) {
+	var matched []*clientv3.Event
+	for _, ev := range events {
+		if !w.matches(ev.Kv.Key) {
+			continue
+		}
+		if !w.prevKV && ev.PrevKv != nil {
+			e := *ev
+			e.PrevKv = nil
+			ev = &e
+		}
+		matched = append(matched, ev)
+	}
+	if len(matched) < 1 {
+		return
+	}
+	w.mu.Lock()
+	w.pending = append(w.pending, clientv3.WatchResponse{
+		Header: pb.ResponseHeader{Revision: rev},
+		Events: matched,
+	})
+	w.mu.Unlock()
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// matches follows the range of etcd, where an end of "\x00" is all the keys from the key on
+func (w *boltWatcher) matches(key []byte) bool {
+	if len(w.end) == 0 {
+		return bytes.Equal(key, w.key)
+	}
+	if bytes.Compare(key, w.key) < 0 {
+		return false
+	}
+	return bytes.Equal(w.end, []byte{0}) || bytes.Compare(key, w.end) < 0
+}
+
+// run delivers the queued responses in order until ctx is done or the registry stops, then closes the channel
+func (w *boltWatcher) run(stopping <-chan struct{}) {
+	defer close(w.ch)
+	for {
+		select {
+		case <-w.notify:
+		case <-w.ctx.Done():
+			return
+		case <-stopping:
+			return
+		}
+		w.mu.Lock()
+		responses := w.pending
+		w.pending = nil
+		w.mu.Unlock()
+		for _, resp := range responses {
+			select {
+			case w.ch <- resp:
+			case <-w.ctx.Done():
+				return
+			case <-stopping:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+var ErrInvalidBundle = errors.New("the bundle is invalid")
+
+// SchemaBundle is a self-contained copy of a stream's schema, which could be imported into another registry
+type SchemaBundle struct {
+	// RetentionPolicy is nil if the group doesn't refer to any policy
+	RetentionPolicy   *commonv1.RetentionPolicy
+	Group             *commonv1.Group
+	Stream            *databasev1.Stream
+	IndexRules        []*databasev1.IndexRule
+	IndexRuleBindings []*databasev1.IndexRuleBinding
+}
+
+// Bundle copies a stream along with its dependencies
+type Bundle interface {
+	// ExportStreamBundle collects the stream, its group and all the index rules and bindings referring to it.
+	// The revisions are cleared since they are meaningless to other registries.
+	ExportStreamBundle(ctx context.Context, metadata *commonv1.Metadata) (*SchemaBundle, error)
+	// ImportBundle recreates the entities of the bundle in the dependency order.
+	// Either all of them are applied, or none of them is.
+	ImportBundle(ctx context.Context, bundle *SchemaBundle) error
+}
+
+func (e *etcdSchemaRegistry) ExportStreamBundle(ctx context.Context, metadata *commonv1.Metadata) (*SchemaBundle, error) {
+	stream, err := e.GetStream(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+	group, err := e.GetGroup(ctx, metadata.GetGroup())
+	if err != nil {
+		return nil, err
+	}
+	bundle := &SchemaBundle{
+		Group:  group,
+		Stream: stream,
+	}
+	if policy := group.GetResourceOpts().GetRetentionPolicy(); policy != "" {
+		if bundle.RetentionPolicy, err = e.GetRetentionPolicy(ctx, policy); err != nil {
+			return nil, err
+		}
+	}
+	bindings, err := e.ListIndexRuleBinding(ctx, ListOpt{Group: metadata.GetGroup()})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	for _, binding := range bindings {
+		sub := binding.GetSubject()
+		if sub.GetCatalog() != commonv1.Catalog_CATALOG_STREAM || sub.GetName() != metadata.GetName() {
+			continue
+		}
+		bundle.IndexRuleBindings = append(bundle.IndexRuleBindings, binding)
+		for _, rule := range binding.GetRules() {
+			if _, ok := seen[rule]; ok {
+				continue
+			}
+			seen[rule] = struct{}{}
+			r, getErr := e.GetIndexRule(ctx, &commonv1.Metadata{
+				Name:  rule,
+				Group: metadata.GetGroup(),
+			})
+			if getErr != nil {
+				return nil, errors.WithMessagef(getErr, "index rule %s of binding %s", rule, binding.GetMetadata().GetName())
+			}
+			bundle.IndexRules = append(bundle.IndexRules, r)
+		}
+	}
+	clearRevisions(bundle)
+	return bundle, nil
+}
+
+func (e *etcdSchemaRegistry) ImportBundle(ctx context.Context, bundle *SchemaBundle) error {
+	if bundle == nil || bundle.Group == nil || bundle.Stream == nil {
+		return errors.Wrap(ErrInvalidBundle, "the group and the stream are required")
+	}
+	group := bundle.Group.GetMetadata().GetName()
+	entities := make([]Metadata, 0, 3+len(bundle.IndexRules)+len(bundle.IndexRuleBindings))
+	if p := bundle.RetentionPolicy; p != nil {
+		entities = append(entities, Metadata{
+			TypeMeta: TypeMeta{Kind: KindRetentionPolicy, Name: p.GetMetadata().GetName()},
+			Spec:     p,
+		})
+	}
+	entities = append(entities, Metadata{
+		TypeMeta: TypeMeta{Kind: KindGroup, Name: group},
+		Spec:     bundle.Group,
+	})
+	for _, r := range bundle.IndexRules {
+		entities = append(entities, Metadata{
+			TypeMeta: TypeMeta{Kind: KindIndexRule, Group: group, Name: r.GetMetadata().GetName()},
+			Spec:     r,
+		})
+	}
+	entities = append(entities, Metadata{
+		TypeMeta: TypeMeta{Kind: KindStream, Group: group, Name: bundle.Stream.GetMetadata().GetName()},
+		Spec:     bundle.Stream,
+	})
+	for _, b := range bundle.IndexRuleBindings {
+		entities = append(entities, Metadata{
+			TypeMeta: TypeMeta{Kind: KindIndexRuleBinding, Group: group, Name: b.GetMetadata().GetName()},
+			Spec:     b,
+		})
+	}
+	for _, md := range entities {
+		if md.Kind == KindGroup || md.Kind == KindRetentionPolicy {
+			continue
+		}
+		if md.Spec.(HasMetadata).GetMetadata().GetGroup() != group {
+			return errors.Wrapf(ErrInvalidBundle, "%s doesn't belong to the group %s", md.Name, group)
+		}
+	}
+	return e.ApplyBatch(ctx, entities)
+}
+
+func clearRevisions(bundle *SchemaBundle) {
+	specs := []HasMetadata{bundle.Group, bundle.Stream}
+	if bundle.RetentionPolicy != nil {
+		specs = append(specs, bundle.RetentionPolicy)
+	}
+	for _, r := range bundle.IndexRules {
+		specs = append(specs, r)
+	}
+	for _, b := range bundle.IndexRuleBindings {
+		specs = append(specs, b)
+	}
+	for _, s := range specs {
+		if m := s.GetMetadata(); m != nil {
+			m.CreateRevision = 0
+			m.ModRevision = 0
+		}
+	}
+}
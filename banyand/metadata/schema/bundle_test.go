@@ -0,0 +1,63 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func Test_Etcd_StreamBundle(t *testing.T) {
+	req := require.New(t)
+	src, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer src.Close()
+	req.NoError(preloadSchema(src))
+	dst, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer dst.Close()
+
+	sw := &commonv1.Metadata{Name: "sw", Group: "default"}
+	bundle, err := src.ExportStreamBundle(context.TODO(), sw)
+	req.NoError(err)
+	req.Equal("default", bundle.Group.GetMetadata().GetName())
+	req.Len(bundle.IndexRuleBindings, 1)
+	req.NotEmpty(bundle.IndexRules)
+	req.Zero(bundle.Stream.GetMetadata().GetModRevision())
+
+	req.ErrorIs(dst.ImportBundle(context.TODO(), &SchemaBundle{Group: bundle.Group}), ErrInvalidBundle)
+	req.NoError(dst.ImportBundle(context.TODO(), bundle))
+	s, err := dst.GetStream(context.TODO(), sw)
+	req.NoError(err)
+	req.Equal(len(bundle.Stream.GetTagFamilies()), len(s.GetTagFamilies()))
+	b, err := dst.GetIndexRuleBinding(context.TODO(), &commonv1.Metadata{Name: "sw-index-rule-binding", Group: "default"})
+	req.NoError(err)
+	for _, rule := range b.GetRules() {
+		_, err = dst.GetIndexRule(context.TODO(), &commonv1.Metadata{Name: rule, Group: "default"})
+		req.NoError(err)
+	}
+	// importing the same bundle again changes nothing
+	req.NoError(dst.ImportBundle(context.TODO(), bundle))
+
+	_, err = src.ExportStreamBundle(context.TODO(), &commonv1.Metadata{Name: "absent", Group: "default"})
+	req.ErrorIs(err, ErrEntityNotFound)
+}
@@ -0,0 +1,101 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/multierr"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+// DeleteStreamCascade deletes the stream along with the bindings referring to it in a single transaction.
+// The index rules are kept since other subjects might bind them.
+// The stream is deleted before the bindings are notified, and the stream service drops its index data
+// once none of the bindings refers to it.
+func (e *etcdSchemaRegistry) DeleteStreamCascade(ctx context.Context, metadata *commonv1.Metadata) (bool, error) {
+	if err := e.gate.enter(); err != nil {
+		return false, err
+	}
+	defer e.gate.leave()
+	streamKey := e.keyLayout.formatStreamKey(metadata)
+	streamResp, err := e.kv.Get(ctx, streamKey)
+	if err != nil {
+		return false, err
+	}
+	if streamResp.Count < 1 {
+		return false, nil
+	}
+	stream := &databasev1.Stream{}
+	if err = unmarshal(streamResp.Kvs[0].Key, streamResp.Kvs[0].Value, stream); err != nil {
+		return false, err
+	}
+	prefix := e.keyLayout.listPrefixesForEntity(metadata.GetGroup(), e.keyLayout.IndexRuleBindingKeyPrefix)
+	bindingResp, err := e.kv.Get(ctx, prefix, clientv3.WithRange(incrementLastByte(prefix)))
+	if err != nil {
+		return false, err
+	}
+	// the entities are expected to stay as they were read
+	cmps := []clientv3.Cmp{clientv3.Compare(clientv3.ModRevision(streamKey), "=", streamResp.Kvs[0].ModRevision)}
+	ops := []clientv3.Op{clientv3.OpDelete(streamKey)}
+	var bindings []*databasev1.IndexRuleBinding
+	for _, kv := range bindingResp.Kvs {
+		binding := &databasev1.IndexRuleBinding{}
+		if innerErr := unmarshal(kv.Key, kv.Value, binding); innerErr != nil {
+			return false, innerErr
+		}
+		if binding.GetSubject().GetCatalog() != commonv1.Catalog_CATALOG_STREAM ||
+			binding.GetSubject().GetName() != metadata.GetName() {
+			continue
+		}
+		bindings = append(bindings, binding)
+		cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(string(kv.Key)), "=", kv.ModRevision))
+		ops = append(ops, clientv3.OpDelete(string(kv.Key)))
+	}
+	txnResp, err := e.kv.Txn(ctx).If(cmps...).Then(ops...).Commit()
+	if err != nil {
+		return false, err
+	}
+	if !txnResp.Succeeded {
+		return false, errors.Wrapf(ErrConcurrentModification, "stream %s", metadata.GetName())
+	}
+	revision := txnResp.Header.GetRevision()
+	notifyErr := e.notifyDelete(Metadata{
+		TypeMeta: TypeMeta{
+			Kind:  KindStream,
+			Name:  metadata.GetName(),
+			Group: metadata.GetGroup(),
+		},
+		Spec: stream,
+	}, revision)
+	for _, binding := range bindings {
+		notifyErr = multierr.Append(notifyErr, e.notifyDelete(Metadata{
+			TypeMeta: TypeMeta{
+				Kind:  KindIndexRuleBinding,
+				Name:  binding.GetMetadata().GetName(),
+				Group: binding.GetMetadata().GetGroup(),
+			},
+			Spec: binding,
+		}, revision))
+	}
+	return true, notifyErr
+}
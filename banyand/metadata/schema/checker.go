@@ -19,6 +19,7 @@ package schema
 
 import (
 	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/testing/protocmp"
 
@@ -28,13 +29,21 @@ import (
 
 type equalityChecker func(a, b proto.Message) bool
 
+// compatibilityChecker returns ErrIncompatibleSchemaChange if the incoming spec changes
+// the types of the existing tags or fields, or moves or removes the existing tags.
+type compatibilityChecker func(existing, incoming proto.Message) error
+
 var (
+	// ignoreReadonlyMetadata skips the fields assigned by the registry
+	ignoreReadonlyMetadata = protocmp.IgnoreFields(&commonv1.Metadata{}, "id", "create_revision", "mod_revision",
+		"created_at", "updated_at")
+
 	checkerMap = map[Kind]equalityChecker{
 		KindIndexRuleBinding: func(a, b proto.Message) bool {
 			return cmp.Equal(a, b,
 				protocmp.IgnoreUnknown(),
 				protocmp.IgnoreFields(&databasev1.IndexRuleBinding{}, "updated_at"),
-				protocmp.IgnoreFields(&commonv1.Metadata{}, "id", "create_revision", "mod_revision"),
+				ignoreReadonlyMetadata,
 				protocmp.Transform(),
 			)
 		},
@@ -42,7 +51,15 @@ var (
 			return cmp.Equal(a, b,
 				protocmp.IgnoreUnknown(),
 				protocmp.IgnoreFields(&databasev1.IndexRule{}, "updated_at"),
-				protocmp.IgnoreFields(&commonv1.Metadata{}, "id", "create_revision", "mod_revision"),
+				ignoreReadonlyMetadata,
+				protocmp.Transform(),
+			)
+		},
+		KindDownsamplingRule: func(a, b proto.Message) bool {
+			return cmp.Equal(a, b,
+				protocmp.IgnoreUnknown(),
+				protocmp.IgnoreFields(&databasev1.DownsamplingRule{}, "updated_at"),
+				ignoreReadonlyMetadata,
 				protocmp.Transform(),
 			)
 		},
@@ -50,7 +67,7 @@ var (
 			return cmp.Equal(a, b,
 				protocmp.IgnoreUnknown(),
 				protocmp.IgnoreFields(&databasev1.Measure{}, "updated_at"),
-				protocmp.IgnoreFields(&commonv1.Metadata{}, "id", "create_revision", "mod_revision"),
+				ignoreReadonlyMetadata,
 				protocmp.Transform(),
 			)
 		},
@@ -58,15 +75,74 @@ var (
 			return cmp.Equal(a, b,
 				protocmp.IgnoreUnknown(),
 				protocmp.IgnoreFields(&databasev1.Stream{}, "updated_at"),
-				protocmp.IgnoreFields(&commonv1.Metadata{}, "id", "create_revision", "mod_revision"),
+				ignoreReadonlyMetadata,
 				protocmp.Transform())
 		},
 		KindGroup: func(a, b proto.Message) bool {
 			return cmp.Equal(a, b,
 				protocmp.IgnoreUnknown(),
 				protocmp.IgnoreFields(&databasev1.Stream{}, "updated_at"),
-				protocmp.IgnoreFields(&commonv1.Metadata{}, "id", "create_revision", "mod_revision"),
+				ignoreReadonlyMetadata,
+				protocmp.Transform())
+		},
+		KindRetentionPolicy: func(a, b proto.Message) bool {
+			return cmp.Equal(a, b,
+				protocmp.IgnoreUnknown(),
+				protocmp.IgnoreFields(&commonv1.RetentionPolicy{}, "updated_at"),
+				ignoreReadonlyMetadata,
 				protocmp.Transform())
 		},
 	}
 )
+
+var compatibilityCheckerMap = map[Kind]compatibilityChecker{
+	KindStream: func(existing, incoming proto.Message) error {
+		return checkTagFamilies(existing.(*databasev1.Stream).GetTagFamilies(),
+			incoming.(*databasev1.Stream).GetTagFamilies())
+	},
+	KindMeasure: func(existing, incoming proto.Message) error {
+		e, i := existing.(*databasev1.Measure), incoming.(*databasev1.Measure)
+		if err := checkTagFamilies(e.GetTagFamilies(), i.GetTagFamilies()); err != nil {
+			return err
+		}
+		fields := make(map[string]databasev1.FieldType, len(i.GetFields()))
+		for _, f := range i.GetFields() {
+			fields[f.GetName()] = f.GetFieldType()
+		}
+		for _, f := range e.GetFields() {
+			if t, ok := fields[f.GetName()]; ok && t != f.GetFieldType() {
+				return errors.Wrapf(ErrIncompatibleSchemaChange, "field %s from %s to %s", f.GetName(), f.GetFieldType(), t)
+			}
+		}
+		return nil
+	},
+}
+
+// checkTagFamilies only allows appending tags and families, since the tags are written and read by their positions.
+// The incoming family at each position keeps the name and the tags of the existing one at the same positions.
+func checkTagFamilies(existing, incoming []*databasev1.TagFamilySpec) error {
+	if len(incoming) < len(existing) {
+		return errors.Wrapf(ErrIncompatibleSchemaChange, "tag family %s is removed", existing[len(incoming)].GetName())
+	}
+	for i, tf := range existing {
+		in := incoming[i]
+		if in.GetName() != tf.GetName() {
+			return errors.Wrapf(ErrIncompatibleSchemaChange, "tag family #%d from %s to %s", i, tf.GetName(), in.GetName())
+		}
+		if len(in.GetTags()) < len(tf.GetTags()) {
+			return errors.Wrapf(ErrIncompatibleSchemaChange, "tag %s.%s is removed",
+				tf.GetName(), tf.GetTags()[len(in.GetTags())].GetName())
+		}
+		for j, t := range tf.GetTags() {
+			it := in.GetTags()[j]
+			name := tf.GetName() + "." + t.GetName()
+			if it.GetName() != t.GetName() {
+				return errors.Wrapf(ErrIncompatibleSchemaChange, "tag #%d of %s from %s to %s", j, tf.GetName(), t.GetName(), it.GetName())
+			}
+			if it.GetType() != t.GetType() {
+				return errors.Wrapf(ErrIncompatibleSchemaChange, "tag %s from %s to %s", name, t.GetType(), it.GetType())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+var ErrCorruptEntity = errors.New("entity is corrupted")
+
+// checksumMagic leads a checksummed value. A valid protobuf encoding never starts with it
+// because the field number 0 is reserved, which lets checksummed and plain values coexist.
+const (
+	checksumMagic     byte = 0x00
+	checksumHeaderLen      = 1 + crc32.Size
+)
+
+// UseChecksum prefixes the stored values with a crc32 checksum.
+// Values are verified on reading whether the option is set or not.
+func UseChecksum() RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.checksum = true
+	}
+}
+
+func (e *etcdSchemaRegistry) marshal(message proto.Message) ([]byte, error) {
+	val, err := proto.Marshal(message)
+	if err != nil || !e.checksum {
+		return val, err
+	}
+	buf := make([]byte, checksumHeaderLen, checksumHeaderLen+len(val))
+	buf[0] = checksumMagic
+	binary.BigEndian.PutUint32(buf[1:], crc32.ChecksumIEEE(val))
+	return append(buf, val...), nil
+}
+
+// verifyChecksum strips the checksum header from the raw value
+func verifyChecksum(key, raw []byte) ([]byte, error) {
+	if len(raw) < 1 || raw[0] != checksumMagic {
+		return raw, nil
+	}
+	if len(raw) < checksumHeaderLen {
+		return nil, errors.Wrapf(ErrCorruptEntity, "key %s: truncated checksum", key)
+	}
+	payload := raw[checksumHeaderLen:]
+	if binary.BigEndian.Uint32(raw[1:]) != crc32.ChecksumIEEE(payload) {
+		return nil, errors.Wrapf(ErrCorruptEntity, "key %s: checksum mismatch", key)
+	}
+	return payload, nil
+}
+
+// unmarshal never panics on a malformed value. The error wraps ErrCorruptEntity and identifies the key.
+func unmarshal(key, raw []byte, message proto.Message) (err error) {
+	payload, err := verifyChecksum(key, raw)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Wrapf(ErrCorruptEntity, "key %s: %v", key, r)
+		}
+	}()
+	if err = proto.Unmarshal(payload, message); err != nil {
+		return errors.Wrapf(ErrCorruptEntity, "key %s: %v", key, err)
+	}
+	// the readonly fields are assigned to the metadata afterward
+	if m, ok := message.(HasMetadata); ok && m.GetMetadata() == nil {
+		return errors.Wrapf(ErrCorruptEntity, "key %s: absent metadata", key)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func Test_Etcd_Checksum(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir(), UseChecksum())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	meta := &commonv1.Metadata{Name: "sw", Group: "default"}
+	s, err := registry.GetStream(context.TODO(), meta)
+	req.NoError(err)
+	streams, err := registry.ListStream(context.TODO(), ListOpt{Group: "default"})
+	req.NoError(err)
+	req.Len(streams, 1)
+
+	kv := registry.(*etcdSchemaRegistry).kv
+	key := registry.(*etcdSchemaRegistry).keyLayout.formatStreamKey(meta)
+	resp, err := kv.Get(context.TODO(), key)
+	req.NoError(err)
+	raw := resp.Kvs[0].Value
+	req.Equal(checksumMagic, raw[0])
+
+	// a plain value written before the rollout
+	plain, err := proto.Marshal(s)
+	req.NoError(err)
+	_, err = kv.Put(context.TODO(), key, string(plain))
+	req.NoError(err)
+	_, err = registry.GetStream(context.TODO(), meta)
+	req.NoError(err)
+
+	corrupted := append([]byte{}, raw...)
+	corrupted[len(corrupted)-1]++
+	_, err = kv.Put(context.TODO(), key, string(corrupted))
+	req.NoError(err)
+	_, err = registry.GetStream(context.TODO(), meta)
+	req.ErrorIs(err, ErrCorruptEntity)
+	req.Contains(err.Error(), key)
+	_, err = registry.ListStream(context.TODO(), ListOpt{Group: "default"})
+	req.ErrorIs(err, ErrCorruptEntity)
+}
@@ -0,0 +1,181 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/multierr"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+// Report is the result of checking the consistency of a group
+type Report struct {
+	// DanglingBindings are the bindings whose subjects are absent
+	DanglingBindings []*databasev1.IndexRuleBinding
+	// OrphanedRules are the index rules referenced by no binding
+	OrphanedRules []*databasev1.IndexRule
+	// MissingTags are the entities referencing undefined tags
+	MissingTags []MissingTags
+}
+
+// Consistent indicates whether the check found nothing
+func (r Report) Consistent() bool {
+	return len(r.DanglingBindings) == 0 && len(r.OrphanedRules) == 0 && len(r.MissingTags) == 0
+}
+
+// MissingTags denotes an entity and the undefined tags it references
+type MissingTags struct {
+	TypeMeta
+	Tags []string
+}
+
+func (e *etcdSchemaRegistry) CheckConsistency(ctx context.Context, group string) (Report, error) {
+	var report Report
+	if group == "" {
+		return report, errors.Wrap(ErrGroupAbsent, "check consistency")
+	}
+	opt := ListOpt{Group: group}
+	streams, err := e.ListStream(ctx, opt)
+	if err != nil {
+		return report, err
+	}
+	measures, err := e.ListMeasure(ctx, opt)
+	if err != nil {
+		return report, err
+	}
+	bindings, err := e.ListIndexRuleBinding(ctx, opt)
+	if err != nil {
+		return report, err
+	}
+	rules, err := e.ListIndexRule(ctx, opt)
+	if err != nil {
+		return report, err
+	}
+	subjects := make(map[commonv1.Catalog]map[string][]*databasev1.TagFamilySpec, 2)
+	subjects[commonv1.Catalog_CATALOG_STREAM] = make(map[string][]*databasev1.TagFamilySpec, len(streams))
+	for _, s := range streams {
+		subjects[commonv1.Catalog_CATALOG_STREAM][s.GetMetadata().GetName()] = s.GetTagFamilies()
+		report.checkTags(TypeMeta{Kind: KindStream, Group: group, Name: s.GetMetadata().GetName()},
+			s.GetTagFamilies(), s.GetEntity().GetTagNames())
+	}
+	subjects[commonv1.Catalog_CATALOG_MEASURE] = make(map[string][]*databasev1.TagFamilySpec, len(measures))
+	for _, m := range measures {
+		subjects[commonv1.Catalog_CATALOG_MEASURE][m.GetMetadata().GetName()] = m.GetTagFamilies()
+		report.checkTags(TypeMeta{Kind: KindMeasure, Group: group, Name: m.GetMetadata().GetName()},
+			m.GetTagFamilies(), m.GetEntity().GetTagNames())
+	}
+	ruleMap := make(map[string]*databasev1.IndexRule, len(rules))
+	for _, r := range rules {
+		ruleMap[r.GetMetadata().GetName()] = r
+	}
+	referenced := make(map[string]bool, len(rules))
+	for _, b := range bindings {
+		families, ok := subjects[b.GetSubject().GetCatalog()][b.GetSubject().GetName()]
+		if !ok {
+			report.DanglingBindings = append(report.DanglingBindings, b)
+			continue
+		}
+		for _, name := range b.GetRules() {
+			referenced[name] = true
+			if r, exist := ruleMap[name]; exist {
+				report.checkTags(TypeMeta{Kind: KindIndexRule, Group: group, Name: name}, families, r.GetTags())
+			}
+		}
+	}
+	for _, r := range rules {
+		if !referenced[r.GetMetadata().GetName()] {
+			report.OrphanedRules = append(report.OrphanedRules, r)
+		}
+	}
+	return report, nil
+}
+
+func (r *Report) checkTags(typeMeta TypeMeta, families []*databasev1.TagFamilySpec, tags []string) {
+	defined := make(map[string]bool)
+	for _, f := range families {
+		for _, t := range f.GetTags() {
+			defined[t.GetName()] = true
+		}
+	}
+	var missing []string
+	for _, t := range tags {
+		if !defined[t] {
+			missing = append(missing, t)
+		}
+	}
+	if len(missing) > 0 {
+		r.MissingTags = append(r.MissingTags, MissingTags{TypeMeta: typeMeta, Tags: missing})
+	}
+}
+
+// Repair deletes the dangling bindings and the orphaned rules in a transaction.
+// It returns the report of the repaired group.
+func (e *etcdSchemaRegistry) Repair(ctx context.Context, group string) (Report, error) {
+	if err := e.gate.enter(); err != nil {
+		return Report{}, err
+	}
+	defer e.gate.leave()
+	report, err := e.CheckConsistency(ctx, group)
+	if err != nil {
+		return report, err
+	}
+	deleted := make([]Metadata, 0, len(report.DanglingBindings)+len(report.OrphanedRules))
+	for _, b := range report.DanglingBindings {
+		deleted = append(deleted, Metadata{
+			TypeMeta: TypeMeta{Kind: KindIndexRuleBinding, Group: group, Name: b.GetMetadata().GetName()},
+			Spec:     b,
+		})
+	}
+	for _, r := range report.OrphanedRules {
+		deleted = append(deleted, Metadata{
+			TypeMeta: TypeMeta{Kind: KindIndexRule, Group: group, Name: r.GetMetadata().GetName()},
+			Spec:     r,
+		})
+	}
+	if len(deleted) == 0 {
+		return report, nil
+	}
+	cmps := make([]clientv3.Cmp, 0, len(deleted))
+	ops := make([]clientv3.Op, 0, len(deleted))
+	for _, md := range deleted {
+		key, errKey := e.keyLayout.Key(md)
+		if errKey != nil {
+			return report, errKey
+		}
+		modRevision := md.Spec.(HasMetadata).GetMetadata().GetModRevision()
+		cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(key), "=", modRevision))
+		ops = append(ops, clientv3.OpDelete(key))
+	}
+	resp, err := e.kv.Txn(ctx).If(cmps...).Then(ops...).Commit()
+	if err != nil {
+		return report, err
+	}
+	if !resp.Succeeded {
+		return report, ErrConcurrentModification
+	}
+	var notifyErr error
+	for _, md := range deleted {
+		notifyErr = multierr.Append(notifyErr, e.notifyDelete(md, resp.Header.GetRevision()))
+	}
+	return report, notifyErr
+}
@@ -0,0 +1,75 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var ErrMalformedCounter = errors.New("the counter is malformed")
+
+// Counter is a cluster-wide atomic counter backed by the storage of the registry,
+// which serves the controllers without extra infrastructure.
+type Counter interface {
+	// IncrementCounter adds delta to the counter of the key and returns the new value.
+	// An absent counter starts from zero, and a zero delta reads the counter.
+	// The concurrent increments of a key never lose each other.
+	IncrementCounter(ctx context.Context, key string, delta int64) (int64, error)
+}
+
+func (e *etcdSchemaRegistry) IncrementCounter(ctx context.Context, key string, delta int64) (value int64, err error) {
+	ctx, span := e.startSpan(ctx, "increment_counter", func() []attribute.KeyValue {
+		return []attribute.KeyValue{attribute.String("schema.counter", key)}
+	})
+	defer func() { span.end(err) }()
+	if key == "" {
+		return 0, errors.Wrap(ErrMalformedCounter, "empty key")
+	}
+	// the STM commits through the client directly, so the gate guards the whole increment
+	if err = e.gate.enter(); err != nil {
+		return 0, err
+	}
+	defer e.gate.leave()
+	abortCtx, cancel := e.withRequestTimeout(ctx)
+	defer cancel()
+	counterKey := e.keyLayout.CounterKeyPrefix + key
+	resp, err := e.backend.transact(abortCtx, func(stm txnStore) error {
+		value = 0
+		if raw := stm.Get(counterKey); raw != "" {
+			current, errParse := strconv.ParseInt(raw, 10, 64)
+			if errParse != nil {
+				return errors.Wrapf(ErrMalformedCounter, "key %s: %v", key, errParse)
+			}
+			value = current
+		}
+		value += delta
+		if delta != 0 {
+			stm.Put(counterKey, strconv.FormatInt(value, 10))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	span.setRevision(resp.Header.GetRevision())
+	return value, nil
+}
@@ -0,0 +1,99 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Etcd_IncrementCounter(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+
+	value, err := registry.IncrementCounter(context.TODO(), "ops", 0)
+	req.NoError(err)
+	req.Zero(value)
+	value, err = registry.IncrementCounter(context.TODO(), "ops", 5)
+	req.NoError(err)
+	req.Equal(int64(5), value)
+	value, err = registry.IncrementCounter(context.TODO(), "ops", -2)
+	req.NoError(err)
+	req.Equal(int64(3), value)
+
+	// the concurrent increments never lose each other
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				_, errInc := registry.IncrementCounter(context.TODO(), "ops", 1)
+				errs <- errInc
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for errInc := range errs {
+		req.NoError(errInc)
+	}
+	value, err = registry.IncrementCounter(context.TODO(), "ops", 0)
+	req.NoError(err)
+	req.Equal(int64(53), value)
+
+	// the counters are apart from each other
+	value, err = registry.IncrementCounter(context.TODO(), "others", 1)
+	req.NoError(err)
+	req.Equal(int64(1), value)
+
+	_, err = registry.IncrementCounter(context.TODO(), "", 1)
+	req.ErrorIs(err, ErrMalformedCounter)
+	_, err = registry.(*etcdSchemaRegistry).kv.Put(context.TODO(), CounterKeyPrefix+"broken", "x")
+	req.NoError(err)
+	_, err = registry.IncrementCounter(context.TODO(), "broken", 1)
+	req.ErrorIs(err, ErrMalformedCounter)
+}
+
+func Test_Bolt_IncrementCounter(t *testing.T) {
+	req := require.New(t)
+	path := filepath.Join(t.TempDir(), "schema.db")
+	registry, err := NewBoltSchemaRegistry(path)
+	req.NoError(err)
+	<-registry.ReadyNotify()
+	value, err := registry.IncrementCounter(context.TODO(), "ops", 2)
+	req.NoError(err)
+	req.Equal(int64(2), value)
+	req.NoError(registry.Close())
+	<-registry.StopNotify()
+
+	registry, err = NewBoltSchemaRegistry(path)
+	req.NoError(err)
+	defer registry.Close()
+	value, err = registry.IncrementCounter(context.TODO(), "ops", 1)
+	req.NoError(err)
+	req.Equal(int64(3), value)
+}
@@ -0,0 +1,107 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/apache/skywalking-banyandb/pkg/meter"
+)
+
+const defaultEntityCountInterval = time.Minute
+
+// EntityCount is the number of the entities of a kind in a group
+type EntityCount struct {
+	Group string
+	Kind  Kind
+	Count int64
+}
+
+// ObserveEntityCounts feeds the entity counts of each group into the observer every interval until the registry is closed.
+// A round takes a count-only range per group per kind, so the values are never transferred.
+// Nothing is counted without this option. A non-positive interval falls back to a minute.
+func ObserveEntityCounts(observer meter.MetricsObserver, interval time.Duration) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.countObserver = observer
+		config.countObserveInterval = interval
+	}
+}
+
+// ObserveEntityCountStats feeds the counts into the observer as gauges labeled by the group and the kind.
+func ObserveEntityCountStats(observer meter.MetricsObserver, counts []EntityCount) {
+	for _, c := range counts {
+		observer.Gauge("schema_entity_count", float64(c.Count), meter.Labels{
+			"group": c.Group,
+			"kind":  kindName(c.Kind),
+		})
+	}
+}
+
+// EntityCounts counts the streams, measures, index rules, index rule bindings and downsampling rules of each group.
+// A kind absent from a group is counted as zero.
+func (e *etcdSchemaRegistry) EntityCounts(ctx context.Context) ([]EntityCount, error) {
+	groups, err := e.ListGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefixes := []struct {
+		kind   Kind
+		prefix string
+	}{
+		{KindStream, e.keyLayout.StreamKeyPrefix},
+		{KindMeasure, e.keyLayout.MeasureKeyPrefix},
+		{KindIndexRule, e.keyLayout.IndexRuleKeyPrefix},
+		{KindIndexRuleBinding, e.keyLayout.IndexRuleBindingKeyPrefix},
+		{KindDownsamplingRule, e.keyLayout.DownsamplingRuleKeyPrefix},
+	}
+	counts := make([]EntityCount, 0, len(groups)*len(prefixes))
+	for _, g := range groups {
+		group := g.GetMetadata().GetName()
+		for _, p := range prefixes {
+			prefix := e.keyLayout.listPrefixesForEntity(group, p.prefix)
+			resp, innerErr := e.kv.Get(ctx, prefix, clientv3.WithRange(incrementLastByte(prefix)), clientv3.WithCountOnly())
+			if innerErr != nil {
+				return nil, errors.Wrapf(innerErr, "count %s of %s", kindName(p.kind), group)
+			}
+			counts = append(counts, EntityCount{Group: group, Kind: p.kind, Count: resp.Count})
+		}
+	}
+	return counts, nil
+}
+
+// observeEntityCounts skips a failed round, the next one reports the latest counts anyway
+func (e *etcdSchemaRegistry) observeEntityCounts(observer meter.MetricsObserver, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			counts, err := e.EntityCounts(context.Background())
+			if err != nil {
+				continue
+			}
+			ObserveEntityCountStats(observer, counts)
+		case <-e.StoppingNotify():
+			return
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/apache/skywalking-banyandb/pkg/meter"
+)
+
+var _ meter.MetricsObserver = (*countRecorder)(nil)
+
+// countRecorder keeps the latest count of each group and kind
+type countRecorder struct {
+	mu     sync.Mutex
+	counts map[string]float64
+}
+
+func (c *countRecorder) Gauge(name string, value float64, labels meter.Labels) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[name+"/"+labels["group"]+"/"+labels["kind"]] = value
+}
+
+func (c *countRecorder) get(key string) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.counts[key]
+	return v, ok
+}
+
+func Test_Etcd_EntityCounts(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+	req.NoError(updateGroup(registry, "empty"))
+
+	counts, err := registry.EntityCounts(context.TODO())
+	req.NoError(err)
+	got := make(map[string]map[Kind]int64)
+	for _, c := range counts {
+		if got[c.Group] == nil {
+			got[c.Group] = make(map[Kind]int64)
+		}
+		got[c.Group][c.Kind] = c.Count
+	}
+	req.Equal(map[Kind]int64{
+		KindStream:           1,
+		KindMeasure:          0,
+		KindIndexRule:        10,
+		KindIndexRuleBinding: 1,
+		KindDownsamplingRule: 0,
+	}, got["default"])
+	req.Len(got["empty"], 5)
+	for _, c := range got["empty"] {
+		req.Zero(c)
+	}
+}
+
+func Test_Etcd_ObserveEntityCounts(t *testing.T) {
+	req := require.New(t)
+	observer := &countRecorder{counts: make(map[string]float64)}
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir(), ObserveEntityCounts(observer, 10*time.Millisecond))
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	req.Eventually(func() bool {
+		v, ok := observer.get("schema_entity_count/default/index_rule")
+		return ok && v == 10
+	}, 5*time.Second, 10*time.Millisecond)
+	v, ok := observer.get("schema_entity_count/default/stream")
+	req.True(ok)
+	req.Equal(float64(1), v)
+}
@@ -0,0 +1,112 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+var ErrInvalidDownsamplingRule = errors.New("the downsampling rule is invalid")
+
+func (e *etcdSchemaRegistry) GetDownsamplingRule(ctx context.Context, metadata *commonv1.Metadata,
+	opts ...ReadOption) (*databasev1.DownsamplingRule, error) {
+	var entity databasev1.DownsamplingRule
+	if err := e.getInGroup(ctx, metadata, e.keyLayout.formatDownsamplingRuleKey, &entity, opts...); err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (e *etcdSchemaRegistry) ListDownsamplingRule(ctx context.Context, opt ListOpt) ([]*databasev1.DownsamplingRule, error) {
+	if opt.Group == "" {
+		return nil, errors.Wrap(ErrGroupAbsent, "list downsampling rule")
+	}
+	messages, err := e.listWithPrefix(ctx, e.keyLayout.listPrefixesForEntity(opt.Group, e.keyLayout.DownsamplingRuleKeyPrefix),
+		func() proto.Message {
+			return &databasev1.DownsamplingRule{}
+		})
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*databasev1.DownsamplingRule, 0, len(messages))
+	for _, message := range messages {
+		entities = append(entities, message.(*databasev1.DownsamplingRule))
+	}
+	return entities, nil
+}
+
+// ListAllDownsamplingRules lists downsampling rules in all groups
+func (e *etcdSchemaRegistry) ListAllDownsamplingRules(ctx context.Context) ([]*databasev1.DownsamplingRule, error) {
+	messages, err := e.listInAllGroups(ctx, e.keyLayout.DownsamplingRuleKeyPrefix, func() proto.Message {
+		return &databasev1.DownsamplingRule{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*databasev1.DownsamplingRule, 0, len(messages))
+	for _, message := range messages {
+		entities = append(entities, message.(*databasev1.DownsamplingRule))
+	}
+	return entities, nil
+}
+
+// UpdateDownsamplingRule fails with ErrInvalidDownsamplingRule if the rule rolls up a measure into itself
+// or the interval isn't positive
+func (e *etcdSchemaRegistry) UpdateDownsamplingRule(ctx context.Context, rule *databasev1.DownsamplingRule, opts ...WriteOption) error {
+	if err := checkDownsamplingRule(rule); err != nil {
+		return err
+	}
+	return e.update(ctx, Metadata{
+		TypeMeta: TypeMeta{
+			Kind:  KindDownsamplingRule,
+			Name:  rule.GetMetadata().GetName(),
+			Group: rule.GetMetadata().GetGroup(),
+		},
+		Spec: rule,
+	}, opts)
+}
+
+func (e *etcdSchemaRegistry) DeleteDownsamplingRule(ctx context.Context, metadata *commonv1.Metadata) (bool, error) {
+	return e.delete(ctx, Metadata{
+		TypeMeta: TypeMeta{
+			Kind:  KindDownsamplingRule,
+			Name:  metadata.GetName(),
+			Group: metadata.GetGroup(),
+		},
+	})
+}
+
+func checkDownsamplingRule(rule *databasev1.DownsamplingRule) error {
+	name := rule.GetMetadata().GetName()
+	if rule.GetSource() == "" || rule.GetTarget() == "" {
+		return errors.Wrapf(ErrInvalidDownsamplingRule, "the source and the target of %s are required", name)
+	}
+	if rule.GetSource() == rule.GetTarget() {
+		return errors.Wrapf(ErrInvalidDownsamplingRule, "%s rolls up %s into itself", name, rule.GetSource())
+	}
+	if rule.GetInterval().AsDuration() <= 0 {
+		return errors.Wrapf(ErrInvalidDownsamplingRule, "the interval of %s isn't positive", name)
+	}
+	return nil
+}
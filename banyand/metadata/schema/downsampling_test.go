@@ -0,0 +1,88 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+func Test_Etcd_DownsamplingRule(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+	handler := newRecordingHandler()
+	close(handler.gate)
+	registry.RegisterHandler(KindDownsamplingRule, handler)
+
+	rule := func(name, source, target string, interval time.Duration) *databasev1.DownsamplingRule {
+		return &databasev1.DownsamplingRule{
+			Metadata: &commonv1.Metadata{Group: "default", Name: name},
+			Source:   source,
+			Target:   target,
+			Interval: durationpb.New(interval),
+		}
+	}
+	req.ErrorIs(registry.UpdateDownsamplingRule(context.TODO(), rule("to_minute", "cpm", "", time.Minute)),
+		ErrInvalidDownsamplingRule)
+	req.ErrorIs(registry.UpdateDownsamplingRule(context.TODO(), rule("to_minute", "cpm", "cpm", time.Minute)),
+		ErrInvalidDownsamplingRule)
+	req.ErrorIs(registry.UpdateDownsamplingRule(context.TODO(), rule("to_minute", "cpm", "cpm_minute", 0)),
+		ErrInvalidDownsamplingRule)
+
+	req.NoError(registry.UpdateDownsamplingRule(context.TODO(), rule("to_minute", "cpm", "cpm_minute", time.Minute)))
+	req.NoError(registry.UpdateDownsamplingRule(context.TODO(), rule("to_hour", "cpm_minute", "cpm_hour", time.Hour)))
+	// the same rule isn't notified again
+	req.NoError(registry.UpdateDownsamplingRule(context.TODO(), rule("to_hour", "cpm_minute", "cpm_hour", time.Hour)))
+	r, err := registry.GetDownsamplingRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "to_hour"})
+	req.NoError(err)
+	req.Equal(time.Hour, r.GetInterval().AsDuration())
+	req.NotNil(r.GetMetadata().GetCreatedAt())
+	rules, err := registry.ListDownsamplingRule(context.TODO(), ListOpt{Group: "default"})
+	req.NoError(err)
+	req.Len(rules, 2)
+	_, err = registry.ListDownsamplingRule(context.TODO(), ListOpt{})
+	req.ErrorIs(err, ErrGroupAbsent)
+	rules, err = registry.ListAllDownsamplingRules(context.TODO())
+	req.NoError(err)
+	req.Len(rules, 2)
+	// the rules are out of the index rules
+	indexRules, err := registry.ListIndexRule(context.TODO(), ListOpt{Group: "default"})
+	req.NoError(err)
+	req.Len(indexRules, 10)
+
+	deleted, err := registry.DeleteDownsamplingRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "to_minute"})
+	req.NoError(err)
+	req.True(deleted)
+	deleted, err = registry.DeleteDownsamplingRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "to_minute"})
+	req.NoError(err)
+	req.False(deleted)
+	_, err = registry.GetDownsamplingRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "to_minute"})
+	req.ErrorIs(err, ErrEntityNotFound)
+	req.Equal([]string{"update to_minute", "update to_hour", "delete to_minute"}, handler.recorded())
+}
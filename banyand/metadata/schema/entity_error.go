@@ -0,0 +1,62 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"fmt"
+)
+
+var _ error = (*EntityError)(nil)
+
+// EntityError tells which entity an operation failed on. It unwraps to the cause, so errors.Is(err, ErrConcurrentModification)
+// and the checks of the other sentinels keep working. The missing entities are reported by NotFoundError instead.
+type EntityError struct {
+	TypeMeta
+	Err error
+}
+
+// entityError attaches the entity to the error of the registry. A nil error stays nil.
+func entityError(typeMeta TypeMeta, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &EntityError{TypeMeta: typeMeta, Err: err}
+}
+
+// typeMetaOf identifies the entity by its key. The Kind is zero and the Name is the key if the key isn't recognized.
+func (l KeyLayout) typeMetaOf(key string) TypeMeta {
+	md, err := l.ParseKey(key)
+	if err != nil {
+		return TypeMeta{Name: key}
+	}
+	return md.TypeMeta
+}
+
+func (e *EntityError) Error() string {
+	if e.Kind == 0 {
+		return fmt.Sprintf("key %s: %v", e.Name, e.Err)
+	}
+	if e.Group == "" {
+		return fmt.Sprintf("%s %s: %v", kindName(e.Kind), e.Name, e.Err)
+	}
+	return fmt.Sprintf("%s %s/%s: %v", kindName(e.Kind), e.Group, e.Name, e.Err)
+}
+
+func (e *EntityError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,73 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func Test_Etcd_EntityError(t *testing.T) {
+	req := require.New(t)
+	dir := randomTempDir()
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), RootDir(dir))
+	req.NoError(err)
+	req.NoError(preloadSchema(registry))
+	req.NoError(registry.Close())
+	<-registry.StopNotify()
+
+	registry, err = NewEtcdSchemaRegistry(useUnixDomain(), RootDir(dir), ReadOnly())
+	req.NoError(err)
+	defer registry.Close()
+	md := &commonv1.Metadata{Name: "sw", Group: "default"}
+	s, err := registry.GetStream(context.TODO(), md)
+	req.NoError(err)
+	s.Entity.TagNames = append(s.Entity.TagNames, "trace_id")
+
+	var entityErr *EntityError
+	err = registry.UpdateStream(context.TODO(), s)
+	req.ErrorIs(err, ErrReadOnly)
+	req.True(errors.As(err, &entityErr))
+	req.Equal(TypeMeta{Kind: KindStream, Group: "default", Name: "sw"}, entityErr.TypeMeta)
+
+	_, err = registry.DeleteIndexRule(context.TODO(), &commonv1.Metadata{Name: "db.instance", Group: "default"})
+	req.ErrorIs(err, ErrReadOnly)
+	req.True(errors.As(err, &entityErr))
+	req.Equal(TypeMeta{Kind: KindIndexRule, Group: "default", Name: "db.instance"}, entityErr.TypeMeta)
+}
+
+func Test_EntityError(t *testing.T) {
+	req := require.New(t)
+	err := entityError(TypeMeta{Kind: KindMeasure, Group: "sw_metric", Name: "service_cpm"}, ErrConcurrentModification)
+	req.ErrorIs(err, ErrConcurrentModification)
+	req.Equal("measure sw_metric/service_cpm: concurrent modification of entities", err.Error())
+	err = entityError(TypeMeta{Kind: KindGroup, Name: "default"}, ErrUnexpectedNumberOfEntities)
+	req.Equal("group default: unexpected number of entities", err.Error())
+	err = entityError(DefaultKeyLayout().typeMetaOf("/unknown"), ErrUnexpectedNumberOfEntities)
+	req.Equal("key /unknown: unexpected number of entities", err.Error())
+	req.NoError(entityError(TypeMeta{Kind: KindGroup}, nil))
+}
@@ -24,15 +24,23 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
-	"strings"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/server/v3/embed"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
 )
 
 var (
@@ -41,20 +49,20 @@ var (
 	_ IndexRule        = (*etcdSchemaRegistry)(nil)
 	_ Measure          = (*etcdSchemaRegistry)(nil)
 	_ Group            = (*etcdSchemaRegistry)(nil)
+	_ Maintenance      = (*etcdSchemaRegistry)(nil)
+	_ Batch            = (*etcdSchemaRegistry)(nil)
+	_ RetentionPolicy  = (*etcdSchemaRegistry)(nil)
+	_ DownsamplingRule = (*etcdSchemaRegistry)(nil)
+	_ Bundle           = (*etcdSchemaRegistry)(nil)
+	_ Transactional    = (*etcdSchemaRegistry)(nil)
 
 	ErrGroupAbsent                = errors.New("group is absent")
 	ErrEntityNotFound             = errors.New("entity is not found")
 	ErrUnexpectedNumberOfEntities = errors.New("unexpected number of entities")
 	ErrConcurrentModification     = errors.New("concurrent modification of entities")
+	ErrInvalidRevisionRange       = errors.New("the revision range is invalid")
 
 	unixDomainSockScheme = "unix"
-
-	GroupsKeyPrefix           = "/groups/"
-	GroupMetadataKey          = "/__meta_group__"
-	StreamKeyPrefix           = "/streams/"
-	IndexRuleBindingKeyPrefix = "/index-rule-bindings/"
-	IndexRuleKeyPrefix        = "/index-rules/"
-	MeasureKeyPrefix          = "/measures/"
 )
 
 type HasMetadata interface {
@@ -71,7 +79,10 @@ func RootDir(rootDir string) RegistryOption {
 }
 
 func randomUnixDomainListener() (string, string) {
-	i := rand.Uint64()
+	return unixDomainListener(rand.Uint64())
+}
+
+func unixDomainListener(i uint64) (string, string) {
 	return fmt.Sprintf("%s://localhost:%d%06d", unixDomainSockScheme, os.Getpid(), i),
 		fmt.Sprintf("%s://localhost:%d%06d", unixDomainSockScheme, os.Getpid(), i+1)
 }
@@ -84,19 +95,82 @@ func UseRandomListener() RegistryOption {
 	}
 }
 
+// UseRandomListenerWithSource picks the random listener from the source instead of the global one.
+// A seeded source makes the listener reproducible in tests.
+// The source is not safe for concurrent use, so don't share it among goroutines.
+func UseRandomListenerWithSource(source *rand.Rand) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		lc, lp := unixDomainListener(source.Uint64())
+		config.listenerClientURL = lc
+		config.listenerPeerURL = lp
+	}
+}
+
 type eventHandler struct {
+	name         string
 	interestKeys Kind
-	handler      EventHandler
+	// group limits the events to the ones of the group. It's empty if the handler is interested in all the groups.
+	group string
+	// priority decides the order of notification, the higher the earlier
+	priority int
+	handler  EventHandler
 }
 
 func (eh *eventHandler) InterestOf(kind Kind) bool {
 	return KindMask&kind&eh.interestKeys != 0
 }
 
+// interestedIn filters the event by both the kind and the group. A resync is never filtered by the group.
+func (eh *eventHandler) interestedIn(ev event) bool {
+	if !eh.InterestOf(ev.metadata.Kind) {
+		return false
+	}
+	if eh.group == "" || ev.resync {
+		return true
+	}
+	group := ev.metadata.Group
+	if ev.metadata.Kind == KindGroup {
+		group = ev.metadata.Name
+	}
+	return group == eh.group
+}
+
+// deliver skips the resync if the handler isn't a Resyncer
+func (eh *eventHandler) deliver(ev event) {
+	switch {
+	case ev.resync:
+		if r, ok := eh.handler.(Resyncer); ok {
+			r.OnResync()
+		}
+	case ev.deleted:
+		eh.handler.OnDelete(ev.metadata)
+	default:
+		eh.handler.OnAddOrUpdate(ev.metadata)
+	}
+}
+
 type etcdSchemaRegistry struct {
-	server   *embed.Etcd
-	kv       clientv3.KV
-	handlers []*eventHandler
+	backend        registryBackend
+	kv             clientv3.KV
+	handlersMu     sync.RWMutex
+	handlers       []*eventHandler
+	queues         []*eventQueue
+	queueSize      int
+	overflowPolicy OverflowPolicy
+	checksum       bool
+	keyLayout      KeyLayout
+	gate           *writeGate
+	lease          leaseGranter
+	// idempotencyKeyTTL is how long the applied idempotency keys are kept
+	idempotencyKeyTTL time.Duration
+	maintenanceMu     sync.Mutex
+	// maintenance counts the nested maintenances. The events are suppressed while it's positive.
+	maintenance     int
+	suppressedKinds Kind
+	// tracer is nil if the registry isn't traced
+	tracer trace.Tracer
+	// requestTimeout bounds the requests which don't go through the kv
+	requestTimeout time.Duration
 }
 
 type etcdSchemaRegistryConfig struct {
@@ -106,34 +180,74 @@ type etcdSchemaRegistryConfig struct {
 	listenerClientURL string
 	// listenerPeerURL is the listener for peer
 	listenerPeerURL string
+	// checksum prefixes the stored values with a checksum
+	checksum bool
+	// keyLayout decides where the entities are stored
+	keyLayout KeyLayout
+	// readOnly rejects all the writes
+	readOnly bool
+	// learnerOf are the endpoints of the cluster the embedded etcd joins as a learner
+	learnerOf []string
+	// queueSize enables the async delivery of the events if it's positive
+	queueSize      int
+	overflowPolicy OverflowPolicy
+	// queueObserver receives the stats of the event queues periodically if it's present
+	queueObserver        meter.MetricsObserver
+	queueObserveInterval time.Duration
+	// countObserver receives the entity counts periodically if it's present
+	countObserver        meter.MetricsObserver
+	countObserveInterval time.Duration
+	idempotencyKeyTTL    time.Duration
+	// tracerProvider traces the operations if it's present
+	tracerProvider trace.TracerProvider
+	// storageTuners apply the storage options to the embedded etcd in order
+	storageTuners []storageTuner
+	// requestTimeout bounds every etcd request if it's positive
+	requestTimeout time.Duration
 }
 
 func (e *etcdSchemaRegistry) RegisterHandler(kind Kind, handler EventHandler) {
-	e.handlers = append(e.handlers, &eventHandler{
-		interestKeys: kind,
-		handler:      handler,
-	})
+	e.RegisterHandlerWithPriority(kind, "", DefaultHandlerPriority, handler)
 }
 
-func (e *etcdSchemaRegistry) notifyUpdate(metadata Metadata) {
-	for _, h := range e.handlers {
-		if h.InterestOf(metadata.Kind) {
-			h.handler.OnAddOrUpdate(metadata)
-		}
-	}
+func (e *etcdSchemaRegistry) RegisterHandlerForGroup(kind Kind, group string, handler EventHandler) {
+	e.RegisterHandlerWithPriority(kind, group, DefaultHandlerPriority, handler)
 }
 
-func (e *etcdSchemaRegistry) notifyDelete(metadata Metadata) {
-	for _, h := range e.handlers {
-		if h.InterestOf(metadata.Kind) {
-			h.handler.OnDelete(metadata)
-		}
+func (e *etcdSchemaRegistry) RegisterHandlerWithPriority(kind Kind, group string, priority int, handler EventHandler) {
+	name, handler := unwrapHandler(handler)
+	h := &eventHandler{
+		name:         name,
+		interestKeys: kind,
+		group:        group,
+		priority:     priority,
+		handler:      handler,
+	}
+	e.handlersMu.Lock()
+	defer e.handlersMu.Unlock()
+	// the handlers are kept in the order of notification. A handler goes after the ones of the same priority.
+	i := sort.Search(len(e.handlers), func(i int) bool {
+		return e.handlers[i].priority < priority
+	})
+	// notify reads the slices without the lock, so they are copied rather than shifted in place
+	handlers := make([]*eventHandler, 0, len(e.handlers)+1)
+	handlers = append(handlers, e.handlers[:i]...)
+	handlers = append(handlers, h)
+	e.handlers = append(handlers, e.handlers[i:]...)
+	if e.queueSize > 0 {
+		queues := make([]*eventQueue, 0, len(e.queues)+1)
+		queues = append(queues, e.queues[:i]...)
+		queues = append(queues, newEventQueue(h, e.queueSize, e.overflowPolicy))
+		e.queues = append(queues, e.queues[i:]...)
 	}
 }
 
-func (e *etcdSchemaRegistry) GetGroup(ctx context.Context, group string) (*commonv1.Group, error) {
+// GetGroup returns the target group if the group is an alias
+func (e *etcdSchemaRegistry) GetGroup(ctx context.Context, group string, opts ...ReadOption) (*commonv1.Group, error) {
 	var entity commonv1.Group
-	err := e.get(ctx, formatGroupKey(group), &entity)
+	err := e.getInGroup(ctx, &commonv1.Metadata{Group: group}, func(metadata *commonv1.Metadata) string {
+		return e.keyLayout.formatGroupKey(metadata.GetGroup())
+	}, &entity, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -141,72 +255,195 @@ func (e *etcdSchemaRegistry) GetGroup(ctx context.Context, group string) (*commo
 }
 
 func (e *etcdSchemaRegistry) ListGroup(ctx context.Context) ([]*commonv1.Group, error) {
-	messages, err := e.kv.Get(ctx, GroupsKeyPrefix, clientv3.WithFromKey(), clientv3.WithRange(incrementLastByte(GroupsKeyPrefix)))
+	return e.ListGroupByPrefix(ctx, "")
+}
+
+// ListGroupByPrefix only ranges the keys of the groups whose names start with namePrefix.
+// An empty namePrefix lists all the groups.
+func (e *etcdSchemaRegistry) ListGroupByPrefix(ctx context.Context, namePrefix string) ([]*commonv1.Group, error) {
+	prefix := e.keyLayout.GroupMetadataKeyPrefix + namePrefix
+	messages, err := e.kv.Get(ctx, prefix, clientv3.WithFromKey(), clientv3.WithRange(incrementLastByte(prefix)))
 	if err != nil {
 		return nil, err
 	}
 
-	var groups []*commonv1.Group
+	groups := make([]*commonv1.Group, 0, len(messages.Kvs))
 	for _, kv := range messages.Kvs {
-		// kv.Key = "/groups/" + {group} + "/__meta_info__"
-		if strings.HasSuffix(string(kv.Key), GroupMetadataKey) {
-			message := &commonv1.Group{}
-			if innerErr := proto.Unmarshal(kv.Value, message); innerErr != nil {
-				return nil, innerErr
-			}
-			groups = append(groups, message)
+		message := &commonv1.Group{}
+		if innerErr := unmarshal(kv.Key, kv.Value, message); innerErr != nil {
+			return nil, innerErr
 		}
+		groups = append(groups, message)
 	}
 
 	return groups, nil
 }
 
 func (e *etcdSchemaRegistry) DeleteGroup(ctx context.Context, group string) (bool, error) {
-	g, err := e.GetGroup(ctx, group)
-	if err != nil {
+	if err := e.gate.enter(); err != nil {
+		return false, err
+	}
+	defer e.gate.leave()
+	// an alias never deletes its target
+	g := &commonv1.Group{}
+	if err := e.get(ctx, e.keyLayout.formatGroupKey(group), g); err != nil {
 		return false, errors.Wrap(err, group)
 	}
-	keyPrefix := GroupsKeyPrefix + g.GetMetadata().GetName() + "/"
-	resp, err := e.kv.Delete(ctx, keyPrefix, clientv3.WithRange(incrementLastByte(keyPrefix)))
+	name := g.GetMetadata().GetName()
+	keyPrefix := e.keyLayout.GroupsKeyPrefix + name + "/"
+	// the group and its entities are deleted at once
+	resp, err := e.kv.Txn(ctx).Then(
+		clientv3.OpDelete(e.keyLayout.formatGroupKey(name)),
+		clientv3.OpDelete(keyPrefix, clientv3.WithRange(incrementLastByte(keyPrefix))),
+	).Commit()
 	if err != nil {
 		return false, err
 	}
-	if resp.Deleted > 0 {
-		e.notifyDelete(Metadata{
+	var deleted int64
+	for _, r := range resp.Responses {
+		deleted += r.GetResponseDeleteRange().GetDeleted()
+	}
+	if deleted > 0 {
+		return true, e.notifyDelete(Metadata{
 			TypeMeta: TypeMeta{
 				Kind: KindGroup,
 				Name: group,
 			},
 			Spec: g,
-		})
+		}, resp.Header.GetRevision())
 	}
 
 	return true, nil
 }
 
-func (e *etcdSchemaRegistry) UpdateGroup(ctx context.Context, group *commonv1.Group) error {
-	return e.update(ctx, Metadata{
+// UpdateGroup emits the update events of the index rules inheriting the default analyzer if it changes
+func (e *etcdSchemaRegistry) UpdateGroup(ctx context.Context, group *commonv1.Group, opts ...WriteOption) error {
+	analyzer, err := defaultAnalyzer(group)
+	if err != nil {
+		return err
+	}
+	// a retry emits no events, even though the analyzer is changed by others in between
+	applied, err := e.applied(ctx, newWriteOptions(opts), e.keyLayout.formatGroupKey(group.GetMetadata().GetName()))
+	if err != nil || applied {
+		return err
+	}
+	aliasCmp, err := e.checkGroupNotAliased(ctx, group.GetMetadata().GetName())
+	if err != nil {
+		return err
+	}
+	cmps := []clientv3.Cmp{aliasCmp}
+	prevAnalyzer := databasev1.IndexRule_ANALYZER_UNSPECIFIED
+	existing, err := e.GetGroup(ctx, group.GetMetadata().GetName())
+	switch {
+	case errors.Is(err, ErrEntityNotFound):
+	case err != nil:
+		return err
+	default:
+		// an unknown analyzer stored before is treated as absent
+		prevAnalyzer, _ = defaultAnalyzer(existing)
+	}
+	if policy := group.GetResourceOpts().GetRetentionPolicy(); policy != "" {
+		cmp, innerErr := e.retentionPolicyExists(ctx, policy)
+		if innerErr != nil {
+			return innerErr
+		}
+		cmps = append(cmps, cmp)
+	}
+	if err = e.update(ctx, Metadata{
 		TypeMeta: TypeMeta{
 			Kind: KindGroup,
 			Name: group.GetMetadata().GetName(),
 		},
 		Spec: group,
-	})
+	}, opts, cmps...); err != nil {
+		return err
+	}
+	if analyzer == prevAnalyzer {
+		return nil
+	}
+	return e.notifyInheritingIndexRules(ctx, group.GetMetadata().GetName())
 }
 
-func (e *etcdSchemaRegistry) GetMeasure(ctx context.Context, metadata *commonv1.Metadata) (*databasev1.Measure, error) {
+func (e *etcdSchemaRegistry) GetMeasure(ctx context.Context, metadata *commonv1.Metadata, opts ...ReadOption) (*databasev1.Measure, error) {
 	var entity databasev1.Measure
-	if err := e.get(ctx, formatMeasureKey(metadata), &entity); err != nil {
+	if err := e.getInGroup(ctx, metadata, e.keyLayout.formatMeasureKey, &entity, opts...); err != nil {
 		return nil, err
 	}
 	return &entity, nil
 }
 
+func (e *etcdSchemaRegistry) GetMeasureWithMeta(ctx context.Context, metadata *commonv1.Metadata,
+	opts ...ReadOption) (*databasev1.Measure, RevisionInfo, error) {
+	var info RevisionInfo
+	entity, err := e.GetMeasure(ctx, metadata, append(opts, withRevisionInfo(&info))...)
+	if err != nil {
+		return nil, RevisionInfo{}, err
+	}
+	return entity, info, nil
+}
+
 func (e *etcdSchemaRegistry) ListMeasure(ctx context.Context, opt ListOpt) ([]*databasev1.Measure, error) {
 	if opt.Group == "" {
 		return nil, errors.Wrap(ErrGroupAbsent, "list measure")
 	}
-	messages, err := e.listWithPrefix(ctx, listPrefixesForEntity(opt.Group, MeasureKeyPrefix), func() proto.Message {
+	messages, err := e.listWithPrefix(ctx, e.keyLayout.listPrefixesForEntity(opt.Group, e.keyLayout.MeasureKeyPrefix), func() proto.Message {
+		return &databasev1.Measure{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*databasev1.Measure, 0, len(messages))
+	for _, message := range messages {
+		entities = append(entities, message.(*databasev1.Measure))
+	}
+	return entities, nil
+}
+
+// ListMeasureInRevisionRange lists the measures of the group last modified within [from, to], both ends included.
+// A measure modified again after to is left out, since only its latest revision is kept.
+func (e *etcdSchemaRegistry) ListMeasureInRevisionRange(ctx context.Context, group string, from, to int64) ([]*databasev1.Measure, error) {
+	if group == "" {
+		return nil, errors.Wrap(ErrGroupAbsent, "list measure in revision range")
+	}
+	if from > to {
+		return nil, errors.Wrapf(ErrInvalidRevisionRange, "[%d, %d]", from, to)
+	}
+	// a zero max mod revision leaves the range unbounded, while no entity is modified at or before it
+	if to <= 0 {
+		return make([]*databasev1.Measure, 0), nil
+	}
+	messages, _, err := e.listWithOpts(ctx, e.keyLayout.listPrefixesForEntity(group, e.keyLayout.MeasureKeyPrefix), func() proto.Message {
+		return &databasev1.Measure{}
+	}, clientv3.WithMinModRev(from), clientv3.WithMaxModRev(to))
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*databasev1.Measure, 0, len(messages))
+	for _, message := range messages {
+		entities = append(entities, message.(*databasev1.Measure))
+	}
+	return entities, nil
+}
+
+// ForEachMeasure hands the measures of the group to fn one by one rather than retaining all of them.
+// The measure is decoded into the same message each time, so fn should clone it to keep it.
+// It stops at the first error of fn and returns it.
+func (e *etcdSchemaRegistry) ForEachMeasure(ctx context.Context, group string, fn func(*databasev1.Measure) error) error {
+	if group == "" {
+		return errors.Wrap(ErrGroupAbsent, "for each measure")
+	}
+	measure := &databasev1.Measure{}
+	_, err := e.rangeWithOpts(ctx, e.keyLayout.listPrefixesForEntity(group, e.keyLayout.MeasureKeyPrefix), func() proto.Message {
+		return measure
+	}, func(proto.Message) error {
+		return fn(measure)
+	})
+	return err
+}
+
+// ListAllMeasures lists measures in all groups
+func (e *etcdSchemaRegistry) ListAllMeasures(ctx context.Context) ([]*databasev1.Measure, error) {
+	messages, err := e.listInAllGroups(ctx, e.keyLayout.MeasureKeyPrefix, func() proto.Message {
 		return &databasev1.Measure{}
 	})
 	if err != nil {
@@ -219,7 +456,7 @@ func (e *etcdSchemaRegistry) ListMeasure(ctx context.Context, opt ListOpt) ([]*d
 	return entities, nil
 }
 
-func (e *etcdSchemaRegistry) UpdateMeasure(ctx context.Context, measure *databasev1.Measure) error {
+func (e *etcdSchemaRegistry) UpdateMeasure(ctx context.Context, measure *databasev1.Measure, opts ...WriteOption) error {
 	return e.update(ctx, Metadata{
 		TypeMeta: TypeMeta{
 			Kind:  KindMeasure,
@@ -227,7 +464,7 @@ func (e *etcdSchemaRegistry) UpdateMeasure(ctx context.Context, measure *databas
 			Name:  measure.GetMetadata().GetName(),
 		},
 		Spec: measure,
-	})
+	}, opts)
 }
 
 func (e *etcdSchemaRegistry) DeleteMeasure(ctx context.Context, metadata *commonv1.Metadata) (bool, error) {
@@ -240,9 +477,14 @@ func (e *etcdSchemaRegistry) DeleteMeasure(ctx context.Context, metadata *common
 	})
 }
 
-func (e *etcdSchemaRegistry) GetStream(ctx context.Context, metadata *commonv1.Metadata) (*databasev1.Stream, error) {
+// DeleteMeasures deletes the measures in a single transaction and returns the number of the removed ones
+func (e *etcdSchemaRegistry) DeleteMeasures(ctx context.Context, metadatas []*commonv1.Metadata) (int, error) {
+	return e.deleteMany(ctx, KindMeasure, metadatas)
+}
+
+func (e *etcdSchemaRegistry) GetStream(ctx context.Context, metadata *commonv1.Metadata, opts ...ReadOption) (*databasev1.Stream, error) {
 	var entity databasev1.Stream
-	if err := e.get(ctx, formatStreamKey(metadata), &entity); err != nil {
+	if err := e.getInGroup(ctx, metadata, e.keyLayout.formatStreamKey, &entity, opts...); err != nil {
 		return nil, err
 	}
 	return &entity, nil
@@ -252,7 +494,7 @@ func (e *etcdSchemaRegistry) ListStream(ctx context.Context, opt ListOpt) ([]*da
 	if opt.Group == "" {
 		return nil, errors.Wrap(ErrGroupAbsent, "list stream")
 	}
-	messages, err := e.listWithPrefix(ctx, listPrefixesForEntity(opt.Group, StreamKeyPrefix), func() proto.Message {
+	messages, err := e.listWithPrefix(ctx, e.keyLayout.listPrefixesForEntity(opt.Group, e.keyLayout.StreamKeyPrefix), func() proto.Message {
 		return &databasev1.Stream{}
 	})
 	if err != nil {
@@ -265,7 +507,51 @@ func (e *etcdSchemaRegistry) ListStream(ctx context.Context, opt ListOpt) ([]*da
 	return entities, nil
 }
 
-func (e *etcdSchemaRegistry) UpdateStream(ctx context.Context, stream *databasev1.Stream) error {
+// ListStreamNames only reads the keys, which spares the transfer and the decoding of the definitions.
+// The names are in the order of the keys.
+func (e *etcdSchemaRegistry) ListStreamNames(ctx context.Context, group string) ([]string, error) {
+	if group == "" {
+		return nil, errors.Wrap(ErrGroupAbsent, "list stream names")
+	}
+	return e.listNamesWithPrefix(ctx, e.keyLayout.listPrefixesForEntity(group, e.keyLayout.StreamKeyPrefix))
+}
+
+// ListAllStreams lists streams in all groups
+func (e *etcdSchemaRegistry) ListAllStreams(ctx context.Context) ([]*databasev1.Stream, error) {
+	messages, err := e.listInAllGroups(ctx, e.keyLayout.StreamKeyPrefix, func() proto.Message {
+		return &databasev1.Stream{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*databasev1.Stream, 0, len(messages))
+	for _, message := range messages {
+		entities = append(entities, message.(*databasev1.Stream))
+	}
+	return entities, nil
+}
+
+// ListStreamSince lists the streams modified after sinceRevision.
+// It also returns the current revision of the registry as the cursor of the next call.
+func (e *etcdSchemaRegistry) ListStreamSince(ctx context.Context, group string, sinceRevision int64) ([]*databasev1.Stream, int64, error) {
+	if group == "" {
+		return nil, 0, errors.Wrap(ErrGroupAbsent, "list stream since")
+	}
+	prefix := e.keyLayout.listPrefixesForEntity(group, e.keyLayout.StreamKeyPrefix)
+	messages, revision, err := e.listWithPrefixSince(ctx, prefix, sinceRevision, func() proto.Message {
+		return &databasev1.Stream{}
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	entities := make([]*databasev1.Stream, 0, len(messages))
+	for _, message := range messages {
+		entities = append(entities, message.(*databasev1.Stream))
+	}
+	return entities, revision, nil
+}
+
+func (e *etcdSchemaRegistry) UpdateStream(ctx context.Context, stream *databasev1.Stream, opts ...WriteOption) error {
 	return e.update(ctx, Metadata{
 		TypeMeta: TypeMeta{
 			Kind:  KindStream,
@@ -273,7 +559,7 @@ func (e *etcdSchemaRegistry) UpdateStream(ctx context.Context, stream *databasev
 			Name:  stream.GetMetadata().GetName(),
 		},
 		Spec: stream,
-	})
+	}, opts)
 }
 
 func (e *etcdSchemaRegistry) DeleteStream(ctx context.Context, metadata *commonv1.Metadata) (bool, error) {
@@ -286,9 +572,14 @@ func (e *etcdSchemaRegistry) DeleteStream(ctx context.Context, metadata *commonv
 	})
 }
 
-func (e *etcdSchemaRegistry) GetIndexRuleBinding(ctx context.Context, metadata *commonv1.Metadata) (*databasev1.IndexRuleBinding, error) {
+// DeleteStreams deletes the streams in a single transaction and returns the number of the removed ones
+func (e *etcdSchemaRegistry) DeleteStreams(ctx context.Context, metadatas []*commonv1.Metadata) (int, error) {
+	return e.deleteMany(ctx, KindStream, metadatas)
+}
+
+func (e *etcdSchemaRegistry) GetIndexRuleBinding(ctx context.Context, metadata *commonv1.Metadata, opts ...ReadOption) (*databasev1.IndexRuleBinding, error) {
 	var indexRuleBinding databasev1.IndexRuleBinding
-	if err := e.get(ctx, formatIndexRuleBindingKey(metadata), &indexRuleBinding); err != nil {
+	if err := e.getInGroup(ctx, metadata, e.keyLayout.formatIndexRuleBindingKey, &indexRuleBinding, opts...); err != nil {
 		return nil, err
 	}
 	return &indexRuleBinding, nil
@@ -298,7 +589,7 @@ func (e *etcdSchemaRegistry) ListIndexRuleBinding(ctx context.Context, opt ListO
 	if opt.Group == "" {
 		return nil, errors.Wrap(ErrGroupAbsent, "list index rule binding")
 	}
-	messages, err := e.listWithPrefix(ctx, listPrefixesForEntity(opt.Group, IndexRuleBindingKeyPrefix), func() proto.Message {
+	messages, err := e.listWithPrefix(ctx, e.keyLayout.listPrefixesForEntity(opt.Group, e.keyLayout.IndexRuleBindingKeyPrefix), func() proto.Message {
 		return &databasev1.IndexRuleBinding{}
 	})
 	if err != nil {
@@ -311,7 +602,11 @@ func (e *etcdSchemaRegistry) ListIndexRuleBinding(ctx context.Context, opt ListO
 	return entities, nil
 }
 
-func (e *etcdSchemaRegistry) UpdateIndexRuleBinding(ctx context.Context, indexRuleBinding *databasev1.IndexRuleBinding) error {
+// UpdateIndexRuleBinding rejects a binding whose subject or rules are of another kind with ErrSubjectKindMismatch
+func (e *etcdSchemaRegistry) UpdateIndexRuleBinding(ctx context.Context, indexRuleBinding *databasev1.IndexRuleBinding, opts ...WriteOption) error {
+	if err := e.checkSubjectKind(ctx, indexRuleBinding, nil); err != nil {
+		return err
+	}
 	return e.update(ctx, Metadata{
 		TypeMeta: TypeMeta{
 			Kind:  KindIndexRuleBinding,
@@ -319,7 +614,7 @@ func (e *etcdSchemaRegistry) UpdateIndexRuleBinding(ctx context.Context, indexRu
 			Group: indexRuleBinding.GetMetadata().GetGroup(),
 		},
 		Spec: indexRuleBinding,
-	})
+	}, opts)
 }
 
 func (e *etcdSchemaRegistry) DeleteIndexRuleBinding(ctx context.Context, metadata *commonv1.Metadata) (bool, error) {
@@ -332,9 +627,74 @@ func (e *etcdSchemaRegistry) DeleteIndexRuleBinding(ctx context.Context, metadat
 	})
 }
 
-func (e *etcdSchemaRegistry) GetIndexRule(ctx context.Context, metadata *commonv1.Metadata) (*databasev1.IndexRule, error) {
+// SwapIndexRuleBinding replaces the content of an existing binding with a single compare-and-swap put.
+// The referenced rules have to exist. The subject sees either the old binding or the new one, never neither.
+func (e *etcdSchemaRegistry) SwapIndexRuleBinding(ctx context.Context, metadata *commonv1.Metadata,
+	newBinding *databasev1.IndexRuleBinding) error {
+	if err := e.gate.enter(); err != nil {
+		return err
+	}
+	defer e.gate.leave()
+	key := e.keyLayout.formatIndexRuleBindingKey(metadata)
+	getResp, err := e.kv.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if getResp.Count < 1 {
+		return errors.Wrapf(ErrEntityNotFound, "index rule binding %s", key)
+	}
+	existing := &databasev1.IndexRuleBinding{}
+	if err = unmarshal(getResp.Kvs[0].Key, getResp.Kvs[0].Value, existing); err != nil {
+		return err
+	}
+	assignRevisions(existing, getResp.Kvs[0])
+	binding := proto.Clone(newBinding).(*databasev1.IndexRuleBinding)
+	binding.Metadata = metadata
+	if err = e.checkSubjectKind(ctx, binding, nil); err != nil {
+		return err
+	}
+	binding = stampTime(binding, existing, time.Now()).(*databasev1.IndexRuleBinding)
+	cmps := []clientv3.Cmp{clientv3.Compare(clientv3.ModRevision(key), "=", getResp.Kvs[0].ModRevision)}
+	for _, rule := range binding.GetRules() {
+		ruleKey := e.keyLayout.formatIndexRuleKey(&commonv1.Metadata{Name: rule, Group: metadata.GetGroup()})
+		ruleResp, innerErr := e.kv.Get(ctx, ruleKey, clientv3.WithCountOnly())
+		if innerErr != nil {
+			return innerErr
+		}
+		if ruleResp.Count < 1 {
+			return errors.Wrapf(ErrEntityNotFound, "index rule %s", ruleKey)
+		}
+		// the rule must not be deleted before the binding is swapped
+		cmps = append(cmps, clientv3.Compare(clientv3.CreateRevision(ruleKey), ">", 0))
+	}
+	val, err := e.marshal(binding)
+	if err != nil {
+		return err
+	}
+	txnResp, err := e.kv.Txn(ctx).
+		If(cmps...).
+		Then(clientv3.OpPut(key, string(val))).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !txnResp.Succeeded {
+		return ErrConcurrentModification
+	}
+	return e.notifyUpdate(Metadata{
+		TypeMeta: TypeMeta{
+			Kind:  KindIndexRuleBinding,
+			Name:  metadata.GetName(),
+			Group: metadata.GetGroup(),
+		},
+		Spec:     binding,
+		PrevSpec: existing,
+	}, txnResp.Header.GetRevision())
+}
+
+func (e *etcdSchemaRegistry) GetIndexRule(ctx context.Context, metadata *commonv1.Metadata, opts ...ReadOption) (*databasev1.IndexRule, error) {
 	var entity databasev1.IndexRule
-	if err := e.get(ctx, formatIndexRuleKey(metadata), &entity); err != nil {
+	if err := e.getInGroup(ctx, metadata, e.keyLayout.formatIndexRuleKey, &entity, opts...); err != nil {
 		return nil, err
 	}
 	return &entity, nil
@@ -344,7 +704,7 @@ func (e *etcdSchemaRegistry) ListIndexRule(ctx context.Context, opt ListOpt) ([]
 	if opt.Group == "" {
 		return nil, errors.Wrap(ErrGroupAbsent, "list index rule")
 	}
-	messages, err := e.listWithPrefix(ctx, listPrefixesForEntity(opt.Group, IndexRuleKeyPrefix), func() proto.Message {
+	messages, err := e.listWithPrefix(ctx, e.keyLayout.listPrefixesForEntity(opt.Group, e.keyLayout.IndexRuleKeyPrefix), func() proto.Message {
 		return &databasev1.IndexRule{}
 	})
 	if err != nil {
@@ -357,7 +717,30 @@ func (e *etcdSchemaRegistry) ListIndexRule(ctx context.Context, opt ListOpt) ([]
 	return entities, nil
 }
 
-func (e *etcdSchemaRegistry) UpdateIndexRule(ctx context.Context, indexRule *databasev1.IndexRule) error {
+// ListAllIndexRules lists index rules in all groups
+func (e *etcdSchemaRegistry) ListAllIndexRules(ctx context.Context) ([]*databasev1.IndexRule, error) {
+	messages, err := e.listInAllGroups(ctx, e.keyLayout.IndexRuleKeyPrefix, func() proto.Message {
+		return &databasev1.IndexRule{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*databasev1.IndexRule, 0, len(messages))
+	for _, message := range messages {
+		entities = append(entities, message.(*databasev1.IndexRule))
+	}
+	return entities, nil
+}
+
+// UpdateIndexRule fails with ErrInvalidIndexRule if the sampling rate is out of [0, 1],
+// or the analyzer tokenizes a tag which isn't a string in the subjects bound to the rule
+func (e *etcdSchemaRegistry) UpdateIndexRule(ctx context.Context, indexRule *databasev1.IndexRule, opts ...WriteOption) error {
+	if err := checkIndexRule(indexRule); err != nil {
+		return err
+	}
+	if err := e.checkAnalyzedTags(ctx, indexRule); err != nil {
+		return err
+	}
 	return e.update(ctx, Metadata{
 		TypeMeta: TypeMeta{
 			Kind:  KindIndexRule,
@@ -365,7 +748,7 @@ func (e *etcdSchemaRegistry) UpdateIndexRule(ctx context.Context, indexRule *dat
 			Group: indexRule.GetMetadata().GetGroup(),
 		},
 		Spec: indexRule,
-	})
+	}, opts)
 }
 
 func (e *etcdSchemaRegistry) DeleteIndexRule(ctx context.Context, metadata *commonv1.Metadata) (bool, error) {
@@ -378,150 +761,385 @@ func (e *etcdSchemaRegistry) DeleteIndexRule(ctx context.Context, metadata *comm
 	})
 }
 
+// DeleteIndexRules deletes the index rules in a single transaction and returns the number of the removed ones
+func (e *etcdSchemaRegistry) DeleteIndexRules(ctx context.Context, metadatas []*commonv1.Metadata) (int, error) {
+	return e.deleteMany(ctx, KindIndexRule, metadatas)
+}
+
 func (e *etcdSchemaRegistry) ReadyNotify() <-chan struct{} {
-	return e.server.Server.ReadyNotify()
+	return e.backend.readyNotify()
 }
 
 func (e *etcdSchemaRegistry) StopNotify() <-chan struct{} {
-	return e.server.Server.StopNotify()
+	return e.backend.stopNotify()
 }
 
 func (e *etcdSchemaRegistry) StoppingNotify() <-chan struct{} {
-	return e.server.Server.StoppingNotify()
+	return e.backend.stoppingNotify()
 }
 
+// Close stops etcd without waiting for the in-flight writes. Shutdown is the graceful one.
 func (e *etcdSchemaRegistry) Close() error {
-	e.server.Close()
+	e.gate.close()
+	e.closeQueues(true)
+	e.backend.close()
 	return nil
 }
 
-func NewEtcdSchemaRegistry(options ...RegistryOption) (Registry, error) {
+func newRegistryConfig(options []RegistryOption) (*etcdSchemaRegistryConfig, error) {
 	registryConfig := &etcdSchemaRegistryConfig{
 		rootDir:           os.TempDir(),
 		listenerClientURL: embed.DefaultListenClientURLs,
 		listenerPeerURL:   embed.DefaultListenPeerURLs,
+		keyLayout:         DefaultKeyLayout(),
+		idempotencyKeyTTL: defaultIdempotencyKeyTTL,
 	}
 	for _, opt := range options {
 		opt(registryConfig)
 	}
+	if err := registryConfig.keyLayout.Validate(); err != nil {
+		return nil, err
+	}
+	return registryConfig, nil
+}
+
+func NewEtcdSchemaRegistry(options ...RegistryOption) (Registry, error) {
+	registryConfig, err := newRegistryConfig(options)
+	if err != nil {
+		return nil, err
+	}
 	// TODO: allow use cluster setting
-	embedConfig := newStandaloneEtcdConfig(registryConfig)
+	embedConfig, err := newStandaloneEtcdConfig(registryConfig)
+	if err != nil {
+		return nil, err
+	}
+	var client *clientv3.Client
+	if len(registryConfig.learnerOf) > 0 {
+		if client, err = joinAsLearner(embedConfig, registryConfig.learnerOf); err != nil {
+			return nil, err
+		}
+	}
 	e, err := embed.StartEtcd(embedConfig)
 	if err != nil {
+		if client != nil {
+			_ = client.Close()
+		}
 		return nil, err
 	}
 	if e != nil {
 		<-e.Server.ReadyNotify() // wait for e.Server to join the cluster
 	}
-	client, err := clientv3.NewFromURL(e.Config().ACUrls[0].String())
-	if err != nil {
-		return nil, err
+	if client == nil {
+		if client, err = clientv3.NewFromURL(e.Config().ACUrls[0].String()); err != nil {
+			return nil, err
+		}
+	}
+	return newRegistry(registryConfig, &etcdBackend{server: e, client: client}, clientv3.NewKV(client), &etcdLease{Lease: clientv3.NewLease(client)})
+}
+
+// newRegistry serves the registry from the kv of the backend
+func newRegistry(registryConfig *etcdSchemaRegistryConfig, b registryBackend, kv clientv3.KV, lease leaseGranter) (Registry, error) {
+	if registryConfig.requestTimeout > 0 {
+		kv = &timeoutKV{KV: kv, timeout: registryConfig.requestTimeout}
 	}
-	kvClient := clientv3.NewKV(client)
+	gate := &writeGate{readOnly: registryConfig.readOnly}
 	reg := &etcdSchemaRegistry{
-		server: e,
-		kv:     kvClient,
+		backend:           b,
+		kv:                kv,
+		gate:              gate,
+		queueSize:         registryConfig.queueSize,
+		overflowPolicy:    registryConfig.overflowPolicy,
+		checksum:          registryConfig.checksum,
+		keyLayout:         registryConfig.keyLayout,
+		lease:             lease,
+		idempotencyKeyTTL: registryConfig.idempotencyKeyTTL,
+		requestTimeout:    registryConfig.requestTimeout,
+	}
+	if err := migrateGroupMetadataKeys(context.Background(), kv, reg.keyLayout, registryConfig.readOnly); err != nil {
+		_ = reg.Close()
+		return nil, err
+	}
+	if registryConfig.tracerProvider != nil {
+		reg.tracer = registryConfig.tracerProvider.Tracer(tracerName)
+	}
+	if registryConfig.queueObserver != nil && registryConfig.queueSize > 0 {
+		interval := registryConfig.queueObserveInterval
+		if interval <= 0 {
+			interval = defaultQueueObserveInterval
+		}
+		go reg.observeEventQueues(registryConfig.queueObserver, interval)
+	}
+	if registryConfig.countObserver != nil {
+		interval := registryConfig.countObserveInterval
+		if interval <= 0 {
+			interval = defaultEntityCountInterval
+		}
+		go reg.observeEntityCounts(registryConfig.countObserver, interval)
 	}
 	return reg, nil
 }
 
-func (e *etcdSchemaRegistry) get(ctx context.Context, key string, message proto.Message) error {
-	resp, err := e.kv.Get(ctx, key)
+func (e *etcdSchemaRegistry) get(ctx context.Context, key string, message proto.Message, opts ...ReadOption) (err error) {
+	ctx, span := e.startSpan(ctx, "get", func() []attribute.KeyValue {
+		return e.keyAttributes(key)
+	})
+	defer func() { span.end(err) }()
+	ro := newReadOptions(opts)
+	resp, err := e.kv.Get(ctx, key, ro.opOptions()...)
 	if err != nil {
 		return err
 	}
+	span.setRevision(resp.Header.GetRevision())
+	ro.observe(resp.Header)
 	if resp.Count == 0 {
-		return ErrEntityNotFound
+		return newNotFoundError(e.keyLayout, key)
 	}
 	if resp.Count > 1 {
-		return ErrUnexpectedNumberOfEntities
+		return entityError(e.keyLayout.typeMetaOf(key), ErrUnexpectedNumberOfEntities)
 	}
-	if err = proto.Unmarshal(resp.Kvs[0].Value, message); err != nil {
+	if err = unmarshal(resp.Kvs[0].Key, resp.Kvs[0].Value, message); err != nil {
 		return err
 	}
-	if messageWithMetadata, ok := message.(HasMetadata); ok {
-		// Assign readonly fields
-		messageWithMetadata.GetMetadata().CreateRevision = resp.Kvs[0].CreateRevision
-		messageWithMetadata.GetMetadata().ModRevision = resp.Kvs[0].ModRevision
+	if ro.revisionInfo != nil {
+		*ro.revisionInfo = RevisionInfo{
+			CreateRevision: resp.Kvs[0].CreateRevision,
+			ModRevision:    resp.Kvs[0].ModRevision,
+		}
 	}
+	assignRevisions(message, resp.Kvs[0])
 	return nil
 }
 
-func (e *etcdSchemaRegistry) update(ctx context.Context, metadata Metadata) error {
-	key, err := metadata.Key()
+func assignRevisions(message proto.Message, kv *mvccpb.KeyValue) {
+	if messageWithMetadata, ok := message.(HasMetadata); ok && messageWithMetadata.GetMetadata() != nil {
+		// Assign readonly fields
+		messageWithMetadata.GetMetadata().CreateRevision = kv.CreateRevision
+		messageWithMetadata.GetMetadata().ModRevision = kv.ModRevision
+	}
+}
+
+// update puts the entity if all the cmps succeed along with the check of concurrent modifications
+func (e *etcdSchemaRegistry) update(ctx context.Context, metadata Metadata, opts []WriteOption, cmps ...clientv3.Cmp) (err error) {
+	ctx, span := e.startSpan(ctx, "update", func() []attribute.KeyValue {
+		return typeMetaAttributes(metadata.TypeMeta)
+	})
+	defer func() { span.end(err) }()
+	if err = e.gate.enter(); err != nil {
+		return entityError(metadata.TypeMeta, err)
+	}
+	defer e.gate.leave()
+	key, err := e.keyLayout.Key(metadata)
 	if err != nil {
 		return err
 	}
+	wo := newWriteOptions(opts)
+	if applied, appliedErr := e.applied(ctx, wo, key); appliedErr != nil || applied {
+		return appliedErr
+	}
 	getResp, err := e.kv.Get(ctx, key)
 	if err != nil {
 		return err
 	}
 	if getResp.Count > 1 {
-		return ErrUnexpectedNumberOfEntities
+		return entityError(metadata.TypeMeta, ErrUnexpectedNumberOfEntities)
 	}
-	val, err := proto.Marshal(metadata.Spec.(proto.Message))
-	if err != nil {
-		return err
-	}
-	replace := getResp.Count > 0
-	if replace {
-		existingVal, innerErr := metadata.Unmarshal(getResp.Kvs[0].Value)
+	var existingVal proto.Message
+	if getResp.Count > 0 {
+		existingRaw, innerErr := verifyChecksum(getResp.Kvs[0].Key, getResp.Kvs[0].Value)
 		if innerErr != nil {
 			return innerErr
 		}
+		existingVal, innerErr = metadata.Unmarshal(existingRaw)
+		if innerErr != nil {
+			return innerErr
+		}
+		assignRevisions(existingVal, getResp.Kvs[0])
 		// directly return if we have the same entity
 		if metadata.Equal(existingVal) {
-			return nil
+			return e.recordNoop(ctx, wo, key)
+		}
+		if innerErr = e.checkCompatibility(ctx, metadata, existingVal); innerErr != nil {
+			return innerErr
 		}
 
 		modRevision := getResp.Kvs[0].ModRevision
+		cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(key), "=", modRevision))
+	}
+	metadata.PrevSpec = existingVal
+	metadata.Spec = stampTime(metadata.Spec.(proto.Message), existingVal, time.Now())
+	val, err := e.marshal(metadata.Spec.(proto.Message))
+	if err != nil {
+		return err
+	}
+	recordCmps, recordOps, err := e.recordOps(ctx, wo, key)
+	if err != nil {
+		return err
+	}
+	cmps = append(cmps, recordCmps...)
+	var revision int64
+	if len(cmps) > 0 {
 		txnResp, txnErr := e.kv.Txn(context.Background()).
-			If(clientv3.Compare(clientv3.ModRevision(key), "=", modRevision)).
-			Then(clientv3.OpPut(key, string(val))).
+			If(cmps...).
+			Then(append([]clientv3.Op{clientv3.OpPut(key, string(val))}, recordOps...)...).
 			Commit()
 		if txnErr != nil {
 			return txnErr
 		}
 		if !txnResp.Succeeded {
-			return ErrConcurrentModification
+			// a concurrent retry with the same idempotency key won the race
+			if applied, appliedErr := e.applied(ctx, wo, key); appliedErr != nil || applied {
+				return appliedErr
+			}
+			return entityError(metadata.TypeMeta, ErrConcurrentModification)
 		}
+		revision = txnResp.Header.GetRevision()
 	} else {
-		_, err = e.kv.Put(ctx, key, string(val))
-		if err != nil {
-			return err
+		putResp, putErr := e.kv.Put(ctx, key, string(val))
+		if putErr != nil {
+			return putErr
 		}
+		revision = putResp.Header.GetRevision()
 	}
-	e.notifyUpdate(metadata)
-	return nil
+	span.setRevision(revision)
+	return e.notifyUpdate(metadata, revision)
+}
+
+// stampTime returns a copy of the spec with the wall-clock time of the write.
+// The creation time is only assigned to a new entity.
+func stampTime(spec, existing proto.Message, now time.Time) proto.Message {
+	withMetadata, ok := spec.(HasMetadata)
+	if !ok || withMetadata.GetMetadata() == nil {
+		return spec
+	}
+	stamped := proto.Clone(withMetadata).(HasMetadata)
+	md := stamped.GetMetadata()
+	md.UpdatedAt = timestamppb.New(now)
+	if existing == nil {
+		md.CreatedAt = md.UpdatedAt
+	} else if e, ok := existing.(HasMetadata); ok {
+		md.CreatedAt = e.GetMetadata().GetCreatedAt()
+	}
+	return stamped
+}
+
+func (e *etcdSchemaRegistry) checkCompatibility(ctx context.Context, metadata Metadata, existing proto.Message) error {
+	if _, ok := compatibilityCheckerMap[metadata.Kind]; !ok {
+		return nil
+	}
+	g, err := e.GetGroup(ctx, metadata.Group)
+	if err != nil {
+		return err
+	}
+	if g.GetResourceOpts().GetAllowIncompatibleSchemaChange() {
+		return nil
+	}
+	return metadata.CheckCompatibility(existing)
 }
 
 func (e *etcdSchemaRegistry) listWithPrefix(ctx context.Context, prefix string, factory func() proto.Message) ([]proto.Message, error) {
-	resp, err := e.kv.Get(ctx, prefix, clientv3.WithFromKey(), clientv3.WithRange(incrementLastByte(prefix)))
+	entities, _, err := e.listWithPrefixSince(ctx, prefix, 0, factory)
+	return entities, err
+}
+
+// listWithPrefixSince leaves the entities last modified at or before sinceRevision out of the range request
+func (e *etcdSchemaRegistry) listWithPrefixSince(ctx context.Context, prefix string, sinceRevision int64,
+	factory func() proto.Message) ([]proto.Message, int64, error) {
+	return e.listWithOpts(ctx, prefix, factory, clientv3.WithMinModRev(sinceRevision+1))
+}
+
+// listInAllGroups lists the entities denoted by entityPrefix regardless of their groups.
+// It ranges the entities of each group rather than all the keys of the groups.
+func (e *etcdSchemaRegistry) listInAllGroups(ctx context.Context, entityPrefix string, factory func() proto.Message) ([]proto.Message, error) {
+	groups, err := e.listNamesWithPrefix(ctx, e.keyLayout.GroupMetadataKeyPrefix)
 	if err != nil {
 		return nil, err
 	}
-	entities := make([]proto.Message, resp.Count)
-	for i := int64(0); i < resp.Count; i++ {
+	entities := make([]proto.Message, 0)
+	for _, group := range groups {
+		messages, _, listErr := e.listWithOpts(ctx, e.keyLayout.listPrefixesForEntity(group, entityPrefix), factory)
+		if listErr != nil {
+			return nil, listErr
+		}
+		entities = append(entities, messages...)
+	}
+	return entities, nil
+}
+
+func (e *etcdSchemaRegistry) listWithOpts(ctx context.Context, prefix string, factory func() proto.Message,
+	opts ...clientv3.OpOption) ([]proto.Message, int64, error) {
+	var entities []proto.Message
+	revision, err := e.rangeWithOpts(ctx, prefix, factory, func(message proto.Message) error {
+		entities = append(entities, message)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if entities == nil {
+		entities = make([]proto.Message, 0)
+	}
+	return entities, revision, nil
+}
+
+// rangeWithOpts decodes the entities under the prefix into the messages created by factory, and hands them to fn in order.
+// The opts narrow down the range request. It stops at the first error of fn.
+func (e *etcdSchemaRegistry) rangeWithOpts(ctx context.Context, prefix string, factory func() proto.Message,
+	fn func(message proto.Message) error, opts ...clientv3.OpOption) (_ int64, err error) {
+	ctx, span := e.startSpan(ctx, "list", func() []attribute.KeyValue {
+		return e.listAttributes(prefix, factory)
+	})
+	defer func() { span.end(err) }()
+	resp, err := e.kv.Get(ctx, prefix, append([]clientv3.OpOption{clientv3.WithFromKey(),
+		clientv3.WithRange(incrementLastByte(prefix))}, opts...)...)
+	if err != nil {
+		return 0, err
+	}
+	span.setRevision(resp.Header.GetRevision())
+	var count int
+	for _, kv := range resp.Kvs {
 		message := factory()
-		if innerErr := proto.Unmarshal(resp.Kvs[i].Value, message); innerErr != nil {
-			return nil, innerErr
+		if err = unmarshal(kv.Key, kv.Value, message); err != nil {
+			return 0, err
 		}
-		entities[i] = message
-		if messageWithMetadata, ok := message.(HasMetadata); ok {
-			// Assign readonly fields
-			messageWithMetadata.GetMetadata().CreateRevision = resp.Kvs[i].CreateRevision
-			messageWithMetadata.GetMetadata().ModRevision = resp.Kvs[i].ModRevision
+		assignRevisions(message, kv)
+		if err = fn(message); err != nil {
+			return 0, err
 		}
+		count++
 	}
-	return entities, nil
+	span.setCount(count)
+	return resp.Header.GetRevision(), nil
 }
 
-func listPrefixesForEntity(group, entityPrefix string) string {
-	return GroupsKeyPrefix + group + entityPrefix
+// listNamesWithPrefix returns the keys under the prefix with the prefix trimmed
+func (e *etcdSchemaRegistry) listNamesWithPrefix(ctx context.Context, prefix string) (_ []string, err error) {
+	ctx, span := e.startSpan(ctx, "list_names", func() []attribute.KeyValue {
+		return []attribute.KeyValue{attribute.String("schema.prefix", prefix)}
+	})
+	defer func() { span.end(err) }()
+	resp, err := e.kv.Get(ctx, prefix, clientv3.WithFromKey(), clientv3.WithRange(incrementLastByte(prefix)), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, err
+	}
+	span.setRevision(resp.Header.GetRevision())
+	names := make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		names = append(names, string(kv.Key[len(prefix):]))
+	}
+	span.setCount(len(names))
+	return names, nil
 }
 
-func (e *etcdSchemaRegistry) delete(ctx context.Context, metadata Metadata) (bool, error) {
-	key, err := metadata.Key()
+func (e *etcdSchemaRegistry) delete(ctx context.Context, metadata Metadata) (_ bool, err error) {
+	ctx, span := e.startSpan(ctx, "delete", func() []attribute.KeyValue {
+		return typeMetaAttributes(metadata.TypeMeta)
+	})
+	defer func() { span.end(err) }()
+	if err = e.gate.enter(); err != nil {
+		return false, entityError(metadata.TypeMeta, err)
+	}
+	defer e.gate.leave()
+	key, err := e.keyLayout.Key(metadata)
 	if err != nil {
 		return false, err
 	}
@@ -529,6 +1147,7 @@ func (e *etcdSchemaRegistry) delete(ctx context.Context, metadata Metadata) (boo
 	if err != nil {
 		return false, err
 	}
+	span.setRevision(resp.Header.GetRevision())
 	if resp.Deleted == 1 {
 		var message proto.Message
 		switch metadata.Kind {
@@ -540,44 +1159,83 @@ func (e *etcdSchemaRegistry) delete(ctx context.Context, metadata Metadata) (boo
 			message = &databasev1.IndexRuleBinding{}
 		case KindIndexRule:
 			message = &databasev1.IndexRule{}
+		case KindDownsamplingRule:
+			message = &databasev1.DownsamplingRule{}
+		case KindRetentionPolicy:
+			message = &commonv1.RetentionPolicy{}
 		}
-		if unmarshalErr := proto.Unmarshal(resp.PrevKvs[0].Value, message); unmarshalErr == nil {
-			e.notifyDelete(Metadata{
+		if unmarshalErr := unmarshal(resp.PrevKvs[0].Key, resp.PrevKvs[0].Value, message); unmarshalErr == nil {
+			return true, e.notifyDelete(Metadata{
 				TypeMeta: TypeMeta{
 					Kind:  metadata.Kind,
 					Name:  metadata.Name,
 					Group: metadata.Group,
 				},
 				Spec: message,
-			})
+			}, resp.Header.GetRevision())
 		}
 		return true, nil
 	}
 	return false, nil
 }
 
-func formatIndexRuleKey(metadata *commonv1.Metadata) string {
-	return formatKey(IndexRuleKeyPrefix, metadata)
-}
-
-func formatIndexRuleBindingKey(metadata *commonv1.Metadata) string {
-	return formatKey(IndexRuleBindingKeyPrefix, metadata)
-}
-
-func formatStreamKey(metadata *commonv1.Metadata) string {
-	return formatKey(StreamKeyPrefix, metadata)
-}
-
-func formatMeasureKey(metadata *commonv1.Metadata) string {
-	return formatKey(MeasureKeyPrefix, metadata)
-}
-
-func formatKey(entityPrefix string, metadata *commonv1.Metadata) string {
-	return GroupsKeyPrefix + metadata.GetGroup() + entityPrefix + metadata.GetName()
-}
-
-func formatGroupKey(group string) string {
-	return GroupsKeyPrefix + group + GroupMetadataKey
+// deleteMany removes all entities in one transaction. The transaction is subject to the max-txn-ops limit of etcd.
+func (e *etcdSchemaRegistry) deleteMany(ctx context.Context, kind Kind, metadatas []*commonv1.Metadata) (int, error) {
+	if err := e.gate.enter(); err != nil {
+		return 0, err
+	}
+	defer e.gate.leave()
+	if len(metadatas) < 1 {
+		return 0, nil
+	}
+	typeMetas := make([]TypeMeta, 0, len(metadatas))
+	ops := make([]clientv3.Op, 0, len(metadatas))
+	seen := make(map[string]struct{}, len(metadatas))
+	for _, m := range metadatas {
+		tm := TypeMeta{
+			Kind:  kind,
+			Name:  m.GetName(),
+			Group: m.GetGroup(),
+		}
+		key, err := e.keyLayout.Key(Metadata{TypeMeta: tm})
+		if err != nil {
+			return 0, err
+		}
+		// etcd rejects a transaction with duplicated keys
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		typeMetas = append(typeMetas, tm)
+		ops = append(ops, clientv3.OpDelete(key, clientv3.WithPrevKV()))
+	}
+	resp, err := e.kv.Txn(ctx).Then(ops...).Commit()
+	if err != nil {
+		return 0, err
+	}
+	var deleted int
+	var notifyErr error
+	for i, r := range resp.Responses {
+		delResp := r.GetResponseDeleteRange()
+		if delResp.GetDeleted() < 1 {
+			continue
+		}
+		deleted++
+		prevKV := delResp.GetPrevKvs()[0]
+		raw, unmarshalErr := verifyChecksum(prevKV.Key, prevKV.Value)
+		if unmarshalErr != nil {
+			continue
+		}
+		message, unmarshalErr := typeMetas[i].Unmarshal(raw)
+		if unmarshalErr != nil {
+			continue
+		}
+		notifyErr = multierr.Append(notifyErr, e.notifyDelete(Metadata{
+			TypeMeta: typeMetas[i],
+			Spec:     message,
+		}, resp.Header.GetRevision()))
+	}
+	return deleted, notifyErr
 }
 
 func incrementLastByte(key string) string {
@@ -586,7 +1244,7 @@ func incrementLastByte(key string) string {
 	return string(bb)
 }
 
-func newStandaloneEtcdConfig(config *etcdSchemaRegistryConfig) *embed.Config {
+func newStandaloneEtcdConfig(config *etcdSchemaRegistryConfig) (*embed.Config, error) {
 	cfg := embed.NewConfig()
 	// TODO: allow user to set path
 	cfg.Dir = filepath.Join(config.rootDir, "metadata")
@@ -597,5 +1255,10 @@ func newStandaloneEtcdConfig(config *etcdSchemaRegistryConfig) *embed.Config {
 	cfg.LCUrls, cfg.ACUrls = []url.URL{*cURL}, []url.URL{*cURL}
 	cfg.LPUrls, cfg.APUrls = []url.URL{*pURL}, []url.URL{*pURL}
 	cfg.InitialCluster = ",default=" + pURL.String()
-	return cfg
+	for _, tune := range config.storageTuners {
+		if err := tune(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
 }
@@ -22,6 +22,8 @@ import (
 	"embed"
 	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"os"
 	"path"
 	"testing"
@@ -31,6 +33,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
@@ -291,6 +294,39 @@ func Test_Etcd_Entity_List(t *testing.T) {
 			},
 			expectedLen: 0,
 		},
+		{
+			name: "List All Streams",
+			list: func(r Registry) (int, error) {
+				entities, innerErr := r.ListAllStreams(context.TODO())
+				if innerErr != nil {
+					return 0, innerErr
+				}
+				return len(entities), nil
+			},
+			expectedLen: 1,
+		},
+		{
+			name: "List All IndexRules",
+			list: func(r Registry) (int, error) {
+				entities, innerErr := r.ListAllIndexRules(context.TODO())
+				if innerErr != nil {
+					return 0, innerErr
+				}
+				return len(entities), nil
+			},
+			expectedLen: 10,
+		},
+		{
+			name: "List All Measures",
+			list: func(r Registry) (int, error) {
+				entities, innerErr := r.ListAllMeasures(context.TODO())
+				if innerErr != nil {
+					return 0, innerErr
+				}
+				return len(entities), nil
+			},
+			expectedLen: 0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -303,6 +339,184 @@ func Test_Etcd_Entity_List(t *testing.T) {
 	}
 }
 
+func Test_Etcd_ForEachMeasure(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(registry.UpdateGroup(context.TODO(), &commonv1.Group{
+		Metadata:     &commonv1.Metadata{Name: "sw_metric"},
+		Catalog:      commonv1.Catalog_CATALOG_MEASURE,
+		ResourceOpts: &commonv1.ResourceOpts{ShardNum: 1},
+	}))
+	for _, name := range []string{"service_cpm", "service_resp_time", "endpoint_cpm"} {
+		req.NoError(registry.UpdateMeasure(context.TODO(), &databasev1.Measure{
+			Metadata: &commonv1.Metadata{Group: "sw_metric", Name: name},
+			Fields:   []*databasev1.FieldSpec{{Name: "total", FieldType: databasev1.FieldType_FIELD_TYPE_INT}},
+		}))
+	}
+
+	var names []string
+	var last *databasev1.Measure
+	req.NoError(registry.ForEachMeasure(context.TODO(), "sw_metric", func(m *databasev1.Measure) error {
+		names = append(names, m.GetMetadata().GetName())
+		req.Positive(m.GetMetadata().GetModRevision())
+		if last != nil {
+			req.Same(last, m)
+		}
+		last = m
+		return nil
+	}))
+	req.Equal([]string{"endpoint_cpm", "service_cpm", "service_resp_time"}, names)
+
+	errStop := errors.New("stop")
+	var visited int
+	req.ErrorIs(registry.ForEachMeasure(context.TODO(), "sw_metric", func(*databasev1.Measure) error {
+		visited++
+		return errStop
+	}), errStop)
+	req.Equal(1, visited)
+	req.ErrorIs(registry.ForEachMeasure(context.TODO(), "", func(*databasev1.Measure) error {
+		return nil
+	}), ErrGroupAbsent)
+}
+
+func Test_Etcd_ListMeasureInRevisionRange(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(registry.UpdateGroup(context.TODO(), &commonv1.Group{
+		Metadata:     &commonv1.Metadata{Name: "sw_metric"},
+		Catalog:      commonv1.Catalog_CATALOG_MEASURE,
+		ResourceOpts: &commonv1.ResourceOpts{ShardNum: 1},
+	}))
+	revisions := make(map[string]int64)
+	for _, name := range []string{"service_cpm", "service_resp_time", "endpoint_cpm"} {
+		md := &commonv1.Metadata{Group: "sw_metric", Name: name}
+		req.NoError(registry.UpdateMeasure(context.TODO(), &databasev1.Measure{
+			Metadata: md,
+			Fields:   []*databasev1.FieldSpec{{Name: "total", FieldType: databasev1.FieldType_FIELD_TYPE_INT}},
+		}))
+		m, getErr := registry.GetMeasure(context.TODO(), md)
+		req.NoError(getErr)
+		revisions[name] = m.GetMetadata().GetModRevision()
+	}
+	names := func(from, to int64) []string {
+		measures, listErr := registry.ListMeasureInRevisionRange(context.TODO(), "sw_metric", from, to)
+		req.NoError(listErr)
+		result := make([]string, 0, len(measures))
+		for _, m := range measures {
+			req.GreaterOrEqual(m.GetMetadata().GetModRevision(), from)
+			req.LessOrEqual(m.GetMetadata().GetModRevision(), to)
+			result = append(result, m.GetMetadata().GetName())
+		}
+		return result
+	}
+	// both ends are included
+	req.Equal([]string{"service_cpm", "service_resp_time"}, names(revisions["service_cpm"], revisions["service_resp_time"]))
+	req.Equal([]string{"service_resp_time"}, names(revisions["service_resp_time"], revisions["service_resp_time"]))
+	req.Empty(names(0, revisions["service_cpm"]-1))
+	req.Empty(names(0, 0))
+	req.Len(names(0, math.MaxInt64), 3)
+
+	_, err = registry.ListMeasureInRevisionRange(context.TODO(), "sw_metric", 2, 1)
+	req.ErrorIs(err, ErrInvalidRevisionRange)
+	_, err = registry.ListMeasureInRevisionRange(context.TODO(), "", 0, 1)
+	req.ErrorIs(err, ErrGroupAbsent)
+}
+
+func Test_Etcd_ListAllStreams(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+
+	// the name of a group prefixes the other one
+	for _, group := range []string{"sw", "sw_metric"} {
+		req.NoError(registry.UpdateGroup(context.TODO(), &commonv1.Group{
+			Metadata:     &commonv1.Metadata{Name: group},
+			ResourceOpts: &commonv1.ResourceOpts{ShardNum: 1},
+		}))
+		req.NoError(registry.UpdateStream(context.TODO(), &databasev1.Stream{
+			Metadata: &commonv1.Metadata{Group: group, Name: "segment"},
+		}))
+	}
+	req.NoError(registry.UpdateMeasure(context.TODO(), &databasev1.Measure{
+		Metadata: &commonv1.Metadata{Group: "sw_metric", Name: "service_cpm"},
+	}))
+	streams, err := registry.ListAllStreams(context.TODO())
+	req.NoError(err)
+	groups := make([]string, 0, len(streams))
+	for _, s := range streams {
+		groups = append(groups, s.GetMetadata().GetGroup())
+	}
+	req.ElementsMatch([]string{"sw", "sw_metric"}, groups)
+	measures, err := registry.ListAllMeasures(context.TODO())
+	req.NoError(err)
+	req.Len(measures, 1)
+}
+
+func Test_Etcd_ListStreamSince(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+
+	req.NoError(preloadSchema(registry))
+
+	entities, revision, err := registry.ListStreamSince(context.TODO(), "default", 0)
+	req.NoError(err)
+	req.Len(entities, 1)
+	req.Greater(revision, int64(0))
+
+	entities, cursor, err := registry.ListStreamSince(context.TODO(), "default", revision)
+	req.NoError(err)
+	req.Empty(entities)
+	req.Equal(revision, cursor)
+
+	s := &databasev1.Stream{}
+	req.NoError(protojson.Unmarshal([]byte(streamJSON), s))
+	s.Metadata.Name = "sw2"
+	req.NoError(registry.UpdateStream(context.TODO(), s))
+
+	entities, cursor, err = registry.ListStreamSince(context.TODO(), "default", revision)
+	req.NoError(err)
+	req.Len(entities, 1)
+	req.Equal("sw2", entities[0].GetMetadata().GetName())
+	req.Greater(cursor, revision)
+
+	_, _, err = registry.ListStreamSince(context.TODO(), "", 0)
+	req.ErrorIs(err, ErrGroupAbsent)
+}
+
+func Test_Etcd_ListStreamNames(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+
+	req.NoError(preloadSchema(registry))
+	s := &databasev1.Stream{}
+	req.NoError(protojson.Unmarshal([]byte(streamJSON), s))
+	s.Metadata.Name = "sw2"
+	req.NoError(registry.UpdateStream(context.TODO(), s))
+
+	names, err := registry.ListStreamNames(context.TODO(), "default")
+	req.NoError(err)
+	req.Equal([]string{"sw", "sw2"}, names)
+
+	names, err = registry.ListStreamNames(context.TODO(), "absent")
+	req.NoError(err)
+	req.Empty(names)
+
+	_, err = registry.ListStreamNames(context.TODO(), "")
+	req.ErrorIs(err, ErrGroupAbsent)
+}
+
 func Test_Etcd_Delete(t *testing.T) {
 	tester := assert.New(t)
 	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
@@ -480,6 +694,26 @@ func Test_Notify(t *testing.T) {
 					mocked.AssertNumberOfCalls(t, "OnDelete", 0)
 			},
 		},
+		{
+			name: "swap indexRuleBinding",
+			testFunc: func(ctx context.Context, r Registry) error {
+				meta := &commonv1.Metadata{
+					Name:  "sw-index-rule-binding",
+					Group: "default",
+				}
+				irb, err := r.GetIndexRuleBinding(ctx, meta)
+				if err != nil {
+					return err
+				}
+
+				irb.Rules = []string{"trace_id"}
+				return r.SwapIndexRuleBinding(ctx, meta, irb)
+			},
+			validationFunc: func(mocked *mockedEventHandler) bool {
+				return mocked.AssertNumberOfCalls(t, "OnAddOrUpdate", 1) &&
+					mocked.AssertNumberOfCalls(t, "OnDelete", 0)
+			},
+		},
 		{
 			name: "delete indexRuleBinding",
 			testFunc: func(ctx context.Context, r Registry) error {
@@ -516,3 +750,292 @@ func Test_Notify(t *testing.T) {
 		})
 	}
 }
+
+func Test_Etcd_Repair(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+
+	req.NoError(preloadSchema(registry))
+	report, err := registry.CheckConsistency(context.TODO(), "default")
+	req.NoError(err)
+	req.True(report.Consistent())
+
+	binding := &databasev1.IndexRuleBinding{}
+	req.NoError(protojson.Unmarshal([]byte(indexRuleBindingJSON), binding))
+	binding.Metadata.Name = "dangling-binding"
+	binding.Subject.Name = "absent"
+	binding.Rules = []string{"trace_id"}
+	req.NoError(registry.UpdateIndexRuleBinding(context.TODO(), binding))
+	rule := &databasev1.IndexRule{
+		Metadata: &commonv1.Metadata{Name: "orphaned-rule", Group: "default"},
+		Tags:     []string{"unknown"},
+		Type:     databasev1.IndexRule_TYPE_INVERTED,
+		Location: databasev1.IndexRule_LOCATION_SERIES,
+	}
+	req.NoError(registry.UpdateIndexRule(context.TODO(), rule))
+
+	report, err = registry.CheckConsistency(context.TODO(), "default")
+	req.NoError(err)
+	req.Len(report.DanglingBindings, 1)
+	req.Equal("dangling-binding", report.DanglingBindings[0].GetMetadata().GetName())
+	req.Len(report.OrphanedRules, 1)
+	req.Equal("orphaned-rule", report.OrphanedRules[0].GetMetadata().GetName())
+	req.Empty(report.MissingTags)
+
+	_, err = registry.Repair(context.TODO(), "default")
+	req.NoError(err)
+	report, err = registry.CheckConsistency(context.TODO(), "default")
+	req.NoError(err)
+	req.True(report.Consistent())
+	_, err = registry.GetIndexRule(context.TODO(), rule.GetMetadata())
+	req.ErrorIs(err, ErrEntityNotFound)
+	rules, err := registry.ListIndexRule(context.TODO(), ListOpt{Group: "default"})
+	req.NoError(err)
+	req.Len(rules, 10)
+}
+
+func Test_Etcd_SwapIndexRuleBinding(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+
+	req.NoError(preloadSchema(registry))
+	meta := &commonv1.Metadata{
+		Name:  "sw-index-rule-binding",
+		Group: "default",
+	}
+	binding, err := registry.GetIndexRuleBinding(context.TODO(), meta)
+	req.NoError(err)
+
+	binding.Rules = []string{"trace_id", "absent"}
+	req.ErrorIs(registry.SwapIndexRuleBinding(context.TODO(), meta, binding), ErrEntityNotFound)
+	req.ErrorIs(registry.SwapIndexRuleBinding(context.TODO(), &commonv1.Metadata{
+		Name:  "absent",
+		Group: "default",
+	}, binding), ErrEntityNotFound)
+
+	binding.Rules = []string{"trace_id", "duration"}
+	req.NoError(registry.SwapIndexRuleBinding(context.TODO(), meta, binding))
+	swapped, err := registry.GetIndexRuleBinding(context.TODO(), meta)
+	req.NoError(err)
+	req.Equal([]string{"trace_id", "duration"}, swapped.GetRules())
+}
+
+func Test_Etcd_IncompatibleSchemaChange(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+
+	req.NoError(preloadSchema(registry))
+	meta := &commonv1.Metadata{
+		Name:  "sw",
+		Group: "default",
+	}
+	s, err := registry.GetStream(context.TODO(), meta)
+	req.NoError(err)
+
+	tags := s.TagFamilies[1].Tags
+	s.TagFamilies[1].Tags = append(tags, &databasev1.TagSpec{Name: "extra", Type: databasev1.TagType_TAG_TYPE_STRING})
+	req.NoError(registry.UpdateStream(context.TODO(), s))
+
+	s, err = registry.GetStream(context.TODO(), meta)
+	req.NoError(err)
+	tag := s.TagFamilies[1].Tags[0]
+	if tag.Type == databasev1.TagType_TAG_TYPE_INT {
+		tag.Type = databasev1.TagType_TAG_TYPE_STRING
+	} else {
+		tag.Type = databasev1.TagType_TAG_TYPE_INT
+	}
+	req.ErrorIs(registry.UpdateStream(context.TODO(), s), ErrIncompatibleSchemaChange)
+
+	// the tags are located by their positions, so they can't be reordered or removed
+	reordered, err := registry.GetStream(context.TODO(), meta)
+	req.NoError(err)
+	reorderedTags := reordered.TagFamilies[1].Tags
+	reorderedTags[0], reorderedTags[1] = reorderedTags[1], reorderedTags[0]
+	req.ErrorIs(registry.UpdateStream(context.TODO(), reordered), ErrIncompatibleSchemaChange)
+	removed, err := registry.GetStream(context.TODO(), meta)
+	req.NoError(err)
+	removed.TagFamilies[1].Tags = removed.TagFamilies[1].Tags[1:]
+	req.ErrorIs(registry.UpdateStream(context.TODO(), removed), ErrIncompatibleSchemaChange)
+	removed, err = registry.GetStream(context.TODO(), meta)
+	req.NoError(err)
+	removed.TagFamilies = removed.TagFamilies[:1]
+	req.ErrorIs(registry.UpdateStream(context.TODO(), removed), ErrIncompatibleSchemaChange)
+
+	g, err := registry.GetGroup(context.TODO(), "default")
+	req.NoError(err)
+	g.ResourceOpts.AllowIncompatibleSchemaChange = true
+	req.NoError(registry.UpdateGroup(context.TODO(), g))
+	req.NoError(registry.UpdateStream(context.TODO(), s))
+}
+
+func Test_Etcd_Timestamps(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+
+	req.NoError(preloadSchema(registry))
+	meta := &commonv1.Metadata{
+		Name:  "sw",
+		Group: "default",
+	}
+	s, err := registry.GetStream(context.TODO(), meta)
+	req.NoError(err)
+	createdAt, updatedAt := s.GetMetadata().GetCreatedAt(), s.GetMetadata().GetUpdatedAt()
+	req.NotNil(createdAt)
+	req.True(proto.Equal(createdAt, updatedAt))
+
+	// nothing is written if the stream doesn't change
+	req.NoError(registry.UpdateStream(context.TODO(), s))
+	s, err = registry.GetStream(context.TODO(), meta)
+	req.NoError(err)
+	req.True(proto.Equal(updatedAt, s.GetMetadata().GetUpdatedAt()))
+
+	tags := s.TagFamilies[1].Tags
+	s.TagFamilies[1].Tags = append(tags, &databasev1.TagSpec{Name: "extra", Type: databasev1.TagType_TAG_TYPE_STRING})
+	req.NoError(registry.UpdateStream(context.TODO(), s))
+	s, err = registry.GetStream(context.TODO(), meta)
+	req.NoError(err)
+	req.True(proto.Equal(createdAt, s.GetMetadata().GetCreatedAt()))
+	req.True(s.GetMetadata().GetUpdatedAt().AsTime().After(updatedAt.AsTime()))
+}
+
+func Test_Etcd_DeleteMany(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+
+	req.NoError(preloadSchema(registry))
+	mockedObj := new(mockedEventHandler)
+	mockedObj.On("OnDelete", mock.Anything).Return()
+	registry.RegisterHandler(KindIndexRule|KindStream, mockedObj)
+
+	deleted, err := registry.DeleteIndexRules(context.TODO(), []*commonv1.Metadata{
+		{Name: "trace_id", Group: "default"},
+		{Name: "duration", Group: "default"},
+		{Name: "absent", Group: "default"},
+		{Name: "trace_id", Group: "default"},
+	})
+	req.NoError(err)
+	req.Equal(2, deleted)
+	mockedObj.AssertNumberOfCalls(t, "OnDelete", 2)
+	rules, err := registry.ListIndexRule(context.TODO(), ListOpt{Group: "default"})
+	req.NoError(err)
+	req.Len(rules, 8)
+
+	deleted, err = registry.DeleteStreams(context.TODO(), []*commonv1.Metadata{{Name: "sw", Group: "default"}})
+	req.NoError(err)
+	req.Equal(1, deleted)
+	mockedObj.AssertNumberOfCalls(t, "OnDelete", 3)
+
+	deleted, err = registry.DeleteMeasures(context.TODO(), nil)
+	req.NoError(err)
+	req.Zero(deleted)
+}
+
+func Test_Etcd_DeleteStreamCascade(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+	ctx := context.TODO()
+
+	req.NoError(preloadSchema(registry))
+	// another stream binding the same rules is left intact
+	other, err := registry.GetStream(ctx, &commonv1.Metadata{Name: "sw", Group: "default"})
+	req.NoError(err)
+	other.Metadata = &commonv1.Metadata{Name: "other", Group: "default"}
+	req.NoError(registry.UpdateStream(ctx, other))
+	otherBinding, err := registry.GetIndexRuleBinding(ctx, &commonv1.Metadata{Name: "sw-index-rule-binding", Group: "default"})
+	req.NoError(err)
+	otherBinding.Metadata = &commonv1.Metadata{Name: "other-index-rule-binding", Group: "default"}
+	otherBinding.Subject.Name = "other"
+	req.NoError(registry.UpdateIndexRuleBinding(ctx, otherBinding))
+
+	mockedObj := new(mockedEventHandler)
+	mockedObj.On("OnDelete", mock.Anything).Return()
+	registry.RegisterHandler(KindStream|KindIndexRuleBinding|KindIndexRule, mockedObj)
+
+	deleted, err := registry.DeleteStreamCascade(ctx, &commonv1.Metadata{Name: "sw", Group: "default"})
+	req.NoError(err)
+	req.True(deleted)
+	mockedObj.AssertNumberOfCalls(t, "OnDelete", 2)
+	mockedObj.AssertCalled(t, "OnDelete", mock.MatchedBy(func(m Metadata) bool {
+		return m.Kind == KindIndexRuleBinding && m.Name == "sw-index-rule-binding"
+	}))
+	_, err = registry.GetStream(ctx, &commonv1.Metadata{Name: "sw", Group: "default"})
+	req.True(errors.Is(err, ErrEntityNotFound))
+	bindings, err := registry.ListIndexRuleBinding(ctx, ListOpt{Group: "default"})
+	req.NoError(err)
+	req.Len(bindings, 1)
+	req.Equal("other-index-rule-binding", bindings[0].GetMetadata().GetName())
+	rules, err := registry.ListIndexRule(ctx, ListOpt{Group: "default"})
+	req.NoError(err)
+	req.Len(rules, 10)
+
+	deleted, err = registry.DeleteStreamCascade(ctx, &commonv1.Metadata{Name: "sw", Group: "default"})
+	req.NoError(err)
+	req.False(deleted)
+	mockedObj.AssertNumberOfCalls(t, "OnDelete", 2)
+}
+
+func Test_UseRandomListenerWithSource(t *testing.T) {
+	req := require.New(t)
+	listener := func(seed int64) *etcdSchemaRegistryConfig {
+		config := &etcdSchemaRegistryConfig{}
+		UseRandomListenerWithSource(rand.New(rand.NewSource(seed)))(config)
+		return config
+	}
+	req.Equal(listener(1), listener(1))
+	req.NotEqual(listener(1), listener(2))
+	config := listener(1)
+	req.NotEqual(config.listenerClientURL, config.listenerPeerURL)
+
+	registry, err := NewEtcdSchemaRegistry(UseRandomListenerWithSource(rand.New(rand.NewSource(1))), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+}
+
+func Test_Etcd_ListGroupByPrefix(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	for _, name := range []string{"tenantA_trace", "tenantA_log", "tenantB_trace", "default"} {
+		req.NoError(updateGroup(registry, name))
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{prefix: "tenantA_", want: []string{"tenantA_log", "tenantA_trace"}},
+		{prefix: "tenant", want: []string{"tenantA_log", "tenantA_trace", "tenantB_trace"}},
+		{prefix: "tenantB_trace", want: []string{"tenantB_trace"}},
+		{prefix: "tenantC_", want: nil},
+		{prefix: "", want: []string{"default", "tenantA_log", "tenantA_trace", "tenantB_trace"}},
+	}
+	for _, tt := range tests {
+		groups, err := registry.ListGroupByPrefix(context.TODO(), tt.prefix)
+		req.NoError(err)
+		var names []string
+		for _, g := range groups {
+			names = append(names, g.GetMetadata().GetName())
+		}
+		req.Equal(tt.want, names, "prefix %q", tt.prefix)
+	}
+}
@@ -0,0 +1,341 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/apache/skywalking-banyandb/pkg/meter"
+)
+
+var ErrEventQueueFull = errors.New("the event queue of the handler is full")
+
+const (
+	defaultQueueObserveInterval = 10 * time.Second
+	// staleEventWindow is how long the revision of a key is kept after its latest event leaves the queue,
+	// so that an older event of the key arriving late is still discarded
+	staleEventWindow = time.Minute
+)
+
+// OverflowPolicy decides what happens to a new event if the queue of a handler is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock holds the write until the handler catches up
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest pending event to make room for the new one
+	OverflowDropOldest
+	// OverflowError discards the new event. The write is persisted anyway, but it reports ErrEventQueueFull
+	OverflowError
+)
+
+// AsyncDelivery delivers the events to each handler from a queue holding at most size events,
+// so a slow handler doesn't hold up the writes or the other handlers.
+// The events of a key are delivered in the order they are committed. An event
+// arriving after a newer one of the same key is discarded instead of being delivered out of order,
+// as long as it arrives within staleEventWindow after the newer one is delivered.
+// The handler priorities only order the enqueueing, the handlers don't wait for the ones of higher priorities.
+func AsyncDelivery(size int, policy OverflowPolicy) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		if size < 1 {
+			size = 1
+		}
+		config.queueSize = size
+		config.overflowPolicy = policy
+	}
+}
+
+// EventQueueStat is a snapshot of the queue of a handler. A growing Depth tells the handler is lagging.
+type EventQueueStat struct {
+	Kind     Kind
+	Depth    int
+	Capacity int
+	// Dropped counts the events discarded by the overflow policy
+	Dropped uint64
+	// Lag is how long the oldest pending event has been waiting. It's zero if the queue is empty.
+	Lag time.Duration
+}
+
+// ObserveEventQueues feeds the stats of the event queues into the observer every interval until the registry is closed.
+// It takes effect along with AsyncDelivery.
+func ObserveEventQueues(observer meter.MetricsObserver, interval time.Duration) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.queueObserver = observer
+		config.queueObserveInterval = interval
+	}
+}
+
+// ObserveEventQueueStats feeds the stats into the observer as gauges. The handlers are labeled by their order of notification.
+func ObserveEventQueueStats(observer meter.MetricsObserver, stats []EventQueueStat) {
+	for i, stat := range stats {
+		labels := meter.Labels{
+			"handler": strconv.Itoa(i),
+			"kind":    strconv.Itoa(int(stat.Kind)),
+		}
+		observer.Gauge("schema_event_queue_depth", float64(stat.Depth), labels)
+		observer.Gauge("schema_event_queue_capacity", float64(stat.Capacity), labels)
+		observer.Gauge("schema_event_queue_dropped", float64(stat.Dropped), labels)
+		observer.Gauge("schema_event_queue_lag_seconds", stat.Lag.Seconds(), labels)
+	}
+}
+
+func (e *etcdSchemaRegistry) observeEventQueues(observer meter.MetricsObserver, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ObserveEventQueueStats(observer, e.EventQueueStats())
+		case <-e.StoppingNotify():
+			return
+		}
+	}
+}
+
+type event struct {
+	metadata   Metadata
+	deleted    bool
+	revision   int64
+	enqueuedAt time.Time
+	// resync carries the kinds of the suppressed events in the metadata
+	resync bool
+}
+
+type eventQueue struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	handler  *eventHandler
+	policy   OverflowPolicy
+	size     int
+	events   []event
+	// revisions are the latest revisions of the keys whose events are queued, or left the queue within staleWindow
+	revisions   map[TypeMeta]int64
+	left        []leftEvent
+	staleWindow time.Duration
+	dropped     uint64
+	closed      bool
+	done        chan struct{}
+}
+
+// leftEvent is an event delivered or dropped, in the order they left the queue
+type leftEvent struct {
+	key      TypeMeta
+	revision int64
+	at       time.Time
+}
+
+func newEventQueue(handler *eventHandler, size int, policy OverflowPolicy) *eventQueue {
+	q := &eventQueue{
+		handler:     handler,
+		policy:      policy,
+		size:        size,
+		events:      make([]event, 0, size),
+		revisions:   make(map[TypeMeta]int64),
+		staleWindow: staleEventWindow,
+		done:        make(chan struct{}),
+	}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+func (q *eventQueue) push(ev event) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	if q.stale(ev) {
+		return nil
+	}
+	for len(q.events) >= q.size {
+		switch q.policy {
+		case OverflowDropOldest:
+			q.leave(q.events[0])
+			q.events = q.events[1:]
+			q.dropped++
+		case OverflowError:
+			q.dropped++
+			return errors.Wrapf(ErrEventQueueFull, "kind %d: %s/%s", ev.metadata.Kind, ev.metadata.Group, ev.metadata.Name)
+		default:
+			q.notFull.Wait()
+			if q.closed {
+				return nil
+			}
+		}
+	}
+	// a newer event might be queued while waiting for the room
+	if q.stale(ev) {
+		return nil
+	}
+	if !ev.resync {
+		q.revisions[ev.metadata.TypeMeta] = ev.revision
+	}
+	ev.enqueuedAt = time.Now()
+	q.events = append(q.events, ev)
+	q.notEmpty.Signal()
+	return nil
+}
+
+func (q *eventQueue) run() {
+	defer close(q.done)
+	for {
+		q.mu.Lock()
+		for len(q.events) < 1 && !q.closed {
+			q.notEmpty.Wait()
+		}
+		if len(q.events) < 1 {
+			q.mu.Unlock()
+			return
+		}
+		ev := q.events[0]
+		q.events = q.events[1:]
+		q.notFull.Signal()
+		q.mu.Unlock()
+		q.handler.deliver(ev)
+		q.mu.Lock()
+		q.leave(ev)
+		q.mu.Unlock()
+	}
+}
+
+// stale tells whether a newer event of the key is queued or left the queue recently
+func (q *eventQueue) stale(ev event) bool {
+	return !ev.resync && ev.revision < q.revisions[ev.metadata.TypeMeta]
+}
+
+// leave records the event leaving the queue, and prunes the revisions of the keys whose latest events
+// left more than staleWindow ago. The caller holds the lock.
+func (q *eventQueue) leave(ev event) {
+	now := time.Now()
+	if !ev.resync {
+		q.left = append(q.left, leftEvent{key: ev.metadata.TypeMeta, revision: ev.revision, at: now})
+	}
+	i := 0
+	for ; i < len(q.left) && now.Sub(q.left[i].at) > q.staleWindow; i++ {
+		if q.revisions[q.left[i].key] == q.left[i].revision {
+			delete(q.revisions, q.left[i].key)
+		}
+	}
+	q.left = q.left[i:]
+}
+
+// close stops accepting events. The pending ones are still delivered unless discard is set.
+func (q *eventQueue) close(discard bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	if discard {
+		q.events = nil
+	}
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+}
+
+func (q *eventQueue) stat() EventQueueStat {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stat := EventQueueStat{
+		Kind:     q.handler.interestKeys,
+		Depth:    len(q.events),
+		Capacity: q.size,
+		Dropped:  q.dropped,
+	}
+	if len(q.events) > 0 {
+		stat.Lag = time.Since(q.events[0].enqueuedAt)
+	}
+	return stat
+}
+
+// EventQueueStats returns the stats of the queues in the order the handlers are notified.
+// It's empty unless AsyncDelivery is set.
+func (e *etcdSchemaRegistry) EventQueueStats() []EventQueueStat {
+	e.handlersMu.RLock()
+	defer e.handlersMu.RUnlock()
+	stats := make([]EventQueueStat, 0, len(e.queues))
+	for _, q := range e.queues {
+		stats = append(stats, q.stat())
+	}
+	return stats
+}
+
+func (e *etcdSchemaRegistry) notify(ev event) error {
+	if !ev.resync && e.suppress(ev.metadata.Kind) {
+		return nil
+	}
+	e.handlersMu.RLock()
+	handlers, queues := e.handlers, e.queues
+	e.handlersMu.RUnlock()
+	if e.queueSize < 1 {
+		for _, h := range handlers {
+			if h.interestedIn(ev) {
+				h.deliver(ev)
+			}
+		}
+		return nil
+	}
+	var err error
+	for _, q := range queues {
+		if !q.handler.interestedIn(ev) {
+			continue
+		}
+		if pushErr := q.push(ev); pushErr != nil && err == nil {
+			err = pushErr
+		}
+	}
+	return err
+}
+
+func (e *etcdSchemaRegistry) notifyUpdate(metadata Metadata, revision int64) error {
+	return e.notify(event{metadata: metadata, revision: revision})
+}
+
+func (e *etcdSchemaRegistry) notifyDelete(metadata Metadata, revision int64) error {
+	return e.notify(event{metadata: metadata, deleted: true, revision: revision})
+}
+
+// closeQueues stops accepting events. The pending ones are still delivered unless discard is set.
+func (e *etcdSchemaRegistry) closeQueues(discard bool) {
+	e.handlersMu.RLock()
+	defer e.handlersMu.RUnlock()
+	for _, q := range e.queues {
+		q.close(discard)
+	}
+}
+
+// drainQueues waits for the pending events to be delivered after the queues are closed
+func (e *etcdSchemaRegistry) drainQueues(ctx context.Context) error {
+	e.handlersMu.RLock()
+	queues := e.queues
+	e.handlersMu.RUnlock()
+	for _, q := range queues {
+		select {
+		case <-q.done:
+		case <-ctx.Done():
+			return errors.Wrapf(ErrDrainTimeout, "%v", ctx.Err())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
+)
+
+var (
+	_ EventHandler          = (*recordingHandler)(nil)
+	_ meter.MetricsObserver = (*gaugeRecorder)(nil)
+)
+
+// recordingHandler records the events after the gate is opened
+type recordingHandler struct {
+	gate   chan struct{}
+	mu     sync.Mutex
+	events []string
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{gate: make(chan struct{})}
+}
+
+func (h *recordingHandler) OnAddOrUpdate(metadata Metadata) {
+	<-h.gate
+	h.record("update " + metadata.Name)
+}
+
+func (h *recordingHandler) OnDelete(metadata Metadata) {
+	<-h.gate
+	h.record("delete " + metadata.Name)
+}
+
+func (h *recordingHandler) record(event string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event)
+}
+
+func (h *recordingHandler) recorded() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string{}, h.events...)
+}
+
+func updateGroup(registry Registry, name string) error {
+	return registry.UpdateGroup(context.TODO(), &commonv1.Group{
+		Metadata: &commonv1.Metadata{Name: name},
+		Catalog:  commonv1.Catalog_CATALOG_STREAM,
+	})
+}
+
+func Test_Etcd_AsyncDelivery_DropOldest(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir(), AsyncDelivery(2, OverflowDropOldest))
+	req.NoError(err)
+	defer registry.Close()
+	handler := newRecordingHandler()
+	registry.RegisterHandler(KindGroup, handler)
+
+	// the handler holds the first event, so the rest pile up in the queue
+	req.NoError(updateGroup(registry, "g1"))
+	req.Eventually(func() bool {
+		return registry.EventQueueStats()[0].Depth == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	for _, name := range []string{"g2", "g3", "g4"} {
+		req.NoError(updateGroup(registry, name))
+	}
+	stats := registry.EventQueueStats()
+	req.Len(stats, 1)
+	req.Equal(2, stats[0].Depth)
+	req.Equal(KindGroup, stats[0].Kind)
+	req.Equal(2, stats[0].Capacity)
+	req.Equal(uint64(1), stats[0].Dropped)
+
+	close(handler.gate)
+	req.Eventually(func() bool {
+		return len(handler.recorded()) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	req.Equal([]string{"update g1", "update g3", "update g4"}, handler.recorded())
+}
+
+func Test_Etcd_AsyncDelivery_Error(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir(), AsyncDelivery(1, OverflowError))
+	req.NoError(err)
+	defer registry.Close()
+	handler := newRecordingHandler()
+	defer close(handler.gate)
+	registry.RegisterHandler(KindGroup, handler)
+
+	req.NoError(updateGroup(registry, "g1"))
+	req.Eventually(func() bool {
+		return registry.EventQueueStats()[0].Depth == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	req.NoError(updateGroup(registry, "g2"))
+	req.ErrorIs(updateGroup(registry, "g3"), ErrEventQueueFull)
+	// the entity is persisted even though its event is lost
+	_, err = registry.GetGroup(context.TODO(), "g3")
+	req.NoError(err)
+	req.Equal(uint64(1), registry.EventQueueStats()[0].Dropped)
+}
+
+func Test_Etcd_AsyncDelivery_Order(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir(), AsyncDelivery(8, OverflowBlock))
+	req.NoError(err)
+	handler := newRecordingHandler()
+	registry.RegisterHandler(KindGroup, handler)
+
+	req.NoError(updateGroup(registry, "g1"))
+	_, err = registry.DeleteGroup(context.TODO(), "g1")
+	req.NoError(err)
+	req.NoError(updateGroup(registry, "g1"))
+	close(handler.gate)
+
+	// Shutdown delivers the pending events
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req.NoError(registry.Shutdown(ctx))
+	<-registry.StopNotify()
+	req.Equal([]string{"update g1", "delete g1", "update g1"}, handler.recorded())
+}
+
+func Test_EventQueue_Stale(t *testing.T) {
+	req := require.New(t)
+	handler := newRecordingHandler()
+	close(handler.gate)
+	q := newEventQueue(&eventHandler{interestKeys: KindGroup, handler: handler}, 4, OverflowBlock)
+	g1 := Metadata{TypeMeta: TypeMeta{Kind: KindGroup, Name: "g1"}}
+	req.NoError(q.push(event{metadata: g1, deleted: true, revision: 3}))
+	// the update committed before the delete arrives late
+	req.NoError(q.push(event{metadata: g1, revision: 2}))
+	q.close(false)
+	<-q.done
+	req.Equal([]string{"delete g1"}, handler.recorded())
+}
+
+func Test_EventQueue_PruneRevisions(t *testing.T) {
+	req := require.New(t)
+	handler := newRecordingHandler()
+	close(handler.gate)
+	q := newEventQueue(&eventHandler{interestKeys: KindGroup, handler: handler}, 4, OverflowBlock)
+	q.mu.Lock()
+	q.staleWindow = 0
+	q.mu.Unlock()
+	revisions := func() int {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return len(q.revisions)
+	}
+	for i, name := range []string{"g1", "g2", "g3"} {
+		req.NoError(q.push(event{metadata: Metadata{TypeMeta: TypeMeta{Kind: KindGroup, Name: name}}, revision: int64(i + 1)}))
+		req.Eventually(func() bool {
+			return len(handler.recorded()) == i+1
+		}, 5*time.Second, 10*time.Millisecond)
+		time.Sleep(time.Millisecond)
+	}
+	// only the last key is kept until another event leaves
+	req.Eventually(func() bool {
+		return revisions() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	q.close(false)
+	<-q.done
+}
+
+func Test_EventQueue_RejectedRevision(t *testing.T) {
+	req := require.New(t)
+	handler := newRecordingHandler()
+	q := newEventQueue(&eventHandler{interestKeys: KindGroup, handler: handler}, 1, OverflowError)
+	g1 := Metadata{TypeMeta: TypeMeta{Kind: KindGroup, Name: "g1"}}
+	g2 := Metadata{TypeMeta: TypeMeta{Kind: KindGroup, Name: "g2"}}
+	// the handler holds the first event, and the second one fills the queue
+	req.NoError(q.push(event{metadata: g1, revision: 1}))
+	req.Eventually(func() bool {
+		return q.stat().Depth == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	req.NoError(q.push(event{metadata: g1, revision: 2}))
+	req.ErrorIs(q.push(event{metadata: g2, revision: 3}), ErrEventQueueFull)
+	q.mu.Lock()
+	_, ok := q.revisions[g2.TypeMeta]
+	q.mu.Unlock()
+	req.False(ok, "the rejected event isn't recorded")
+	close(handler.gate)
+	// the rejected event doesn't hold an older one of the key off
+	req.Eventually(func() bool {
+		return q.push(event{metadata: g2, revision: 2}) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	q.close(false)
+	<-q.done
+	req.Equal([]string{"update g1", "update g1", "update g2"}, handler.recorded())
+}
+
+// gaugeRecorder keeps the latest value of each gauge
+type gaugeRecorder struct {
+	mu     sync.Mutex
+	gauges map[string]float64
+}
+
+func (g *gaugeRecorder) Gauge(name string, value float64, labels meter.Labels) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.gauges[name+"/"+labels["handler"]] = value
+}
+
+func (g *gaugeRecorder) get(name string) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.gauges[name]
+}
+
+func Test_Etcd_ObserveEventQueues(t *testing.T) {
+	req := require.New(t)
+	observer := &gaugeRecorder{gauges: make(map[string]float64)}
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir(),
+		AsyncDelivery(4, OverflowBlock), ObserveEventQueues(observer, 10*time.Millisecond))
+	req.NoError(err)
+	defer registry.Close()
+	handler := newRecordingHandler()
+	defer close(handler.gate)
+	registry.RegisterHandler(KindGroup, handler)
+
+	// the handler holds g1, then g2 waits in the queue
+	req.NoError(updateGroup(registry, "g1"))
+	req.NoError(updateGroup(registry, "g2"))
+	req.Eventually(func() bool {
+		return observer.get("schema_event_queue_depth/0") == 1 &&
+			observer.get("schema_event_queue_lag_seconds/0") > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	req.Equal(float64(4), observer.get("schema_event_queue_capacity/0"))
+	req.Equal(float64(0), observer.get("schema_event_queue_dropped/0"))
+	stat := registry.EventQueueStats()[0]
+	req.Positive(stat.Lag)
+}
+
+func Test_Etcd_RegisterHandlerForGroup(t *testing.T) {
+	for name, opts := range map[string][]RegistryOption{
+		"sync":  nil,
+		"async": {AsyncDelivery(8, OverflowBlock)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := require.New(t)
+			registry, err := NewEtcdSchemaRegistry(append([]RegistryOption{useUnixDomain(), useRandomTempDir()}, opts...)...)
+			req.NoError(err)
+			defer registry.Close()
+			handler := newRecordingHandler()
+			close(handler.gate)
+			registry.RegisterHandlerForGroup(KindGroup|KindIndexRule, "g1", handler)
+			all := newRecordingHandler()
+			close(all.gate)
+			registry.RegisterHandler(KindGroup|KindIndexRule, all)
+
+			updateRule := func(group, name string) error {
+				return registry.UpdateIndexRule(context.TODO(), &databasev1.IndexRule{
+					Metadata: &commonv1.Metadata{Group: group, Name: name},
+					Tags:     []string{name},
+					Type:     databasev1.IndexRule_TYPE_TREE,
+					Location: databasev1.IndexRule_LOCATION_SERIES,
+				})
+			}
+			req.NoError(updateGroup(registry, "g1"))
+			req.NoError(updateGroup(registry, "g2"))
+			req.NoError(updateRule("g1", "r1"))
+			req.NoError(updateRule("g2", "r2"))
+			_, err = registry.DeleteIndexRule(context.TODO(), &commonv1.Metadata{Group: "g2", Name: "r2"})
+			req.NoError(err)
+			_, err = registry.DeleteIndexRule(context.TODO(), &commonv1.Metadata{Group: "g1", Name: "r1"})
+			req.NoError(err)
+
+			req.Eventually(func() bool {
+				return len(all.recorded()) == 6
+			}, 5*time.Second, 10*time.Millisecond)
+			req.Equal([]string{"update g1", "update r1", "delete r1"}, handler.recorded())
+		})
+	}
+}
+
+func Test_Etcd_PrevSpec(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+	handler := &mockedEventHandler{}
+	handler.On("OnAddOrUpdate", mock.Anything).Return()
+	registry.RegisterHandler(KindStream|KindGroup, handler)
+
+	md := &commonv1.Metadata{Name: "sw", Group: "default"}
+	s, err := registry.GetStream(context.TODO(), md)
+	req.NoError(err)
+	updated := proto.Clone(s).(*databasev1.Stream)
+	updated.Entity.TagNames = append(updated.Entity.TagNames, "trace_id")
+	req.NoError(registry.UpdateStream(context.TODO(), updated))
+	req.NoError(updateGroup(registry, "g1"))
+
+	req.Len(handler.Calls, 2)
+	streamEvent := handler.Calls[0].Arguments.Get(0).(Metadata)
+	req.Equal([]string{"service_id", "service_instance_id", "state", "trace_id"}, streamEvent.Spec.(*databasev1.Stream).GetEntity().GetTagNames())
+	prev, ok := streamEvent.PrevSpec.(*databasev1.Stream)
+	req.True(ok)
+	req.Equal(s.GetEntity().GetTagNames(), prev.GetEntity().GetTagNames())
+	req.Equal(s.GetMetadata().GetModRevision(), prev.GetMetadata().GetModRevision())
+	// a created entity has nothing to be compared with
+	req.Nil(handler.Calls[1].Arguments.Get(0).(Metadata).PrevSpec)
+
+	handler.Calls = nil
+	batchUpdated := proto.Clone(updated).(*databasev1.Stream)
+	batchUpdated.Entity.TagNames = batchUpdated.Entity.TagNames[:3]
+	req.NoError(registry.ApplyBatch(context.TODO(), []Metadata{{
+		TypeMeta: TypeMeta{Kind: KindStream, Group: md.Group, Name: md.Name},
+		Spec:     batchUpdated,
+	}}))
+	req.Len(handler.Calls, 1)
+	prev, ok = handler.Calls[0].Arguments.Get(0).(Metadata).PrevSpec.(*databasev1.Stream)
+	req.True(ok)
+	req.Equal(updated.GetEntity().GetTagNames(), prev.GetEntity().GetTagNames())
+
+	handler.Calls = nil
+	req.NoError(registry.Transaction(context.TODO(), func(tx RegistryTxn) error {
+		typeMeta := TypeMeta{Kind: KindStream, Group: md.Group, Name: md.Name}
+		for _, tagNames := range [][]string{updated.GetEntity().GetTagNames(), s.GetEntity().GetTagNames()} {
+			txnUpdated := proto.Clone(batchUpdated).(*databasev1.Stream)
+			txnUpdated.Entity.TagNames = tagNames
+			if err := tx.Put(Metadata{TypeMeta: typeMeta, Spec: txnUpdated}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	req.Len(handler.Calls, 1)
+	prev, ok = handler.Calls[0].Arguments.Get(0).(Metadata).PrevSpec.(*databasev1.Stream)
+	req.True(ok)
+	// the update is compared with the stream before the transaction
+	req.Equal(batchUpdated.GetEntity().GetTagNames(), prev.GetEntity().GetTagNames())
+}
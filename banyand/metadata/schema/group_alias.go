@@ -0,0 +1,204 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+var (
+	ErrGroupAliasCycle    = errors.New("the aliases of groups make a cycle")
+	ErrGroupAliasConflict = errors.New("the alias conflicts with a group")
+)
+
+// maxGroupAliasDepth bounds the chain of aliases, which keeps a cycle made by concurrent writers from hanging the lookups
+const maxGroupAliasDepth = 16
+
+// CreateGroupAlias creates or re-points the alias. It fails with ErrGroupAbsent if the target doesn't resolve to a group,
+// ErrGroupAliasConflict if a group has the same name as the alias, and ErrGroupAliasCycle if the chain of the target
+// leads back to the alias.
+func (e *etcdSchemaRegistry) CreateGroupAlias(ctx context.Context, alias, target string) error {
+	if err := e.gate.enter(); err != nil {
+		return err
+	}
+	defer e.gate.leave()
+	groupKey := e.keyLayout.formatGroupKey(alias)
+	groupResp, err := e.kv.Get(ctx, groupKey, clientv3.WithCountOnly())
+	if err != nil {
+		return err
+	}
+	if groupResp.Count > 0 {
+		return errors.Wrapf(ErrGroupAliasConflict, "group %s", alias)
+	}
+	// none of the hops to the group is allowed to change before the alias is put
+	cmps := []clientv3.Cmp{clientv3.Compare(clientv3.CreateRevision(groupKey), "=", 0)}
+	for name, depth := target, 0; ; depth++ {
+		if name == alias || depth > maxGroupAliasDepth {
+			return errors.Wrapf(ErrGroupAliasCycle, "alias %s of %s", alias, target)
+		}
+		hopKey := e.keyLayout.formatGroupKey(name)
+		if groupResp, err = e.kv.Get(ctx, hopKey, clientv3.WithCountOnly()); err != nil {
+			return err
+		}
+		if groupResp.Count > 0 {
+			cmps = append(cmps, clientv3.Compare(clientv3.CreateRevision(hopKey), ">", 0))
+			break
+		}
+		hopKey = e.keyLayout.formatGroupAliasKey(name)
+		aliasResp, innerErr := e.kv.Get(ctx, hopKey)
+		if innerErr != nil {
+			return innerErr
+		}
+		if aliasResp.Count < 1 {
+			return errors.Wrapf(ErrGroupAbsent, "target %s of alias %s", target, alias)
+		}
+		hop := &commonv1.Metadata{}
+		if innerErr = unmarshal(aliasResp.Kvs[0].Key, aliasResp.Kvs[0].Value, hop); innerErr != nil {
+			return innerErr
+		}
+		cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(hopKey), "=", aliasResp.Kvs[0].ModRevision))
+		name = hop.GetGroup()
+	}
+	key := e.keyLayout.formatGroupAliasKey(alias)
+	getResp, err := e.kv.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	existing := &commonv1.Metadata{}
+	var prevSpec Spec
+	var modRevision int64
+	if getResp.Count > 0 {
+		if err = unmarshal(getResp.Kvs[0].Key, getResp.Kvs[0].Value, existing); err != nil {
+			return err
+		}
+		modRevision = getResp.Kvs[0].ModRevision
+		prevSpec = existing
+	}
+	cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(key), "=", modRevision))
+	spec := &commonv1.Metadata{
+		Name:      alias,
+		Group:     target,
+		UpdatedAt: timestamppb.New(time.Now()),
+	}
+	spec.CreatedAt = spec.UpdatedAt
+	if existing.GetCreatedAt() != nil {
+		spec.CreatedAt = existing.GetCreatedAt()
+	}
+	val, err := e.marshal(spec)
+	if err != nil {
+		return err
+	}
+	txnResp, err := e.kv.Txn(ctx).If(cmps...).Then(clientv3.OpPut(key, string(val))).Commit()
+	if err != nil {
+		return err
+	}
+	if !txnResp.Succeeded {
+		return ErrConcurrentModification
+	}
+	return e.notifyUpdate(Metadata{
+		TypeMeta: TypeMeta{
+			Kind: KindGroupAlias,
+			Name: alias,
+		},
+		Spec:     spec,
+		PrevSpec: prevSpec,
+	}, txnResp.Header.GetRevision())
+}
+
+// DeleteGroupAlias leaves the aliases pointing to the alias dangling, whose lookups fail as the group is absent
+func (e *etcdSchemaRegistry) DeleteGroupAlias(ctx context.Context, alias string) (bool, error) {
+	if err := e.gate.enter(); err != nil {
+		return false, err
+	}
+	defer e.gate.leave()
+	resp, err := e.kv.Delete(ctx, e.keyLayout.formatGroupAliasKey(alias), clientv3.WithPrevKV())
+	if err != nil {
+		return false, err
+	}
+	if resp.Deleted < 1 {
+		return false, nil
+	}
+	spec := &commonv1.Metadata{}
+	if unmarshalErr := unmarshal(resp.PrevKvs[0].Key, resp.PrevKvs[0].Value, spec); unmarshalErr == nil {
+		return true, e.notifyDelete(Metadata{
+			TypeMeta: TypeMeta{
+				Kind: KindGroupAlias,
+				Name: alias,
+			},
+			Spec: spec,
+		}, resp.Header.GetRevision())
+	}
+	return true, nil
+}
+
+// resolveGroupAlias follows the chain of the aliases from name. ok is false if name is not an alias.
+func (e *etcdSchemaRegistry) resolveGroupAlias(ctx context.Context, name string) (target string, ok bool, err error) {
+	target = name
+	for depth := 0; ; depth++ {
+		alias := &commonv1.Metadata{}
+		err = e.get(ctx, e.keyLayout.formatGroupAliasKey(target), alias)
+		if errors.Is(err, ErrEntityNotFound) {
+			return target, depth > 0, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		if depth >= maxGroupAliasDepth {
+			return "", false, errors.Wrapf(ErrGroupAliasCycle, "alias %s", name)
+		}
+		target = alias.GetGroup()
+	}
+}
+
+// getInGroup retries the lookup in the target group if the group of the metadata turns out to be an alias
+func (e *etcdSchemaRegistry) getInGroup(ctx context.Context, metadata *commonv1.Metadata, formatKey func(*commonv1.Metadata) string,
+	message proto.Message, opts ...ReadOption) error {
+	err := e.get(ctx, formatKey(metadata), message, opts...)
+	if !errors.Is(err, ErrEntityNotFound) {
+		return err
+	}
+	target, ok, resolveErr := e.resolveGroupAlias(ctx, metadata.GetGroup())
+	if resolveErr != nil {
+		return resolveErr
+	}
+	if !ok {
+		return err
+	}
+	return e.get(ctx, formatKey(&commonv1.Metadata{Group: target, Name: metadata.GetName()}), message, opts...)
+}
+
+// checkGroupNotAliased returns the condition which keeps the alias of the same name from being created until the group is put
+func (e *etcdSchemaRegistry) checkGroupNotAliased(ctx context.Context, group string) (clientv3.Cmp, error) {
+	key := e.keyLayout.formatGroupAliasKey(group)
+	resp, err := e.kv.Get(ctx, key, clientv3.WithCountOnly())
+	if err != nil {
+		return clientv3.Cmp{}, err
+	}
+	if resp.Count > 0 {
+		return clientv3.Cmp{}, errors.Wrapf(ErrGroupAliasConflict, "alias %s", group)
+	}
+	return clientv3.Compare(clientv3.CreateRevision(key), "=", 0), nil
+}
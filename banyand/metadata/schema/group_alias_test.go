@@ -0,0 +1,71 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func Test_Etcd_GroupAlias(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+	handler := newRecordingHandler()
+	close(handler.gate)
+	registry.RegisterHandler(KindGroupAlias, handler)
+
+	req.ErrorIs(registry.CreateGroupAlias(context.TODO(), "legacy", "absent"), ErrGroupAbsent)
+	req.ErrorIs(registry.CreateGroupAlias(context.TODO(), "default", "default"), ErrGroupAliasConflict)
+	req.NoError(registry.CreateGroupAlias(context.TODO(), "legacy", "default"))
+	req.NoError(registry.CreateGroupAlias(context.TODO(), "older", "legacy"))
+	req.ErrorIs(registry.CreateGroupAlias(context.TODO(), "legacy", "older"), ErrGroupAliasCycle)
+	req.ErrorIs(updateGroup(registry, "legacy"), ErrGroupAliasConflict)
+
+	for _, alias := range []string{"legacy", "older"} {
+		g, getErr := registry.GetGroup(context.TODO(), alias)
+		req.NoError(getErr)
+		req.Equal("default", g.GetMetadata().GetName())
+		s, getErr := registry.GetStream(context.TODO(), &commonv1.Metadata{Group: alias, Name: "sw"})
+		req.NoError(getErr)
+		req.Equal("default", s.GetMetadata().GetGroup())
+		_, getErr = registry.GetIndexRule(context.TODO(), &commonv1.Metadata{Group: alias, Name: "trace_id"})
+		req.NoError(getErr)
+	}
+	_, err = registry.GetStream(context.TODO(), &commonv1.Metadata{Group: "legacy", Name: "absent"})
+	req.ErrorIs(err, ErrEntityNotFound)
+
+	// the alias never deletes its target
+	_, err = registry.DeleteGroup(context.TODO(), "legacy")
+	req.ErrorIs(err, ErrEntityNotFound)
+	deleted, err := registry.DeleteGroupAlias(context.TODO(), "legacy")
+	req.NoError(err)
+	req.True(deleted)
+	_, err = registry.GetGroup(context.TODO(), "older")
+	req.ErrorIs(err, ErrEntityNotFound)
+	_, err = registry.GetGroup(context.TODO(), "default")
+	req.NoError(err)
+	req.Equal([]string{"update legacy", "update older", "delete legacy"}, handler.recorded())
+}
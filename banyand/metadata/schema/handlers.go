@@ -0,0 +1,68 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import "fmt"
+
+// DefaultHandlerPriority is the priority of the handlers registered by RegisterHandler and RegisterHandlerForGroup
+const DefaultHandlerPriority = 0
+
+// HandlerInfo describes a registered handler for debugging the event propagation
+type HandlerInfo struct {
+	// Name is the one given by NamedHandler, or the type of the handler
+	Name string
+	Kind Kind
+	// Group is empty if the handler is interested in all the groups
+	Group    string
+	Priority int
+}
+
+type namedHandler struct {
+	EventHandler
+	name string
+}
+
+// NamedHandler labels the handler with a name, which Handlers reports.
+// The registry unwraps the handler on registering, so the handler still receives the resyncs if it's a Resyncer.
+func NamedHandler(name string, handler EventHandler) EventHandler {
+	return namedHandler{EventHandler: handler, name: name}
+}
+
+// unwrapHandler returns the name of the handler along with the handler itself
+func unwrapHandler(handler EventHandler) (string, EventHandler) {
+	if named, ok := handler.(namedHandler); ok {
+		return named.name, named.EventHandler
+	}
+	return fmt.Sprintf("%T", handler), handler
+}
+
+// Handlers returns the registered handlers in the order they are notified
+func (e *etcdSchemaRegistry) Handlers() []HandlerInfo {
+	e.handlersMu.RLock()
+	defer e.handlersMu.RUnlock()
+	infos := make([]HandlerInfo, 0, len(e.handlers))
+	for _, h := range e.handlers {
+		infos = append(infos, HandlerInfo{
+			Name:     h.name,
+			Kind:     h.interestKeys,
+			Group:    h.group,
+			Priority: h.priority,
+		})
+	}
+	return infos
+}
@@ -0,0 +1,105 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Etcd_Handlers(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	plain := newRecordingHandler()
+	close(plain.gate)
+	registry.RegisterHandler(KindStream|KindIndexRule, plain)
+	resyncing := resyncingHandler{newRecordingHandler()}
+	close(resyncing.gate)
+	registry.RegisterHandlerForGroup(KindGroup, "g1", NamedHandler("group-cache", resyncing))
+
+	req.Equal([]HandlerInfo{
+		{Name: "*schema.recordingHandler", Kind: KindStream | KindIndexRule},
+		{Name: "group-cache", Kind: KindGroup, Group: "g1"},
+	}, registry.Handlers())
+
+	// the named handler still receives the events and resyncs
+	req.NoError(updateGroup(registry, "g1"))
+	registry.BeginMaintenance()
+	req.NoError(updateGroup(registry, "g2"))
+	req.NoError(registry.EndMaintenance())
+	req.Eventually(func() bool {
+		return len(resyncing.recorded()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	req.Equal([]string{"update g1", "resync"}, resyncing.recorded())
+}
+
+// orderHandler records its label into the shared log on each event
+type orderHandler struct {
+	label string
+	mu    *sync.Mutex
+	log   *[]string
+}
+
+func (h orderHandler) OnAddOrUpdate(_ Metadata) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.log = append(*h.log, h.label)
+}
+
+func (h orderHandler) OnDelete(metadata Metadata) {
+	h.OnAddOrUpdate(metadata)
+}
+
+func Test_Etcd_HandlerPriority(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	var mu sync.Mutex
+	var log []string
+	register := func(label string, priority int) {
+		registry.RegisterHandlerWithPriority(KindGroup, "", priority, NamedHandler(label, orderHandler{label: label, mu: &mu, log: &log}))
+	}
+	register("cache", -10)
+	registry.RegisterHandler(KindGroup, NamedHandler("default-1", orderHandler{label: "default-1", mu: &mu, log: &log}))
+	register("indexer", 10)
+	register("low", -10)
+	registry.RegisterHandlerForGroup(KindGroup, "g1", NamedHandler("default-2", orderHandler{label: "default-2", mu: &mu, log: &log}))
+	register("indexer-2", 10)
+
+	want := []string{"indexer", "indexer-2", "default-1", "default-2", "cache", "low"}
+	infos := registry.Handlers()
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	req.Equal(want, names)
+
+	req.NoError(updateGroup(registry, "g1"))
+	req.Equal(want, log)
+	log = nil
+	_, err = registry.DeleteGroup(context.TODO(), "g1")
+	req.NoError(err)
+	req.Equal(want, log)
+}
@@ -0,0 +1,144 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const defaultIdempotencyKeyTTL = time.Hour
+
+var ErrIdempotencyKeyReused = errors.New("the idempotency key is used by another entity")
+
+// leaseGranter grants the leases which the idempotency keys are attached to
+type leaseGranter interface {
+	Grant(ctx context.Context, ttl int64) (*clientv3.LeaseGrantResponse, error)
+	// opPutWithLease builds the put which attaches the key to the lease
+	opPutWithLease(key, val string, lease clientv3.LeaseID) clientv3.Op
+}
+
+var _ leaseGranter = (*etcdLease)(nil)
+
+// etcdLease attaches the keys to the leases of etcd by WithLease
+type etcdLease struct {
+	clientv3.Lease
+}
+
+func (l *etcdLease) opPutWithLease(key, val string, lease clientv3.LeaseID) clientv3.Op {
+	return clientv3.OpPut(key, val, clientv3.WithLease(lease))
+}
+
+// WriteOption tunes the Update methods of the registry
+type WriteOption func(*writeOptions)
+
+type writeOptions struct {
+	idempotencyKey string
+}
+
+// IdempotencyKey deduplicates the retries of a logical write.
+// The first write with the key is applied and recorded. A retry with the same key returns the outcome of the first one
+// without applying it again, even though the entity is changed by others in between.
+// The key is forgotten once its TTL expires, see IdempotencyKeyTTL.
+func IdempotencyKey(key string) WriteOption {
+	return func(opts *writeOptions) {
+		opts.idempotencyKey = key
+	}
+}
+
+// IdempotencyKeyTTL sets how long the idempotency keys are kept
+func IdempotencyKeyTTL(ttl time.Duration) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.idempotencyKeyTTL = ttl
+	}
+}
+
+func newWriteOptions(opts []WriteOption) writeOptions {
+	var wo writeOptions
+	for _, opt := range opts {
+		opt(&wo)
+	}
+	return wo
+}
+
+func (wo writeOptions) sidecarKey(layout KeyLayout) string {
+	if wo.idempotencyKey == "" {
+		return ""
+	}
+	return layout.IdempotencyKeyPrefix + wo.idempotencyKey
+}
+
+// applied tells whether the write with the idempotency key was applied to the entity before
+func (e *etcdSchemaRegistry) applied(ctx context.Context, wo writeOptions, entityKey string) (bool, error) {
+	sidecar := wo.sidecarKey(e.keyLayout)
+	if sidecar == "" {
+		return false, nil
+	}
+	resp, err := e.kv.Get(ctx, sidecar)
+	if err != nil {
+		return false, err
+	}
+	if resp.Count < 1 {
+		return false, nil
+	}
+	// the sidecar key holds the key of the entity which the write is applied to
+	if applied := string(resp.Kvs[0].Value); applied != entityKey {
+		return false, errors.Wrapf(ErrIdempotencyKeyReused, "key %s is applied to %s", wo.idempotencyKey, applied)
+	}
+	return true, nil
+}
+
+// recordOps returns the ops which record the idempotency key along with the write,
+// and the condition which keeps a concurrent write with the same key from being applied twice
+func (e *etcdSchemaRegistry) recordOps(ctx context.Context, wo writeOptions, entityKey string) ([]clientv3.Cmp, []clientv3.Op, error) {
+	sidecar := wo.sidecarKey(e.keyLayout)
+	if sidecar == "" {
+		return nil, nil, nil
+	}
+	grantCtx, cancel := e.withRequestTimeout(ctx)
+	defer cancel()
+	lease, err := e.lease.Grant(grantCtx, int64(e.idempotencyKeyTTL/time.Second))
+	if err != nil {
+		return nil, nil, err
+	}
+	return []clientv3.Cmp{clientv3.Compare(clientv3.CreateRevision(sidecar), "=", 0)},
+		[]clientv3.Op{e.lease.opPutWithLease(sidecar, entityKey, lease.ID)},
+		nil
+}
+
+// recordNoop records the idempotency key of a write which changes nothing,
+// so that its retry won't revert the changes made after it
+func (e *etcdSchemaRegistry) recordNoop(ctx context.Context, wo writeOptions, entityKey string) error {
+	cmps, ops, err := e.recordOps(ctx, wo, entityKey)
+	if err != nil || len(ops) < 1 {
+		return err
+	}
+	resp, err := e.kv.Txn(ctx).If(cmps...).Then(ops...).Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		// a concurrent write with the same key is recorded
+		_, err = e.applied(ctx, wo, entityKey)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func Test_Etcd_IdempotencyKey(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+
+	policy := func(name string, days uint32) *commonv1.RetentionPolicy {
+		return &commonv1.RetentionPolicy{
+			Metadata: &commonv1.Metadata{Name: name},
+			Ttl:      &commonv1.Duration{Val: days, Unit: commonv1.Duration_DURATION_UNIT_DAY},
+		}
+	}
+	ttl := func(name string) uint32 {
+		p, innerErr := registry.GetRetentionPolicy(context.TODO(), name)
+		req.NoError(innerErr)
+		return p.GetTtl().GetVal()
+	}
+
+	req.NoError(registry.UpdateRetentionPolicy(context.TODO(), policy("week", 7), IdempotencyKey("k1")))
+	req.NoError(registry.UpdateRetentionPolicy(context.TODO(), policy("week", 3)))
+	// the retry doesn't revert the change made in between
+	req.NoError(registry.UpdateRetentionPolicy(context.TODO(), policy("week", 7), IdempotencyKey("k1")))
+	req.Equal(uint32(3), ttl("week"))
+	req.ErrorIs(registry.UpdateRetentionPolicy(context.TODO(), policy("month", 30), IdempotencyKey("k1")), ErrIdempotencyKeyReused)
+
+	// the write which changes nothing is recorded as well
+	req.NoError(registry.UpdateRetentionPolicy(context.TODO(), policy("week", 3), IdempotencyKey("k2")))
+	req.NoError(registry.UpdateRetentionPolicy(context.TODO(), policy("week", 5)))
+	req.NoError(registry.UpdateRetentionPolicy(context.TODO(), policy("week", 3), IdempotencyKey("k2")))
+	req.Equal(uint32(5), ttl("week"))
+
+	// a new key is applied
+	req.NoError(registry.UpdateRetentionPolicy(context.TODO(), policy("week", 1), IdempotencyKey("k3")))
+	req.Equal(uint32(1), ttl("week"))
+}
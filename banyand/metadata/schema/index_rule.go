@@ -0,0 +1,128 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"math"
+
+	"github.com/pkg/errors"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+var ErrInvalidIndexRule = errors.New("the index rule is invalid")
+
+// checkIndexRule fails with ErrInvalidIndexRule if the sampling rate is NaN or out of [0, 1].
+// Zero leaves the rate unset, which indexes all the values as one does.
+// A tokenizing analyzer only applies to the inverted index of a single tag, see checkAnalyzedTags for the tag type.
+func checkIndexRule(rule *databasev1.IndexRule) error {
+	rate := rule.GetSamplingRate()
+	if math.IsNaN(rate) || rate < 0 || rate > 1 {
+		return errors.Wrapf(ErrInvalidIndexRule, "the sampling rate %v of %s is out of [0, 1]",
+			rate, rule.GetMetadata().GetName())
+	}
+	if tokenizes(rule.GetAnalyzer()) &&
+		(rule.GetType() != databasev1.IndexRule_TYPE_INVERTED || len(rule.GetTags()) != 1) {
+		return errors.Wrapf(ErrInvalidIndexRule, "the analyzer %s of %s only applies to the inverted index of a single string tag",
+			rule.GetAnalyzer(), rule.GetMetadata().GetName())
+	}
+	return nil
+}
+
+// tokenizes reports whether the analyzer splits a value into multiple terms
+func tokenizes(analyzer databasev1.IndexRule_Analyzer) bool {
+	return analyzer != databasev1.IndexRule_ANALYZER_UNSPECIFIED && analyzer != databasev1.IndexRule_ANALYZER_KEYWORD
+}
+
+// checkAnalyzedTag fails with ErrInvalidIndexRule if the rule tokenizes a tag which isn't a string
+func checkAnalyzedTag(rule *databasev1.IndexRule, tagTypes map[string]databasev1.TagType, subject *databasev1.Subject) error {
+	if !tokenizes(rule.GetAnalyzer()) {
+		return nil
+	}
+	for _, tag := range rule.GetTags() {
+		if tagType, ok := tagTypes[tag]; ok && tagType != databasev1.TagType_TAG_TYPE_STRING {
+			return errors.Wrapf(ErrInvalidIndexRule, "the analyzer %s of %s only applies to a string tag, but tag %s of %s %s is %s",
+				rule.GetAnalyzer(), rule.GetMetadata().GetName(), tag, subject.GetCatalog(), subject.GetName(), tagType)
+		}
+	}
+	return nil
+}
+
+// checkAnalyzedTags verifies the tags of the rule against the subjects of the bindings which refer to it.
+// The bindings created later are verified by checkSubjectKind.
+func (e *etcdSchemaRegistry) checkAnalyzedTags(ctx context.Context, rule *databasev1.IndexRule) error {
+	if !tokenizes(rule.GetAnalyzer()) {
+		return nil
+	}
+	group := rule.GetMetadata().GetGroup()
+	bindings, err := e.ListIndexRuleBinding(ctx, ListOpt{Group: group})
+	if err != nil {
+		return err
+	}
+	for _, binding := range bindings {
+		if !containsRule(binding, rule.GetMetadata().GetName()) {
+			continue
+		}
+		subject := binding.GetSubject()
+		md := &commonv1.Metadata{Name: subject.GetName(), Group: group}
+		var tagFamilies []*databasev1.TagFamilySpec
+		switch subject.GetCatalog() {
+		case commonv1.Catalog_CATALOG_STREAM:
+			var s databasev1.Stream
+			err = e.getInGroup(ctx, md, e.keyLayout.formatStreamKey, &s)
+			tagFamilies = s.GetTagFamilies()
+		case commonv1.Catalog_CATALOG_MEASURE:
+			var m databasev1.Measure
+			err = e.getInGroup(ctx, md, e.keyLayout.formatMeasureKey, &m)
+			tagFamilies = m.GetTagFamilies()
+		default:
+			continue
+		}
+		if errors.Is(err, ErrEntityNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err = checkAnalyzedTag(rule, tagTypesOf(tagFamilies), subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsRule(binding *databasev1.IndexRuleBinding, name string) bool {
+	for _, r := range binding.GetRules() {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+func tagTypesOf(tagFamilies []*databasev1.TagFamilySpec) map[string]databasev1.TagType {
+	tagTypes := make(map[string]databasev1.TagType)
+	for _, tf := range tagFamilies {
+		for _, tag := range tf.GetTags() {
+			tagTypes[tag.GetName()] = tag.GetType()
+		}
+	}
+	return tagTypes
+}
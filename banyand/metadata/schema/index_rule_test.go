@@ -0,0 +1,104 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+func Test_Etcd_IndexRuleSamplingRate(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	rule := func(rate float64) *databasev1.IndexRule {
+		return &databasev1.IndexRule{
+			Metadata:     &commonv1.Metadata{Group: "default", Name: "sampled"},
+			Tags:         []string{"trace_id"},
+			Type:         databasev1.IndexRule_TYPE_INVERTED,
+			Location:     databasev1.IndexRule_LOCATION_SERIES,
+			SamplingRate: rate,
+		}
+	}
+	for _, rate := range []float64{-0.1, 1.5, math.NaN(), math.Inf(1)} {
+		req.ErrorIs(registry.UpdateIndexRule(context.TODO(), rule(rate)), ErrInvalidIndexRule, "rate %v", rate)
+		invalid := Metadata{
+			TypeMeta: TypeMeta{Kind: KindIndexRule, Group: "default", Name: "sampled"},
+			Spec:     rule(rate),
+		}
+		req.ErrorIs(registry.ApplyBatch(context.TODO(), []Metadata{invalid}), ErrInvalidIndexRule, "rate %v", rate)
+		req.ErrorIs(registry.Transaction(context.TODO(), func(tx RegistryTxn) error {
+			return tx.Put(invalid)
+		}), ErrInvalidIndexRule, "rate %v", rate)
+	}
+	_, err = registry.GetIndexRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "sampled"})
+	req.ErrorIs(err, ErrEntityNotFound)
+
+	for _, rate := range []float64{0, 0.1, 1} {
+		req.NoError(registry.UpdateIndexRule(context.TODO(), rule(rate)), "rate %v", rate)
+	}
+}
+
+func Test_Etcd_IndexRuleAnalyzer(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	rule := func(name string, tags ...string) *databasev1.IndexRule {
+		return &databasev1.IndexRule{
+			Metadata: &commonv1.Metadata{Group: "default", Name: name},
+			Tags:     tags,
+			Type:     databasev1.IndexRule_TYPE_INVERTED,
+			Location: databasev1.IndexRule_LOCATION_SERIES,
+			Analyzer: databasev1.IndexRule_ANALYZER_STANDARD,
+		}
+	}
+	// the string tag of the bound stream
+	req.NoError(registry.UpdateIndexRule(context.TODO(), rule("trace_id", "trace_id")))
+	// the int tag of the bound stream
+	req.ErrorIs(registry.UpdateIndexRule(context.TODO(), rule("duration", "duration")), ErrInvalidIndexRule)
+	// a tokenizing analyzer doesn't apply to a tree or a multi-tag index
+	tree := rule("tree", "trace_id")
+	tree.Type = databasev1.IndexRule_TYPE_TREE
+	req.ErrorIs(registry.UpdateIndexRule(context.TODO(), tree), ErrInvalidIndexRule)
+	req.ErrorIs(registry.UpdateIndexRule(context.TODO(), rule("multi", "trace_id", "endpoint_id")), ErrInvalidIndexRule)
+	keyword := rule("duration", "duration")
+	keyword.Analyzer = databasev1.IndexRule_ANALYZER_KEYWORD
+	req.NoError(registry.UpdateIndexRule(context.TODO(), keyword))
+
+	// the rule is verified once it's bound
+	req.NoError(registry.UpdateIndexRule(context.TODO(), rule("state", "state")))
+	req.ErrorIs(registry.UpdateIndexRuleBinding(context.TODO(), &databasev1.IndexRuleBinding{
+		Metadata: &commonv1.Metadata{Group: "default", Name: "state-binding"},
+		Rules:    []string{"state"},
+		Subject:  &databasev1.Subject{Catalog: commonv1.Catalog_CATALOG_STREAM, Name: "sw"},
+	}), ErrInvalidIndexRule)
+}
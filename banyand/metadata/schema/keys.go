@@ -0,0 +1,239 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+var ErrInvalidKeyLayout = errors.New("the key layout is invalid")
+
+// The default layout of the keys. All the prefixes are declared here, see KeyLayout.Validate.
+const (
+	GroupsKeyPrefix           = "/groups/"
+	GroupMetadataKeyPrefix    = "/group-meta/"
+	LegacyGroupMetadataKey    = "/__meta_group__"
+	StreamKeyPrefix           = "/streams/"
+	IndexRuleBindingKeyPrefix = "/index-rule-bindings/"
+	IndexRuleKeyPrefix        = "/index-rules/"
+	MeasureKeyPrefix          = "/measures/"
+	RetentionPolicyKeyPrefix  = "/retention-policies/"
+	GroupAliasKeyPrefix       = "/group-aliases/"
+	DownsamplingRuleKeyPrefix = "/downsampling-rules/"
+	CounterKeyPrefix          = "/counters/"
+	IdempotencyKeyPrefix      = "/idempotency-keys/"
+)
+
+// KeyLayout decides where a registry stores the entities.
+// The entities of a group are stored under GroupsKeyPrefix + {group} + {entity prefix} + {name},
+// and the group itself is stored under GroupMetadataKeyPrefix + {group}.
+type KeyLayout struct {
+	GroupsKeyPrefix        string
+	GroupMetadataKeyPrefix string
+	// LegacyGroupMetadataKey is the suffix of the group keys stored under GroupsKeyPrefix by the former layout.
+	// Only the migration recognizes it, see migrateGroupMetadataKeys.
+	LegacyGroupMetadataKey    string
+	StreamKeyPrefix           string
+	IndexRuleBindingKeyPrefix string
+	IndexRuleKeyPrefix        string
+	MeasureKeyPrefix          string
+	RetentionPolicyKeyPrefix  string
+	GroupAliasKeyPrefix       string
+	DownsamplingRuleKeyPrefix string
+	// CounterKeyPrefix is where the counters are kept, apart from the entities, see Counter
+	CounterKeyPrefix string
+	// IdempotencyKeyPrefix is where the applied idempotency keys are kept until they expire, see IdempotencyKey
+	IdempotencyKeyPrefix string
+}
+
+func DefaultKeyLayout() KeyLayout {
+	return KeyLayout{
+		GroupsKeyPrefix:           GroupsKeyPrefix,
+		GroupMetadataKeyPrefix:    GroupMetadataKeyPrefix,
+		LegacyGroupMetadataKey:    LegacyGroupMetadataKey,
+		StreamKeyPrefix:           StreamKeyPrefix,
+		IndexRuleBindingKeyPrefix: IndexRuleBindingKeyPrefix,
+		IndexRuleKeyPrefix:        IndexRuleKeyPrefix,
+		MeasureKeyPrefix:          MeasureKeyPrefix,
+		RetentionPolicyKeyPrefix:  RetentionPolicyKeyPrefix,
+		GroupAliasKeyPrefix:       GroupAliasKeyPrefix,
+		DownsamplingRuleKeyPrefix: DownsamplingRuleKeyPrefix,
+		CounterKeyPrefix:          CounterKeyPrefix,
+		IdempotencyKeyPrefix:      IdempotencyKeyPrefix,
+	}
+}
+
+// Validate makes sure that the range scans of a kind never bleed into the keys of another kind.
+// The prefixes at the same level must be non-empty and none of them is a prefix of the others.
+func (l KeyLayout) Validate() error {
+	levels := [][]string{
+		{l.GroupsKeyPrefix, l.GroupMetadataKeyPrefix, l.RetentionPolicyKeyPrefix, l.GroupAliasKeyPrefix, l.CounterKeyPrefix,
+			l.IdempotencyKeyPrefix},
+		{l.LegacyGroupMetadataKey, l.StreamKeyPrefix, l.IndexRuleBindingKeyPrefix, l.IndexRuleKeyPrefix, l.MeasureKeyPrefix,
+			l.DownsamplingRuleKeyPrefix},
+	}
+	for _, prefixes := range levels {
+		for i, a := range prefixes {
+			if a == "" {
+				return errors.Wrap(ErrInvalidKeyLayout, "empty prefix")
+			}
+			for j, b := range prefixes {
+				if i != j && strings.HasPrefix(b, a) {
+					return errors.Wrapf(ErrInvalidKeyLayout, "%q is a prefix of %q", a, b)
+				}
+			}
+		}
+	}
+	// the group name is followed by the entity prefixes
+	for _, p := range levels[1] {
+		if !strings.HasPrefix(p, "/") {
+			return errors.Wrapf(ErrInvalidKeyLayout, "%q doesn't start with /", p)
+		}
+	}
+	return nil
+}
+
+// WithKeyLayout makes the registry store the entities in the layout instead of the default one
+func WithKeyLayout(layout KeyLayout) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.keyLayout = layout
+	}
+}
+
+func (l KeyLayout) Key(m Metadata) (string, error) {
+	switch m.Kind {
+	case KindGroup:
+		return l.formatGroupKey(m.Name), nil
+	case KindMeasure:
+		return l.formatMeasureKey(&commonv1.Metadata{
+			Group: m.Group,
+			Name:  m.Name,
+		}), nil
+	case KindStream:
+		return l.formatStreamKey(&commonv1.Metadata{
+			Group: m.Group,
+			Name:  m.Name,
+		}), nil
+	case KindIndexRule:
+		return l.formatIndexRuleKey(&commonv1.Metadata{
+			Group: m.Group,
+			Name:  m.Name,
+		}), nil
+	case KindIndexRuleBinding:
+		return l.formatIndexRuleBindingKey(&commonv1.Metadata{
+			Group: m.Group,
+			Name:  m.Name,
+		}), nil
+	case KindDownsamplingRule:
+		return l.formatDownsamplingRuleKey(&commonv1.Metadata{
+			Group: m.Group,
+			Name:  m.Name,
+		}), nil
+	case KindRetentionPolicy:
+		return l.formatRetentionPolicyKey(m.Name), nil
+	case KindGroupAlias:
+		return l.formatGroupAliasKey(m.Name), nil
+	default:
+		return "", ErrUnsupportedEntityType
+	}
+}
+
+// ParseKey is the inverse of Key. The Spec of the returned Metadata is absent.
+func (l KeyLayout) ParseKey(key string) (Metadata, error) {
+	if strings.HasPrefix(key, l.RetentionPolicyKeyPrefix) && len(key) > len(l.RetentionPolicyKeyPrefix) {
+		return Metadata{TypeMeta: TypeMeta{Kind: KindRetentionPolicy, Name: key[len(l.RetentionPolicyKeyPrefix):]}}, nil
+	}
+	if strings.HasPrefix(key, l.GroupAliasKeyPrefix) && len(key) > len(l.GroupAliasKeyPrefix) {
+		return Metadata{TypeMeta: TypeMeta{Kind: KindGroupAlias, Name: key[len(l.GroupAliasKeyPrefix):]}}, nil
+	}
+	if strings.HasPrefix(key, l.GroupMetadataKeyPrefix) && len(key) > len(l.GroupMetadataKeyPrefix) {
+		return Metadata{TypeMeta: TypeMeta{Kind: KindGroup, Name: key[len(l.GroupMetadataKeyPrefix):]}}, nil
+	}
+	if !strings.HasPrefix(key, l.GroupsKeyPrefix) {
+		return Metadata{}, errors.Wrapf(ErrUnrecognizedKey, "key %s", key)
+	}
+	rest := key[len(l.GroupsKeyPrefix):]
+	i := strings.Index(rest, "/")
+	if i < 1 {
+		return Metadata{}, errors.Wrapf(ErrUnrecognizedKey, "key %s", key)
+	}
+	group, entityKey := rest[:i], rest[i:]
+	for _, p := range []struct {
+		prefix string
+		kind   Kind
+	}{
+		{l.StreamKeyPrefix, KindStream},
+		{l.MeasureKeyPrefix, KindMeasure},
+		{l.IndexRuleBindingKeyPrefix, KindIndexRuleBinding},
+		{l.IndexRuleKeyPrefix, KindIndexRule},
+		{l.DownsamplingRuleKeyPrefix, KindDownsamplingRule},
+	} {
+		if strings.HasPrefix(entityKey, p.prefix) && len(entityKey) > len(p.prefix) {
+			return Metadata{TypeMeta: TypeMeta{
+				Kind:  p.kind,
+				Group: group,
+				Name:  entityKey[len(p.prefix):],
+			}}, nil
+		}
+	}
+	return Metadata{}, errors.Wrapf(ErrUnrecognizedKey, "key %s", key)
+}
+
+func (l KeyLayout) listPrefixesForEntity(group, entityPrefix string) string {
+	return l.GroupsKeyPrefix + group + entityPrefix
+}
+
+func (l KeyLayout) formatIndexRuleKey(metadata *commonv1.Metadata) string {
+	return l.formatKey(l.IndexRuleKeyPrefix, metadata)
+}
+
+func (l KeyLayout) formatIndexRuleBindingKey(metadata *commonv1.Metadata) string {
+	return l.formatKey(l.IndexRuleBindingKeyPrefix, metadata)
+}
+
+func (l KeyLayout) formatStreamKey(metadata *commonv1.Metadata) string {
+	return l.formatKey(l.StreamKeyPrefix, metadata)
+}
+
+func (l KeyLayout) formatMeasureKey(metadata *commonv1.Metadata) string {
+	return l.formatKey(l.MeasureKeyPrefix, metadata)
+}
+
+func (l KeyLayout) formatDownsamplingRuleKey(metadata *commonv1.Metadata) string {
+	return l.formatKey(l.DownsamplingRuleKeyPrefix, metadata)
+}
+
+func (l KeyLayout) formatKey(entityPrefix string, metadata *commonv1.Metadata) string {
+	return l.GroupsKeyPrefix + metadata.GetGroup() + entityPrefix + metadata.GetName()
+}
+
+func (l KeyLayout) formatRetentionPolicyKey(name string) string {
+	return l.RetentionPolicyKeyPrefix + name
+}
+
+func (l KeyLayout) formatGroupKey(group string) string {
+	return l.GroupMetadataKeyPrefix + group
+}
+
+func (l KeyLayout) formatGroupAliasKey(alias string) string {
+	return l.GroupAliasKeyPrefix + alias
+}
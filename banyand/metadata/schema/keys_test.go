@@ -0,0 +1,133 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func Test_Etcd_KeyLayout(t *testing.T) {
+	req := require.New(t)
+	layout := KeyLayout{
+		GroupsKeyPrefix:           "/tenant-a/groups/",
+		GroupMetadataKeyPrefix:    "/tenant-a/group-meta/",
+		LegacyGroupMetadataKey:    "/__meta__",
+		StreamKeyPrefix:           "/s/",
+		IndexRuleBindingKeyPrefix: "/irb/",
+		IndexRuleKeyPrefix:        "/ir/",
+		MeasureKeyPrefix:          "/m/",
+		RetentionPolicyKeyPrefix:  "/tenant-a/retention-policies/",
+		GroupAliasKeyPrefix:       "/tenant-a/group-aliases/",
+		DownsamplingRuleKeyPrefix: "/dr/",
+		CounterKeyPrefix:          "/tenant-a/counters/",
+		IdempotencyKeyPrefix:      "/tenant-a/idempotency-keys/",
+	}
+	custom, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir(), WithKeyLayout(layout))
+	req.NoError(err)
+	defer custom.Close()
+	req.NoError(preloadSchema(custom))
+	defaults, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer defaults.Close()
+	req.NoError(preloadSchema(defaults))
+
+	sw := &commonv1.Metadata{Name: "sw", Group: "default"}
+	for _, r := range []Registry{custom, defaults} {
+		_, err = r.GetStream(context.TODO(), sw)
+		req.NoError(err)
+		rules, listErr := r.ListIndexRule(context.TODO(), ListOpt{Group: "default"})
+		req.NoError(listErr)
+		req.Len(rules, 10)
+		groups, listErr := r.ListGroup(context.TODO())
+		req.NoError(listErr)
+		req.Len(groups, 1)
+	}
+
+	kv := custom.(*etcdSchemaRegistry).kv
+	resp, err := kv.Get(context.TODO(), "/tenant-a/groups/default/s/sw", clientv3.WithCountOnly())
+	req.NoError(err)
+	req.Equal(int64(1), resp.Count)
+	resp, err = kv.Get(context.TODO(), GroupsKeyPrefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	req.NoError(err)
+	req.Zero(resp.Count)
+	_, err = custom.IncrementCounter(context.TODO(), "ops", 1)
+	req.NoError(err)
+	resp, err = kv.Get(context.TODO(), "/tenant-a/counters/ops", clientv3.WithCountOnly())
+	req.NoError(err)
+	req.Equal(int64(1), resp.Count)
+	resp, err = kv.Get(context.TODO(), CounterKeyPrefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	req.NoError(err)
+	req.Zero(resp.Count)
+	req.NoError(custom.UpdateRetentionPolicy(context.TODO(), &commonv1.RetentionPolicy{
+		Metadata: &commonv1.Metadata{Name: "week"},
+		Ttl:      &commonv1.Duration{Val: 7, Unit: commonv1.Duration_DURATION_UNIT_DAY},
+	}, IdempotencyKey("k1")))
+	resp, err = kv.Get(context.TODO(), "/tenant-a/idempotency-keys/k1", clientv3.WithCountOnly())
+	req.NoError(err)
+	req.Equal(int64(1), resp.Count)
+	resp, err = kv.Get(context.TODO(), IdempotencyKeyPrefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	req.NoError(err)
+	req.Zero(resp.Count)
+
+	for _, tm := range []TypeMeta{
+		{Kind: KindGroup, Name: "default"},
+		{Kind: KindStream, Group: "default", Name: "sw"},
+		{Kind: KindIndexRule, Group: "default", Name: "trace_id"},
+		{Kind: KindRetentionPolicy, Name: "week"},
+		{Kind: KindGroupAlias, Name: "legacy"},
+		{Kind: KindDownsamplingRule, Group: "default", Name: "minute_to_hour"},
+	} {
+		key, keyErr := layout.Key(Metadata{TypeMeta: tm})
+		req.NoError(keyErr)
+		md, keyErr := layout.ParseKey(key)
+		req.NoError(keyErr)
+		req.Equal(tm, md.TypeMeta)
+		_, keyErr = ParseKey(key)
+		req.ErrorIs(keyErr, ErrUnrecognizedKey)
+	}
+}
+
+func Test_KeyLayout_Validate(t *testing.T) {
+	req := require.New(t)
+	req.NoError(DefaultKeyLayout().Validate())
+
+	overlapped := DefaultKeyLayout()
+	overlapped.IndexRuleKeyPrefix = "/index-rule"
+	req.ErrorIs(overlapped.Validate(), ErrInvalidKeyLayout)
+	overlapped = DefaultKeyLayout()
+	overlapped.RetentionPolicyKeyPrefix = "/groups/policies/"
+	req.ErrorIs(overlapped.Validate(), ErrInvalidKeyLayout)
+	overlapped = DefaultKeyLayout()
+	overlapped.CounterKeyPrefix = "/group-meta/counters/"
+	req.ErrorIs(overlapped.Validate(), ErrInvalidKeyLayout)
+	overlapped = DefaultKeyLayout()
+	overlapped.IdempotencyKeyPrefix = "/counters/idempotency-keys/"
+	req.ErrorIs(overlapped.Validate(), ErrInvalidKeyLayout)
+	empty := DefaultKeyLayout()
+	empty.MeasureKeyPrefix = ""
+	req.ErrorIs(empty.Validate(), ErrInvalidKeyLayout)
+
+	_, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir(), WithKeyLayout(overlapped))
+	req.ErrorIs(err, ErrInvalidKeyLayout)
+}
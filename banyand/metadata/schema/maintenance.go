@@ -0,0 +1,68 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"github.com/pkg/errors"
+)
+
+var ErrNotInMaintenance = errors.New("the registry is not in maintenance")
+
+// Resyncer is an EventHandler rebuilding its state from the registry at once.
+// It receives a single OnResync instead of the events suppressed during the maintenance.
+type Resyncer interface {
+	OnResync()
+}
+
+func (e *etcdSchemaRegistry) BeginMaintenance() {
+	e.maintenanceMu.Lock()
+	defer e.maintenanceMu.Unlock()
+	e.maintenance++
+}
+
+func (e *etcdSchemaRegistry) EndMaintenance() error {
+	e.maintenanceMu.Lock()
+	if e.maintenance < 1 {
+		e.maintenanceMu.Unlock()
+		return ErrNotInMaintenance
+	}
+	e.maintenance--
+	if e.maintenance > 0 {
+		e.maintenanceMu.Unlock()
+		return nil
+	}
+	kinds := e.suppressedKinds
+	e.suppressedKinds = 0
+	e.maintenanceMu.Unlock()
+	if kinds == 0 {
+		return nil
+	}
+	// the resync follows the events queued before the maintenance
+	return e.notify(event{metadata: Metadata{TypeMeta: TypeMeta{Kind: kinds}}, resync: true})
+}
+
+// suppress records the kind of the event if the registry is in maintenance
+func (e *etcdSchemaRegistry) suppress(kind Kind) bool {
+	e.maintenanceMu.Lock()
+	defer e.maintenanceMu.Unlock()
+	if e.maintenance < 1 {
+		return false
+	}
+	e.suppressedKinds |= kind
+	return true
+}
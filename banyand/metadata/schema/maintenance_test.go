@@ -0,0 +1,81 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _ Resyncer = (*resyncingHandler)(nil)
+
+type resyncingHandler struct {
+	*recordingHandler
+}
+
+func (h resyncingHandler) OnResync() {
+	<-h.gate
+	h.record("resync")
+}
+
+func Test_Etcd_Maintenance(t *testing.T) {
+	for name, opts := range map[string][]RegistryOption{
+		"sync":  nil,
+		"async": {AsyncDelivery(8, OverflowBlock)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := require.New(t)
+			registry, err := NewEtcdSchemaRegistry(append([]RegistryOption{useUnixDomain(), useRandomTempDir()}, opts...)...)
+			req.NoError(err)
+			defer registry.Close()
+			resyncing := resyncingHandler{newRecordingHandler()}
+			close(resyncing.gate)
+			registry.RegisterHandler(KindGroup, resyncing)
+			plain := newRecordingHandler()
+			close(plain.gate)
+			registry.RegisterHandler(KindGroup, plain)
+			untouched := resyncingHandler{newRecordingHandler()}
+			close(untouched.gate)
+			registry.RegisterHandler(KindStream, untouched)
+
+			req.ErrorIs(registry.EndMaintenance(), ErrNotInMaintenance)
+			req.NoError(updateGroup(registry, "g1"))
+			registry.BeginMaintenance()
+			registry.BeginMaintenance()
+			req.NoError(updateGroup(registry, "g2"))
+			_, err = registry.DeleteGroup(context.TODO(), "g1")
+			req.NoError(err)
+			// the inner maintenance doesn't resume the events
+			req.NoError(registry.EndMaintenance())
+			req.NoError(updateGroup(registry, "g3"))
+			req.NoError(registry.EndMaintenance())
+			req.NoError(updateGroup(registry, "g4"))
+
+			req.Eventually(func() bool {
+				return len(resyncing.recorded()) == 3 && len(plain.recorded()) == 2
+			}, 5*time.Second, 10*time.Millisecond)
+			req.Equal([]string{"update g1", "resync", "update g4"}, resyncing.recorded())
+			req.Equal([]string{"update g1", "update g4"}, plain.recorded())
+			// nothing of interest is suppressed
+			req.Empty(untouched.recorded())
+		})
+	}
+}
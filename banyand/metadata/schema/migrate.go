@@ -0,0 +1,62 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// migrateGroupMetadataKeys moves the groups stored by the former layout,
+// GroupsKeyPrefix + {group} + LegacyGroupMetadataKey, to GroupMetadataKeyPrefix + {group}.
+// A key is recognized as a legacy group only if the group name has no "/", so an entity named
+// with the legacy suffix is left untouched.
+//
+// A read-only registry can't migrate, so it fails with ErrReadOnly if there is any group to migrate.
+func migrateGroupMetadataKeys(ctx context.Context, kv clientv3.KV, l KeyLayout, readOnly bool) error {
+	resp, err := kv.Get(ctx, l.GroupsKeyPrefix, clientv3.WithRange(incrementLastByte(l.GroupsKeyPrefix)))
+	if err != nil {
+		return err
+	}
+	for _, item := range resp.Kvs {
+		legacyKey := string(item.Key)
+		rest := legacyKey[len(l.GroupsKeyPrefix):]
+		i := strings.Index(rest, "/")
+		if i < 1 || rest[i:] != l.LegacyGroupMetadataKey {
+			continue
+		}
+		if readOnly {
+			return errors.Wrapf(ErrReadOnly, "the group %s is stored by the former layout", rest[:i])
+		}
+		// the value is moved as it is, since the checksum doesn't cover the key
+		txnResp, innerErr := kv.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(legacyKey), "=", item.ModRevision)).
+			Then(clientv3.OpPut(l.formatGroupKey(rest[:i]), string(item.Value)), clientv3.OpDelete(legacyKey)).
+			Commit()
+		if innerErr != nil {
+			return innerErr
+		}
+		if !txnResp.Succeeded {
+			return errors.Wrapf(ErrConcurrentModification, "migrating key %s", legacyKey)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+func Test_Etcd_MigrateGroupMetadataKeys(t *testing.T) {
+	req := require.New(t)
+	dir := randomTempDir()
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), RootDir(dir))
+	req.NoError(err)
+	req.NoError(preloadSchema(registry))
+	// a stream named with the legacy suffix is never taken as a group
+	sw, err := registry.GetStream(context.TODO(), &commonv1.Metadata{Name: "sw", Group: "default"})
+	req.NoError(err)
+	s := proto.Clone(sw).(*databasev1.Stream)
+	s.Metadata = &commonv1.Metadata{Name: LegacyGroupMetadataKey[1:], Group: "default"}
+	req.NoError(registry.UpdateStream(context.TODO(), s))
+	groups, err := registry.ListGroup(context.TODO())
+	req.NoError(err)
+	req.Len(groups, 1)
+
+	// store the group as the former layout did
+	kv := registry.(*etcdSchemaRegistry).kv
+	resp, err := kv.Get(context.TODO(), GroupMetadataKeyPrefix+"default")
+	req.NoError(err)
+	req.Len(resp.Kvs, 1)
+	legacyKey := GroupsKeyPrefix + "default" + LegacyGroupMetadataKey
+	_, err = kv.Txn(context.TODO()).Then(
+		clientv3.OpPut(legacyKey, string(resp.Kvs[0].Value)),
+		clientv3.OpDelete(GroupMetadataKeyPrefix+"default"),
+	).Commit()
+	req.NoError(err)
+	req.NoError(registry.Close())
+	<-registry.StopNotify()
+
+	// a read-only registry can't migrate the keys
+	_, err = NewEtcdSchemaRegistry(useUnixDomain(), RootDir(dir), ReadOnly())
+	req.ErrorIs(err, ErrReadOnly)
+
+	registry, err = NewEtcdSchemaRegistry(useUnixDomain(), RootDir(dir))
+	req.NoError(err)
+	defer registry.Close()
+	g, err := registry.GetGroup(context.TODO(), "default")
+	req.NoError(err)
+	req.Equal("default", g.GetMetadata().GetName())
+	groups, err = registry.ListGroup(context.TODO())
+	req.NoError(err)
+	req.Len(groups, 1)
+	kv = registry.(*etcdSchemaRegistry).kv
+	resp, err = kv.Get(context.TODO(), legacyKey, clientv3.WithCountOnly())
+	req.NoError(err)
+	req.Zero(resp.Count)
+	_, err = registry.GetStream(context.TODO(), s.GetMetadata())
+	req.NoError(err)
+}
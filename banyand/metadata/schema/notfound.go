@@ -0,0 +1,81 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ error = (*NotFoundError)(nil)
+
+// NotFoundError tells which entity is missing. It satisfies errors.Is(err, ErrEntityNotFound),
+// so the callers checking the sentinel keep working.
+// The Kind is zero and the Name is the raw key if the key isn't recognized.
+type NotFoundError struct {
+	Kind  Kind
+	Group string
+	Name  string
+}
+
+func newNotFoundError(layout KeyLayout, key string) *NotFoundError {
+	tm := layout.typeMetaOf(key)
+	return &NotFoundError{Kind: tm.Kind, Group: tm.Group, Name: tm.Name}
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Kind == 0 {
+		return errors.Wrapf(ErrEntityNotFound, "key %s", e.Name).Error()
+	}
+	if e.Group == "" {
+		return errors.Wrapf(ErrEntityNotFound, "%s %s", kindName(e.Kind), e.Name).Error()
+	}
+	return errors.Wrapf(ErrEntityNotFound, "%s %s/%s", kindName(e.Kind), e.Group, e.Name).Error()
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrEntityNotFound
+}
+
+// resourceName is the group qualified name, which is the key of the entity in the registry services
+func (e *NotFoundError) resourceName() string {
+	if e.Group == "" {
+		return e.Name
+	}
+	return e.Group + "/" + e.Name
+}
+
+// GRPCStatus is a NotFound status carrying a ResourceInfo detail of the missing entity
+func (e *NotFoundError) GRPCStatus() *status.Status {
+	st := status.New(codes.NotFound, e.Error())
+	resourceType := "unknown"
+	if e.Kind != 0 {
+		resourceType = kindName(e.Kind)
+	}
+	withDetails, err := st.WithDetails(&errdetails.ResourceInfo{
+		ResourceType: resourceType,
+		ResourceName: e.resourceName(),
+		Description:  ErrEntityNotFound.Error(),
+	})
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
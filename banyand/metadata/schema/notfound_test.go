@@ -0,0 +1,54 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func Test_Etcd_NotFoundError(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	_, err = registry.GetStream(context.TODO(), &commonv1.Metadata{Group: "default", Name: "unknown"})
+	req.ErrorIs(err, ErrEntityNotFound)
+	var notFound *NotFoundError
+	req.True(errors.As(err, &notFound))
+	req.Equal(NotFoundError{Kind: KindStream, Group: "default", Name: "unknown"}, *notFound)
+	req.Contains(err.Error(), "stream default/unknown")
+
+	_, err = registry.GetGroup(context.TODO(), "unknown")
+	req.True(errors.As(err, &notFound))
+	req.Equal(NotFoundError{Kind: KindGroup, Name: "unknown"}, *notFound)
+	req.Contains(err.Error(), "group unknown")
+}
+
+func Test_NotFoundError_UnrecognizedKey(t *testing.T) {
+	err := error(newNotFoundError(DefaultKeyLayout(), "/unknown"))
+	require.ErrorIs(t, err, ErrEntityNotFound)
+	require.Equal(t, "key /unknown: entity is not found", err.Error())
+}
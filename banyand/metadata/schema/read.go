@@ -0,0 +1,86 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// ReadOption tunes the Get methods of the registry
+type ReadOption func(*readOptions)
+
+type readOptions struct {
+	serializable bool
+	revision     *int64
+	revisionInfo *RevisionInfo
+}
+
+// RevisionInfo is the revisions of an entity in the store
+type RevisionInfo struct {
+	// CreateRevision is the revision when the entity was created
+	CreateRevision int64
+	// ModRevision is the revision when the entity was modified last time
+	ModRevision int64
+}
+
+// Serializable serves the read from the local member without a round of consensus.
+// It's cheaper, but the result might be stale. ObservedRevision tells how stale it is.
+func Serializable() ReadOption {
+	return func(opts *readOptions) {
+		opts.serializable = true
+	}
+}
+
+// ObservedRevision stores the revision of the store which the read observed into revision.
+// It's set even if the entity is not found.
+// A caller which already knows a newer revision, for example, the ModRevision of an entity it wrote,
+// could tell the result is stale and retry without Serializable.
+func ObservedRevision(revision *int64) ReadOption {
+	return func(opts *readOptions) {
+		opts.revision = revision
+	}
+}
+
+// withRevisionInfo stores the revisions of the entity into info regardless of the type of the entity
+func withRevisionInfo(info *RevisionInfo) ReadOption {
+	return func(opts *readOptions) {
+		opts.revisionInfo = info
+	}
+}
+
+func newReadOptions(opts []ReadOption) readOptions {
+	var ro readOptions
+	for _, opt := range opts {
+		opt(&ro)
+	}
+	return ro
+}
+
+func (ro readOptions) opOptions() []clientv3.OpOption {
+	if ro.serializable {
+		return []clientv3.OpOption{clientv3.WithSerializable()}
+	}
+	return nil
+}
+
+func (ro readOptions) observe(header *etcdserverpb.ResponseHeader) {
+	if ro.revision != nil {
+		*ro.revision = header.GetRevision()
+	}
+}
@@ -0,0 +1,92 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+func Test_Etcd_ObservedRevision(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	md := &commonv1.Metadata{Name: "sw", Group: "default"}
+	var rev int64
+	s, err := registry.GetStream(context.TODO(), md, Serializable(), ObservedRevision(&rev))
+	req.NoError(err)
+	req.GreaterOrEqual(rev, s.GetMetadata().GetModRevision())
+
+	req.NoError(registry.UpdateGroup(context.TODO(), &commonv1.Group{
+		Metadata:     &commonv1.Metadata{Name: "observed"},
+		Catalog:      commonv1.Catalog_CATALOG_STREAM,
+		ResourceOpts: &commonv1.ResourceOpts{ShardNum: 1},
+	}))
+	var groupRev int64
+	g, err := registry.GetGroup(context.TODO(), "observed", Serializable(), ObservedRevision(&groupRev))
+	req.NoError(err)
+	req.Greater(groupRev, rev)
+	req.GreaterOrEqual(groupRev, g.GetMetadata().GetModRevision())
+
+	rev = 0
+	_, err = registry.GetStream(context.TODO(), &commonv1.Metadata{Name: "absent", Group: "default"}, ObservedRevision(&rev))
+	req.ErrorIs(err, ErrEntityNotFound)
+	req.Positive(rev)
+}
+
+func Test_Etcd_GetMeasureWithMeta(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(registry.UpdateGroup(context.TODO(), &commonv1.Group{
+		Metadata:     &commonv1.Metadata{Name: "sw_metric"},
+		Catalog:      commonv1.Catalog_CATALOG_MEASURE,
+		ResourceOpts: &commonv1.ResourceOpts{ShardNum: 1},
+	}))
+	md := &commonv1.Metadata{Group: "sw_metric", Name: "service_cpm"}
+	req.NoError(registry.UpdateMeasure(context.TODO(), &databasev1.Measure{
+		Metadata: md,
+		Fields:   []*databasev1.FieldSpec{{Name: "total", FieldType: databasev1.FieldType_FIELD_TYPE_INT}},
+	}))
+
+	m, created, err := registry.GetMeasureWithMeta(context.TODO(), md)
+	req.NoError(err)
+	req.Positive(created.CreateRevision)
+	req.Equal(created.CreateRevision, created.ModRevision)
+	req.Equal(m.GetMetadata().GetModRevision(), created.ModRevision)
+
+	m.Fields = append(m.Fields, &databasev1.FieldSpec{Name: "value", FieldType: databasev1.FieldType_FIELD_TYPE_INT})
+	req.NoError(registry.UpdateMeasure(context.TODO(), m))
+	_, modified, err := registry.GetMeasureWithMeta(context.TODO(), md)
+	req.NoError(err)
+	req.Equal(created.CreateRevision, modified.CreateRevision)
+	req.Greater(modified.ModRevision, created.ModRevision)
+
+	_, info, err := registry.GetMeasureWithMeta(context.TODO(), &commonv1.Metadata{Group: "sw_metric", Name: "absent"})
+	req.ErrorIs(err, ErrEntityNotFound)
+	req.Zero(info)
+}
@@ -0,0 +1,93 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+)
+
+const learnerJoinTimeout = 10 * time.Second
+
+var ErrReadOnly = errors.New("the registry is read-only")
+
+// ReadOnly makes the registry a replica serving reads, for example, on a query node.
+// All the methods writing entities fail with ErrReadOnly before touching the store.
+//
+// The embedded etcd stays a standalone member serving the data in the root directory. Learner joins it
+// to the cluster of the writable registry instead.
+func ReadOnly() RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.readOnly = true
+	}
+}
+
+// Learner makes the registry a read-only replica of the etcd cluster serving the endpoints.
+// The embedded etcd joins the cluster as a learner, which replicates the schema without voting.
+//
+// A learner serves neither the linearizable reads nor the watches, so the registry reads from the endpoints.
+func Learner(endpoints ...string) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.readOnly = true
+		config.learnerOf = endpoints
+	}
+}
+
+// joinAsLearner adds the embedded etcd to the cluster as a learner, then returns the client of the cluster.
+// A restarted learner has been a member already, so it rejoins with its data.
+func joinAsLearner(cfg *embed.Config, endpoints []string) (*clientv3.Client, error) {
+	client, err := clientv3.New(clientv3.Config{Endpoints: endpoints, DialTimeout: learnerJoinTimeout})
+	if err != nil {
+		return nil, err
+	}
+	peerURL := cfg.APUrls[0].String()
+	// the name is derived from the peer url, which keeps it across restarts
+	cfg.Name = fmt.Sprintf("learner-%x", convert.HashStr(peerURL))
+	cfg.ClusterState = embed.ClusterStateFlagExisting
+	if _, err = os.Stat(filepath.Join(cfg.Dir, "member")); err == nil {
+		return client, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), learnerJoinTimeout)
+	defer cancel()
+	resp, err := client.MemberAddAsLearner(ctx, []string{peerURL})
+	if err != nil {
+		_ = client.Close()
+		return nil, errors.WithMessage(err, "failed to join the cluster as a learner")
+	}
+	cfg.InitialCluster = ""
+	for _, m := range resp.Members {
+		name := m.Name
+		if m.ID == resp.Member.ID {
+			name = cfg.Name
+		}
+		for _, u := range m.PeerURLs {
+			cfg.InitialCluster += fmt.Sprintf(",%s=%s", name, u)
+		}
+	}
+	cfg.InitialCluster = cfg.InitialCluster[1:]
+	return client, nil
+}
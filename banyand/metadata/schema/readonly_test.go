@@ -0,0 +1,93 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func Test_Etcd_ReadOnly(t *testing.T) {
+	req := require.New(t)
+	dir := randomTempDir()
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), RootDir(dir))
+	req.NoError(err)
+	req.NoError(preloadSchema(registry))
+	req.NoError(registry.Close())
+	<-registry.StopNotify()
+
+	registry, err = NewEtcdSchemaRegistry(useUnixDomain(), RootDir(dir), ReadOnly())
+	req.NoError(err)
+	defer registry.Close()
+	md := &commonv1.Metadata{Name: "sw", Group: "default"}
+	s, err := registry.GetStream(context.TODO(), md)
+	req.NoError(err)
+	streams, err := registry.ListStream(context.TODO(), ListOpt{Group: "default"})
+	req.NoError(err)
+	req.Len(streams, 1)
+
+	s.GetMetadata().ModRevision = 0
+	s.Entity.TagNames = append(s.Entity.TagNames, "trace_id")
+	req.ErrorIs(registry.UpdateStream(context.TODO(), s), ErrReadOnly)
+	_, err = registry.DeleteStream(context.TODO(), md)
+	req.ErrorIs(err, ErrReadOnly)
+	_, err = registry.DeleteGroup(context.TODO(), "default")
+	req.ErrorIs(err, ErrReadOnly)
+	req.ErrorIs(registry.ApplyBatch(context.TODO(), []Metadata{{
+		TypeMeta: TypeMeta{Kind: KindGroup, Name: "g"},
+		Spec:     &commonv1.Group{Metadata: &commonv1.Metadata{Name: "g"}},
+	}}), ErrReadOnly)
+	_, err = registry.Repair(context.TODO(), "default")
+	req.ErrorIs(err, ErrReadOnly)
+
+	_, err = registry.GetStream(context.TODO(), md)
+	req.NoError(err)
+}
+
+func Test_Etcd_Learner(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+	endpoint := registry.(*etcdSchemaRegistry).backend.(*etcdBackend).server.Config().ACUrls[0].String()
+
+	learner, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir(), Learner(endpoint))
+	req.NoError(err)
+	defer learner.Close()
+	req.True(learner.(*etcdSchemaRegistry).backend.(*etcdBackend).server.Server.IsLearner())
+	md := &commonv1.Metadata{Name: "sw", Group: "default"}
+	s, err := learner.GetStream(context.TODO(), md)
+	req.NoError(err)
+	s.GetMetadata().ModRevision = 0
+	req.ErrorIs(learner.UpdateStream(context.TODO(), s), ErrReadOnly)
+
+	// the writes of the writable registry are visible through the learner
+	_, err = registry.DeleteStream(context.TODO(), md)
+	req.NoError(err)
+	_, err = learner.GetStream(context.TODO(), md)
+	req.ErrorIs(err, ErrEntityNotFound)
+}
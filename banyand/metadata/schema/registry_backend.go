@@ -0,0 +1,94 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+// registryBackend is the storage serving the kv of the registry
+type registryBackend interface {
+	readyNotify() <-chan struct{}
+	stopNotify() <-chan struct{}
+	stoppingNotify() <-chan struct{}
+	close()
+	// version is the version of the storage. It's empty if the storage isn't etcd.
+	version(ctx context.Context) (string, error)
+	// transact commits the writes of apply at once if none of the keys read by apply are modified in the meantime,
+	// otherwise apply is retried. ctx aborts the retries.
+	transact(ctx context.Context, apply func(stm txnStore) error) (*clientv3.TxnResponse, error)
+	// watch streams the changes of the key. The channel is closed once ctx is done.
+	watch(ctx context.Context, key string, opts ...clientv3.OpOption) (clientv3.WatchChan, error)
+}
+
+// txnStore is the part of concurrency.STM the RegistryTxn relies on
+type txnStore interface {
+	Get(key ...string) string
+	Put(key, val string, opts ...clientv3.OpOption)
+	Rev(key string) int64
+	Del(key string)
+}
+
+var _ registryBackend = (*etcdBackend)(nil)
+
+type etcdBackend struct {
+	server *embed.Etcd
+	client *clientv3.Client
+}
+
+func (b *etcdBackend) readyNotify() <-chan struct{} {
+	return b.server.Server.ReadyNotify()
+}
+
+func (b *etcdBackend) stopNotify() <-chan struct{} {
+	return b.server.Server.StopNotify()
+}
+
+func (b *etcdBackend) stoppingNotify() <-chan struct{} {
+	return b.server.Server.StoppingNotify()
+}
+
+func (b *etcdBackend) close() {
+	b.server.Close()
+}
+
+func (b *etcdBackend) version(ctx context.Context) (string, error) {
+	endpoints := b.client.Endpoints()
+	if len(endpoints) < 1 {
+		return "", ErrNoEndpoint
+	}
+	resp, err := b.client.Status(ctx, endpoints[0])
+	if err != nil {
+		return "", err
+	}
+	return resp.Version, nil
+}
+
+func (b *etcdBackend) transact(ctx context.Context, apply func(stm txnStore) error) (*clientv3.TxnResponse, error) {
+	return concurrency.NewSTM(b.client, func(stm concurrency.STM) error {
+		return apply(stm)
+	}, concurrency.WithAbortContext(ctx))
+}
+
+func (b *etcdBackend) watch(ctx context.Context, key string, opts ...clientv3.OpOption) (clientv3.WatchChan, error) {
+	return b.client.Watch(ctx, key, opts...), nil
+}
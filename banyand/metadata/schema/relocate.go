@@ -0,0 +1,201 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/multierr"
+)
+
+var (
+	ErrDataDirInUse     = errors.New("data directory is in use")
+	ErrDataDirNotEmpty  = errors.New("data directory is not empty")
+	ErrRestoreCorrupted = errors.New("restored data is inconsistent with the snapshot")
+)
+
+const lockTimeout = 100 * time.Millisecond
+
+// RelocateDataDir moves the data of the registry from oldDir to newDir.
+// Both are the root directories passed to RootDir. The data in oldDir is kept as a backup.
+//
+// The procedure is:
+//  1. Close the registry. RelocateDataDir returns ErrDataDirInUse if it's still running.
+//  2. Call RelocateDataDir to snapshot the backend of oldDir and restore it into newDir along with the WAL.
+//     The restored backend is validated against the snapshot.
+//  3. Restart the registry with RootDir(newDir).
+//
+// If it fails after the copy starts, the data in newDir is removed, so that it could be retried.
+func RelocateDataDir(ctx context.Context, oldDir, newDir string) (err error) {
+	src, dst := filepath.Join(oldDir, "metadata"), filepath.Join(newDir, "metadata")
+	srcDB, dstDB := dbPath(src), dbPath(dst)
+	// the backend is only read, so that the backup stays untouched
+	srcBackend, err := openReadOnly(srcDB)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Append(err, srcBackend.Close())
+	}()
+	entries, err := os.ReadDir(dst)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(entries) > 0 {
+		return errors.Wrapf(ErrDataDirNotEmpty, "dir %s", dst)
+	}
+	defer func() {
+		if err != nil {
+			err = multierr.Append(err, os.RemoveAll(dst))
+		}
+	}()
+	if err = copyDir(ctx, filepath.Join(src, "member", "wal"), filepath.Join(dst, "member", "wal"), nil); err != nil {
+		return errors.WithMessage(err, "copy the wal")
+	}
+	if err = copyDir(ctx, filepath.Dir(srcDB), filepath.Dir(dstDB), func(name string) bool {
+		return name != filepath.Base(srcDB)
+	}); err != nil {
+		return errors.WithMessage(err, "copy the raft snapshots")
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	if err = snapshot(srcBackend, dstDB); err != nil {
+		return errors.WithMessage(err, "snapshot the backend")
+	}
+	return validateRestore(srcBackend, dstDB)
+}
+
+func dbPath(dataDir string) string {
+	return filepath.Join(dataDir, "member", "snap", "db")
+}
+
+// openReadOnly relies on the exclusive file lock which the running etcd holds on the backend
+func openReadOnly(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: lockTimeout, ReadOnly: true})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, errors.Wrapf(ErrDataDirInUse, "backend %s", path)
+	}
+	return db, err
+}
+
+func snapshot(src *bolt.DB, path string) (err error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Append(err, f.Close())
+	}()
+	if err = src.View(func(tx *bolt.Tx) error {
+		_, errWrite := tx.WriteTo(f)
+		return errWrite
+	}); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// validateRestore compares the hash of the restored backend in path with the one of src
+func validateRestore(src *bolt.DB, path string) (err error) {
+	dst, err := openReadOnly(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Append(err, dst.Close())
+	}()
+	want, err := hashBackend(src)
+	if err != nil {
+		return err
+	}
+	got, err := hashBackend(dst)
+	if err != nil {
+		return err
+	}
+	if want != got {
+		return errors.Wrapf(ErrRestoreCorrupted, "hash %d, want %d", got, want)
+	}
+	return nil
+}
+
+// hashBackend sums up the names of the buckets and the keys and values in them
+func hashBackend(db *bolt.DB) (uint32, error) {
+	h := crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	err := db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			_, _ = h.Write(name)
+			return b.ForEach(func(k, v []byte) error {
+				_, _ = h.Write(k)
+				_, _ = h.Write(v)
+				return nil
+			})
+		})
+	})
+	return h.Sum32(), err
+}
+
+func copyDir(ctx context.Context, src, dst string, filter func(name string) bool) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dst, 0o700); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() || (filter != nil && !filter(entry.Name())) {
+			continue
+		}
+		if err = copyFile(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Append(err, in.Close())
+	}()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Append(err, out.Close())
+	}()
+	if _, err = io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Sync()
+}
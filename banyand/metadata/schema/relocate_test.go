@@ -0,0 +1,70 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func Test_RelocateDataDir(t *testing.T) {
+	req := require.New(t)
+	oldDir, newDir := randomTempDir(), randomTempDir()
+	defer func() {
+		_ = os.RemoveAll(oldDir)
+		_ = os.RemoveAll(newDir)
+	}()
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), RootDir(oldDir))
+	req.NoError(err)
+	req.NoError(preloadSchema(registry))
+
+	req.ErrorIs(RelocateDataDir(context.TODO(), oldDir, newDir), ErrDataDirInUse)
+	req.NoError(registry.Close())
+	backup, err := os.ReadFile(dbPath(filepath.Join(oldDir, "metadata")))
+	req.NoError(err)
+
+	// a failed relocation leaves nothing behind, so it could be retried
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req.ErrorIs(RelocateDataDir(ctx, oldDir, newDir), context.Canceled)
+	_, err = os.Stat(filepath.Join(newDir, "metadata"))
+	req.True(os.IsNotExist(err))
+
+	req.NoError(RelocateDataDir(context.TODO(), oldDir, newDir))
+	req.ErrorIs(RelocateDataDir(context.TODO(), oldDir, newDir), ErrDataDirNotEmpty)
+	// the backup isn't modified by the relocation
+	db, err := os.ReadFile(dbPath(filepath.Join(oldDir, "metadata")))
+	req.NoError(err)
+	req.Equal(backup, db)
+
+	registry, err = NewEtcdSchemaRegistry(useUnixDomain(), RootDir(newDir))
+	req.NoError(err)
+	defer registry.Close()
+	s, err := registry.GetStream(context.TODO(), &commonv1.Metadata{Name: "sw", Group: "default"})
+	req.NoError(err)
+	req.Equal("sw", s.GetMetadata().GetName())
+	rules, err := registry.ListIndexRule(context.TODO(), ListOpt{Group: "default"})
+	req.NoError(err)
+	req.Len(rules, 10)
+}
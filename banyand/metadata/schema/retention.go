@@ -0,0 +1,128 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+var ErrRetentionPolicyInUse = errors.New("the retention policy is referred by groups")
+
+func (e *etcdSchemaRegistry) GetRetentionPolicy(ctx context.Context, name string, opts ...ReadOption) (*commonv1.RetentionPolicy, error) {
+	var entity commonv1.RetentionPolicy
+	if err := e.get(ctx, e.keyLayout.formatRetentionPolicyKey(name), &entity, opts...); err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (e *etcdSchemaRegistry) ListRetentionPolicy(ctx context.Context) ([]*commonv1.RetentionPolicy, error) {
+	messages, err := e.listWithPrefix(ctx, e.keyLayout.RetentionPolicyKeyPrefix, func() proto.Message {
+		return &commonv1.RetentionPolicy{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*commonv1.RetentionPolicy, 0, len(messages))
+	for _, message := range messages {
+		entities = append(entities, message.(*commonv1.RetentionPolicy))
+	}
+	return entities, nil
+}
+
+func (e *etcdSchemaRegistry) UpdateRetentionPolicy(ctx context.Context, policy *commonv1.RetentionPolicy, opts ...WriteOption) error {
+	return e.update(ctx, Metadata{
+		TypeMeta: TypeMeta{
+			Kind: KindRetentionPolicy,
+			Name: policy.GetMetadata().GetName(),
+		},
+		Spec: policy,
+	}, opts)
+}
+
+// DeleteRetentionPolicy fails with ErrRetentionPolicyInUse if any group refers to the policy
+func (e *etcdSchemaRegistry) DeleteRetentionPolicy(ctx context.Context, name string) (bool, error) {
+	if err := e.gate.enter(); err != nil {
+		return false, err
+	}
+	defer e.gate.leave()
+	prefix := e.keyLayout.GroupMetadataKeyPrefix
+	resp, err := e.kv.Get(ctx, prefix, clientv3.WithRange(incrementLastByte(prefix)))
+	if err != nil {
+		return false, err
+	}
+	var referrers []string
+	// none of the groups is allowed to change before the policy is deleted
+	cmps := make([]clientv3.Cmp, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		g := &commonv1.Group{}
+		if innerErr := unmarshal(kv.Key, kv.Value, g); innerErr != nil {
+			return false, innerErr
+		}
+		if g.GetResourceOpts().GetRetentionPolicy() == name {
+			referrers = append(referrers, g.GetMetadata().GetName())
+		}
+		cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(string(kv.Key)), "=", kv.ModRevision))
+	}
+	if len(referrers) > 0 {
+		return false, errors.Wrapf(ErrRetentionPolicyInUse, "policy %s is referred by %s", name, strings.Join(referrers, ","))
+	}
+	key := e.keyLayout.formatRetentionPolicyKey(name)
+	txnResp, err := e.kv.Txn(ctx).If(cmps...).Then(clientv3.OpDelete(key, clientv3.WithPrevKV())).Commit()
+	if err != nil {
+		return false, err
+	}
+	if !txnResp.Succeeded {
+		return false, ErrConcurrentModification
+	}
+	delResp := txnResp.Responses[0].GetResponseDeleteRange()
+	if delResp.GetDeleted() < 1 {
+		return false, nil
+	}
+	policy := &commonv1.RetentionPolicy{}
+	if unmarshalErr := unmarshal(delResp.GetPrevKvs()[0].Key, delResp.GetPrevKvs()[0].Value, policy); unmarshalErr == nil {
+		return true, e.notifyDelete(Metadata{
+			TypeMeta: TypeMeta{
+				Kind: KindRetentionPolicy,
+				Name: name,
+			},
+			Spec: policy,
+		}, txnResp.Header.GetRevision())
+	}
+	return true, nil
+}
+
+// retentionPolicyExists returns the condition which keeps the policy from being deleted until the referrer is put
+func (e *etcdSchemaRegistry) retentionPolicyExists(ctx context.Context, name string) (clientv3.Cmp, error) {
+	key := e.keyLayout.formatRetentionPolicyKey(name)
+	resp, err := e.kv.Get(ctx, key, clientv3.WithCountOnly())
+	if err != nil {
+		return clientv3.Cmp{}, err
+	}
+	if resp.Count < 1 {
+		return clientv3.Cmp{}, errors.Wrapf(ErrEntityNotFound, "retention policy %s", name)
+	}
+	return clientv3.Compare(clientv3.CreateRevision(key), ">", 0), nil
+}
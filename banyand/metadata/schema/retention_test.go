@@ -0,0 +1,81 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func Test_Etcd_RetentionPolicy(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	policy := &commonv1.RetentionPolicy{
+		Metadata: &commonv1.Metadata{Name: "week"},
+		Ttl:      &commonv1.Duration{Val: 7, Unit: commonv1.Duration_DURATION_UNIT_DAY},
+	}
+	group := func(name, policy string) *commonv1.Group {
+		return &commonv1.Group{
+			Metadata: &commonv1.Metadata{Name: name},
+			Catalog:  commonv1.Catalog_CATALOG_STREAM,
+			ResourceOpts: &commonv1.ResourceOpts{
+				ShardNum:        2,
+				RetentionPolicy: policy,
+			},
+		}
+	}
+
+	req.ErrorIs(registry.UpdateGroup(context.TODO(), group("g1", "week")), ErrEntityNotFound)
+	req.NoError(registry.UpdateRetentionPolicy(context.TODO(), policy))
+	req.NoError(registry.UpdateGroup(context.TODO(), group("g1", "week")))
+	req.NoError(registry.UpdateGroup(context.TODO(), group("g2", "week")))
+	p, err := registry.GetRetentionPolicy(context.TODO(), "week")
+	req.NoError(err)
+	req.Equal(uint32(7), p.GetTtl().GetVal())
+	policies, err := registry.ListRetentionPolicy(context.TODO())
+	req.NoError(err)
+	req.Len(policies, 1)
+
+	_, err = registry.DeleteRetentionPolicy(context.TODO(), "week")
+	req.ErrorIs(err, ErrRetentionPolicyInUse)
+	req.NoError(registry.UpdateGroup(context.TODO(), group("g1", "")))
+	_, err = registry.DeleteRetentionPolicy(context.TODO(), "week")
+	req.ErrorIs(err, ErrRetentionPolicyInUse)
+	_, err = registry.DeleteGroup(context.TODO(), "g2")
+	req.NoError(err)
+
+	deleted, err := registry.DeleteRetentionPolicy(context.TODO(), "week")
+	req.NoError(err)
+	req.True(deleted)
+	_, err = registry.GetRetentionPolicy(context.TODO(), "week")
+	req.ErrorIs(err, ErrEntityNotFound)
+
+	md, err := ParseKey(DefaultKeyLayout().formatRetentionPolicyKey("week"))
+	req.NoError(err)
+	req.Equal(KindRetentionPolicy, md.Kind)
+	req.Equal("week", md.Name)
+}
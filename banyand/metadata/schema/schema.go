@@ -29,7 +29,9 @@ import (
 )
 
 var (
-	ErrUnsupportedEntityType = errors.New("unsupported entity type")
+	ErrUnsupportedEntityType    = errors.New("unsupported entity type")
+	ErrUnrecognizedKey          = errors.New("unrecognized key")
+	ErrIncompatibleSchemaChange = errors.New("incompatible schema change")
 )
 
 type Kind int
@@ -45,9 +47,14 @@ const (
 	KindMeasure
 	KindIndexRuleBinding
 	KindIndexRule
+	KindRetentionPolicy
+	// KindGroupAlias is an alias of a group, whose spec is a commonv1.Metadata naming the target by its Group
+	KindGroupAlias
+	KindDownsamplingRule
 )
 
-const KindMask = KindGroup | KindStream | KindMeasure | KindIndexRuleBinding | KindIndexRule
+const KindMask = KindGroup | KindStream | KindMeasure | KindIndexRuleBinding | KindIndexRule | KindRetentionPolicy |
+	KindGroupAlias | KindDownsamplingRule
 
 type ListOpt struct {
 	Group string
@@ -55,6 +62,11 @@ type ListOpt struct {
 
 type Registry interface {
 	io.Closer
+	Shutdown(ctx context.Context) error
+	EventQueueStats() []EventQueueStat
+	EntityCounts(ctx context.Context) ([]EntityCount, error)
+	Handlers() []HandlerInfo
+	Version(ctx context.Context) (RegistryVersion, error)
 	ReadyNotify() <-chan struct{}
 	StopNotify() <-chan struct{}
 	StoppingNotify() <-chan struct{}
@@ -63,6 +75,13 @@ type Registry interface {
 	IndexRuleBinding
 	Measure
 	Group
+	Maintenance
+	Batch
+	RetentionPolicy
+	DownsamplingRule
+	Bundle
+	Transactional
+	Counter
 }
 
 type TypeMeta struct {
@@ -77,6 +96,9 @@ type Metadata struct {
 	// Spec holds the configuration object as a protobuf message
 	// Or a metadataHolder as a container
 	Spec Spec
+	// PrevSpec is the Spec replaced by an update, so a handler can tell what is changed.
+	// It's nil if the entity is created or the event is not an update.
+	PrevSpec Spec
 }
 
 type Spec interface {
@@ -94,6 +116,12 @@ func (tm TypeMeta) Unmarshal(data []byte) (m proto.Message, err error) {
 		m = &databasev1.IndexRuleBinding{}
 	case KindIndexRule:
 		m = &databasev1.IndexRule{}
+	case KindRetentionPolicy:
+		m = &commonv1.RetentionPolicy{}
+	case KindGroupAlias:
+		m = &commonv1.Metadata{}
+	case KindDownsamplingRule:
+		m = &databasev1.DownsamplingRule{}
 	default:
 		return nil, ErrUnsupportedEntityType
 	}
@@ -101,33 +129,14 @@ func (tm TypeMeta) Unmarshal(data []byte) (m proto.Message, err error) {
 	return
 }
 
+// Key returns the key of the entity in the default layout
 func (m Metadata) Key() (string, error) {
-	switch m.Kind {
-	case KindGroup:
-		return formatGroupKey(m.Name), nil
-	case KindMeasure:
-		return formatMeasureKey(&commonv1.Metadata{
-			Group: m.Group,
-			Name:  m.Name,
-		}), nil
-	case KindStream:
-		return formatStreamKey(&commonv1.Metadata{
-			Group: m.Group,
-			Name:  m.Name,
-		}), nil
-	case KindIndexRule:
-		return formatIndexRuleKey(&commonv1.Metadata{
-			Group: m.Group,
-			Name:  m.Name,
-		}), nil
-	case KindIndexRuleBinding:
-		return formatIndexRuleBindingKey(&commonv1.Metadata{
-			Group: m.Group,
-			Name:  m.Name,
-		}), nil
-	default:
-		return "", ErrUnsupportedEntityType
-	}
+	return DefaultKeyLayout().Key(m)
+}
+
+// ParseKey is the inverse of Metadata.Key. The Spec of the returned Metadata is absent.
+func ParseKey(key string) (Metadata, error) {
+	return DefaultKeyLayout().ParseKey(key)
 }
 
 func (m Metadata) Equal(other proto.Message) bool {
@@ -142,40 +151,118 @@ func (m Metadata) Equal(other proto.Message) bool {
 	return false
 }
 
+// CheckCompatibility verifies the spec can replace the existing one without corrupting the stored data
+func (m Metadata) CheckCompatibility(existing proto.Message) error {
+	if checker, ok := compatibilityCheckerMap[m.Kind]; ok {
+		return checker(existing, m.Spec.(proto.Message))
+	}
+	return nil
+}
+
 type Stream interface {
-	GetStream(ctx context.Context, metadata *commonv1.Metadata) (*databasev1.Stream, error)
+	GetStream(ctx context.Context, metadata *commonv1.Metadata, opts ...ReadOption) (*databasev1.Stream, error)
 	ListStream(ctx context.Context, opt ListOpt) ([]*databasev1.Stream, error)
-	UpdateStream(ctx context.Context, stream *databasev1.Stream) error
+	// ListStreamNames lists the names of the streams in the group without reading their definitions
+	ListStreamNames(ctx context.Context, group string) ([]string, error)
+	ListStreamSince(ctx context.Context, group string, sinceRevision int64) ([]*databasev1.Stream, int64, error)
+	ListAllStreams(ctx context.Context) ([]*databasev1.Stream, error)
+	UpdateStream(ctx context.Context, stream *databasev1.Stream, opts ...WriteOption) error
 	DeleteStream(ctx context.Context, metadata *commonv1.Metadata) (bool, error)
+	DeleteStreams(ctx context.Context, metadatas []*commonv1.Metadata) (int, error)
+	DeleteStreamCascade(ctx context.Context, metadata *commonv1.Metadata) (bool, error)
 	RegisterHandler(Kind, EventHandler)
+	// RegisterHandlerForGroup only notifies the handler of the events in the group, including the ones of the group itself.
+	// The entities out of any group, for example, the retention policies, are never notified to it.
+	RegisterHandlerForGroup(kind Kind, group string, handler EventHandler)
+	// RegisterHandlerWithPriority notifies the handlers of higher priorities earlier. The handlers of the same priority
+	// are notified in the order of registration. An empty group stands for all the groups.
+	RegisterHandlerWithPriority(kind Kind, group string, priority int, handler EventHandler)
 }
 
 type IndexRule interface {
-	GetIndexRule(ctx context.Context, metadata *commonv1.Metadata) (*databasev1.IndexRule, error)
+	GetIndexRule(ctx context.Context, metadata *commonv1.Metadata, opts ...ReadOption) (*databasev1.IndexRule, error)
 	ListIndexRule(ctx context.Context, opt ListOpt) ([]*databasev1.IndexRule, error)
-	UpdateIndexRule(ctx context.Context, indexRule *databasev1.IndexRule) error
+	ListAllIndexRules(ctx context.Context) ([]*databasev1.IndexRule, error)
+	UpdateIndexRule(ctx context.Context, indexRule *databasev1.IndexRule, opts ...WriteOption) error
 	DeleteIndexRule(ctx context.Context, metadata *commonv1.Metadata) (bool, error)
+	DeleteIndexRules(ctx context.Context, metadatas []*commonv1.Metadata) (int, error)
+	// EffectiveAnalyzer resolves the analyzer of the rule with the default_analyzer of its group
+	EffectiveAnalyzer(ctx context.Context, rule *databasev1.IndexRule) (databasev1.IndexRule_Analyzer, error)
 }
 
 type IndexRuleBinding interface {
-	GetIndexRuleBinding(ctx context.Context, metadata *commonv1.Metadata) (*databasev1.IndexRuleBinding, error)
+	GetIndexRuleBinding(ctx context.Context, metadata *commonv1.Metadata, opts ...ReadOption) (*databasev1.IndexRuleBinding, error)
 	ListIndexRuleBinding(ctx context.Context, opt ListOpt) ([]*databasev1.IndexRuleBinding, error)
-	UpdateIndexRuleBinding(ctx context.Context, indexRuleBinding *databasev1.IndexRuleBinding) error
+	UpdateIndexRuleBinding(ctx context.Context, indexRuleBinding *databasev1.IndexRuleBinding, opts ...WriteOption) error
 	DeleteIndexRuleBinding(ctx context.Context, metadata *commonv1.Metadata) (bool, error)
+	SwapIndexRuleBinding(ctx context.Context, metadata *commonv1.Metadata, newBinding *databasev1.IndexRuleBinding) error
 }
 
 type Measure interface {
-	GetMeasure(ctx context.Context, metadata *commonv1.Metadata) (*databasev1.Measure, error)
+	GetMeasure(ctx context.Context, metadata *commonv1.Metadata, opts ...ReadOption) (*databasev1.Measure, error)
+	// GetMeasureWithMeta returns the revisions of the measure apart from the measure,
+	// rather than relying on the ones assigned to its metadata.
+	GetMeasureWithMeta(ctx context.Context, metadata *commonv1.Metadata, opts ...ReadOption) (*databasev1.Measure, RevisionInfo, error)
 	ListMeasure(ctx context.Context, opt ListOpt) ([]*databasev1.Measure, error)
-	UpdateMeasure(ctx context.Context, measure *databasev1.Measure) error
+	// ListMeasureInRevisionRange lists the measures last modified within [from, to]
+	ListMeasureInRevisionRange(ctx context.Context, group string, from, to int64) ([]*databasev1.Measure, error)
+	// ForEachMeasure walks through the measures of the group without retaining them. The measure passed to fn is reused.
+	ForEachMeasure(ctx context.Context, group string, fn func(*databasev1.Measure) error) error
+	ListAllMeasures(ctx context.Context) ([]*databasev1.Measure, error)
+	UpdateMeasure(ctx context.Context, measure *databasev1.Measure, opts ...WriteOption) error
 	DeleteMeasure(ctx context.Context, metadata *commonv1.Metadata) (bool, error)
+	DeleteMeasures(ctx context.Context, metadatas []*commonv1.Metadata) (int, error)
 	RegisterHandler(Kind, EventHandler)
+	// RegisterHandlerForGroup only notifies the handler of the events in the group, including the ones of the group itself.
+	// The entities out of any group, for example, the retention policies, are never notified to it.
+	RegisterHandlerForGroup(kind Kind, group string, handler EventHandler)
+	// RegisterHandlerWithPriority notifies the handlers of higher priorities earlier. The handlers of the same priority
+	// are notified in the order of registration. An empty group stands for all the groups.
+	RegisterHandlerWithPriority(kind Kind, group string, priority int, handler EventHandler)
+}
+
+// Maintenance checks and repairs the references among entities, and pauses the events during bulk changes
+type Maintenance interface {
+	CheckConsistency(ctx context.Context, group string) (Report, error)
+	Repair(ctx context.Context, group string) (Report, error)
+	// BeginMaintenance suppresses the events until the paired EndMaintenance. The calls could be nested.
+	BeginMaintenance()
+	// EndMaintenance sends a single resync to each Resyncer interested in any suppressed event once the outermost
+	// maintenance ends.
+	// It returns ErrNotInMaintenance if there is no maintenance to end.
+	EndMaintenance() error
+}
+
+// RetentionPolicy is shared by the groups referring to it.
+// A group can't refer to an absent policy, and a policy referred by any group can't be deleted.
+type RetentionPolicy interface {
+	GetRetentionPolicy(ctx context.Context, name string, opts ...ReadOption) (*commonv1.RetentionPolicy, error)
+	ListRetentionPolicy(ctx context.Context) ([]*commonv1.RetentionPolicy, error)
+	UpdateRetentionPolicy(ctx context.Context, policy *commonv1.RetentionPolicy, opts ...WriteOption) error
+	DeleteRetentionPolicy(ctx context.Context, name string) (bool, error)
+}
+
+// DownsamplingRule rolls up a measure into another one of the same group.
+// The handlers registered with KindDownsamplingRule are notified to manage the rollups.
+type DownsamplingRule interface {
+	GetDownsamplingRule(ctx context.Context, metadata *commonv1.Metadata, opts ...ReadOption) (*databasev1.DownsamplingRule, error)
+	ListDownsamplingRule(ctx context.Context, opt ListOpt) ([]*databasev1.DownsamplingRule, error)
+	ListAllDownsamplingRules(ctx context.Context) ([]*databasev1.DownsamplingRule, error)
+	UpdateDownsamplingRule(ctx context.Context, rule *databasev1.DownsamplingRule, opts ...WriteOption) error
+	DeleteDownsamplingRule(ctx context.Context, metadata *commonv1.Metadata) (bool, error)
 }
 
 type Group interface {
-	GetGroup(ctx context.Context, group string) (*commonv1.Group, error)
+	GetGroup(ctx context.Context, group string, opts ...ReadOption) (*commonv1.Group, error)
 	ListGroup(ctx context.Context) ([]*commonv1.Group, error)
+	ListGroupByPrefix(ctx context.Context, namePrefix string) ([]*commonv1.Group, error)
 	// DeleteGroup delete all items belonging to the group
 	DeleteGroup(ctx context.Context, group string) (bool, error)
-	UpdateGroup(ctx context.Context, group *commonv1.Group) error
+	UpdateGroup(ctx context.Context, group *commonv1.Group, opts ...WriteOption) error
+	// CreateGroupAlias lets the alias stand for the target group in the lookups of the group and its entities.
+	// The target could be another alias, but the chain must end with an existing group.
+	CreateGroupAlias(ctx context.Context, alias, target string) error
+	DeleteGroupAlias(ctx context.Context, alias string) (bool, error)
+	// WatchGroups streams the creations, updates and deletions of the groups from now on, see GroupEvent.
+	WatchGroups(ctx context.Context) (<-chan GroupEvent, error)
 }
@@ -0,0 +1,66 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		typeMeta TypeMeta
+	}{
+		{
+			name:     "group",
+			typeMeta: TypeMeta{Kind: KindGroup, Name: "default"},
+		},
+		{
+			name:     "stream",
+			typeMeta: TypeMeta{Kind: KindStream, Group: "default", Name: "sw"},
+		},
+		{
+			name:     "measure",
+			typeMeta: TypeMeta{Kind: KindMeasure, Group: "default", Name: "service_cpm_minute"},
+		},
+		{
+			name:     "index rule binding",
+			typeMeta: TypeMeta{Kind: KindIndexRuleBinding, Group: "default", Name: "sw-index-rule-binding"},
+		},
+		{
+			name:     "index rule",
+			typeMeta: TypeMeta{Kind: KindIndexRule, Group: "default", Name: "trace_id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			key, err := Metadata{TypeMeta: tt.typeMeta}.Key()
+			req.NoError(err)
+			md, err := ParseKey(key)
+			req.NoError(err)
+			req.Equal(tt.typeMeta, md.TypeMeta)
+		})
+	}
+	for _, key := range []string{"", "/streams/sw", "/groups/", "/groups/default", "/groups/default/unknown/sw", "/groups/default/streams/"} {
+		_, err := ParseKey(key)
+		require.ErrorIs(t, err, ErrUnrecognizedKey, key)
+	}
+}
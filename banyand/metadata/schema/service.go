@@ -0,0 +1,190 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+// RegisterReadOnlyServices exposes the Get and List methods of the registry services
+// on the server. The mutating methods respond with codes.Unimplemented.
+// It lets a sidecar serve schema reads directly from the Registry.
+func RegisterReadOnlyServices(s grpc.ServiceRegistrar, registry Registry) {
+	databasev1.RegisterGroupRegistryServiceServer(s, &groupReader{registry: registry})
+	databasev1.RegisterStreamRegistryServiceServer(s, &streamReader{registry: registry})
+	databasev1.RegisterMeasureRegistryServiceServer(s, &measureReader{registry: registry})
+	databasev1.RegisterIndexRuleBindingRegistryServiceServer(s, &indexRuleBindingReader{registry: registry})
+	databasev1.RegisterIndexRuleRegistryServiceServer(s, &indexRuleReader{registry: registry})
+}
+
+func toStatus(err error) error {
+	var notFound *NotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		return notFound.GRPCStatus().Err()
+	case errors.Is(err, ErrEntityNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrGroupAbsent):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+type groupReader struct {
+	registry Registry
+	databasev1.UnimplementedGroupRegistryServiceServer
+}
+
+func (r *groupReader) Get(ctx context.Context,
+	req *databasev1.GroupRegistryServiceGetRequest) (*databasev1.GroupRegistryServiceGetResponse, error) {
+	g, err := r.registry.GetGroup(ctx, req.GetGroup())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &databasev1.GroupRegistryServiceGetResponse{
+		Group: g,
+	}, nil
+}
+
+func (r *groupReader) List(ctx context.Context,
+	_ *databasev1.GroupRegistryServiceListRequest) (*databasev1.GroupRegistryServiceListResponse, error) {
+	groups, err := r.registry.ListGroup(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &databasev1.GroupRegistryServiceListResponse{
+		Group: groups,
+	}, nil
+}
+
+type streamReader struct {
+	registry Registry
+	databasev1.UnimplementedStreamRegistryServiceServer
+}
+
+func (r *streamReader) Get(ctx context.Context,
+	req *databasev1.StreamRegistryServiceGetRequest) (*databasev1.StreamRegistryServiceGetResponse, error) {
+	entity, err := r.registry.GetStream(ctx, req.GetMetadata())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &databasev1.StreamRegistryServiceGetResponse{
+		Stream: entity,
+	}, nil
+}
+
+func (r *streamReader) List(ctx context.Context,
+	req *databasev1.StreamRegistryServiceListRequest) (*databasev1.StreamRegistryServiceListResponse, error) {
+	entities, err := r.registry.ListStream(ctx, ListOpt{Group: req.GetGroup()})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &databasev1.StreamRegistryServiceListResponse{
+		Stream: entities,
+	}, nil
+}
+
+type measureReader struct {
+	registry Registry
+	databasev1.UnimplementedMeasureRegistryServiceServer
+}
+
+func (r *measureReader) Get(ctx context.Context,
+	req *databasev1.MeasureRegistryServiceGetRequest) (*databasev1.MeasureRegistryServiceGetResponse, error) {
+	entity, err := r.registry.GetMeasure(ctx, req.GetMetadata())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &databasev1.MeasureRegistryServiceGetResponse{
+		Measure: entity,
+	}, nil
+}
+
+func (r *measureReader) List(ctx context.Context,
+	req *databasev1.MeasureRegistryServiceListRequest) (*databasev1.MeasureRegistryServiceListResponse, error) {
+	entities, err := r.registry.ListMeasure(ctx, ListOpt{Group: req.GetGroup()})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &databasev1.MeasureRegistryServiceListResponse{
+		Measure: entities,
+	}, nil
+}
+
+type indexRuleBindingReader struct {
+	registry Registry
+	databasev1.UnimplementedIndexRuleBindingRegistryServiceServer
+}
+
+func (r *indexRuleBindingReader) Get(ctx context.Context,
+	req *databasev1.IndexRuleBindingRegistryServiceGetRequest) (
+	*databasev1.IndexRuleBindingRegistryServiceGetResponse, error) {
+	entity, err := r.registry.GetIndexRuleBinding(ctx, req.GetMetadata())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &databasev1.IndexRuleBindingRegistryServiceGetResponse{
+		IndexRuleBinding: entity,
+	}, nil
+}
+
+func (r *indexRuleBindingReader) List(ctx context.Context,
+	req *databasev1.IndexRuleBindingRegistryServiceListRequest) (
+	*databasev1.IndexRuleBindingRegistryServiceListResponse, error) {
+	entities, err := r.registry.ListIndexRuleBinding(ctx, ListOpt{Group: req.GetGroup()})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &databasev1.IndexRuleBindingRegistryServiceListResponse{
+		IndexRuleBinding: entities,
+	}, nil
+}
+
+type indexRuleReader struct {
+	registry Registry
+	databasev1.UnimplementedIndexRuleRegistryServiceServer
+}
+
+func (r *indexRuleReader) Get(ctx context.Context,
+	req *databasev1.IndexRuleRegistryServiceGetRequest) (*databasev1.IndexRuleRegistryServiceGetResponse, error) {
+	entity, err := r.registry.GetIndexRule(ctx, req.GetMetadata())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &databasev1.IndexRuleRegistryServiceGetResponse{
+		IndexRule: entity,
+	}, nil
+}
+
+func (r *indexRuleReader) List(ctx context.Context,
+	req *databasev1.IndexRuleRegistryServiceListRequest) (*databasev1.IndexRuleRegistryServiceListResponse, error) {
+	entities, err := r.registry.ListIndexRule(ctx, ListOpt{Group: req.GetGroup()})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &databasev1.IndexRuleRegistryServiceListResponse{
+		IndexRule: entities,
+	}, nil
+}
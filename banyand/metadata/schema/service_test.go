@@ -0,0 +1,85 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+func Test_RegisterReadOnlyServices(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	RegisterReadOnlyServices(server, registry)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	defer server.Stop()
+	conn, err := grpc.DialContext(context.TODO(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithInsecure())
+	req.NoError(err)
+	defer conn.Close()
+
+	client := databasev1.NewStreamRegistryServiceClient(conn)
+	getResp, err := client.Get(context.TODO(), &databasev1.StreamRegistryServiceGetRequest{
+		Metadata: &commonv1.Metadata{Name: "sw", Group: "default"},
+	})
+	req.NoError(err)
+	req.Equal("sw", getResp.GetStream().GetMetadata().GetName())
+
+	listResp, err := databasev1.NewIndexRuleRegistryServiceClient(conn).List(context.TODO(),
+		&databasev1.IndexRuleRegistryServiceListRequest{Group: "default"})
+	req.NoError(err)
+	req.NotEmpty(listResp.GetIndexRule())
+
+	_, err = client.Get(context.TODO(), &databasev1.StreamRegistryServiceGetRequest{
+		Metadata: &commonv1.Metadata{Name: "unknown", Group: "default"},
+	})
+	req.Equal(codes.NotFound, status.Code(err))
+	details := status.Convert(err).Details()
+	req.Len(details, 1)
+	info, ok := details[0].(*errdetails.ResourceInfo)
+	req.True(ok)
+	req.Equal("stream", info.GetResourceType())
+	req.Equal("default/unknown", info.GetResourceName())
+
+	_, err = client.Delete(context.TODO(), &databasev1.StreamRegistryServiceDeleteRequest{
+		Metadata: &commonv1.Metadata{Name: "sw", Group: "default"},
+	})
+	req.Equal(codes.Unimplemented, status.Code(err))
+}
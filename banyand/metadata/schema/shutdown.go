@@ -0,0 +1,95 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrRegistryClosed = errors.New("the registry is shut down")
+	ErrDrainTimeout   = errors.New("the in-flight writes are dropped when the shutdown deadline exceeds")
+)
+
+// Shutdown stops accepting writes, waits for the in-flight ones to be persisted and their events to be delivered,
+// then closes the embedded etcd.
+// The writes after Shutdown fail with ErrRegistryClosed, and the reads work until etcd is closed.
+// If ctx is done before the in-flight writes finish, etcd is closed anyway and
+// ErrDrainTimeout is returned, which tells the forced shutdown from the clean one.
+func (e *etcdSchemaRegistry) Shutdown(ctx context.Context) error {
+	err := e.gate.drain(ctx)
+	e.closeQueues(err != nil)
+	if err == nil {
+		if err = e.drainQueues(ctx); err != nil {
+			e.closeQueues(true)
+		}
+	}
+	e.backend.close()
+	return err
+}
+
+// writeGate tracks the in-flight writes, and rejects new ones once it's closed or if it's read-only.
+// Every write method enters it once, so a write is drained along with the delivery of its events
+// rather than cut off between its requests.
+type writeGate struct {
+	mu       sync.Mutex
+	readOnly bool
+	closed   bool
+	inFlight sync.WaitGroup
+}
+
+func (g *writeGate) enter() error {
+	if g.readOnly {
+		return ErrReadOnly
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return ErrRegistryClosed
+	}
+	g.inFlight.Add(1)
+	return nil
+}
+
+func (g *writeGate) leave() {
+	g.inFlight.Done()
+}
+
+func (g *writeGate) close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.closed = true
+}
+
+func (g *writeGate) drain(ctx context.Context) error {
+	g.close()
+	done := make(chan struct{})
+	go func() {
+		g.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ErrDrainTimeout, "%v", ctx.Err())
+	}
+}
@@ -0,0 +1,106 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func Test_Etcd_Shutdown(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NoError(preloadSchema(registry))
+	gate := registry.(*etcdSchemaRegistry).gate
+
+	// a write is in flight until it leaves the gate
+	req.NoError(gate.enter())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		gate.leave()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req.NoError(registry.Shutdown(ctx))
+	<-registry.StopNotify()
+
+	_, err = registry.DeleteStream(context.TODO(), &commonv1.Metadata{Name: "sw", Group: "default"})
+	req.ErrorIs(err, ErrRegistryClosed)
+}
+
+func Test_Etcd_Shutdown_Deadline(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	gate := registry.(*etcdSchemaRegistry).gate
+
+	// the write never finishes
+	req.NoError(gate.enter())
+	defer gate.leave()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req.ErrorIs(registry.Shutdown(ctx), ErrDrainTimeout)
+	<-registry.StopNotify()
+}
+
+// blockingHandler holds the delivery of the events until it's released
+type blockingHandler struct {
+	delivering chan struct{}
+	release    chan struct{}
+}
+
+func (h *blockingHandler) OnAddOrUpdate(Metadata) {
+	close(h.delivering)
+	<-h.release
+}
+
+func (h *blockingHandler) OnDelete(Metadata) {}
+
+func Test_Etcd_Shutdown_DrainsWholeWrite(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	h := &blockingHandler{delivering: make(chan struct{}), release: make(chan struct{})}
+	registry.RegisterHandler(KindGroup, h)
+
+	updated := make(chan error, 1)
+	go func() {
+		updated <- updateGroup(registry, "g1")
+	}()
+	<-h.delivering
+	// the write is in flight until its event is delivered, though its request to etcd is done
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- registry.Shutdown(context.Background())
+	}()
+	select {
+	case <-shutdown:
+		req.FailNow("the shutdown doesn't wait for the write")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(h.release)
+	req.NoError(<-updated)
+	req.NoError(<-shutdown)
+	<-registry.StopNotify()
+}
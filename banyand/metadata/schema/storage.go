@@ -0,0 +1,84 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+var ErrInvalidStorageOption = errors.New("invalid storage option of the embedded etcd")
+
+// storageTuner applies a storage option to the embedded etcd. It fails if the option is out of etcd's range.
+type storageTuner func(cfg *embed.Config) error
+
+// WithSnapshotCount sets how many committed transactions trigger a snapshot, after which the older WAL entries are released.
+// A smaller count bounds the WAL replayed on restart at the cost of more frequent snapshots.
+// The count should be positive because etcd silently replaces zero with its default.
+func WithSnapshotCount(n uint64) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.storageTuners = append(config.storageTuners, func(cfg *embed.Config) error {
+			if n < 1 {
+				return errors.Wrap(ErrInvalidStorageOption, "the snapshot count should be positive")
+			}
+			cfg.SnapshotCount = n
+			return nil
+		})
+	}
+}
+
+// WithMaxSnapshots sets how many snapshot files are retained. Zero retains all of them.
+func WithMaxSnapshots(n uint) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.storageTuners = append(config.storageTuners, func(cfg *embed.Config) error {
+			cfg.MaxSnapFiles = n
+			return nil
+		})
+	}
+}
+
+// WithMaxWALs sets how many WAL segment files are retained. Zero retains all of them.
+// Only the segments released by a snapshot are purged, so the count works along with WithSnapshotCount.
+func WithMaxWALs(n uint) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.storageTuners = append(config.storageTuners, func(cfg *embed.Config) error {
+			cfg.MaxWalFiles = n
+			return nil
+		})
+	}
+}
+
+// WithWALDir puts the WAL in a dedicated directory, for example, on a faster disk.
+// The directory should be absolute and out of the data directory.
+func WithWALDir(dir string) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.storageTuners = append(config.storageTuners, func(cfg *embed.Config) error {
+			if !filepath.IsAbs(dir) {
+				return errors.Wrapf(ErrInvalidStorageOption, "the WAL directory %q should be absolute", dir)
+			}
+			if rel, err := filepath.Rel(cfg.Dir, dir); err == nil && !strings.HasPrefix(rel, "..") {
+				return errors.Wrapf(ErrInvalidStorageOption, "the WAL directory %q is in the data directory %q", dir, cfg.Dir)
+			}
+			cfg.WalDir = dir
+			return nil
+		})
+	}
+}
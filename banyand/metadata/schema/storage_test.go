@@ -0,0 +1,65 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Etcd_StorageOptions(t *testing.T) {
+	req := require.New(t)
+	rootDir := randomTempDir()
+	walDir := randomTempDir()
+	defer os.RemoveAll(walDir)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), RootDir(rootDir),
+		WithSnapshotCount(10), WithMaxSnapshots(2), WithMaxWALs(2), WithWALDir(walDir))
+	req.NoError(err)
+	defer registry.Close()
+
+	for i := 0; i < 30; i++ {
+		req.NoError(updateGroup(registry, fmt.Sprintf("g%d", i)))
+	}
+	wals, err := filepath.Glob(filepath.Join(walDir, "*.wal"))
+	req.NoError(err)
+	req.NotEmpty(wals)
+	req.Eventually(func() bool {
+		snaps, _ := filepath.Glob(filepath.Join(rootDir, "metadata", "member", "snap", "*.snap"))
+		return len(snaps) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func Test_Etcd_InvalidStorageOptions(t *testing.T) {
+	rootDir := randomTempDir()
+	for name, opt := range map[string]RegistryOption{
+		"zero snapshot count":    WithSnapshotCount(0),
+		"relative WAL dir":       WithWALDir("wal"),
+		"WAL dir in data dir":    WithWALDir(filepath.Join(rootDir, "metadata", "wal")),
+		"WAL dir being data dir": WithWALDir(filepath.Join(rootDir, "metadata")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewEtcdSchemaRegistry(useUnixDomain(), RootDir(rootDir), opt)
+			require.ErrorIs(t, err, ErrInvalidStorageOption)
+		})
+	}
+}
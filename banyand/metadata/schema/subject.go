@@ -0,0 +1,118 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+var ErrSubjectKindMismatch = errors.New("the subject doesn't match the kind of the binding")
+
+// checkSubjectKind resolves the subject of the binding and verifies the rules are applicable to it,
+// including that a tokenizing analyzer only applies to a string tag.
+// The absent entities are skipped since a binding could be created ahead of its subject and rules.
+// The entities in staged, keyed by their keys, are written along with the binding, so they are resolved
+// ahead of the stored ones.
+func (e *etcdSchemaRegistry) checkSubjectKind(ctx context.Context, binding *databasev1.IndexRuleBinding,
+	staged map[string]proto.Message) error {
+	group := binding.GetMetadata().GetGroup()
+	subject := binding.GetSubject()
+	var g commonv1.Group
+	err := e.getStaged(ctx, staged, &commonv1.Metadata{Group: group}, func(metadata *commonv1.Metadata) string {
+		return e.keyLayout.formatGroupKey(metadata.GetGroup())
+	}, &g)
+	if err != nil && !errors.Is(err, ErrEntityNotFound) {
+		return err
+	}
+	if catalog := g.GetCatalog(); catalog != commonv1.Catalog_CATALOG_UNSPECIFIED && catalog != subject.GetCatalog() {
+		return errors.Wrapf(ErrSubjectKindMismatch, "subject %s is %s, but group %s is %s",
+			subject.GetName(), subject.GetCatalog(), group, catalog)
+	}
+	md := &commonv1.Metadata{Name: subject.GetName(), Group: group}
+	var tagFamilies []*databasev1.TagFamilySpec
+	var otherKey string
+	switch subject.GetCatalog() {
+	case commonv1.Catalog_CATALOG_STREAM:
+		var s databasev1.Stream
+		err = e.getStaged(ctx, staged, md, e.keyLayout.formatStreamKey, &s)
+		tagFamilies = s.GetTagFamilies()
+		otherKey = e.keyLayout.formatMeasureKey(md)
+	case commonv1.Catalog_CATALOG_MEASURE:
+		var m databasev1.Measure
+		err = e.getStaged(ctx, staged, md, e.keyLayout.formatMeasureKey, &m)
+		tagFamilies = m.GetTagFamilies()
+		otherKey = e.keyLayout.formatStreamKey(md)
+	default:
+		return errors.Wrapf(ErrSubjectKindMismatch, "unknown catalog of subject %s", subject.GetName())
+	}
+	if errors.Is(err, ErrEntityNotFound) {
+		// the subject is defined as the other kind
+		if _, ok := staged[otherKey]; ok {
+			return errors.Wrapf(ErrSubjectKindMismatch, "subject %s is not %s", subject.GetName(), subject.GetCatalog())
+		}
+		resp, innerErr := e.kv.Get(ctx, otherKey, clientv3.WithCountOnly())
+		if innerErr != nil {
+			return innerErr
+		}
+		if resp.Count > 0 {
+			return errors.Wrapf(ErrSubjectKindMismatch, "subject %s is not %s", subject.GetName(), subject.GetCatalog())
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	declared := tagTypesOf(tagFamilies)
+	for _, name := range binding.GetRules() {
+		var rule databasev1.IndexRule
+		innerErr := e.getStaged(ctx, staged, &commonv1.Metadata{Name: name, Group: group}, e.keyLayout.formatIndexRuleKey, &rule)
+		if errors.Is(innerErr, ErrEntityNotFound) {
+			continue
+		}
+		if innerErr != nil {
+			return innerErr
+		}
+		for _, tag := range rule.GetTags() {
+			if _, ok := declared[tag]; !ok {
+				return errors.Wrapf(ErrSubjectKindMismatch, "index rule %s refers to tag %s absent in %s %s",
+					name, tag, subject.GetCatalog(), subject.GetName())
+			}
+		}
+		if innerErr = checkAnalyzedTag(&rule, declared, subject); innerErr != nil {
+			return innerErr
+		}
+	}
+	return nil
+}
+
+// getStaged reads the staged entity if there is one, otherwise the stored one
+func (e *etcdSchemaRegistry) getStaged(ctx context.Context, staged map[string]proto.Message, metadata *commonv1.Metadata,
+	formatKey func(*commonv1.Metadata) string, message proto.Message) error {
+	if spec, ok := staged[formatKey(metadata)]; ok {
+		proto.Merge(message, spec)
+		return nil
+	}
+	return e.getInGroup(ctx, metadata, formatKey, message)
+}
@@ -0,0 +1,127 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+func Test_Etcd_SubjectKindMismatch(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	binding := func(group string, catalog commonv1.Catalog, subject string, rules ...string) *databasev1.IndexRuleBinding {
+		return &databasev1.IndexRuleBinding{
+			Metadata: &commonv1.Metadata{Group: group, Name: "binding"},
+			Rules:    rules,
+			Subject:  &databasev1.Subject{Catalog: catalog, Name: subject},
+		}
+	}
+
+	// the group only holds streams
+	req.ErrorIs(registry.UpdateIndexRuleBinding(context.TODO(),
+		binding("default", commonv1.Catalog_CATALOG_MEASURE, "sw", "trace_id")), ErrSubjectKindMismatch)
+
+	req.NoError(registry.UpdateIndexRule(context.TODO(), &databasev1.IndexRule{
+		Metadata: &commonv1.Metadata{Group: "default", Name: "value"},
+		Tags:     []string{"value"},
+		Type:     databasev1.IndexRule_TYPE_TREE,
+		Location: databasev1.IndexRule_LOCATION_SERIES,
+	}))
+	req.ErrorIs(registry.UpdateIndexRuleBinding(context.TODO(),
+		binding("default", commonv1.Catalog_CATALOG_STREAM, "sw", "trace_id", "value")), ErrSubjectKindMismatch)
+	// the absent rules are skipped
+	req.NoError(registry.UpdateIndexRuleBinding(context.TODO(),
+		binding("default", commonv1.Catalog_CATALOG_STREAM, "sw", "trace_id", "absent")))
+	req.ErrorIs(registry.SwapIndexRuleBinding(context.TODO(), &commonv1.Metadata{Group: "default", Name: "binding"},
+		binding("default", commonv1.Catalog_CATALOG_STREAM, "sw", "value")), ErrSubjectKindMismatch)
+	mismatched := Metadata{
+		TypeMeta: TypeMeta{Kind: KindIndexRuleBinding, Group: "default", Name: "binding"},
+		Spec:     binding("default", commonv1.Catalog_CATALOG_STREAM, "sw", "value"),
+	}
+	req.ErrorIs(registry.ApplyBatch(context.TODO(), []Metadata{mismatched}), ErrSubjectKindMismatch)
+	req.ErrorIs(registry.Transaction(context.TODO(), func(tx RegistryTxn) error {
+		return tx.Put(mismatched)
+	}), ErrSubjectKindMismatch)
+
+	req.NoError(registry.UpdateGroup(context.TODO(), &commonv1.Group{
+		Metadata:     &commonv1.Metadata{Name: "mixed"},
+		ResourceOpts: &commonv1.ResourceOpts{ShardNum: 1},
+	}))
+	req.NoError(registry.UpdateMeasure(context.TODO(), &databasev1.Measure{
+		Metadata: &commonv1.Metadata{Group: "mixed", Name: "service_cpm"},
+	}))
+	req.ErrorIs(registry.UpdateIndexRuleBinding(context.TODO(),
+		binding("mixed", commonv1.Catalog_CATALOG_STREAM, "service_cpm")), ErrSubjectKindMismatch)
+	req.NoError(registry.UpdateIndexRuleBinding(context.TODO(),
+		binding("mixed", commonv1.Catalog_CATALOG_MEASURE, "service_cpm")))
+	// the subject could be created later
+	req.NoError(registry.UpdateIndexRuleBinding(context.TODO(),
+		binding("mixed", commonv1.Catalog_CATALOG_STREAM, "absent")))
+
+	// a batch resolves the subject and the rules it creates along with the binding
+	batched := []Metadata{
+		{
+			TypeMeta: TypeMeta{Kind: KindMeasure, Group: "mixed", Name: "batched_cpm"},
+			Spec: &databasev1.Measure{
+				Metadata: &commonv1.Metadata{Group: "mixed", Name: "batched_cpm"},
+				TagFamilies: []*databasev1.TagFamilySpec{{
+					Name: "default",
+					Tags: []*databasev1.TagSpec{{Name: "entity_id", Type: databasev1.TagType_TAG_TYPE_STRING}},
+				}},
+			},
+		},
+		{
+			TypeMeta: TypeMeta{Kind: KindIndexRule, Group: "mixed", Name: "entity_id"},
+			Spec: &databasev1.IndexRule{
+				Metadata: &commonv1.Metadata{Group: "mixed", Name: "entity_id"},
+				Tags:     []string{"entity_id"},
+				Type:     databasev1.IndexRule_TYPE_INVERTED,
+				Location: databasev1.IndexRule_LOCATION_SERIES,
+			},
+		},
+		{
+			TypeMeta: TypeMeta{Kind: KindIndexRuleBinding, Group: "mixed", Name: "binding"},
+			Spec:     binding("mixed", commonv1.Catalog_CATALOG_MEASURE, "batched_cpm", "entity_id", "value"),
+		},
+		{
+			TypeMeta: TypeMeta{Kind: KindIndexRule, Group: "mixed", Name: "value"},
+			Spec: &databasev1.IndexRule{
+				Metadata: &commonv1.Metadata{Group: "mixed", Name: "value"},
+				Tags:     []string{"value"},
+				Type:     databasev1.IndexRule_TYPE_TREE,
+				Location: databasev1.IndexRule_LOCATION_SERIES,
+			},
+		},
+	}
+	req.ErrorIs(registry.ApplyBatch(context.TODO(), batched), ErrSubjectKindMismatch, "rule value refers to an undeclared tag")
+	batched[2].Spec = binding("mixed", commonv1.Catalog_CATALOG_STREAM, "batched_cpm", "entity_id")
+	req.ErrorIs(registry.ApplyBatch(context.TODO(), batched), ErrSubjectKindMismatch, "the batched subject is a measure")
+	batched[2].Spec = binding("mixed", commonv1.Catalog_CATALOG_MEASURE, "batched_cpm", "entity_id")
+	req.NoError(registry.ApplyBatch(context.TODO(), batched))
+}
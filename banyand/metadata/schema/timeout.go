@@ -0,0 +1,119 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// WithRequestTimeout bounds every etcd request of the registry by d.
+// The deadline of the caller's context still applies if it's the earlier one.
+// A non-positive d leaves the requests bounded by the caller's context only, which is the default.
+func WithRequestTimeout(d time.Duration) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.requestTimeout = d
+	}
+}
+
+// withRequestTimeout derives the context of a request which doesn't go through the kv, e.g. the lease and the STM
+func (e *etcdSchemaRegistry) withRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if e.requestTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, e.requestTimeout)
+}
+
+var _ clientv3.KV = (*timeoutKV)(nil)
+
+// timeoutKV bounds each request by the timeout
+type timeoutKV struct {
+	clientv3.KV
+	timeout time.Duration
+}
+
+func (k *timeoutKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, k.timeout)
+	defer cancel()
+	return k.KV.Put(ctx, key, val, opts...)
+}
+
+func (k *timeoutKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, k.timeout)
+	defer cancel()
+	return k.KV.Get(ctx, key, opts...)
+}
+
+func (k *timeoutKV) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, k.timeout)
+	defer cancel()
+	return k.KV.Delete(ctx, key, opts...)
+}
+
+func (k *timeoutKV) Compact(ctx context.Context, rev int64, opts ...clientv3.CompactOption) (*clientv3.CompactResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, k.timeout)
+	defer cancel()
+	return k.KV.Compact(ctx, rev, opts...)
+}
+
+func (k *timeoutKV) Do(ctx context.Context, op clientv3.Op) (clientv3.OpResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, k.timeout)
+	defer cancel()
+	return k.KV.Do(ctx, op)
+}
+
+// Txn defers the request to Commit, so the timeout doesn't elapse while the transaction is being built
+func (k *timeoutKV) Txn(ctx context.Context) clientv3.Txn {
+	return &timeoutTxn{
+		kv:      k.KV,
+		ctx:     ctx,
+		timeout: k.timeout,
+	}
+}
+
+type timeoutTxn struct {
+	kv      clientv3.KV
+	ctx     context.Context
+	timeout time.Duration
+	cmps    []clientv3.Cmp
+	thenOps []clientv3.Op
+	elseOps []clientv3.Op
+}
+
+func (t *timeoutTxn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	t.cmps = append(t.cmps, cs...)
+	return t
+}
+
+func (t *timeoutTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	t.thenOps = append(t.thenOps, ops...)
+	return t
+}
+
+func (t *timeoutTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	t.elseOps = append(t.elseOps, ops...)
+	return t
+}
+
+func (t *timeoutTxn) Commit() (*clientv3.TxnResponse, error) {
+	ctx, cancel := context.WithTimeout(t.ctx, t.timeout)
+	defer cancel()
+	return t.kv.Txn(ctx).If(t.cmps...).Then(t.thenOps...).Else(t.elseOps...).Commit()
+}
@@ -0,0 +1,57 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func Test_Etcd_RequestTimeout(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir(), WithRequestTimeout(10*time.Second))
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+	md := &commonv1.Metadata{Name: "sw", Group: "default"}
+	_, err = registry.GetStream(context.Background(), md)
+	req.NoError(err)
+
+	// the caller's deadline is the earlier one
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	_, err = registry.GetStream(ctx, md)
+	req.ErrorIs(err, context.DeadlineExceeded)
+
+	// the registry's timeout is the earlier one
+	e := registry.(*etcdSchemaRegistry)
+	e.kv.(*timeoutKV).timeout = time.Nanosecond
+	e.requestTimeout = time.Nanosecond
+	_, err = registry.GetStream(context.Background(), md)
+	req.ErrorIs(err, context.DeadlineExceeded)
+	_, err = registry.DeleteStream(context.Background(), md)
+	req.ErrorIs(err, context.DeadlineExceeded)
+	_, err = registry.Version(context.Background())
+	req.ErrorIs(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,136 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+const tracerName = "github.com/apache/skywalking-banyandb/banyand/metadata/schema"
+
+var kindNames = map[Kind]string{
+	KindGroup:            "group",
+	KindStream:           "stream",
+	KindMeasure:          "measure",
+	KindIndexRuleBinding: "index_rule_binding",
+	KindIndexRule:        "index_rule",
+	KindRetentionPolicy:  "retention_policy",
+	KindGroupAlias:       "group_alias",
+	KindDownsamplingRule: "downsampling_rule",
+}
+
+// TraceWith starts a span for each get, update, delete and list of the registry.
+// The registry isn't traced without this option.
+func TraceWith(provider trace.TracerProvider) RegistryOption {
+	return func(config *etcdSchemaRegistryConfig) {
+		config.tracerProvider = provider
+	}
+}
+
+// opSpan wraps a span of an operation. A nil opSpan discards everything, so the untraced registry pays nothing.
+type opSpan struct {
+	span trace.Span
+}
+
+// startSpan only builds the attributes if the registry is traced
+func (e *etcdSchemaRegistry) startSpan(ctx context.Context, op string, attrs func() []attribute.KeyValue) (context.Context, *opSpan) {
+	if e.tracer == nil {
+		return ctx, nil
+	}
+	ctx, span := e.tracer.Start(ctx, "schema."+op, trace.WithAttributes(attrs()...))
+	return ctx, &opSpan{span: span}
+}
+
+func (s *opSpan) setRevision(revision int64) {
+	if s == nil {
+		return
+	}
+	s.span.SetAttributes(attribute.Int64("schema.revision", revision))
+}
+
+func (s *opSpan) setCount(count int) {
+	if s == nil {
+		return
+	}
+	s.span.SetAttributes(attribute.Int("schema.count", count))
+}
+
+func (s *opSpan) end(err error) {
+	if s == nil {
+		return
+	}
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+func kindName(kind Kind) string {
+	if name, ok := kindNames[kind]; ok {
+		return name
+	}
+	return strconv.Itoa(int(kind))
+}
+
+func typeMetaAttributes(tm TypeMeta) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("schema.kind", kindName(tm.Kind))}
+	if tm.Group != "" {
+		attrs = append(attrs, attribute.String("schema.group", tm.Group))
+	}
+	if tm.Name != "" {
+		attrs = append(attrs, attribute.String("schema.name", tm.Name))
+	}
+	return attrs
+}
+
+// keyAttributes falls back to the raw key if it's not an entity's key
+func (e *etcdSchemaRegistry) keyAttributes(key string) []attribute.KeyValue {
+	md, err := e.keyLayout.ParseKey(key)
+	if err != nil {
+		return []attribute.KeyValue{attribute.String("schema.key", key)}
+	}
+	return typeMetaAttributes(md.TypeMeta)
+}
+
+// listAttributes derives the kind from the listed messages and the group from the prefix
+func (e *etcdSchemaRegistry) listAttributes(prefix string, factory func() proto.Message) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("schema.prefix", prefix)}
+	name := proto.MessageName(factory())
+	for kind := Kind(1); kind&KindMask != 0; kind <<= 1 {
+		m, err := TypeMeta{Kind: kind}.Unmarshal(nil)
+		if err == nil && proto.MessageName(m) == name {
+			attrs = append(attrs, attribute.String("schema.kind", kindName(kind)))
+			break
+		}
+	}
+	rest := strings.TrimPrefix(prefix, e.keyLayout.GroupsKeyPrefix)
+	if len(rest) < len(prefix) {
+		if i := strings.Index(rest, "/"); i > 0 {
+			attrs = append(attrs, attribute.String("schema.group", rest[:i]))
+		}
+	}
+	return attrs
+}
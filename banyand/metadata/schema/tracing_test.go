@@ -0,0 +1,81 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+func spanAttributes(span *sdktrace.SpanSnapshot) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func Test_Etcd_TraceWith(t *testing.T) {
+	req := require.New(t)
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir(), TraceWith(provider))
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+	exporter.Reset()
+
+	_, err = registry.GetIndexRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "trace_id"})
+	req.NoError(err)
+	_, err = registry.GetIndexRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "absent"})
+	req.ErrorIs(err, ErrEntityNotFound)
+	rules, err := registry.ListIndexRule(context.TODO(), ListOpt{Group: "default"})
+	req.NoError(err)
+	deleted, err := registry.DeleteIndexRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "trace_id"})
+	req.NoError(err)
+	req.True(deleted)
+
+	// the lookups of the group and its alias are traced as well
+	var spans []*sdktrace.SpanSnapshot
+	for _, span := range exporter.GetSpans() {
+		if spanAttributes(span)["schema.kind"].AsString() == "index_rule" {
+			spans = append(spans, span)
+		}
+	}
+	req.Len(spans, 4)
+	for _, span := range spans {
+		attrs := spanAttributes(span)
+		req.Equal("default", attrs["schema.group"].AsString(), span.Name)
+		req.Positive(attrs["schema.revision"].AsInt64(), span.Name)
+	}
+	req.Equal("schema.get", spans[0].Name)
+	req.Equal("trace_id", spanAttributes(spans[0])["schema.name"].AsString())
+	req.Equal(codes.Unset, spans[0].StatusCode)
+	req.Equal(codes.Error, spans[1].StatusCode)
+	req.Equal("schema.list", spans[2].Name)
+	req.Equal(int64(len(rules)), spanAttributes(spans[2])["schema.count"].AsInt64())
+	req.Equal("schema.delete", spans[3].Name)
+}
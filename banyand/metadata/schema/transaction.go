@@ -0,0 +1,203 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/proto"
+
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+// RegistryTxn reads and writes the entities in a transaction.
+// The entities are denoted by the TypeMeta of the Metadata, and their keys follow Metadata.Key.
+type RegistryTxn interface {
+	// Get returns the spec of the entity. It returns ErrEntityNotFound if the entity is absent.
+	// Only the ModRevision of the readonly fields is assigned.
+	Get(metadata Metadata) (proto.Message, error)
+	// Put creates or updates the entity with the spec of the metadata.
+	Put(metadata Metadata) error
+	// Delete removes the entity. It returns false if the entity is absent.
+	Delete(metadata Metadata) (bool, error)
+}
+
+// Transactional lets a caller read and write several entities atomically with its own logic
+type Transactional interface {
+	// Transaction commits all the writes of fn at once if none of the entities read by fn are modified in the meantime.
+	// Otherwise, fn is invoked again against the latest entities, so it should have no side effects out of the RegistryTxn.
+	// Nothing is committed if fn returns an error.
+	Transaction(ctx context.Context, fn func(tx RegistryTxn) error) error
+}
+
+func (e *etcdSchemaRegistry) Transaction(ctx context.Context, fn func(tx RegistryTxn) error) error {
+	// the STM commits through the client directly, so the gate guards the whole transaction
+	if err := e.gate.enter(); err != nil {
+		return err
+	}
+	defer e.gate.leave()
+	// the timeout bounds all the attempts of the STM as a whole
+	abortCtx, cancel := e.withRequestTimeout(ctx)
+	defer cancel()
+	var txn *registryTxn
+	resp, err := e.backend.transact(abortCtx, func(stm txnStore) error {
+		// the changes of an aborted attempt are dropped along with it
+		txn = &registryTxn{
+			ctx:      ctx,
+			registry: e,
+			stm:      stm,
+			now:      time.Now(),
+			changes:  make(map[string]txnChange),
+		}
+		return fn(txn)
+	})
+	if err != nil {
+		return err
+	}
+	var notifyErr error
+	for _, key := range txn.keys {
+		change := txn.changes[key]
+		if change.deleted {
+			notifyErr = multierr.Append(notifyErr, e.notifyDelete(change.Metadata, resp.Header.GetRevision()))
+			continue
+		}
+		notifyErr = multierr.Append(notifyErr, e.notifyUpdate(change.Metadata, resp.Header.GetRevision()))
+	}
+	return notifyErr
+}
+
+type txnChange struct {
+	Metadata
+	deleted bool
+}
+
+type registryTxn struct {
+	ctx      context.Context
+	registry *etcdSchemaRegistry
+	stm      txnStore
+	now      time.Time
+	// changes holds the last change of each key, and keys keeps the order they are changed first
+	changes map[string]txnChange
+	keys    []string
+}
+
+func (t *registryTxn) Get(metadata Metadata) (proto.Message, error) {
+	key, err := t.registry.keyLayout.Key(metadata)
+	if err != nil {
+		return nil, err
+	}
+	return t.get(metadata.TypeMeta, key)
+}
+
+func (t *registryTxn) get(tm TypeMeta, key string) (proto.Message, error) {
+	val := t.stm.Get(key)
+	if val == "" {
+		return nil, errors.Wrapf(ErrEntityNotFound, "key %s", key)
+	}
+	message, err := tm.Unmarshal(nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = unmarshal([]byte(key), []byte(val), message); err != nil {
+		return nil, err
+	}
+	if messageWithMetadata, ok := message.(HasMetadata); ok && messageWithMetadata.GetMetadata() != nil {
+		// Assign readonly fields
+		messageWithMetadata.GetMetadata().ModRevision = t.stm.Rev(key)
+	}
+	return message, nil
+}
+
+func (t *registryTxn) Put(metadata Metadata) error {
+	key, err := t.registry.keyLayout.Key(metadata)
+	if err != nil {
+		return err
+	}
+	if err = checkSpec(metadata); err != nil {
+		return errors.WithMessagef(err, "key %s", key)
+	}
+	if metadata.Kind == KindIndexRule {
+		if err = checkIndexRule(metadata.Spec.(*databasev1.IndexRule)); err != nil {
+			return err
+		}
+	}
+	if metadata.Kind == KindIndexRuleBinding {
+		if err = t.registry.checkSubjectKind(t.ctx, metadata.Spec.(*databasev1.IndexRuleBinding), nil); err != nil {
+			return err
+		}
+	}
+	existing, err := t.get(metadata.TypeMeta, key)
+	if err != nil && !errors.Is(err, ErrEntityNotFound) {
+		return err
+	}
+	if existing != nil {
+		if err = t.registry.checkCompatibility(t.ctx, metadata, existing); err != nil {
+			return err
+		}
+		metadata.PrevSpec = existing
+	}
+	metadata.Spec = stampTime(metadata.Spec.(proto.Message), existing, t.now)
+	val, err := t.registry.marshal(metadata.Spec.(proto.Message))
+	if err != nil {
+		return err
+	}
+	t.stm.Put(key, string(val))
+	t.record(key, txnChange{Metadata: metadata})
+	return nil
+}
+
+func (t *registryTxn) Delete(metadata Metadata) (bool, error) {
+	key, err := t.registry.keyLayout.Key(metadata)
+	if err != nil {
+		return false, err
+	}
+	existing, err := t.get(metadata.TypeMeta, key)
+	if errors.Is(err, ErrEntityNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	t.stm.Del(key)
+	t.record(key, txnChange{
+		Metadata: Metadata{TypeMeta: metadata.TypeMeta, Spec: existing},
+		deleted:  true,
+	})
+	return true, nil
+}
+
+func (t *registryTxn) record(key string, change txnChange) {
+	prevChange, ok := t.changes[key]
+	if !ok {
+		t.keys = append(t.keys, key)
+		t.changes[key] = change
+		return
+	}
+	// the update is compared with the entity before the transaction
+	if !change.deleted {
+		if prevChange.deleted {
+			change.PrevSpec = prevChange.Spec
+		} else {
+			change.PrevSpec = prevChange.PrevSpec
+		}
+	}
+	t.changes[key] = change
+}
@@ -0,0 +1,105 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+func Test_Etcd_Transaction(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	req.NotNil(registry)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	ruleMeta := func(name string) Metadata {
+		return Metadata{TypeMeta: TypeMeta{Kind: KindIndexRule, Group: "default", Name: name}}
+	}
+	copyRule := func(tx RegistryTxn, from, to string) error {
+		spec, innerErr := tx.Get(ruleMeta(from))
+		if innerErr != nil {
+			return innerErr
+		}
+		rule := proto.Clone(spec).(*databasev1.IndexRule)
+		rule.Metadata = &commonv1.Metadata{Group: "default", Name: to}
+		md := ruleMeta(to)
+		md.Spec = rule
+		return tx.Put(md)
+	}
+
+	errAbort := errors.New("abort")
+	req.ErrorIs(registry.Transaction(context.TODO(), func(tx RegistryTxn) error {
+		if innerErr := copyRule(tx, "endpoint_id", "endpoint_id_v2"); innerErr != nil {
+			return innerErr
+		}
+		return errAbort
+	}), errAbort)
+	_, err = registry.GetIndexRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "endpoint_id_v2"})
+	req.ErrorIs(err, ErrEntityNotFound)
+
+	var attempts int
+	req.NoError(registry.Transaction(context.TODO(), func(tx RegistryTxn) error {
+		attempts++
+		if innerErr := copyRule(tx, "endpoint_id", "endpoint_id_v2"); innerErr != nil {
+			return innerErr
+		}
+		if attempts == 1 {
+			// modify the rule read by the transaction to make it retry
+			rule, innerErr := registry.GetIndexRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "endpoint_id"})
+			if innerErr != nil {
+				return innerErr
+			}
+			rule.Tags = []string{"endpoint_id", "service_id"}
+			if innerErr = registry.UpdateIndexRule(context.TODO(), rule); innerErr != nil {
+				return innerErr
+			}
+		}
+		deleted, innerErr := tx.Delete(ruleMeta("trace_id"))
+		if innerErr != nil {
+			return innerErr
+		}
+		if !deleted {
+			return errors.New("trace_id is absent")
+		}
+		return nil
+	}))
+	req.Equal(2, attempts)
+	r, err := registry.GetIndexRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "endpoint_id_v2"})
+	req.NoError(err)
+	req.Equal([]string{"endpoint_id", "service_id"}, r.GetTags())
+	_, err = registry.GetIndexRule(context.TODO(), &commonv1.Metadata{Group: "default", Name: "trace_id"})
+	req.ErrorIs(err, ErrEntityNotFound)
+
+	req.NoError(registry.Transaction(context.TODO(), func(tx RegistryTxn) error {
+		_, innerErr := tx.Get(ruleMeta("trace_id"))
+		req.ErrorIs(innerErr, ErrEntityNotFound)
+		deleted, innerErr := tx.Delete(ruleMeta("trace_id"))
+		req.False(deleted)
+		return innerErr
+	}))
+}
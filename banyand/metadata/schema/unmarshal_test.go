@@ -0,0 +1,135 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+// unmarshalCorpus returns the encoded entities of testdata and all their truncations
+func unmarshalCorpus(t *testing.T) map[Kind][][]byte {
+	req := require.New(t)
+	e := &etcdSchemaRegistry{checksum: true}
+	corpus := make(map[Kind][][]byte)
+	add := func(kind Kind, jsonData []byte, message proto.Message) {
+		req.NoError(protojson.Unmarshal(jsonData, message))
+		for _, checksum := range []bool{false, true} {
+			e.checksum = checksum
+			val, err := e.marshal(message)
+			req.NoError(err)
+			for i := 0; i <= len(val); i++ {
+				corpus[kind] = append(corpus[kind], val[:i])
+			}
+		}
+	}
+	add(KindGroup, []byte(groupJSON), &commonv1.Group{})
+	add(KindStream, []byte(streamJSON), &databasev1.Stream{})
+	add(KindIndexRuleBinding, []byte(indexRuleBindingJSON), &databasev1.IndexRuleBinding{})
+	entries, err := indexRuleStore.ReadDir(indexRuleDir)
+	req.NoError(err)
+	for _, entry := range entries {
+		data, err := indexRuleStore.ReadFile(indexRuleDir + "/" + entry.Name())
+		req.NoError(err)
+		add(KindIndexRule, data, &databasev1.IndexRule{})
+	}
+	return corpus
+}
+
+func checkUnmarshal(t *testing.T, kind Kind, raw []byte) {
+	key := []byte(fmt.Sprintf("/malformed/%d", kind))
+	message, err := TypeMeta{Kind: kind}.Unmarshal(nil)
+	require.NoError(t, err)
+	require.NotPanics(t, func() {
+		err = unmarshal(key, raw, message)
+	}, "raw %x", raw)
+	if err != nil {
+		require.ErrorIs(t, err, ErrCorruptEntity, "raw %x", raw)
+		require.Contains(t, err.Error(), string(key))
+	}
+}
+
+func Test_Unmarshal_Truncated(t *testing.T) {
+	for kind, values := range unmarshalCorpus(t) {
+		for _, raw := range values {
+			checkUnmarshal(t, kind, raw)
+		}
+	}
+}
+
+func Test_Unmarshal_Fuzz(t *testing.T) {
+	const rounds = 2000
+	r := rand.New(rand.NewSource(1))
+	for kind, values := range unmarshalCorpus(t) {
+		for i := 0; i < rounds; i++ {
+			raw := append([]byte{}, values[r.Intn(len(values))]...)
+			if len(raw) < 1 {
+				continue
+			}
+			// flip, drop or duplicate some random bytes
+			for n := r.Intn(4) + 1; n > 0 && len(raw) > 0; n-- {
+				j := r.Intn(len(raw))
+				switch r.Intn(3) {
+				case 0:
+					raw[j] ^= byte(r.Intn(255) + 1)
+				case 1:
+					raw = append(raw[:j], raw[j+1:]...)
+				default:
+					raw = append(raw[:j+1], raw[j:]...)
+				}
+			}
+			checkUnmarshal(t, kind, raw)
+		}
+	}
+}
+
+func Test_Etcd_Malformed(t *testing.T) {
+	req := require.New(t)
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	req.NoError(err)
+	defer registry.Close()
+	req.NoError(preloadSchema(registry))
+
+	meta := &commonv1.Metadata{Name: "sw", Group: "default"}
+	kv := registry.(*etcdSchemaRegistry).kv
+	key := registry.(*etcdSchemaRegistry).keyLayout.formatStreamKey(meta)
+	resp, err := kv.Get(context.TODO(), key)
+	req.NoError(err)
+	raw := resp.Kvs[0].Value
+
+	// an empty value and a value truncated in the middle of a field
+	for _, malformed := range [][]byte{{}, raw[:len(raw)/2]} {
+		_, err = kv.Put(context.TODO(), key, string(malformed))
+		req.NoError(err)
+		_, err = registry.GetStream(context.TODO(), meta)
+		req.ErrorIs(err, ErrCorruptEntity)
+		req.Contains(err.Error(), key)
+		_, err = registry.ListStream(context.TODO(), ListOpt{Group: "default"})
+		req.ErrorIs(err, ErrCorruptEntity)
+		req.Contains(err.Error(), key)
+	}
+}
@@ -0,0 +1,52 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// SchemaAPIVersion is the version of the schema API served by the registry.
+// Bump it whenever the registry gains a feature that clients may negotiate, e.g. field-mask updates.
+const SchemaAPIVersion = "1.0.0"
+
+var ErrNoEndpoint = errors.New("the registry has no endpoint")
+
+// RegistryVersion lets a client enable or disable the features based on what the registry supports
+type RegistryVersion struct {
+	// SchemaAPI is the SchemaAPIVersion of the registry
+	SchemaAPI string
+	// Etcd is the version of the embedded etcd server. It's empty if the registry isn't backed by etcd.
+	Etcd string
+}
+
+// Version reports the status of the embedded etcd server, so it fails if the server is unreachable.
+func (e *etcdSchemaRegistry) Version(ctx context.Context) (RegistryVersion, error) {
+	ctx, cancel := e.withRequestTimeout(ctx)
+	defer cancel()
+	v, err := e.backend.version(ctx)
+	if err != nil {
+		return RegistryVersion{}, err
+	}
+	return RegistryVersion{
+		SchemaAPI: SchemaAPIVersion,
+		Etcd:      v,
+	}, nil
+}
@@ -0,0 +1,119 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+)
+
+// GroupEventType tells how a group is changed
+type GroupEventType int
+
+const (
+	GroupCreated GroupEventType = iota
+	GroupUpdated
+	// GroupDeleted is emitted for the group itself. The deletions of its entities are never watched.
+	GroupDeleted
+)
+
+// GroupEvent is a change of a group. Group is the latest value, or the last one before the deletion.
+// It's nil if the value fails to decode, for example, it's corrupted.
+type GroupEvent struct {
+	Type     GroupEventType
+	Name     string
+	Group    *commonv1.Group
+	Revision int64
+}
+
+// WatchGroups only watches the keys of the groups, so the changes of the other entities never wake the receiver.
+// The channel is closed once ctx is done, the registry stops, or the watch fails, for example, the revision is compacted.
+// A receiver should list the groups again before watching them again, since the events in between are missed.
+func (e *etcdSchemaRegistry) WatchGroups(ctx context.Context) (<-chan GroupEvent, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	watchCh, err := e.backend.watch(ctx, e.keyLayout.GroupMetadataKeyPrefix, clientv3.WithPrefix(), clientv3.WithPrevKV())
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	ch := make(chan GroupEvent)
+	go func() {
+		defer close(ch)
+		defer cancel()
+		for {
+			var resp clientv3.WatchResponse
+			var ok bool
+			select {
+			case resp, ok = <-watchCh:
+			case <-e.StoppingNotify():
+				return
+			}
+			if !ok || resp.Err() != nil {
+				return
+			}
+			for _, ev := range resp.Events {
+				groupEvent, isGroup := e.groupEvent(ev)
+				if !isGroup {
+					continue
+				}
+				select {
+				case ch <- groupEvent:
+				case <-ctx.Done():
+					return
+				case <-e.StoppingNotify():
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// groupEvent is false if the key isn't a group's, which happens if a custom layout nests another prefix under the one of groups
+func (e *etcdSchemaRegistry) groupEvent(ev *clientv3.Event) (GroupEvent, bool) {
+	md, err := e.keyLayout.ParseKey(string(ev.Kv.Key))
+	if err != nil || md.Kind != KindGroup {
+		return GroupEvent{}, false
+	}
+	groupEvent := GroupEvent{
+		Type:     GroupUpdated,
+		Name:     md.Name,
+		Revision: ev.Kv.ModRevision,
+	}
+	kv := ev.Kv
+	switch {
+	case ev.Type == mvccpb.DELETE:
+		groupEvent.Type = GroupDeleted
+		kv = ev.PrevKv
+	case ev.IsCreate():
+		groupEvent.Type = GroupCreated
+	}
+	if kv == nil {
+		return groupEvent, true
+	}
+	var group commonv1.Group
+	if unmarshal(kv.Key, kv.Value, &group) == nil {
+		assignRevisions(&group, kv)
+		groupEvent.Group = &group
+	}
+	return groupEvent, true
+}
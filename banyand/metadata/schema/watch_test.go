@@ -0,0 +1,92 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+func nextGroupEvent(t *testing.T, ch <-chan GroupEvent) GroupEvent {
+	select {
+	case ev, ok := <-ch:
+		require.True(t, ok)
+		return ev
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no group event")
+	}
+	return GroupEvent{}
+}
+
+func Test_Etcd_WatchGroups(t *testing.T) {
+	registry, err := NewEtcdSchemaRegistry(useUnixDomain(), useRandomTempDir())
+	require.NoError(t, err)
+	defer registry.Close()
+	testWatchGroups(t, registry)
+}
+
+func testWatchGroups(t *testing.T, registry Registry) {
+	req := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := registry.WatchGroups(ctx)
+	req.NoError(err)
+
+	req.NoError(updateGroup(registry, "g1"))
+	ev := nextGroupEvent(t, ch)
+	req.Equal(GroupCreated, ev.Type)
+	req.Equal("g1", ev.Name)
+	req.Equal("g1", ev.Group.GetMetadata().GetName())
+	req.Equal(ev.Revision, ev.Group.GetMetadata().GetModRevision())
+
+	// the entities of the group are not watched
+	req.NoError(registry.UpdateStream(context.TODO(), &databasev1.Stream{
+		Metadata: &commonv1.Metadata{Name: "s", Group: "g1"},
+		TagFamilies: []*databasev1.TagFamilySpec{{
+			Name: "default",
+			Tags: []*databasev1.TagSpec{{Name: "id", Type: databasev1.TagType_TAG_TYPE_STRING}},
+		}},
+		Entity: &databasev1.Entity{TagNames: []string{"id"}},
+	}))
+	req.NoError(registry.UpdateGroup(context.TODO(), &commonv1.Group{
+		Metadata:     &commonv1.Metadata{Name: "g1"},
+		Catalog:      commonv1.Catalog_CATALOG_STREAM,
+		ResourceOpts: &commonv1.ResourceOpts{ShardNum: 2},
+	}))
+	ev = nextGroupEvent(t, ch)
+	req.Equal(GroupUpdated, ev.Type)
+	req.Equal(uint32(2), ev.Group.GetResourceOpts().GetShardNum())
+
+	_, err = registry.DeleteGroup(context.TODO(), "g1")
+	req.NoError(err)
+	ev = nextGroupEvent(t, ch)
+	req.Equal(GroupDeleted, ev.Type)
+	req.Equal("g1", ev.Name)
+	req.Equal(uint32(2), ev.Group.GetResourceOpts().GetShardNum())
+
+	cancel()
+	req.Eventually(func() bool {
+		_, ok := <-ch
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -22,6 +22,8 @@ import (
 	// Register pprof package
 	_ "net/http/pprof"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 	"github.com/apache/skywalking-banyandb/pkg/run"
 )
@@ -45,7 +47,7 @@ type pprofService struct {
 
 func (p *pprofService) FlagSet() *run.FlagSet {
 	flagSet := run.NewFlagSet("prof")
-	flagSet.StringVar(&p.listenAddr, "pprof-listener-addr", "127.0.0.1:6060", "listen addr for pprof")
+	flagSet.StringVar(&p.listenAddr, "pprof-listener-addr", "127.0.0.1:6060", "listen addr for pprof and the metrics")
 	return flagSet
 }
 
@@ -61,6 +63,7 @@ func (p *pprofService) Serve() run.StopNotify {
 	p.l = logger.GetLogger(p.Name())
 	go func() {
 		p.l.Info().Str("listenAddr", p.listenAddr).Msg("Start pprof server")
+		http.Handle("/metrics", promhttp.Handler())
 		_ = http.ListenAndServe(p.listenAddr, nil)
 	}()
 
@@ -21,6 +21,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/apache/skywalking-banyandb/api/event"
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
@@ -30,6 +32,7 @@ import (
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/pkg/encoding"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
 	resourceSchema "github.com/apache/skywalking-banyandb/pkg/schema"
 )
 
@@ -39,7 +42,8 @@ type schemaRepo struct {
 	metadata metadata.Repo
 }
 
-func newSchemaRepo(path string, metadata metadata.Repo, repo discovery.ServiceRepo, l *logger.Logger) schemaRepo {
+func newSchemaRepo(path string, metadata metadata.Repo, repo discovery.ServiceRepo, observer meter.MetricsObserver,
+	verifySeriesHint bool, l *logger.Logger) schemaRepo {
 	return schemaRepo{
 		l:        l,
 		metadata: metadata,
@@ -47,7 +51,8 @@ func newSchemaRepo(path string, metadata metadata.Repo, repo discovery.ServiceRe
 			metadata,
 			repo,
 			l,
-			newSupplier(path, metadata, l),
+			newSupplier(path, metadata, observer, verifySeriesHint, l),
+			commonv1.Catalog_CATALOG_STREAM,
 			event.StreamTopicShardEvent,
 			event.StreamTopicEntityEvent,
 		),
@@ -127,6 +132,9 @@ func (sr *schemaRepo) OnDelete(m schema.Metadata) {
 			Metadata: g.GetMetadata(),
 		})
 	case schema.KindStream:
+		if err := sr.dropIndex(m.Spec.(*databasev1.Stream).GetMetadata()); err != nil {
+			sr.l.Error().Err(err).Str("stream", m.Name).Msg("fail to drop the index")
+		}
 		sr.SendMetadataEvent(resourceSchema.MetadataEvent{
 			Typ:      resourceSchema.EventDelete,
 			Kind:     resourceSchema.EventKindResource,
@@ -140,6 +148,10 @@ func (sr *schemaRepo) OnDelete(m schema.Metadata) {
 				Group: m.Group,
 			})
 			cancel()
+			if errors.Is(err, schema.ErrEntityNotFound) {
+				// the subject is deleted along with the binding
+				return
+			}
 			if err != nil {
 				sr.l.Error().Err(err).Msg("fail to get subject")
 				return
@@ -155,6 +167,48 @@ func (sr *schemaRepo) OnDelete(m schema.Metadata) {
 	}
 }
 
+// dropIndex removes the index data of the deleted stream once none of the bindings refers to it,
+// for example, the stream is deleted by DeleteStreamCascade
+func (sr *schemaRepo) dropIndex(metadata *commonv1.Metadata) error {
+	s, ok := sr.loadStream(metadata)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	bindings, err := sr.metadata.IndexRuleBindingRegistry().ListIndexRuleBinding(ctx, schema.ListOpt{Group: metadata.GetGroup()})
+	cancel()
+	if err != nil {
+		return err
+	}
+	for _, binding := range bindings {
+		if binding.GetSubject().GetCatalog() == commonv1.Catalog_CATALOG_STREAM &&
+			binding.GetSubject().GetName() == metadata.GetName() {
+			return nil
+		}
+	}
+	return s.dropIndex()
+}
+
+// lookupStream returns ErrStreamNotRegistered with the group and name if the stream is unknown.
+// A stream missing in the repository is fetched from the registry, whose registration might not be handled yet.
+func (sr *schemaRepo) lookupStream(metadata *commonv1.Metadata) (*stream, error) {
+	if s, ok := sr.loadStream(metadata); ok {
+		return s, nil
+	}
+	r, err := sr.FetchResource(metadata)
+	if errors.Is(err, schema.ErrEntityNotFound) {
+		return nil, errors.Wrapf(ErrStreamNotRegistered, "group %s name %s", metadata.GetGroup(), metadata.GetName())
+	}
+	if err != nil {
+		return nil, err
+	}
+	s, ok := r.(*stream)
+	if !ok {
+		return nil, errors.Wrapf(ErrStreamNotRegistered, "group %s name %s", metadata.GetGroup(), metadata.GetName())
+	}
+	return s, nil
+}
+
 func (sr *schemaRepo) loadStream(metadata *commonv1.Metadata) (*stream, bool) {
 	r, ok := sr.LoadResource(metadata)
 	if !ok {
@@ -167,24 +221,33 @@ func (sr *schemaRepo) loadStream(metadata *commonv1.Metadata) (*stream, bool) {
 var _ resourceSchema.ResourceSupplier = (*supplier)(nil)
 
 type supplier struct {
-	path     string
-	metadata metadata.Repo
-	l        *logger.Logger
+	path             string
+	metadata         metadata.Repo
+	observer         meter.MetricsObserver
+	verifySeriesHint bool
+	l                *logger.Logger
 }
 
-func newSupplier(path string, metadata metadata.Repo, l *logger.Logger) *supplier {
+func newSupplier(path string, metadata metadata.Repo, observer meter.MetricsObserver, verifySeriesHint bool, l *logger.Logger) *supplier {
 	return &supplier{
-		path:     path,
-		metadata: metadata,
-		l:        l,
+		path:             path,
+		metadata:         metadata,
+		observer:         observer,
+		verifySeriesHint: verifySeriesHint,
+		l:                l,
 	}
 }
 
 func (s *supplier) OpenResource(shardNum uint32, db tsdb.Supplier, spec resourceSchema.ResourceSpec) (resourceSchema.Resource, error) {
 	streamSchema := spec.Schema.(*databasev1.Stream)
 	return openStream(shardNum, db, streamSpec{
-		schema:     streamSchema,
-		indexRules: spec.IndexRules,
+		schema:            streamSchema,
+		indexRules:        spec.IndexRules,
+		strictIndexing:    spec.StrictIndexing,
+		verifySeriesHint:  s.verifySeriesHint,
+		utf8Policy:        spec.UTF8Policy,
+		binaryCompression: spec.BinaryCompression,
+		observer:          s.observer,
 	}, s.l)
 }
 func (s *supplier) ResourceSchema(repo metadata.Repo, md *commonv1.Metadata) (resourceSchema.ResourceSchema, error) {
@@ -193,6 +256,20 @@ func (s *supplier) ResourceSchema(repo metadata.Repo, md *commonv1.Metadata) (re
 	return s.metadata.StreamRegistry().GetStream(ctx, md)
 }
 
+func (s *supplier) ResourceSchemas(repo metadata.Repo, group string) ([]resourceSchema.ResourceSchema, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	schemas, err := s.metadata.StreamRegistry().ListStream(ctx, schema.ListOpt{Group: group})
+	if err != nil {
+		return nil, err
+	}
+	rr := make([]resourceSchema.ResourceSchema, 0, len(schemas))
+	for _, sm := range schemas {
+		rr = append(rr, sm)
+	}
+	return rr, nil
+}
+
 func (s *supplier) OpenDB(groupSchema *commonv1.Group) (tsdb.Database, error) {
 	return tsdb.OpenDatabase(
 		context.TODO(),
@@ -203,5 +280,6 @@ func (s *supplier) OpenDB(groupSchema *commonv1.Group) (tsdb.Database, error) {
 				EncoderPool: encoding.NewPlainEncoderPool(chunkSize),
 				DecoderPool: encoding.NewPlainDecoderPool(chunkSize),
 			},
+			MetricsObserver: s.observer,
 		})
 }
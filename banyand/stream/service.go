@@ -26,11 +26,13 @@ import (
 
 	"github.com/apache/skywalking-banyandb/api/data"
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	streamv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/stream/v1"
 	"github.com/apache/skywalking-banyandb/banyand/discovery"
 	"github.com/apache/skywalking-banyandb/banyand/metadata"
 	"github.com/apache/skywalking-banyandb/banyand/metadata/schema"
 	"github.com/apache/skywalking-banyandb/banyand/queue"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
 	"github.com/apache/skywalking-banyandb/pkg/run"
 )
 
@@ -44,6 +46,7 @@ type Service interface {
 	run.Config
 	run.Service
 	Query
+	Writer
 }
 
 var _ Service = (*service)(nil)
@@ -56,6 +59,10 @@ type service struct {
 	root          string
 	pipeline      queue.Queue
 	repo          discovery.ServiceRepo
+	// observer receives the statistics of the databases if it's present
+	observer meter.MetricsObserver
+	// verifySeriesHint checks the series hints against the entity tags, which costs the series computation they save
+	verifySeriesHint bool
 	// stop channel for the service
 	stopCh chan struct{}
 }
@@ -68,9 +75,35 @@ func (s *service) Stream(metadata *commonv1.Metadata) (Stream, error) {
 	return sm, nil
 }
 
+func (s *service) Write(request *streamv1.WriteRequest) error {
+	sm, err := s.schemaRepo.lookupStream(request.GetMetadata())
+	if err != nil {
+		return err
+	}
+	return sm.Write(request.GetElement())
+}
+
+func (s *service) WriteBatch(requests []*streamv1.WriteRequest) error {
+	// the streams are looked up once per batch
+	streams := make(map[string]*stream)
+	return writeBatch(func(metadata *commonv1.Metadata) (*stream, error) {
+		key := metadata.GetGroup() + "/" + metadata.GetName()
+		if sm, ok := streams[key]; ok {
+			return sm, nil
+		}
+		sm, err := s.schemaRepo.lookupStream(metadata)
+		if err != nil {
+			return nil, err
+		}
+		streams[key] = sm
+		return sm, nil
+	}, requests)
+}
+
 func (s *service) FlagSet() *run.FlagSet {
 	flagS := run.NewFlagSet("storage")
 	flagS.StringVar(&s.root, "stream-root-path", "/tmp", "the root path of database")
+	flagS.BoolVar(&s.verifySeriesHint, "stream-verify-series-hint", false, "verify the series hints of the writes against their entity tags")
 	return flagS
 }
 
@@ -93,7 +126,7 @@ func (s *service) PreRun() error {
 	if err != nil {
 		return err
 	}
-	s.schemaRepo = newSchemaRepo(path.Join(s.root, s.Name()), s.metadata, s.repo, s.l)
+	s.schemaRepo = newSchemaRepo(path.Join(s.root, s.Name()), s.metadata, s.repo, s.observer, s.verifySeriesHint, s.l)
 	for _, g := range groups {
 		if g.Catalog != commonv1.Catalog_CATALOG_STREAM {
 			continue
@@ -144,8 +177,9 @@ func (s *service) GracefulStop() {
 }
 
 // NewService returns a new service
-func NewService(_ context.Context, metadata metadata.Repo, repo discovery.ServiceRepo, pipeline queue.Queue) (Service, error) {
+func NewService(ctx context.Context, metadata metadata.Repo, repo discovery.ServiceRepo, pipeline queue.Queue) (Service, error) {
 	return &service{
+		observer: meter.Fetch(ctx),
 		metadata: metadata,
 		repo:     repo,
 		pipeline: pipeline,
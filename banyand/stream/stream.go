@@ -25,6 +25,7 @@ import (
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb/index"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
 	"github.com/apache/skywalking-banyandb/pkg/partition"
 	pbv1 "github.com/apache/skywalking-banyandb/pkg/pb/v1"
 	"github.com/apache/skywalking-banyandb/pkg/schema"
@@ -48,6 +49,14 @@ type stream struct {
 	entityLocator          partition.EntityLocator
 	indexRules             []*databasev1.IndexRule
 	indexWriter            *index.Writer
+	// strictIndexing rejects the data which fails to be indexed
+	strictIndexing bool
+	// verifySeriesHint checks the hints of WriteWithHint against the entity tags
+	verifySeriesHint  bool
+	utf8Policy        commonv1.ResourceOpts_UTF8Policy
+	binaryCompression commonv1.Compression
+	// binaryFamilies marks the tag families which only hold binary tags
+	binaryFamilies []bool
 }
 
 func (s *stream) GetMetadata() *commonv1.Metadata {
@@ -74,29 +83,50 @@ func (s *stream) parseSpec() {
 	s.name, s.group = s.schema.GetMetadata().GetName(), s.schema.GetMetadata().GetGroup()
 	s.entityLocator = partition.NewEntityLocator(s.schema.GetTagFamilies(), s.schema.GetEntity())
 	s.maxObservedModRevision = pbv1.ParseMaxModRevision(s.indexRules)
+	s.binaryFamilies = make([]bool, len(s.schema.GetTagFamilies()))
+	for i, tf := range s.schema.GetTagFamilies() {
+		s.binaryFamilies[i] = len(tf.GetTags()) > 0
+		for _, tag := range tf.GetTags() {
+			if tag.GetType() != databasev1.TagType_TAG_TYPE_DATA_BINARY {
+				s.binaryFamilies[i] = false
+				break
+			}
+		}
+	}
 }
 
 type streamSpec struct {
-	schema     *databasev1.Stream
-	indexRules []*databasev1.IndexRule
+	schema            *databasev1.Stream
+	indexRules        []*databasev1.IndexRule
+	strictIndexing    bool
+	verifySeriesHint  bool
+	utf8Policy        commonv1.ResourceOpts_UTF8Policy
+	binaryCompression commonv1.Compression
+	observer          meter.MetricsObserver
 }
 
 func openStream(shardNum uint32, db tsdb.Supplier, spec streamSpec, l *logger.Logger) (*stream, error) {
 	sm := &stream{
-		shardNum:   shardNum,
-		schema:     spec.schema,
-		indexRules: spec.indexRules,
-		l:          l,
+		shardNum:          shardNum,
+		schema:            spec.schema,
+		indexRules:        spec.indexRules,
+		strictIndexing:    spec.strictIndexing,
+		verifySeriesHint:  spec.verifySeriesHint,
+		utf8Policy:        spec.utf8Policy,
+		binaryCompression: spec.binaryCompression,
+		l:                 l,
 	}
 	sm.parseSpec()
 	ctx := context.WithValue(context.Background(), logger.ContextKey, l)
 
 	sm.db = db
 	sm.indexWriter = index.NewWriter(ctx, index.WriterOptions{
-		DB:         db,
-		ShardNum:   shardNum,
-		Families:   spec.schema.TagFamilies,
-		IndexRules: spec.indexRules,
+		DB:              db,
+		ShardNum:        shardNum,
+		Families:        spec.schema.TagFamilies,
+		IndexRules:      spec.indexRules,
+		Subject:         spec.schema.GetMetadata(),
+		MetricsObserver: spec.observer,
 	})
 	return sm, nil
 }
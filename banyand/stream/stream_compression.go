@@ -0,0 +1,64 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package stream
+
+import (
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/pkg/encoding"
+)
+
+var ErrUnknownCompression = errors.New("unknown compression")
+
+// marshalTagFamily compresses the tag family if it only holds binary tags.
+// The compression of the element takes precedence over the one of the group.
+func (s *stream) marshalTagFamily(fi int, family *modelv1.TagFamilyForWrite, compression commonv1.Compression) ([]byte, error) {
+	bb, err := proto.Marshal(family)
+	if err != nil {
+		return nil, err
+	}
+	if fi >= len(s.binaryFamilies) || !s.binaryFamilies[fi] {
+		return bb, nil
+	}
+	if compression == commonv1.Compression_COMPRESSION_UNSPECIFIED {
+		compression = s.binaryCompression
+	}
+	switch compression {
+	case commonv1.Compression_COMPRESSION_UNSPECIFIED, commonv1.Compression_COMPRESSION_NONE:
+		return bb, nil
+	case commonv1.Compression_COMPRESSION_ZSTD:
+		return encoding.Compress(encoding.CodecZSTD, bb)
+	}
+	return nil, errors.Wrapf(ErrUnknownCompression, "compression %d", compression)
+}
+
+// unmarshalTagFamily decompresses the tag family with the codec recorded when it was written
+func unmarshalTagFamily(raw []byte) (*modelv1.TagFamilyForWrite, error) {
+	bb, err := encoding.Decompress(raw)
+	if err != nil {
+		return nil, err
+	}
+	tagFamily := &modelv1.TagFamilyForWrite{}
+	if err = proto.Unmarshal(bb, tagFamily); err != nil {
+		return nil, err
+	}
+	return tagFamily, nil
+}
@@ -0,0 +1,58 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package stream
+
+import (
+	"go.uber.org/multierr"
+
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+)
+
+// dropIndex removes the local index data of all the series of the stream.
+// The global index is kept, because its fields are shared by all the streams binding the rule.
+func (s *stream) dropIndex() (err error) {
+	shards, err := s.Shards(nil)
+	if err != nil {
+		return err
+	}
+	// every entry of the entity matches any value
+	entity := make(tsdb.Entity, len(s.schema.GetEntity().GetTagNames()))
+	for _, shard := range shards {
+		seriesList, errList := shard.Series().List(tsdb.NewPath(entity))
+		if errList != nil {
+			err = multierr.Append(err, errList)
+			continue
+		}
+		var fieldKeys []index.FieldKey
+		for _, series := range seriesList {
+			for _, rule := range s.indexRules {
+				if rule.GetLocation() == databasev1.IndexRule_LOCATION_GLOBAL {
+					continue
+				}
+				fieldKeys = append(fieldKeys, index.FieldKey{
+					SeriesID:    series.ID(),
+					IndexRuleID: rule.GetMetadata().GetId(),
+				})
+			}
+		}
+		err = multierr.Append(err, shard.DropIndex(fieldKeys))
+	}
+	return err
+}
@@ -21,7 +21,6 @@ import (
 	"io"
 
 	"github.com/pkg/errors"
-	"google.golang.org/protobuf/proto"
 
 	"github.com/apache/skywalking-banyandb/api/common"
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
@@ -43,14 +42,22 @@ type Query interface {
 type Stream interface {
 	io.Closer
 	Write(value *streamv1.ElementValue) error
+	WriteWithHint(value *streamv1.ElementValue, hint SeriesHint) error
 	Shards(entity tsdb.Entity) ([]tsdb.Shard, error)
 	Shard(id common.ShardID) (tsdb.Shard, error)
 	ParseTagFamily(family string, item tsdb.Item) (*modelv1.TagFamily, error)
 	ParseElementID(item tsdb.Item) (string, error)
+	// SamplingRates returns the rates of the rules indexing a fraction of the values, keyed by their names.
+	// A lookup by such a rule is best-effort.
+	SamplingRates() map[string]float64
 }
 
 var _ Stream = (*stream)(nil)
 
+func (s *stream) SamplingRates() map[string]float64 {
+	return s.indexWriter.SamplingRates()
+}
+
 func (s *stream) Shards(entity tsdb.Entity) ([]tsdb.Shard, error) {
 	wrap := func(shards []tsdb.Shard) []tsdb.Shard {
 		result := make([]tsdb.Shard, len(shards))
@@ -92,12 +99,10 @@ func (s *stream) ParseTagFamily(family string, item tsdb.Item) (*modelv1.TagFami
 	if err != nil {
 		return nil, errors.Wrapf(err, "parse family %s", family)
 	}
-	tagFamily := &modelv1.TagFamilyForWrite{}
-	err = proto.Unmarshal(familyRawBytes, tagFamily)
+	tagFamily, err := unmarshalTagFamily(familyRawBytes)
 	if err != nil {
 		return nil, err
 	}
-	tags := make([]*modelv1.Tag, len(tagFamily.GetTags()))
 	var tagSpec []*databasev1.TagSpec
 	for _, tf := range s.schema.GetTagFamilies() {
 		if tf.GetName() == family {
@@ -107,12 +112,17 @@ func (s *stream) ParseTagFamily(family string, item tsdb.Item) (*modelv1.TagFami
 	if tagSpec == nil {
 		return nil, ErrTagFamilyNotExist
 	}
-	for i, tag := range tagFamily.GetTags() {
+	// the tags absent from a short family, for example, a skipped one, are null
+	tags := make([]*modelv1.Tag, len(tagSpec))
+	written := tagFamily.GetTags()
+	for i, spec := range tagSpec {
+		value := &modelv1.TagValue{Value: &modelv1.TagValue_Null{}}
+		if i < len(written) {
+			value = &modelv1.TagValue{Value: written[i].GetValue()}
+		}
 		tags[i] = &modelv1.Tag{
-			Key: tagSpec[i].GetName(),
-			Value: &modelv1.TagValue{
-				Value: tag.GetValue(),
-			},
+			Key:   spec.GetName(),
+			Value: value,
 		}
 	}
 	return &modelv1.TagFamily{
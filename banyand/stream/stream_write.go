@@ -18,10 +18,18 @@
 package stream
 
 import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
 	"github.com/pkg/errors"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/apache/skywalking-banyandb/api/common"
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
 	streamv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/stream/v1"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb/index"
@@ -32,16 +40,72 @@ import (
 )
 
 var (
-	ErrMalformedElement = errors.New("element is malformed")
+	ErrMalformedElement    = errors.New("element is malformed")
+	ErrSeriesHintMismatch  = errors.New("series hint doesn't match the entity tags")
+	ErrStreamNotRegistered = errors.New("stream is not registered")
 )
 
+// Writer writes the elements to the streams registered in the schema.
+type Writer interface {
+	// Write fails with ErrStreamNotRegistered before validating the element if the stream is unknown.
+	Write(request *streamv1.WriteRequest) error
+	// WriteBatch writes the elements of several streams, which might belong to different groups, all at once.
+	// It returns a BatchError holding the failed elements, the others are written.
+	WriteBatch(requests []*streamv1.WriteRequest) error
+}
+
+// BatchError maps the index of an element in the batch to the error failing it.
+type BatchError map[int]error
+
+func (e BatchError) Error() string {
+	indices := make([]int, 0, len(e))
+	for i := range e {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	msgs := make([]string, 0, len(indices))
+	for _, i := range indices {
+		msgs = append(msgs, fmt.Sprintf("#%d: %v", i, e[i]))
+	}
+	return fmt.Sprintf("%d of the elements failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// SeriesHint is the precomputed location of an element.
+// It lets a caller that already knows the target shard and series skip the series computation.
+type SeriesHint struct {
+	ShardID    common.ShardID
+	SeriesHash []byte
+}
+
 func (s *stream) Write(value *streamv1.ElementValue) error {
 	entity, shardID, err := s.entityLocator.Locate(s.name, value.GetTagFamilies(), s.shardNum)
 	if err != nil {
 		return err
 	}
+	return s.writeAndWait(shardID, tsdb.HashEntity(entity), value)
+}
+
+// WriteWithHint writes an element to the shard and series denoted by the hint.
+// The hint is checked against the entity tags only if the stream verifies the series hints.
+func (s *stream) WriteWithHint(value *streamv1.ElementValue, hint SeriesHint) error {
+	if s.verifySeriesHint {
+		entity, shardID, err := s.entityLocator.Locate(s.name, value.GetTagFamilies(), s.shardNum)
+		if err != nil {
+			return err
+		}
+		if shardID != hint.ShardID {
+			return errors.Wrapf(ErrSeriesHintMismatch, "expected shard %d, got %d", shardID, hint.ShardID)
+		}
+		if seriesHash := tsdb.HashEntity(entity); !bytes.Equal(seriesHash, hint.SeriesHash) {
+			return errors.Wrapf(ErrSeriesHintMismatch, "expected series hash %x, got %x", seriesHash, hint.SeriesHash)
+		}
+	}
+	return s.writeAndWait(hint.ShardID, hint.SeriesHash, value)
+}
+
+func (s *stream) writeAndWait(shardID common.ShardID, seriesHashKey []byte, value *streamv1.ElementValue) error {
 	waitCh := make(chan struct{})
-	err = s.write(shardID, tsdb.HashEntity(entity), value, func() {
+	err := s.write(shardID, seriesHashKey, value, func() {
 		close(waitCh)
 	})
 	if err != nil {
@@ -52,14 +116,101 @@ func (s *stream) Write(value *streamv1.ElementValue) error {
 	return nil
 }
 
-func (s *stream) write(shardID common.ShardID, seriesHashKey []byte, value *streamv1.ElementValue, cb index.CallbackFn) error {
+// writeBatch sends the elements to their streams without waiting for each other, then waits for all of them.
+func writeBatch(lookup func(*commonv1.Metadata) (*stream, error), requests []*streamv1.WriteRequest) error {
+	var (
+		wg  sync.WaitGroup
+		mux sync.Mutex
+	)
+	batchErr := make(BatchError)
+	fail := func(i int, err error) {
+		mux.Lock()
+		batchErr[i] = err
+		mux.Unlock()
+	}
+	for i, req := range requests {
+		s, err := lookup(req.GetMetadata())
+		if err != nil {
+			fail(i, err)
+			continue
+		}
+		entity, shardID, err := s.entityLocator.Locate(s.name, req.GetElement().GetTagFamilies(), s.shardNum)
+		if err != nil {
+			fail(i, err)
+			continue
+		}
+		wg.Add(1)
+		if err = s.write(shardID, tsdb.HashEntity(entity), req.GetElement(), wg.Done); err != nil {
+			wg.Done()
+			fail(i, err)
+		}
+	}
+	wg.Wait()
+	if len(batchErr) > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+// ValidateWrite runs the checks of Write on the element, but neither persists nor indexes it.
+// The element is left untouched.
+func (s *stream) ValidateWrite(value *streamv1.ElementValue) error {
+	// the validation stamps the element and applies the UTF-8 policy
+	value, _ = proto.Clone(value).(*streamv1.ElementValue)
+	if _, _, err := s.entityLocator.Locate(s.name, value.GetTagFamilies(), s.shardNum); err != nil {
+		return err
+	}
+	return s.validate(value)
+}
+
+func (s *stream) validate(value *streamv1.ElementValue) error {
 	sm := s.schema
+	if value.GetTimestamp() == nil {
+		// the client asks the server to stamp the element
+		value.Timestamp = timestamppb.Now()
+	}
+	if err := value.GetTimestamp().CheckValid(); err != nil {
+		return errors.Wrap(ErrMalformedElement, err.Error())
+	}
 	fLen := len(value.GetTagFamilies())
 	if fLen < 1 {
 		return errors.Wrap(ErrMalformedElement, "no tag family")
 	}
-	if fLen > len(sm.TagFamilies) {
-		return errors.Wrap(ErrMalformedElement, "tag family number is more than expected")
+	if err := pbv1.CheckTagFamilyCount(value.GetTagFamilies(), sm.GetTagFamilies()); err != nil {
+		return err
+	}
+	if err := pbv1.ApplyUTF8Policy(value.GetTagFamilies(), s.utf8Policy); err != nil {
+		return err
+	}
+	for fi, family := range value.GetTagFamilies() {
+		familySpec := sm.GetTagFamilies()[fi]
+		if len(family.GetTags()) > len(familySpec.GetTags()) {
+			return errors.Wrap(ErrMalformedElement, "tag number is more than expected")
+		}
+		for ti, tag := range family.GetTags() {
+			tagSpec := familySpec.GetTags()[ti]
+			tType, isNull := pbv1.TagValueTypeConv(tag)
+			if isNull {
+				continue
+			}
+			if tType != tagSpec.GetType() {
+				return errors.Wrapf(ErrMalformedElement, "tag %s type is unexpected", tagSpec.GetName())
+			}
+		}
+	}
+	if s.strictIndexing {
+		return s.indexWriter.Check(index.Value{
+			TagFamilies: value.GetTagFamilies(),
+			Timestamp:   value.GetTimestamp().AsTime(),
+		})
+	}
+	return nil
+}
+
+func (s *stream) write(shardID common.ShardID, seriesHashKey []byte, value *streamv1.ElementValue, cb index.CallbackFn) error {
+	sm := s.schema
+	if err := s.validate(value); err != nil {
+		return err
 	}
 	shard, err := s.db.SupplyTSDB().Shard(shardID)
 	if err != nil {
@@ -80,21 +231,7 @@ func (s *stream) write(shardID common.ShardID, seriesHashKey []byte, value *stre
 	writeFn := func() (tsdb.Writer, error) {
 		builder := wp.WriterBuilder().Time(t)
 		for fi, family := range value.GetTagFamilies() {
-			familySpec := sm.GetTagFamilies()[fi]
-			if len(family.GetTags()) > len(familySpec.GetTags()) {
-				return nil, errors.Wrap(ErrMalformedElement, "tag number is more than expected")
-			}
-			for ti, tag := range family.GetTags() {
-				tagSpec := familySpec.GetTags()[ti]
-				tType, isNull := pbv1.TagValueTypeConv(tag)
-				if isNull {
-					continue
-				}
-				if tType != tagSpec.GetType() {
-					return nil, errors.Wrapf(ErrMalformedElement, "tag %s type is unexpected", tagSpec.GetName())
-				}
-			}
-			bb, errMarshal := proto.Marshal(family)
+			bb, errMarshal := s.marshalTagFamily(fi, family, value.GetCompression())
 			if errMarshal != nil {
 				return nil, errMarshal
 			}
@@ -132,6 +269,12 @@ func (s *stream) write(shardID common.ShardID, seriesHashKey []byte, value *stre
 		BlockCloser: wp,
 		Cb:          cb,
 	}
+	if s.indexWriter.StoresPayload() {
+		if m.Value.Payload, err = proto.Marshal(value); err != nil {
+			_ = wp.Close()
+			return err
+		}
+	}
 	s.indexWriter.Write(m)
 	return err
 }
@@ -155,12 +298,12 @@ func (w *writeCallback) Rev(message bus.Message) (resp bus.Message) {
 		w.l.Warn().Msg("invalid event data type")
 		return
 	}
-	stm, ok := w.schemaRepo.loadStream(writeEvent.GetRequest().GetMetadata())
-	if !ok {
-		w.l.Warn().Msg("cannot find stream definition")
+	stm, err := w.schemaRepo.lookupStream(writeEvent.GetRequest().GetMetadata())
+	if err != nil {
+		w.l.Warn().Err(err).Msg("cannot find stream definition")
 		return
 	}
-	err := stm.write(common.ShardID(writeEvent.GetShardId()), writeEvent.GetSeriesHash(), writeEvent.GetRequest().GetElement(), nil)
+	err = stm.write(common.ShardID(writeEvent.GetShardId()), writeEvent.GetSeriesHash(), writeEvent.GetRequest().GetElement(), nil)
 	if err != nil {
 		w.l.Debug().Err(err).Msg("fail to write entity")
 	}
@@ -18,15 +18,29 @@
 package stream
 
 import (
+	"context"
 	"encoding/base64"
+	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/api/event"
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
 	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
 	streamv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/stream/v1"
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
+	"github.com/apache/skywalking-banyandb/pkg/partition"
+	pbv1 "github.com/apache/skywalking-banyandb/pkg/pb/v1"
+	"github.com/apache/skywalking-banyandb/pkg/test"
 )
 
 var _ = Describe("Write", func() {
@@ -35,7 +49,7 @@ var _ = Describe("Write", func() {
 		deferFn func()
 	)
 
-	BeforeEach(func() {
+	setUpStream := func() {
 		var svcs *services
 		svcs, deferFn = setUp()
 		var ok bool
@@ -44,11 +58,7 @@ var _ = Describe("Write", func() {
 			Group: "default",
 		})
 		Expect(ok).To(BeTrue())
-	})
-
-	AfterEach(func() {
-		deferFn()
-	})
+	}
 
 	type args struct {
 		ele *streamv1.ElementValue
@@ -180,6 +190,11 @@ var _ = Describe("Write", func() {
 		},
 	}
 	Context("Writing stream", func() {
+		BeforeEach(setUpStream)
+		AfterEach(func() {
+			deferFn()
+		})
+
 		for _, tt := range tests {
 			It(tt.name, func() {
 				err := s.Write(tt.args.ele)
@@ -191,8 +206,415 @@ var _ = Describe("Write", func() {
 			})
 		}
 	})
+	// the stream is brought up once for the following specs, which leave it as they find it
+	Context("Checking stream", Ordered, func() {
+		BeforeAll(setUpStream)
+		AfterAll(func() {
+			deferFn()
+		})
+
+		Context("Validating stream", func() {
+			for _, tt := range tests {
+				It(tt.name, func() {
+					err := s.ValidateWrite(tt.args.ele)
+					if tt.wantErr {
+						Expect(err).Should(HaveOccurred())
+						return
+					}
+					Expect(err).ShouldNot(HaveOccurred())
+				})
+			}
+			It("leaves the element untouched", func() {
+				ele := getEle(
+					"trace_id-xxfff.111323",
+					0,
+					"webapp_id",
+					"10.0.0.1_id",
+				)
+				ele.Timestamp = nil
+				Expect(s.ValidateWrite(ele)).Should(Succeed())
+				Expect(ele.GetTimestamp()).Should(BeNil())
+			})
+			It("rejects a timestamp out of bounds", func() {
+				ele := getEle(
+					"trace_id-xxfff.111323",
+					0,
+					"webapp_id",
+					"10.0.0.1_id",
+				)
+				ele.Timestamp = &timestamppb.Timestamp{Seconds: -62135596801}
+				err := s.ValidateWrite(ele)
+				Expect(errors.Is(err, ErrMalformedElement)).Should(BeTrue())
+			})
+			It("names the missing entity tag", func() {
+				err := s.ValidateWrite(getEle(
+					nil,
+					1,
+					"webapp_id",
+				))
+				Expect(errors.Is(err, partition.ErrMissingEntityTag)).Should(BeTrue())
+			})
+		})
+		Context("Writing stream without an entity tag", func() {
+			It("names the missing entity tag", func() {
+				err := s.Write(getEle(
+					nil,
+					1,
+					"webapp_id",
+				))
+				Expect(errors.Is(err, partition.ErrMissingEntityTag)).Should(BeTrue())
+				Expect(err).Should(MatchError(ContainSubstring("service_instance_id")))
+			})
+
+			It("names the null entity tag", func() {
+				err := s.Write(getEle(
+					nil,
+					nil,
+					"webapp_id",
+					"10.0.0.1_id",
+				))
+				Expect(errors.Is(err, partition.ErrMissingEntityTag)).Should(BeTrue())
+				Expect(err).Should(MatchError(ContainSubstring("state")))
+			})
+		})
+		Context("Writing stream with a server-side timestamp", func() {
+			It("stamps the element on receipt", func() {
+				ele := getEle(
+					"trace_id-xxfff.111323",
+					0,
+					"webapp_id",
+					"10.0.0.1_id",
+				)
+				ele.Timestamp = nil
+				before := time.Now()
+				Expect(s.Write(ele)).Should(Succeed())
+				Expect(ele.GetTimestamp()).ShouldNot(BeNil())
+				Expect(ele.GetTimestamp().AsTime()).Should(BeTemporally(">=", before))
+			})
+		})
+		Context("Writing stream with an unindexable tag", func() {
+			var ele *streamv1.ElementValue
+
+			BeforeEach(func() {
+				ele = getEle(
+					nil,
+					1,
+					"webapp_id",
+					"10.0.0.1_id",
+				)
+			})
+
+			It("stores the element and skips the failed index rules", func() {
+				recorder := &failureRecorder{failures: make(map[string]float64)}
+				observed, err := openStream(s.shardNum, s.db, streamSpec{
+					schema:     s.schema,
+					indexRules: s.indexRules,
+					observer:   recorder,
+				}, s.l)
+				Expect(err).ShouldNot(HaveOccurred())
+				defer func() {
+					Expect(observed.Close()).Should(Succeed())
+				}()
+				Expect(observed.Write(ele)).Should(Succeed())
+				Eventually(recorder.observed, 10*time.Second).Should(HaveKeyWithValue("default/sw/trace_id", 1.0))
+			})
+
+			It("rejects the element in the strict indexing mode", func() {
+				s.strictIndexing = true
+				defer func() {
+					s.strictIndexing = false
+				}()
+				Expect(s.Write(ele)).Should(HaveOccurred())
+			})
+		})
+		Context("Writing stream with a compression hint", func() {
+			var ele *streamv1.ElementValue
+
+			BeforeEach(func() {
+				ele = getEle(
+					"trace_id-xxfff.111323",
+					0,
+					"webapp_id",
+					"10.0.0.1_id",
+				)
+				ele.Compression = commonv1.Compression_COMPRESSION_ZSTD
+			})
+
+			It("compresses the binary tag family only", func() {
+				raw, err := proto.Marshal(ele.GetTagFamilies()[0])
+				Expect(err).ShouldNot(HaveOccurred())
+				bb, err := s.marshalTagFamily(0, ele.GetTagFamilies()[0], ele.GetCompression())
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(bb).ShouldNot(Equal(raw))
+				family, err := unmarshalTagFamily(bb)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(proto.Equal(family, ele.GetTagFamilies()[0])).Should(BeTrue())
+
+				raw, err = proto.Marshal(ele.GetTagFamilies()[1])
+				Expect(err).ShouldNot(HaveOccurred())
+				bb, err = s.marshalTagFamily(1, ele.GetTagFamilies()[1], ele.GetCompression())
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(bb).Should(Equal(raw))
+			})
+
+			It("stores the compressed element", func() {
+				Expect(s.Write(ele)).Should(Succeed())
+			})
+		})
+		Context("Writing stream with a skipped tag family", func() {
+			var ele *streamv1.ElementValue
+
+			BeforeEach(func() {
+				ele = getEle(
+					"trace_id-xxfff.111323",
+					0,
+					"webapp_id",
+					"10.0.0.1_id",
+				)
+				ele.TagFamilies[0].Tags = nil
+			})
+
+			It("stores the element", func() {
+				Expect(s.Write(ele)).Should(Succeed())
+			})
+
+			It("reads the skipped tags as null", func() {
+				bb, err := s.marshalTagFamily(0, ele.GetTagFamilies()[0], ele.GetCompression())
+				Expect(err).ShouldNot(HaveOccurred())
+				family, err := s.ParseTagFamily("data", familyItem{"data": bb})
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(family.GetTags()).Should(HaveLen(1))
+				Expect(family.GetTags()[0].GetKey()).Should(Equal("data_binary"))
+				Expect(family.GetTags()[0].GetValue().GetNull()).ShouldNot(BeNil())
+			})
+		})
+		Context("Writing stream with a series hint", func() {
+			var ele *streamv1.ElementValue
+			var hint SeriesHint
+
+			BeforeEach(func() {
+				ele = getEle(
+					"trace_id-xxfff.111323",
+					0,
+					"webapp_id",
+					"10.0.0.1_id",
+				)
+				entity, shardID, err := s.entityLocator.Locate(s.name, ele.GetTagFamilies(), s.shardNum)
+				Expect(err).ShouldNot(HaveOccurred())
+				hint = SeriesHint{
+					ShardID:    shardID,
+					SeriesHash: tsdb.HashEntity(entity),
+				}
+				s.verifySeriesHint = true
+			})
+
+			AfterEach(func() {
+				s.verifySeriesHint = false
+			})
+
+			It("writes to the hinted series", func() {
+				Expect(s.WriteWithHint(ele, hint)).Should(Succeed())
+			})
+
+			It("rejects a mismatched series hash", func() {
+				hint.SeriesHash = tsdb.HashEntity(tsdb.Entity{tsdb.Entry("unknown")})
+				err := s.WriteWithHint(ele, hint)
+				Expect(err).Should(MatchError(ContainSubstring(ErrSeriesHintMismatch.Error())))
+				Expect(err).Should(MatchError(ContainSubstring("expected series hash")))
+			})
+
+			It("rejects a mismatched shard", func() {
+				hint.ShardID = (hint.ShardID + 1) % common.ShardID(s.shardNum)
+				err := s.WriteWithHint(ele, hint)
+				Expect(err).Should(MatchError(ContainSubstring(ErrSeriesHintMismatch.Error())))
+				Expect(err).Should(MatchError(ContainSubstring("expected shard")))
+			})
+		})
+		Context("Reporting the sampling rates", func() {
+			It("skips the rules indexing all the values", func() {
+				Expect(s.SamplingRates()).Should(BeEmpty())
+			})
+
+			It("reports the sampled rules", func() {
+				rule := proto.Clone(s.indexRules[0]).(*databasev1.IndexRule)
+				rule.SamplingRate = 0.5
+				sampled, err := openStream(s.shardNum, s.db, streamSpec{
+					schema:     s.schema,
+					indexRules: []*databasev1.IndexRule{rule},
+				}, s.l)
+				Expect(err).ShouldNot(HaveOccurred())
+				defer func() {
+					Expect(sampled.Close()).Should(Succeed())
+				}()
+				Expect(sampled.SamplingRates()).Should(Equal(map[string]float64{rule.GetMetadata().GetName(): 0.5}))
+			})
+		})
+	})
 })
 
+type failureRecorder struct {
+	sync.Mutex
+	failures map[string]float64
+}
+
+func (r *failureRecorder) Gauge(name string, value float64, labels meter.Labels) {
+	if name != "index_write_failures" {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.failures[labels["group"]+"/"+labels["name"]+"/"+labels["rule"]] = value
+}
+
+func (r *failureRecorder) observed() map[string]float64 {
+	r.Lock()
+	defer r.Unlock()
+	observed := make(map[string]float64, len(r.failures))
+	for k, v := range r.failures {
+		observed[k] = v
+	}
+	return observed
+}
+
+var _ = Describe("Write to the service", Ordered, func() {
+	var (
+		svcs    *services
+		deferFn func()
+	)
+
+	BeforeAll(func() {
+		svcs, deferFn = setUp()
+	})
+
+	AfterAll(func() {
+		deferFn()
+	})
+
+	It("writes to a registered stream", func() {
+		Expect(svcs.stream.Write(&streamv1.WriteRequest{
+			Metadata: &commonv1.Metadata{Name: "sw", Group: "default"},
+			Element:  getEle("trace_id-xxfff.111323", 0, "webapp_id", "10.0.0.1_id"),
+		})).Should(Succeed())
+	})
+
+	It("rejects an unregistered stream before validating the element", func() {
+		err := svcs.stream.Write(&streamv1.WriteRequest{
+			Metadata: &commonv1.Metadata{Name: "unknown", Group: "default"},
+		})
+		Expect(errors.Is(err, ErrStreamNotRegistered)).Should(BeTrue())
+		Expect(err).Should(MatchError(ContainSubstring("unknown")))
+	})
+
+	It("loads a registered stream missing in the repository on the first write", func() {
+		// the repository hasn't handled any event, like the one of a node joining the cluster
+		svcs.repo.EXPECT().Publish(event.StreamTopicShardEvent, test.NewShardEventMatcher(databasev1.Action_ACTION_PUT)).Times(2)
+		svcs.repo.EXPECT().Publish(event.StreamTopicEntityEvent, test.NewEntityEventMatcher(databasev1.Action_ACTION_PUT)).Times(1)
+		path, deferFunc, err := test.NewSpace()
+		Expect(err).NotTo(HaveOccurred())
+		sr := newSchemaRepo(path, svcs.metadataService, svcs.repo, nil, false, logger.GetLogger("test"))
+		defer func() {
+			sr.Close()
+			deferFunc()
+		}()
+		md := &commonv1.Metadata{Name: "sw", Group: "default"}
+		_, ok := sr.loadStream(md)
+		Expect(ok).To(BeFalse())
+		s, err := sr.lookupStream(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Write(getEle("trace_id-xxfff.111323", 0, "webapp_id", "10.0.0.1_id"))).Should(Succeed())
+		_, ok = sr.loadStream(md)
+		Expect(ok).To(BeTrue())
+
+		_, err = sr.lookupStream(&commonv1.Metadata{Name: "unknown", Group: "default"})
+		Expect(errors.Is(err, ErrStreamNotRegistered)).Should(BeTrue())
+		_, err = sr.lookupStream(&commonv1.Metadata{Name: "sw", Group: "unknown"})
+		Expect(errors.Is(err, ErrStreamNotRegistered)).Should(BeTrue())
+	})
+
+	It("catches up with the streams created during a maintenance", func() {
+		svcs.repo.EXPECT().Publish(event.StreamTopicEntityEvent, test.NewEntityEventMatcher(databasev1.Action_ACTION_PUT)).Times(1)
+		registry := svcs.metadataService.SchemaRegistry()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		sw, err := registry.GetStream(ctx, &commonv1.Metadata{Name: "sw", Group: "default"})
+		Expect(err).NotTo(HaveOccurred())
+		md := &commonv1.Metadata{Name: "sw_maintenance", Group: "default"}
+		registry.BeginMaintenance()
+		Expect(registry.UpdateStream(ctx, &databasev1.Stream{
+			Metadata:    md,
+			TagFamilies: sw.GetTagFamilies(),
+			Entity:      sw.GetEntity(),
+		})).Should(Succeed())
+		Consistently(func() bool {
+			_, ok := svcs.stream.schemaRepo.loadStream(md)
+			return ok
+		}, time.Second).Should(BeFalse())
+		Expect(registry.EndMaintenance()).Should(Succeed())
+		Eventually(func() bool {
+			_, ok := svcs.stream.schemaRepo.loadStream(md)
+			return ok
+		}, 10*time.Second).Should(BeTrue())
+	})
+
+	It("reports an empty element of a registered stream", func() {
+		err := svcs.stream.Write(&streamv1.WriteRequest{
+			Metadata: &commonv1.Metadata{Name: "sw", Group: "default"},
+		})
+		Expect(err).Should(HaveOccurred())
+		Expect(errors.Is(err, ErrStreamNotRegistered)).Should(BeFalse())
+	})
+
+	It("writes a batch and attributes the failures to their indices", func() {
+		b := pbv1.NewStreamWriteBatchBuilder()
+		b.Add(&streamv1.WriteRequest{
+			Metadata: &commonv1.Metadata{Name: "sw", Group: "default"},
+			Element:  getEle("trace_id-1", 0, "webapp_id", "10.0.0.1_id"),
+		})
+		b.Add(&streamv1.WriteRequest{
+			Metadata: &commonv1.Metadata{Name: "unknown", Group: "default"},
+		})
+		b.Add(&streamv1.WriteRequest{
+			Metadata: &commonv1.Metadata{Name: "sw", Group: "default"},
+			Element:  getEle("trace_id-2", 1, "webapp_id", "10.0.0.2_id"),
+		})
+		err := svcs.stream.WriteBatch(b.Build())
+		var batchErr BatchError
+		Expect(errors.As(err, &batchErr)).Should(BeTrue())
+		Expect(batchErr).Should(HaveLen(1))
+		Expect(errors.Is(batchErr[1], ErrStreamNotRegistered)).Should(BeTrue())
+		Expect(err).Should(MatchError(ContainSubstring("#1")))
+	})
+
+	It("writes a batch without any failure", func() {
+		Expect(svcs.stream.WriteBatch([]*streamv1.WriteRequest{
+			{
+				Metadata: &commonv1.Metadata{Name: "sw", Group: "default"},
+				Element:  getEle("trace_id-1", 0, "webapp_id", "10.0.0.1_id"),
+			},
+			{
+				Metadata: &commonv1.Metadata{Name: "sw", Group: "default"},
+				Element:  getEle("trace_id-2", 1, "webapp_id", "10.0.0.2_id"),
+			},
+		})).Should(Succeed())
+	})
+})
+
+type familyItem map[string][]byte
+
+func (f familyItem) Family(family string) ([]byte, error) {
+	return f[family], nil
+}
+
+func (f familyItem) Val() ([]byte, error) { return nil, nil }
+
+func (f familyItem) ID() common.ItemID { return 0 }
+
+func (f familyItem) SortedField() []byte { return nil }
+
+func (f familyItem) Time() uint64 { return 0 }
+
+func (f familyItem) Payload() ([]byte, error) { return nil, nil }
+
 func getEle(tags ...interface{}) *streamv1.ElementValue {
 	searchableTags := make([]*modelv1.TagValue, 0)
 	for _, tag := range tags {
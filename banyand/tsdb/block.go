@@ -26,6 +26,7 @@ import (
 
 	"github.com/dgraph-io/ristretto/z"
 	"go.uber.org/atomic"
+	"go.uber.org/multierr"
 
 	"github.com/apache/skywalking-banyandb/api/common"
 	"github.com/apache/skywalking-banyandb/banyand/kv"
@@ -168,6 +169,32 @@ func (b *block) isClosed() bool {
 	return b.closed.Load()
 }
 
+func (b *block) indexStats() map[string]index.Stats {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if b.isClosed() {
+		return nil
+	}
+	return map[string]index.Stats{
+		"primary":  b.primaryIndex.Stats(),
+		"inverted": b.invertedIndex.Stats(),
+		"lsm":      b.lsmIndex.Stats(),
+	}
+}
+
+func (b *block) dropIndex(fieldKeys []index.FieldKey) (err error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if b.isClosed() {
+		return nil
+	}
+	for _, key := range fieldKeys {
+		err = multierr.Append(err, b.invertedIndex.DropField(key))
+		err = multierr.Append(err, b.lsmIndex.DropField(key))
+	}
+	return err
+}
+
 func (b *block) String() string {
 	return b.Reporter.String()
 }
@@ -179,7 +206,11 @@ type blockDelegate interface {
 	writePrimaryIndex(field index.Field, id common.ItemID) error
 	writeLSMIndex(field index.Field, id common.ItemID) error
 	writeInvertedIndex(field index.Field, id common.ItemID) error
+	deleteLSMIndex(field index.Field, id common.ItemID) error
+	deleteInvertedIndex(field index.Field, id common.ItemID) error
+	writePayload(docID common.ItemID, payload []byte) error
 	dataReader() kv.TimeSeriesReader
+	payloadReader() index.PayloadStore
 	lsmIndexReader() index.Searcher
 	invertedIndexReader() index.Searcher
 	primaryIndexReader() index.Searcher
@@ -240,6 +271,37 @@ func (d *bDelegate) writeInvertedIndex(field index.Field, id common.ItemID) erro
 	return d.delegate.invertedIndex.Write(field, id)
 }
 
+func (d *bDelegate) deleteLSMIndex(field index.Field, id common.ItemID) error {
+	if d.delegate.lsmIndex == nil {
+		return nil
+	}
+	return d.delegate.lsmIndex.DeleteTerm(field, id)
+}
+
+func (d *bDelegate) deleteInvertedIndex(field index.Field, id common.ItemID) error {
+	if d.delegate.invertedIndex == nil {
+		return nil
+	}
+	return d.delegate.invertedIndex.DeleteTerm(field, id)
+}
+
+func (d *bDelegate) writePayload(docID common.ItemID, payload []byte) error {
+	payloads := d.payloadReader()
+	if payloads == nil {
+		return nil
+	}
+	return payloads.SetDocPayload(docID, payload)
+}
+
+// payloadReader returns nil if the inverted index doesn't store payloads
+func (d *bDelegate) payloadReader() index.PayloadStore {
+	payloads, ok := d.delegate.invertedIndex.(index.PayloadStore)
+	if !ok {
+		return nil
+	}
+	return payloads
+}
+
 func (d *bDelegate) contains(ts time.Time) bool {
 	return d.delegate.Contains(uint64(ts.UnixNano()))
 }
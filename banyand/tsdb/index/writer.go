@@ -26,11 +26,13 @@ import (
 	"go.uber.org/multierr"
 
 	"github.com/apache/skywalking-banyandb/api/common"
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
 	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/pkg/index"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
 	"github.com/apache/skywalking-banyandb/pkg/partition"
 	pbv1 "github.com/apache/skywalking-banyandb/pkg/pb/v1"
 )
@@ -43,11 +45,16 @@ type Message struct {
 	LocalWriter tsdb.Writer
 	BlockCloser io.Closer
 	Cb          CallbackFn
+	// Overwritten is the value of the item which Value replaces. The item is removed from its local index terms
+	// which Value doesn't carry. The global index keeps them, since it can't remove a single item from a term.
+	Overwritten *Value
 }
 
 type Value struct {
 	TagFamilies []*modelv1.TagFamilyForWrite
 	Timestamp   time.Time
+	// Payload is the serialized item, which is stored only if any of the series rules asks for it
+	Payload []byte
 }
 
 type WriterOptions struct {
@@ -55,6 +62,10 @@ type WriterOptions struct {
 	Families   []*databasev1.TagFamilySpec
 	IndexRules []*databasev1.IndexRule
 	DB         tsdb.Supplier
+	// Subject is the stream or the measure the writer indexes, which labels the metrics
+	Subject *commonv1.Metadata
+	// MetricsObserver receives the number of the failures of each index rule if it's present
+	MetricsObserver meter.MetricsObserver
 }
 
 type Writer struct {
@@ -63,6 +74,11 @@ type Writer struct {
 	shardNum       uint32
 	ch             chan Message
 	indexRuleIndex []*partition.IndexRuleLocator
+	storesPayload  bool
+	observer       meter.MetricsObserver
+	// failures are only counted by the index generator, and ruleLabels are the labels of the rules in the same order
+	failures   []uint64
+	ruleLabels []meter.Labels
 }
 
 func NewWriter(ctx context.Context, options WriterOptions) *Writer {
@@ -76,6 +92,21 @@ func NewWriter(ctx context.Context, options WriterOptions) *Writer {
 	w.shardNum = options.ShardNum
 	w.db = options.DB
 	w.indexRuleIndex = partition.ParseIndexRuleLocators(options.Families, options.IndexRules)
+	w.observer = options.MetricsObserver
+	w.failures = make([]uint64, len(w.indexRuleIndex))
+	w.ruleLabels = make([]meter.Labels, 0, len(w.indexRuleIndex))
+	for _, ruleIndex := range w.indexRuleIndex {
+		w.ruleLabels = append(w.ruleLabels, meter.Labels{
+			"group": options.Subject.GetGroup(),
+			"name":  options.Subject.GetName(),
+			"rule":  ruleIndex.Rule.GetMetadata().GetName(),
+		})
+	}
+	for _, rule := range options.IndexRules {
+		if rule.GetStorePayload() && rule.GetLocation() == databasev1.IndexRule_LOCATION_SERIES {
+			w.storesPayload = true
+		}
+	}
 	w.ch = make(chan Message)
 	w.bootIndexGenerator()
 	return w
@@ -92,11 +123,52 @@ func (s *Writer) Write(value Message) {
 	}(value)
 }
 
+// WriteSync indexes the message before it returns. Write doesn't keep the order of the messages,
+// so the messages of an item which overwrite each other have to be indexed by WriteSync under the lock of the item.
+func (s *Writer) WriteSync(m Message) {
+	s.index(m)
+}
+
+// Check verifies every index rule is able to index the value.
+func (s *Writer) Check(value Value) error {
+	for _, ruleIndex := range s.indexRuleIndex {
+		if _, _, err := getIndexValue(ruleIndex, value); err != nil && !errors.Is(err, pbv1.ErrNullTagSkipped) {
+			return err
+		}
+	}
+	return nil
+}
+
+// StoresPayload tells whether the caller should fill Value.Payload
+func (s *Writer) StoresPayload() bool {
+	return s.storesPayload
+}
+
+// SamplingRates returns the effective sampling rates of the rules indexing a fraction of the values, keyed by their names.
+// A lookup by such a rule is best-effort.
+func (s *Writer) SamplingRates() map[string]float64 {
+	rates := make(map[string]float64)
+	for _, ruleIndex := range s.indexRuleIndex {
+		if rate := index.SamplingRate(ruleIndex.Rule); rate < 1 {
+			rates[ruleIndex.Rule.GetMetadata().GetName()] = rate
+		}
+	}
+	return rates
+}
+
 func (s *Writer) Close() error {
 	close(s.ch)
 	return nil
 }
 
+// observeFailure counts a value the rule failed to index, and hands the total to the observer
+func (s *Writer) observeFailure(rule int) {
+	s.failures[rule]++
+	if s.observer != nil {
+		s.observer.Gauge("index_write_failures", float64(s.failures[rule]), s.ruleLabels[rule])
+	}
+}
+
 func (s *Writer) bootIndexGenerator() {
 	go func() {
 		for {
@@ -104,33 +176,67 @@ func (s *Writer) bootIndexGenerator() {
 			if !more {
 				return
 			}
-			var err error
-			for _, ruleIndex := range s.indexRuleIndex {
-				rule := ruleIndex.Rule
-				switch rule.GetLocation() {
-				case databasev1.IndexRule_LOCATION_SERIES:
-					err = multierr.Append(err, writeLocalIndex(m.LocalWriter, ruleIndex, m.Value))
-				case databasev1.IndexRule_LOCATION_GLOBAL:
-					err = multierr.Append(err, s.writeGlobalIndex(m.Scope, ruleIndex, m.LocalWriter.ItemID(), m.Value))
-				}
-			}
-			err = multierr.Append(err, m.BlockCloser.Close())
-			if err != nil {
-				s.l.Error().Err(err).Msg("encounter some errors when generating indices")
-			}
-			if m.Cb != nil {
-				m.Cb()
-			}
+			s.index(m)
 		}
 	}()
 }
 
+func (s *Writer) index(m Message) {
+	var err error
+	for i, ruleIndex := range s.indexRuleIndex {
+		rule := ruleIndex.Rule
+		var errIndex error
+		switch rule.GetLocation() {
+		case databasev1.IndexRule_LOCATION_SERIES:
+			if m.Overwritten != nil {
+				errIndex = deleteLocalIndex(m.LocalWriter, ruleIndex, *m.Overwritten, m.Value)
+			}
+			errIndex = multierr.Append(errIndex, writeLocalIndex(m.LocalWriter, ruleIndex, m.Value))
+		case databasev1.IndexRule_LOCATION_GLOBAL:
+			errIndex = s.writeGlobalIndex(m.Scope, ruleIndex, m.LocalWriter.ItemID(), m.Value)
+		}
+		if errIndex != nil {
+			// the data has been stored, skip the failed rule
+			s.observeFailure(i)
+			err = multierr.Append(err, errIndex)
+		}
+	}
+	if s.storesPayload && m.Value.Payload != nil {
+		if errPayload := m.LocalWriter.WritePayload(m.Value.Payload); errPayload != nil {
+			err = multierr.Append(err, errPayload)
+		}
+	}
+	if err != nil {
+		s.l.Warn().Err(err).Msg("skip some index rules when generating indices")
+	}
+	if errClose := m.BlockCloser.Close(); errClose != nil {
+		s.l.Error().Err(errClose).Msg("fail to close the block")
+	}
+	if m.Cb != nil {
+		m.Cb()
+	}
+}
+
 //TODO: should listen to pipeline in a distributed cluster
 func (s *Writer) writeGlobalIndex(scope tsdb.Entry, ruleIndex *partition.IndexRuleLocator, ref tsdb.GlobalItemID, value Value) error {
-	val, _, err := getIndexValue(ruleIndex, value)
+	val, tagType, err := getIndexValue(ruleIndex, value)
+	if errors.Is(err, pbv1.ErrNullTagSkipped) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
+	if !index.Sampled(ruleIndex.Rule, val) {
+		return nil
+	}
+	var errs error
+	for _, term := range analyze(ruleIndex, val, tagType) {
+		errs = multierr.Append(errs, s.writeGlobalTerm(scope, ruleIndex.Rule, ref, value.Timestamp, term))
+	}
+	return errs
+}
+
+func (s *Writer) writeGlobalTerm(scope tsdb.Entry, rule *databasev1.IndexRule, ref tsdb.GlobalItemID, ts time.Time, val []byte) error {
 	indexShardID, err := partition.ShardID(val, s.shardNum)
 	if err != nil {
 		return err
@@ -143,17 +249,17 @@ func (s *Writer) writeGlobalIndex(scope tsdb.Entry, ruleIndex *partition.IndexRu
 	indexWriter, err := builder.
 		Scope(scope).
 		GlobalItemID(ref).
-		Time(value.Timestamp).
+		Time(ts).
 		Build()
 	if err != nil {
 		return err
 	}
-	rule := ruleIndex.Rule
 	switch rule.GetType() {
 	case databasev1.IndexRule_TYPE_INVERTED:
 		return indexWriter.WriteInvertedIndex(index.Field{
 			Key: index.FieldKey{
-				IndexRuleID: rule.GetMetadata().GetId(),
+				IndexRuleID:      rule.GetMetadata().GetId(),
+				PostingBlockSize: int(rule.GetPostingBlockSize()),
 			},
 			Term: val,
 		})
@@ -169,19 +275,29 @@ func (s *Writer) writeGlobalIndex(scope tsdb.Entry, ruleIndex *partition.IndexRu
 }
 
 func writeLocalIndex(writer tsdb.Writer, ruleIndex *partition.IndexRuleLocator, value Value) (err error) {
-	val, _, err := getIndexValue(ruleIndex, value)
+	val, tagType, err := getIndexValue(ruleIndex, value)
+	if errors.Is(err, pbv1.ErrNullTagSkipped) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
+	if !index.Sampled(ruleIndex.Rule, val) {
+		return nil
+	}
 	rule := ruleIndex.Rule
 	switch rule.GetType() {
 	case databasev1.IndexRule_TYPE_INVERTED:
-		return writer.WriteInvertedIndex(index.Field{
-			Key: index.FieldKey{
-				IndexRuleID: rule.GetMetadata().GetId(),
-			},
-			Term: val,
-		})
+		for _, term := range analyze(ruleIndex, val, tagType) {
+			err = multierr.Append(err, writer.WriteInvertedIndex(index.Field{
+				Key: index.FieldKey{
+					IndexRuleID:      rule.GetMetadata().GetId(),
+					PostingBlockSize: int(rule.GetPostingBlockSize()),
+				},
+				Term: term,
+			}))
+		}
+		return err
 	case databasev1.IndexRule_TYPE_TREE:
 		return writer.WriteLSMIndex(index.Field{
 			Key: index.FieldKey{
@@ -193,25 +309,78 @@ func writeLocalIndex(writer tsdb.Writer, ruleIndex *partition.IndexRuleLocator,
 	return err
 }
 
-func getIndexValue(ruleIndex *partition.IndexRuleLocator, value Value) (val []byte, isInt bool, err error) {
+// deleteLocalIndex removes the item from the terms of the overwritten value which the new value doesn't carry.
+// The shared terms are kept, since the item is written to them again.
+func deleteLocalIndex(writer tsdb.Writer, ruleIndex *partition.IndexRuleLocator, overwritten, value Value) (err error) {
+	kept := make(map[string]struct{})
+	for _, term := range localTerms(ruleIndex, value) {
+		kept[string(term)] = struct{}{}
+	}
+	rule := ruleIndex.Rule
+	for _, term := range localTerms(ruleIndex, overwritten) {
+		if _, ok := kept[string(term)]; ok {
+			continue
+		}
+		switch rule.GetType() {
+		case databasev1.IndexRule_TYPE_INVERTED:
+			err = multierr.Append(err, writer.DeleteInvertedIndex(index.Field{
+				Key: index.FieldKey{
+					IndexRuleID:      rule.GetMetadata().GetId(),
+					PostingBlockSize: int(rule.GetPostingBlockSize()),
+				},
+				Term: term,
+			}))
+		case databasev1.IndexRule_TYPE_TREE:
+			err = multierr.Append(err, writer.DeleteLSMIndex(index.Field{
+				Key: index.FieldKey{
+					IndexRuleID: rule.GetMetadata().GetId(),
+				},
+				Term: term,
+			}))
+		}
+	}
+	return err
+}
+
+// localTerms returns the terms which writeLocalIndex writes the value to. It's empty if the value isn't indexed by the rule.
+func localTerms(ruleIndex *partition.IndexRuleLocator, value Value) [][]byte {
+	val, tagType, err := getIndexValue(ruleIndex, value)
+	if err != nil || !index.Sampled(ruleIndex.Rule, val) {
+		return nil
+	}
+	if ruleIndex.Rule.GetType() == databasev1.IndexRule_TYPE_TREE {
+		return [][]byte{val}
+	}
+	return analyze(ruleIndex, val, tagType)
+}
+
+// analyze tokenizes the value of a single string tag by the analyzer of the inverted index rule.
+// The values of the other types, for example, the order-preserving float terms, are kept as a single term.
+func analyze(ruleIndex *partition.IndexRuleLocator, val []byte, tagType databasev1.TagType) [][]byte {
+	rule := ruleIndex.Rule
+	if tagType != databasev1.TagType_TAG_TYPE_STRING || rule.GetType() != databasev1.IndexRule_TYPE_INVERTED {
+		return [][]byte{val}
+	}
+	return index.NewAnalyzer(rule.GetAnalyzer()).Analyze(val)
+}
+
+// getIndexValue returns the term of the rule and the type of its tag.
+// The type is TAG_TYPE_UNSPECIFIED if the rule is composed of multiple tags or the tag is null.
+func getIndexValue(ruleIndex *partition.IndexRuleLocator, value Value) (val []byte, tagType databasev1.TagType, err error) {
 	val = make([]byte, 0, len(ruleIndex.TagIndices))
-	var existInt bool
 	for _, tIndex := range ruleIndex.TagIndices {
 		tag, err := partition.GetTagByOffset(value.TagFamilies, tIndex.FamilyOffset, tIndex.TagOffset)
 		if err != nil {
-			return nil, false, errors.WithMessagef(err, "index rule:%v", ruleIndex.Rule.Metadata)
+			return nil, databasev1.TagType_TAG_TYPE_UNSPECIFIED, errors.WithMessagef(err, "index rule:%v", ruleIndex.Rule.Metadata)
 		}
-		if tag.GetInt() != nil {
-			existInt = true
+		if len(ruleIndex.TagIndices) == 1 {
+			tagType, _ = pbv1.TagValueTypeConv(tag)
 		}
-		v, err := pbv1.MarshalIndexFieldValue(tag)
+		v, err := pbv1.MarshalIndexFieldValue(tag, ruleIndex.Rule.GetNullPolicy())
 		if err != nil {
-			return nil, false, err
+			return nil, databasev1.TagType_TAG_TYPE_UNSPECIFIED, errors.WithMessagef(err, "index rule:%v", ruleIndex.Rule.Metadata)
 		}
 		val = append(val, v...)
 	}
-	if len(ruleIndex.TagIndices) == 1 && existInt {
-		return val, true, nil
-	}
-	return val, false, nil
+	return val, tagType, nil
 }
@@ -0,0 +1,159 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+	"github.com/apache/skywalking-banyandb/pkg/index/inverted"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/partition"
+	pbv1 "github.com/apache/skywalking-banyandb/pkg/pb/v1"
+	"github.com/apache/skywalking-banyandb/pkg/test"
+)
+
+// storeWriter writes the inverted index of an item into a store
+type storeWriter struct {
+	tsdb.Writer
+	store  index.Store
+	itemID common.ItemID
+}
+
+func (w *storeWriter) WriteInvertedIndex(field index.Field) error {
+	return w.store.Write(field, w.itemID)
+}
+
+func (w *storeWriter) DeleteInvertedIndex(field index.Field) error {
+	return w.store.DeleteTerm(field, w.itemID)
+}
+
+func TestWriteLocalIndex_FloatWithStandardAnalyzer(t *testing.T) {
+	tester := assert.New(t)
+	is := require.New(t)
+	is.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	path, fn := test.Space(is)
+	defer fn()
+	store, err := inverted.NewStore(inverted.StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	is.NoError(err)
+	defer func() {
+		tester.NoError(store.Close())
+	}()
+	rule := &databasev1.IndexRule{
+		Metadata: &commonv1.Metadata{Id: 1, Name: "latency", Group: "default"},
+		Tags:     []string{"latency"},
+		Type:     databasev1.IndexRule_TYPE_INVERTED,
+		Analyzer: databasev1.IndexRule_ANALYZER_STANDARD,
+	}
+	ruleIndex := &partition.IndexRuleLocator{
+		Rule:       rule,
+		TagIndices: []partition.TagLocator{{FamilyOffset: 0, TagOffset: 0}},
+	}
+	values := []float64{-100.5, -1, -0.001, 0, 0.5, 3, 1e10}
+	for i, v := range values {
+		w := &storeWriter{store: store, itemID: common.ItemID(i)}
+		is.NoError(writeLocalIndex(w, ruleIndex, Value{
+			TagFamilies: []*modelv1.TagFamilyForWrite{{Tags: []*modelv1.TagValue{
+				{Value: &modelv1.TagValue_Float{Float: &modelv1.Float{Value: v}}},
+			}}},
+		}))
+	}
+	fieldKey := index.FieldKey{IndexRuleID: rule.GetMetadata().GetId()}
+	list, err := store.Range(fieldKey, index.RangeOpts{
+		Lower: pbv1.MarshalFloat(-1), Upper: pbv1.MarshalFloat(1),
+		IncludesLower: true, IncludesUpper: true,
+	})
+	is.NoError(err)
+	got := list.ToSlice()
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	tester.Equal([]common.ItemID{1, 2, 3, 4}, got)
+	for i, v := range values {
+		list, err = store.MatchTerms(index.Field{Key: fieldKey, Term: pbv1.MarshalFloat(v)})
+		is.NoError(err)
+		tester.Equal([]common.ItemID{common.ItemID(i)}, list.ToSlice(), "value %v", v)
+	}
+}
+
+func TestDeleteLocalIndex(t *testing.T) {
+	tester := assert.New(t)
+	is := require.New(t)
+	is.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	path, fn := test.Space(is)
+	defer fn()
+	store, err := inverted.NewStore(inverted.StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	is.NoError(err)
+	defer func() {
+		tester.NoError(store.Close())
+	}()
+	rule := &databasev1.IndexRule{
+		Metadata: &commonv1.Metadata{Id: 1, Name: "endpoint", Group: "default"},
+		Tags:     []string{"endpoint"},
+		Type:     databasev1.IndexRule_TYPE_INVERTED,
+		Analyzer: databasev1.IndexRule_ANALYZER_STANDARD,
+	}
+	ruleIndex := &partition.IndexRuleLocator{
+		Rule:       rule,
+		TagIndices: []partition.TagLocator{{FamilyOffset: 0, TagOffset: 0}},
+	}
+	value := func(endpoint string) Value {
+		return Value{
+			TagFamilies: []*modelv1.TagFamilyForWrite{{Tags: []*modelv1.TagValue{
+				{Value: &modelv1.TagValue_Str{Str: &modelv1.Str{Value: endpoint}}},
+			}}},
+		}
+	}
+	match := func(term string) []common.ItemID {
+		list, errMatch := store.MatchTerms(index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte(term)})
+		is.NoError(errMatch)
+		got := list.ToSlice()
+		sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+		return got
+	}
+	w1, w2 := &storeWriter{store: store, itemID: 1}, &storeWriter{store: store, itemID: 2}
+	is.NoError(writeLocalIndex(w1, ruleIndex, value("get users")))
+	is.NoError(writeLocalIndex(w2, ruleIndex, value("get orders")))
+
+	// the newer value of item 1 drops "users", and keeps "get" shared with the overwritten value
+	is.NoError(deleteLocalIndex(w1, ruleIndex, value("get users"), value("get items")))
+	is.NoError(writeLocalIndex(w1, ruleIndex, value("get items")))
+	tester.Empty(match("users"))
+	tester.Equal([]common.ItemID{1, 2}, match("get"))
+	tester.Equal([]common.ItemID{1}, match("items"))
+	tester.Equal([]common.ItemID{2}, match("orders"))
+}
@@ -17,7 +17,10 @@
 //
 package tsdb
 
-import "github.com/apache/skywalking-banyandb/api/common"
+import (
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+)
 
 var _ Shard = (*ScopedShard)(nil)
 
@@ -57,6 +60,10 @@ func (sd *ScopedShard) State() ShardState {
 	return sd.delegated.State()
 }
 
+func (sd *ScopedShard) DropIndex(fieldKeys []index.FieldKey) error {
+	return sd.delegated.DropIndex(fieldKeys)
+}
+
 var _ SeriesDatabase = (*scopedSeriesDatabase)(nil)
 
 type scopedSeriesDatabase struct {
@@ -103,6 +103,7 @@ func (s *series) Get(id GlobalItemID) (Item, io.Closer, error) {
 		data:     b.dataReader(),
 		itemID:   id.ID,
 		seriesID: s.id,
+		payloads: b.payloadReader(),
 	}, b, nil
 }
 
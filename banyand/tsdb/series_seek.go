@@ -18,6 +18,8 @@
 package tsdb
 
 import (
+	"github.com/pkg/errors"
+
 	"github.com/apache/skywalking-banyandb/api/common"
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
 	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
@@ -37,6 +39,9 @@ type Item interface {
 	ID() common.ItemID
 	SortedField() []byte
 	Time() uint64
+	// Payload returns the serialized item stored in the index. It fails with index.ErrPayloadNotFound
+	// if none of the index rules stores payloads.
+	Payload() ([]byte, error)
 }
 
 type SeekerBuilder interface {
@@ -58,6 +63,8 @@ type seekerBuilder struct {
 	conditions []struct {
 		indexRuleType databasev1.IndexRule_Type
 		indexRuleID   uint32
+		aliasOf       uint32
+		comparator    string
 		condition     Condition
 	}
 	order               modelv1.Sort
@@ -109,6 +116,7 @@ type item struct {
 	data        kv.TimeSeriesReader
 	seriesID    common.SeriesID
 	sortedField []byte
+	payloads    index.PayloadStore
 }
 
 func (i *item) Time() uint64 {
@@ -134,6 +142,13 @@ func (i *item) Val() ([]byte, error) {
 	return i.data.Get(d.marshal(), uint64(i.itemID))
 }
 
+func (i *item) Payload() ([]byte, error) {
+	if i.payloads == nil {
+		return nil, errors.WithStack(index.ErrPayloadNotFound)
+	}
+	return i.payloads.GetDocPayload(payloadDocID(i.seriesID, i.itemID))
+}
+
 func (i *item) ID() common.ItemID {
 	return i.itemID
 }
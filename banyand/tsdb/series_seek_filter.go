@@ -33,10 +33,14 @@ func (s *seekerBuilder) Filter(indexRule *databasev1.IndexRule, condition Condit
 	s.conditions = append(s.conditions, struct {
 		indexRuleType databasev1.IndexRule_Type
 		indexRuleID   uint32
+		aliasOf       uint32
+		comparator    string
 		condition     Condition
 	}{
 		indexRuleType: indexRule.GetType(),
 		indexRuleID:   indexRule.GetMetadata().GetId(),
+		aliasOf:       indexRule.GetAliasOf(),
+		comparator:    indexRule.GetComparator(),
 		condition:     condition,
 	})
 	return s
@@ -61,6 +65,8 @@ func (s *seekerBuilder) buildConditions() ([]condWithIRT, error) {
 		term := index.FieldKey{
 			SeriesID:    s.seriesSpan.seriesID,
 			IndexRuleID: condition.indexRuleID,
+			Comparator:  condition.comparator,
+			AliasOf:     condition.aliasOf,
 		}
 		for _, c := range condition.condition {
 			cond[term] = c
@@ -69,6 +69,8 @@ func (s *seekerBuilder) buildSeriesByIndex(conditions []condWithIRT) (series []I
 		fieldKey := index.FieldKey{
 			SeriesID:    s.seriesSpan.seriesID,
 			IndexRuleID: s.indexRuleForSorting.GetMetadata().GetId(),
+			Comparator:  s.indexRuleForSorting.GetComparator(),
+			AliasOf:     s.indexRuleForSorting.GetAliasOf(),
 		}
 		filters := []filterFn{timeFilter}
 		filter, err := s.buildIndexFilter(b, conditions)
@@ -88,7 +90,7 @@ func (s *seekerBuilder) buildSeriesByIndex(conditions []condWithIRT) (series []I
 			return nil, err
 		}
 		if inner != nil {
-			series = append(series, newSearcherIterator(s.seriesSpan.l, inner, b.dataReader(), s.seriesSpan.seriesID, filters))
+			series = append(series, newSearcherIterator(s.seriesSpan.l, inner, b.dataReader(), b.payloadReader(), s.seriesSpan.seriesID, filters))
 		}
 	}
 	return
@@ -134,9 +136,9 @@ func (s *seekerBuilder) buildSeriesByTime(conditions []condWithIRT) ([]Iterator,
 				return nil, err
 			}
 			if filter == nil {
-				delegated = append(delegated, newSearcherIterator(s.seriesSpan.l, inner, b.dataReader(), s.seriesSpan.seriesID, emptyFilters))
+				delegated = append(delegated, newSearcherIterator(s.seriesSpan.l, inner, b.dataReader(), b.payloadReader(), s.seriesSpan.seriesID, emptyFilters))
 			} else {
-				delegated = append(delegated, newSearcherIterator(s.seriesSpan.l, inner, b.dataReader(), s.seriesSpan.seriesID, []filterFn{filter}))
+				delegated = append(delegated, newSearcherIterator(s.seriesSpan.l, inner, b.dataReader(), b.payloadReader(), s.seriesSpan.seriesID, []filterFn{filter}))
 			}
 		}
 	}
@@ -156,6 +158,7 @@ type searcherIterator struct {
 	curKey        []byte
 	cur           posting.Iterator
 	data          kv.TimeSeriesReader
+	payloads      index.PayloadStore
 	seriesID      common.SeriesID
 	filters       []filterFn
 	l             *logger.Logger
@@ -192,6 +195,7 @@ func (s *searcherIterator) Val() Item {
 		sortedField: s.curKey,
 		itemID:      s.cur.Current(),
 		data:        s.data,
+		payloads:    s.payloads,
 		seriesID:    s.seriesID,
 	}
 }
@@ -201,10 +205,11 @@ func (s *searcherIterator) Close() error {
 }
 
 func newSearcherIterator(l *logger.Logger, fieldIterator index.FieldIterator, data kv.TimeSeriesReader,
-	seriesID common.SeriesID, filters []filterFn) Iterator {
+	payloads index.PayloadStore, seriesID common.SeriesID, filters []filterFn) Iterator {
 	return &searcherIterator{
 		fieldIterator: fieldIterator,
 		data:          data,
+		payloads:      payloads,
 		seriesID:      seriesID,
 		filters:       filters,
 		l:             l,
@@ -38,6 +38,12 @@ type WriterBuilder interface {
 
 type Writer interface {
 	IndexWriter
+	// DeleteLSMIndex and DeleteInvertedIndex remove the item from a term of the local index,
+	// for example, the former value of a tag which a newer data point overwrites
+	DeleteLSMIndex(field index.Field) error
+	DeleteInvertedIndex(field index.Field) error
+	// WritePayload stores the serialized item in the index, which Item.Payload returns
+	WritePayload(payload []byte) error
 	Write() (GlobalItemID, error)
 	ItemID() GlobalItemID
 	String() string
@@ -153,6 +159,28 @@ func (w *writer) WriteInvertedIndex(field index.Field) error {
 	return w.block.writeInvertedIndex(field, w.itemID.ID)
 }
 
+func (w *writer) DeleteLSMIndex(field index.Field) error {
+	field.Key.SeriesID = w.itemID.SeriesID
+	return w.block.deleteLSMIndex(field, w.itemID.ID)
+}
+
+func (w *writer) DeleteInvertedIndex(field index.Field) error {
+	field.Key.SeriesID = w.itemID.SeriesID
+	return w.block.deleteInvertedIndex(field, w.itemID.ID)
+}
+
+func (w *writer) WritePayload(payload []byte) error {
+	return w.block.writePayload(payloadDocID(w.itemID.SeriesID, w.itemID.ID), payload)
+}
+
+// payloadDocID identifies the item in the index of the block, which is shared by all the series
+func payloadDocID(seriesID common.SeriesID, itemID common.ItemID) common.ItemID {
+	return common.ItemID(convert.Hash(bytes.Join([][]byte{
+		seriesID.Marshal(),
+		convert.Uint64ToBytes(uint64(itemID)),
+	}, nil)))
+}
+
 func (w *writer) String() string {
 	var buf []byte
 	buf = append(buf, "block:"...)
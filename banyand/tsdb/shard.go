@@ -24,9 +24,11 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 
 	"github.com/apache/skywalking-banyandb/api/common"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb/bucket"
+	"github.com/apache/skywalking-banyandb/pkg/index"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 	"github.com/apache/skywalking-banyandb/pkg/timestamp"
 )
@@ -112,14 +114,29 @@ func (s *shard) State() (shardState ShardState) {
 					SegID:   b.segID,
 					BlockID: b.blockID,
 				},
-				TimeRange: b.TimeRange,
-				Closed:    b.isClosed(),
+				TimeRange:  b.TimeRange,
+				Closed:     b.isClosed(),
+				IndexStats: b.indexStats(),
 			})
 		}
 	}
 	return shardState
 }
 
+// DropIndex opens the closed blocks, since the fields are stored in all of them
+func (s *shard) DropIndex(fieldKeys []index.FieldKey) (err error) {
+	if len(fieldKeys) < 1 {
+		return nil
+	}
+	for _, seg := range s.segmentController.segments() {
+		bc := seg.blockController
+		for _, b := range bc.ensureBlockOpen(bc.blocks()) {
+			err = multierr.Append(err, b.dropIndex(fieldKeys))
+		}
+	}
+	return err
+}
+
 func (s *shard) Close() error {
 	s.segmentManageStrategy.Close()
 	s.segmentController.close()
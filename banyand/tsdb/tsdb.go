@@ -27,13 +27,16 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
 
 	"github.com/apache/skywalking-banyandb/api/common"
 	"github.com/apache/skywalking-banyandb/pkg/encoding"
+	"github.com/apache/skywalking-banyandb/pkg/index"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
 	"github.com/apache/skywalking-banyandb/pkg/timestamp"
 )
 
@@ -56,6 +59,8 @@ const (
 	blockDayFormat    = "0102"
 
 	dirPerm = 0700
+
+	defaultStatsInterval = time.Minute
 )
 
 var (
@@ -82,6 +87,8 @@ type Shard interface {
 	Series() SeriesDatabase
 	Index() IndexDatabase
 	State() ShardState
+	// DropIndex removes the fields from the inverted and lsm indices of all the blocks
+	DropIndex(fieldKeys []index.FieldKey) error
 }
 
 var _ Database = (*database)(nil)
@@ -92,6 +99,9 @@ type DatabaseOpts struct {
 	EncodingMethod EncodingMethod
 	SegmentSize    IntervalRule
 	BlockSize      IntervalRule
+	// MetricsObserver receives the statistics of indices periodically if it's present
+	MetricsObserver meter.MetricsObserver
+	StatsInterval   time.Duration
 }
 
 type EncodingMethod struct {
@@ -108,6 +118,8 @@ type BlockState struct {
 	ID        BlockID
 	TimeRange timestamp.TimeRange
 	Closed    bool
+	// IndexStats is the statistics of indices in an opened block
+	IndexStats map[string]index.Stats
 }
 type ShardState struct {
 	OpenedBlocks []BlockState
@@ -120,7 +132,10 @@ type database struct {
 	segmentSize IntervalRule
 	blockSize   IntervalRule
 
-	sLst []Shard
+	sLst   []Shard
+	stopCh chan struct{}
+	// statsWG waits for the observation of the statistics to stop
+	statsWG sync.WaitGroup
 	sync.Mutex
 }
 
@@ -136,6 +151,11 @@ func (d *database) Shard(id common.ShardID) (Shard, error) {
 }
 
 func (d *database) Close() error {
+	if d.stopCh != nil {
+		close(d.stopCh)
+		// the shards are closed after the last observation
+		d.statsWG.Wait()
+	}
 	var err error
 	for _, s := range d.sLst {
 		innerErr := s.Close()
@@ -185,10 +205,54 @@ func OpenDatabase(ctx context.Context, opts DatabaseOpts) (Database, error) {
 	}
 	thisContext := context.WithValue(ctx, logger.ContextKey, db.logger)
 	thisContext = context.WithValue(thisContext, encodingMethodKey, opts.EncodingMethod)
+	var result Database
 	if len(entries) > 0 {
-		return loadDatabase(thisContext, db)
+		result, err = loadDatabase(thisContext, db)
+	} else {
+		result, err = initDatabase(thisContext, db)
+	}
+	if err != nil {
+		return result, err
+	}
+	if opts.MetricsObserver != nil {
+		interval := opts.StatsInterval
+		if interval <= 0 {
+			interval = defaultStatsInterval
+		}
+		db.stopCh = make(chan struct{})
+		db.statsWG.Add(1)
+		go db.observeStats(opts.MetricsObserver, interval)
+	}
+	return result, nil
+}
+
+func (d *database) observeStats(observer meter.MetricsObserver, interval time.Duration) {
+	defer d.statsWG.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.observe(observer)
+		case <-d.stopCh:
+			return
+		}
+	}
+}
+
+func (d *database) observe(observer meter.MetricsObserver) {
+	for _, s := range d.sLst {
+		for _, bs := range s.State().OpenedBlocks {
+			for name, stats := range bs.IndexStats {
+				index.ObserveStats(observer, stats, meter.Labels{
+					"shard":   strconv.Itoa(int(s.ID())),
+					"segment": strconv.Itoa(int(bs.ID.SegID)),
+					"block":   strconv.Itoa(int(bs.ID.BlockID)),
+					"index":   name,
+				})
+			}
+		}
 	}
-	return initDatabase(thisContext, db)
 }
 
 func initDatabase(ctx context.Context, db *database) (Database, error) {
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 
 	"github.com/apache/skywalking-banyandb/pkg/encoding"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
 	"github.com/apache/skywalking-banyandb/pkg/test"
 )
 
@@ -54,6 +56,64 @@ func TestReOpenDatabase(t *testing.T) {
 	verifyDatabaseStructure(tester, tempDir)
 }
 
+type statsRecorder struct {
+	sync.Mutex
+	shards map[string]int
+}
+
+func (r *statsRecorder) Gauge(name string, _ float64, labels meter.Labels) {
+	if name != "index_segment_count" {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.shards[labels["shard"]]++
+}
+
+func (r *statsRecorder) observed() int {
+	r.Lock()
+	defer r.Unlock()
+	n := 0
+	for _, c := range r.shards {
+		n += c
+	}
+	return n
+}
+
+func TestObserveStats(t *testing.T) {
+	req := require.New(t)
+	tempDir, deferFunc := test.Space(req)
+	defer deferFunc()
+	req.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	recorder := &statsRecorder{shards: make(map[string]int)}
+	db, err := OpenDatabase(
+		context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")),
+		DatabaseOpts{
+			Location: tempDir,
+			ShardNum: 2,
+			EncodingMethod: EncodingMethod{
+				EncoderPool: encoding.NewPlainEncoderPool(0),
+				DecoderPool: encoding.NewPlainDecoderPool(0),
+			},
+			MetricsObserver: recorder,
+			StatsInterval:   10 * time.Millisecond,
+		})
+	req.NoError(err)
+	req.Eventually(func() bool {
+		recorder.Lock()
+		defer recorder.Unlock()
+		return recorder.shards["0"] > 0 && recorder.shards["1"] > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	req.NoError(db.Close())
+	// the observation stops along with the database
+	observed := recorder.observed()
+	time.Sleep(50 * time.Millisecond)
+	req.Equal(observed, recorder.observed())
+}
+
 func verifyDatabaseStructure(tester *assert.Assertions, tempDir string) {
 	shardPath := fmt.Sprintf(shardTemplate, tempDir, 0)
 	validateDirectory(tester, shardPath)
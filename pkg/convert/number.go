@@ -19,6 +19,7 @@ package convert
 
 import (
 	"encoding/binary"
+	"math"
 )
 
 func Uint64ToBytes(u uint64) []byte {
@@ -41,6 +42,17 @@ func Int64ToBytes(i int64) []byte {
 	return Uint64ToBytes(u)
 }
 
+// Float64ToBytes encodes a float64 into 8 bytes whose byte-wise order matches the numeric order
+func Float64ToBytes(f float64) []byte {
+	u := math.Float64bits(f)
+	if u&(1<<63) == 0 {
+		u = u | 1<<63
+	} else {
+		u = ^u
+	}
+	return Uint64ToBytes(u)
+}
+
 func Uint16ToBytes(u uint16) []byte {
 	bs := make([]byte, 2)
 	binary.BigEndian.PutUint16(bs, u)
@@ -67,6 +79,16 @@ func BytesToInt64(b []byte) int64 {
 	return abs
 }
 
+func BytesToFloat64(b []byte) float64 {
+	u := binary.BigEndian.Uint64(b)
+	if u&(1<<63) != 0 {
+		u = u ^ 1<<63
+	} else {
+		u = ^u
+	}
+	return math.Float64frombits(u)
+}
+
 func BytesToUint64(b []byte) uint64 {
 	return binary.BigEndian.Uint64(b)
 }
@@ -0,0 +1,63 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encoding
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Codec denotes how a block of bytes is compressed
+type Codec byte
+
+const (
+	CodecNone Codec = iota
+	CodecZSTD
+)
+
+// compressedMark leads the compressed bytes. A protobuf message never starts with it
+// because zero is not a valid field number, which keeps the uncompressed values readable.
+const compressedMark byte = 0
+
+var ErrUnknownCodec = errors.New("unknown codec")
+
+// Compress compresses src with the codec, and records the codec ahead of the result.
+// It returns src as it is if the codec is CodecNone.
+func Compress(codec Codec, src []byte) ([]byte, error) {
+	switch codec {
+	case CodecNone:
+		return src, nil
+	case CodecZSTD:
+		dst := make([]byte, 2, len(src)/2+2)
+		dst[0], dst[1] = compressedMark, byte(codec)
+		return zstdEncoder.EncodeAll(src, dst), nil
+	}
+	return nil, errors.Wrapf(ErrUnknownCodec, "codec %d", codec)
+}
+
+// Decompress reverts Compress with the codec recorded in src.
+// The bytes which are not compressed are returned as they are.
+func Decompress(src []byte) ([]byte, error) {
+	if len(src) < 2 || src[0] != compressedMark {
+		return src, nil
+	}
+	switch Codec(src[1]) {
+	case CodecZSTD:
+		return zstdDecoder.DecodeAll(src[2:], nil)
+	}
+	return nil, errors.Wrapf(ErrUnknownCodec, "codec %d", src[1])
+}
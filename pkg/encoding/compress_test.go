@@ -0,0 +1,53 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encoding
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	src := bytes.Repeat([]byte("\x0a\x12span data"), 100)
+	tests := []struct {
+		name  string
+		codec Codec
+	}{
+		{name: "none", codec: CodecNone},
+		{name: "zstd", codec: CodecZSTD},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed, err := Compress(tt.codec, src)
+			require.NoError(t, err)
+			if tt.codec != CodecNone {
+				assert.Less(t, len(compressed), len(src))
+			}
+			got, err := Decompress(compressed)
+			require.NoError(t, err)
+			assert.Equal(t, src, got)
+		})
+	}
+	_, err := Decompress([]byte{compressedMark, 0xff, 0x01})
+	assert.ErrorIs(t, err, ErrUnknownCodec)
+	_, err = Compress(Codec(0xff), src)
+	assert.ErrorIs(t, err, ErrUnknownCodec)
+}
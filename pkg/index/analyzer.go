@@ -0,0 +1,88 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"bytes"
+	"unicode"
+
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+// Analyzer splits a value into terms. Each term is indexed as a separate posting.
+type Analyzer interface {
+	Analyze(value []byte) [][]byte
+}
+
+var (
+	_ Analyzer = (*keywordAnalyzer)(nil)
+	_ Analyzer = (*whitespaceAnalyzer)(nil)
+	_ Analyzer = (*standardAnalyzer)(nil)
+
+	KeywordAnalyzer    Analyzer = &keywordAnalyzer{}
+	WhitespaceAnalyzer Analyzer = &whitespaceAnalyzer{}
+	StandardAnalyzer   Analyzer = &standardAnalyzer{}
+)
+
+// NewAnalyzer returns the analyzer an index rule chooses
+func NewAnalyzer(analyzer databasev1.IndexRule_Analyzer) Analyzer {
+	switch analyzer {
+	case databasev1.IndexRule_ANALYZER_WHITESPACE:
+		return WhitespaceAnalyzer
+	case databasev1.IndexRule_ANALYZER_STANDARD:
+		return StandardAnalyzer
+	}
+	return KeywordAnalyzer
+}
+
+type keywordAnalyzer struct{}
+
+func (keywordAnalyzer) Analyze(value []byte) [][]byte {
+	return [][]byte{value}
+}
+
+type whitespaceAnalyzer struct{}
+
+func (whitespaceAnalyzer) Analyze(value []byte) [][]byte {
+	return dedup(bytes.Fields(value))
+}
+
+type standardAnalyzer struct{}
+
+func (standardAnalyzer) Analyze(value []byte) [][]byte {
+	tokens := bytes.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i := range tokens {
+		tokens[i] = bytes.ToLower(tokens[i])
+	}
+	return dedup(tokens)
+}
+
+func dedup(tokens [][]byte) [][]byte {
+	seen := make(map[string]struct{}, len(tokens))
+	result := tokens[:0]
+	for _, t := range tokens {
+		if _, ok := seen[string(t)]; ok {
+			continue
+		}
+		seen[string(t)] = struct{}{}
+		result = append(result, t)
+	}
+	return result
+}
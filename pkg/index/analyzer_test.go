@@ -0,0 +1,80 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+func TestAnalyzer(t *testing.T) {
+	tests := []struct {
+		name     string
+		analyzer databasev1.IndexRule_Analyzer
+		value    string
+		want     []string
+	}{
+		{
+			name:  "unspecified",
+			value: "GET /home failed",
+			want:  []string{"GET /home failed"},
+		},
+		{
+			name:     "keyword",
+			analyzer: databasev1.IndexRule_ANALYZER_KEYWORD,
+			value:    "GET /home failed",
+			want:     []string{"GET /home failed"},
+		},
+		{
+			name:     "whitespace",
+			analyzer: databasev1.IndexRule_ANALYZER_WHITESPACE,
+			value:    " GET  /home\tfailed\nGET ",
+			want:     []string{"GET", "/home", "failed"},
+		},
+		{
+			name:     "whitespace on a blank value",
+			analyzer: databasev1.IndexRule_ANALYZER_WHITESPACE,
+			value:    " \t",
+			want:     []string{},
+		},
+		{
+			name:     "standard",
+			analyzer: databasev1.IndexRule_ANALYZER_STANDARD,
+			value:    "GET /home/Index.html failed: connection-refused, get",
+			want:     []string{"get", "home", "index", "html", "failed", "connection", "refused"},
+		},
+		{
+			name:     "standard with unicode",
+			analyzer: databasev1.IndexRule_ANALYZER_STANDARD,
+			value:    "Ошибка 500:服务",
+			want:     []string{"ошибка", "500", "服务"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, token := range NewAnalyzer(tt.analyzer).Analyze([]byte(tt.value)) {
+				got = append(got, string(token))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+)
+
+// bloomFalsePositiveRate is the rate of the false positives which NewBloom sizes the filter for
+const bloomFalsePositiveRate = 0.01
+
+// Bloom is a Bloom filter of the items, which tells an item is absent for sure or might be present
+type Bloom struct {
+	hashes uint32
+	bits   []uint64
+}
+
+// NewBloom sizes the filter for n items with about 1% false positives. Inserting more items raises the rate.
+func NewBloom(n int) *Bloom {
+	if n < 1 {
+		n = 1
+	}
+	m := math.Ceil(-float64(n) * math.Log(bloomFalsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Max(1, math.Round(m/float64(n)*math.Ln2))
+	return &Bloom{
+		hashes: uint32(k),
+		bits:   make([]uint64, (uint64(m)+63)/64),
+	}
+}
+
+func (b *Bloom) Insert(id common.ItemID) {
+	b.locate(id, func(word int, mask uint64) bool {
+		b.bits[word] |= mask
+		return true
+	})
+}
+
+func (b *Bloom) MightContain(id common.ItemID) bool {
+	return b.locate(id, func(word int, mask uint64) bool {
+		return b.bits[word]&mask != 0
+	})
+}
+
+// locate visits the bits of the item derived by the double hashing, and stops once fn returns false
+func (b *Bloom) locate(id common.ItemID, fn func(word int, mask uint64) bool) bool {
+	h := convert.Hash(convert.Uint64ToBytes(uint64(id)))
+	h1, h2 := h, h>>32|1
+	m := uint64(len(b.bits)) * 64
+	for i := uint64(0); i < uint64(b.hashes); i++ {
+		bit := (h1 + i*h2) % m
+		if !fn(int(bit/64), 1<<(bit%64)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Bloom) Marshal() []byte {
+	raw := make([]byte, 4+8*len(b.bits))
+	binary.BigEndian.PutUint32(raw, b.hashes)
+	for i, w := range b.bits {
+		binary.BigEndian.PutUint64(raw[4+8*i:], w)
+	}
+	return raw
+}
+
+func UnmarshalBloom(raw []byte) (*Bloom, error) {
+	if len(raw) < 12 || (len(raw)-4)%8 != 0 {
+		return nil, errors.Wrap(ErrMalformed, "unmarshal a bloom filter")
+	}
+	b := &Bloom{
+		hashes: binary.BigEndian.Uint32(raw),
+		bits:   make([]uint64, (len(raw)-4)/8),
+	}
+	if b.hashes < 1 {
+		return nil, errors.Wrap(ErrMalformed, "unmarshal a bloom filter")
+	}
+	for i := range b.bits {
+		b.bits[i] = binary.BigEndian.Uint64(raw[4+8*i:])
+	}
+	return b, nil
+}
@@ -0,0 +1,50 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+)
+
+func TestBloom(t *testing.T) {
+	req := require.New(t)
+	const n = 10000
+	b := NewBloom(n)
+	for i := 0; i < n; i++ {
+		b.Insert(common.ItemID(i))
+	}
+	restored, err := UnmarshalBloom(b.Marshal())
+	req.NoError(err)
+	var falsePositives int
+	for i := 0; i < n; i++ {
+		// no false negative
+		req.True(restored.MightContain(common.ItemID(i)))
+		if restored.MightContain(common.ItemID(n + i)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, float64(falsePositives)/n, 2*bloomFalsePositiveRate)
+
+	_, err = UnmarshalBloom([]byte{0, 0, 0, 1})
+	req.ErrorIs(err, ErrMalformed)
+}
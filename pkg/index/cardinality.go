@@ -0,0 +1,129 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/pkg/errors"
+
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+	"github.com/apache/skywalking-banyandb/pkg/index/posting"
+)
+
+// sketchPrecision decides the number of the registers, 2^sketchPrecision.
+// The standard error of the estimate is 1.04/sqrt(2^sketchPrecision), which is about 1.6%.
+const sketchPrecision = 12
+
+const sketchRegisters = 1 << sketchPrecision
+
+// CardinalityEstimator estimates the number of the distinct terms of a field without scanning them
+type CardinalityEstimator interface {
+	// ApproxDistinctTermCount reads the sketch of the field maintained on writing. It's zero if the field is absent.
+	// The error is about 2%, so DistinctTermCount fits better with the fields having a few terms.
+	ApproxDistinctTermCount(fieldKey FieldKey) (uint64, error)
+}
+
+// RecommendPostingBlockSize suggests the posting block size of the field by its observed cardinality,
+// see posting.RecommendBlockSize
+func RecommendPostingBlockSize(estimator CardinalityEstimator, fieldKey FieldKey) (int, error) {
+	count, err := estimator.ApproxDistinctTermCount(fieldKey)
+	if err != nil {
+		return 0, err
+	}
+	return posting.RecommendBlockSize(count), nil
+}
+
+// DistinctTermCount counts the terms of the field exactly by iterating all of them
+func DistinctTermCount(iterable FieldIterable, fieldKey FieldKey) (count uint64, err error) {
+	iter, err := iterable.Iterator(fieldKey, RangeOpts{}, modelv1.Sort_SORT_ASC)
+	if err != nil || iter == nil {
+		return 0, err
+	}
+	defer func() {
+		if closeErr := iter.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	for iter.Next() {
+		count++
+	}
+	return count, nil
+}
+
+// Sketch is a HyperLogLog sketch, which estimates the number of the distinct terms inserted into it
+type Sketch struct {
+	registers [sketchRegisters]uint8
+}
+
+func NewSketch() *Sketch {
+	return &Sketch{}
+}
+
+func (s *Sketch) Insert(term []byte) {
+	h := convert.Hash(term)
+	idx := h >> (64 - sketchPrecision)
+	// the guard bit caps the rank at 64-sketchPrecision+1
+	rank := uint8(bits.LeadingZeros64(h<<sketchPrecision|1<<(sketchPrecision-1))) + 1
+	if rank > s.registers[idx] {
+		s.registers[idx] = rank
+	}
+}
+
+// Merge makes the sketch cover the terms of other as well
+func (s *Sketch) Merge(other *Sketch) {
+	for i, r := range other.registers {
+		if r > s.registers[i] {
+			s.registers[i] = r
+		}
+	}
+}
+
+// Estimate takes constant time regardless of the number of the inserted terms
+func (s *Sketch) Estimate() uint64 {
+	var sum float64
+	var zeros int
+	for _, r := range s.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	m := float64(sketchRegisters)
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	// the linear counting is more accurate for the small cardinalities
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+func (s *Sketch) Marshal() []byte {
+	return append([]byte{}, s.registers[:]...)
+}
+
+func UnmarshalSketch(raw []byte) (*Sketch, error) {
+	if len(raw) != sketchRegisters {
+		return nil, errors.Wrap(ErrMalformed, "unmarshal a sketch")
+	}
+	s := &Sketch{}
+	copy(s.registers[:], raw)
+	return s, nil
+}
@@ -0,0 +1,57 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSketch(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 100000} {
+		t.Run(fmt.Sprintf("%d terms", n), func(t *testing.T) {
+			s := NewSketch()
+			for i := 0; i < n; i++ {
+				// the duplicated terms are counted once
+				s.Insert([]byte(fmt.Sprintf("term-%d", i)))
+				s.Insert([]byte(fmt.Sprintf("term-%d", i)))
+			}
+			assert.InDelta(t, n, s.Estimate(), float64(n)*0.05)
+		})
+	}
+}
+
+func TestSketch_MergeAndMarshal(t *testing.T) {
+	is := require.New(t)
+	a, b := NewSketch(), NewSketch()
+	for i := 0; i < 5000; i++ {
+		a.Insert([]byte(fmt.Sprintf("term-%d", i)))
+		b.Insert([]byte(fmt.Sprintf("term-%d", i+2500)))
+	}
+	a.Merge(b)
+	is.InDelta(7500, a.Estimate(), 7500*0.05)
+
+	restored, err := UnmarshalSketch(a.Marshal())
+	is.NoError(err)
+	is.Equal(a.Estimate(), restored.Estimate())
+	_, err = UnmarshalSketch([]byte{1, 2, 3})
+	is.ErrorIs(err, ErrMalformed)
+}
@@ -0,0 +1,235 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+)
+
+const (
+	ComparatorBytes   = "bytes"
+	ComparatorNumeric = "numeric"
+	ComparatorVersion = "version"
+)
+
+var (
+	ErrComparatorExists  = errors.New("the comparator is registered")
+	ErrUnknownComparator = errors.New("the comparator is unknown")
+)
+
+// Comparator returns a negative number if a < b, zero if a == b, and a positive number if a > b
+type Comparator func(a, b []byte) int
+
+var comparators = struct {
+	sync.RWMutex
+	repo map[string]Comparator
+}{
+	repo: map[string]Comparator{
+		ComparatorBytes:   bytes.Compare,
+		ComparatorNumeric: compareNumeric,
+		ComparatorVersion: compareVersion,
+	},
+}
+
+// RegisterComparator makes a custom comparator available to the index rules which refer to it by name.
+// It should be called in an init function, so that the comparator is ready before any index is opened:
+//
+//	func init() {
+//		_ = index.RegisterComparator("reverse", func(a, b []byte) int {
+//			return bytes.Compare(b, a)
+//		})
+//	}
+//
+// A comparator can't be replaced once registered.
+func RegisterComparator(name string, c Comparator) error {
+	comparators.Lock()
+	defer comparators.Unlock()
+	if _, ok := comparators.repo[name]; ok || name == "" {
+		return errors.Wrapf(ErrComparatorExists, "comparator %q", name)
+	}
+	comparators.repo[name] = c
+	return nil
+}
+
+// GetComparator looks up a comparator by name. An empty name refers to the byte-wise one.
+func GetComparator(name string) (Comparator, error) {
+	if name == "" {
+		return bytes.Compare, nil
+	}
+	comparators.RLock()
+	defer comparators.RUnlock()
+	c, ok := comparators.repo[name]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownComparator, "comparator %q", name)
+	}
+	return c, nil
+}
+
+// byteWise reports whether the terms of the field are ordered as they are stored
+func byteWise(name string) bool {
+	return name == "" || name == ComparatorBytes
+}
+
+// compareNumeric compares the integers encoded by convert.Int64ToBytes
+func compareNumeric(a, b []byte) int {
+	if len(a) != 8 || len(b) != 8 {
+		return bytes.Compare(a, b)
+	}
+	x, y := convert.BytesToInt64(a), convert.BytesToInt64(b)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// compareVersion compares semantic versions, for example, "v1.10.0" > "1.9.2" > "1.9.2-rc.1".
+// The build metadata is ignored. The identifiers which are not numbers are compared lexically.
+func compareVersion(a, b []byte) int {
+	coreA, preA := splitVersion(string(a))
+	coreB, preB := splitVersion(string(b))
+	if c := compareIdentifiers(coreA, coreB, true); c != 0 {
+		return c
+	}
+	switch {
+	case preA == nil && preB == nil:
+		return 0
+	case preA == nil:
+		return 1
+	case preB == nil:
+		return -1
+	}
+	return compareIdentifiers(preA, preB, false)
+}
+
+func splitVersion(v string) (core, pre []string) {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		pre = strings.Split(v[i+1:], ".")
+		v = v[:i]
+	}
+	return strings.Split(v, "."), pre
+}
+
+// compareIdentifiers compares the dot-separated identifiers one by one.
+// The absent identifiers of the core version are zeros.
+func compareIdentifiers(a, b []string, padding bool) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y string
+		switch {
+		case i < len(a) && i < len(b):
+			x, y = a[i], b[i]
+		case !padding && i >= len(a):
+			return -1
+		case !padding:
+			return 1
+		case i < len(a):
+			x, y = a[i], "0"
+		default:
+			x, y = "0", b[i]
+		}
+		if c := compareIdentifier(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareIdentifier(x, y string) int {
+	nx, errX := strconv.ParseUint(x, 10, 64)
+	ny, errY := strconv.ParseUint(y, 10, 64)
+	switch {
+	case errX == nil && errY == nil:
+		switch {
+		case nx < ny:
+			return -1
+		case nx > ny:
+			return 1
+		}
+		return 0
+	case errX == nil:
+		return -1
+	case errY == nil:
+		return 1
+	}
+	return strings.Compare(x, y)
+}
+
+var _ FieldIterator = (*sortedTermIterator)(nil)
+
+type sortedTermIterator struct {
+	values []*PostingValue
+	index  int
+	err    error
+}
+
+// SortTerms reorders the terms of the iterator by the comparator of the field.
+// The iterator is returned as it is if the terms are compared byte-wise.
+func SortTerms(iter FieldIterator, fieldKey FieldKey, order modelv1.Sort) (FieldIterator, error) {
+	if iter == nil || byteWise(fieldKey.Comparator) {
+		return iter, nil
+	}
+	compare, err := GetComparator(fieldKey.Comparator)
+	if err != nil {
+		return nil, multierr.Append(err, iter.Close())
+	}
+	var values []*PostingValue
+	for iter.Next() {
+		values = append(values, iter.Val())
+	}
+	reverse := order == modelv1.Sort_SORT_DESC
+	sort.SliceStable(values, func(i, j int) bool {
+		if reverse {
+			return compare(values[i].Term, values[j].Term) > 0
+		}
+		return compare(values[i].Term, values[j].Term) < 0
+	})
+	return &sortedTermIterator{
+		values: values,
+		index:  -1,
+		err:    iter.Close(),
+	}, nil
+}
+
+func (s *sortedTermIterator) Next() bool {
+	s.index++
+	return s.index < len(s.values)
+}
+
+func (s *sortedTermIterator) Val() *PostingValue {
+	return s.values[s.index]
+}
+
+func (s *sortedTermIterator) Close() error {
+	return s.err
+}
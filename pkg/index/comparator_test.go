@@ -0,0 +1,75 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+)
+
+func TestComparator(t *testing.T) {
+	tests := []struct {
+		name       string
+		comparator string
+		a, b       []byte
+		want       int
+	}{
+		{name: "default", a: []byte("a"), b: []byte("b"), want: -1},
+		{name: "bytes", comparator: ComparatorBytes, a: []byte("b"), b: []byte("a"), want: 1},
+		{name: "numeric", comparator: ComparatorNumeric, a: convert.Int64ToBytes(-10), b: convert.Int64ToBytes(2), want: -1},
+		{name: "numeric equal", comparator: ComparatorNumeric, a: convert.Int64ToBytes(7), b: convert.Int64ToBytes(7), want: 0},
+		{name: "version", comparator: ComparatorVersion, a: []byte("1.10.0"), b: []byte("1.9.2"), want: 1},
+		{name: "version prefix", comparator: ComparatorVersion, a: []byte("v1.2"), b: []byte("1.2.0"), want: 0},
+		{name: "version build", comparator: ComparatorVersion, a: []byte("1.2.0+build.1"), b: []byte("1.2.0"), want: 0},
+		{name: "version pre-release", comparator: ComparatorVersion, a: []byte("1.2.0-rc.1"), b: []byte("1.2.0"), want: -1},
+		{name: "version pre-release number", comparator: ComparatorVersion, a: []byte("1.2.0-rc.10"), b: []byte("1.2.0-rc.9"), want: 1},
+		{name: "version pre-release length", comparator: ComparatorVersion, a: []byte("1.2.0-rc"), b: []byte("1.2.0-rc.1"), want: -1},
+		{name: "version pre-release alpha", comparator: ComparatorVersion, a: []byte("1.2.0-alpha"), b: []byte("1.2.0-beta"), want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := GetComparator(tt.comparator)
+			assert.NoError(t, err)
+			got := c(tt.a, tt.b)
+			switch {
+			case got < 0:
+				got = -1
+			case got > 0:
+				got = 1
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegisterComparator(t *testing.T) {
+	_, err := GetComparator("reverse")
+	assert.ErrorIs(t, err, ErrUnknownComparator)
+	assert.NoError(t, RegisterComparator("reverse", func(a, b []byte) int {
+		return bytes.Compare(b, a)
+	}))
+	assert.ErrorIs(t, RegisterComparator("reverse", bytes.Compare), ErrComparatorExists)
+	assert.ErrorIs(t, RegisterComparator(ComparatorVersion, bytes.Compare), ErrComparatorExists)
+	c, err := GetComparator("reverse")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c([]byte("a"), []byte("b")))
+}
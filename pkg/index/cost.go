@@ -0,0 +1,137 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+var ErrUnsupportedQuery = errors.New("the query is not supported by the cost estimator")
+
+// Cost estimates how expensive a query is. A planner compares Work against the number of the items a full scan reads
+// to decide whether the index pays off.
+type Cost struct {
+	// Items is the expected number of the matched items. It's an upper bound rather than an exact count.
+	Items uint64
+	// Work is the number of the postings read from the index and merged by the intersections and the unions
+	Work uint64
+}
+
+// TermCardinality counts the items of a term without building its posting list
+type TermCardinality interface {
+	CardinalityOfTerm(field Field) (uint64, error)
+}
+
+// CardinalityOfTerm prefers the TermCardinality of the searcher, and falls back to the length of the postings of the term
+func CardinalityOfTerm(searcher Searcher, field Field) (uint64, error) {
+	if tc, ok := searcher.(TermCardinality); ok {
+		return tc.CardinalityOfTerm(field)
+	}
+	list, err := searcher.MatchTerms(field)
+	if err != nil {
+		return 0, err
+	}
+	if list == nil {
+		return 0, nil
+	}
+	return uint64(list.Len()), nil
+}
+
+// EstimateCost walks the query built by BuildTree without executing it, so it has to be called before Execute
+// which consumes the tree.
+// The leaves matching a term are estimated by CardinalityOfTerm, and the ranges are bounded by all the items of the field.
+// An intersection matches at most the smallest sub-query while a union matches at most all of them,
+// and both of them merge the results of the sub-queries.
+func EstimateCost(query Executor) (Cost, error) {
+	switch q := query.(type) {
+	case *andNode:
+		return estimateNode(q.node, func(a, b uint64) uint64 {
+			if a < b {
+				return a
+			}
+			return b
+		})
+	case *orNode:
+		return estimateNode(q.node, func(a, b uint64) uint64 {
+			return a + b
+		})
+	case *not:
+		all, err := fieldCardinality(q.searcher, q.Key)
+		if err != nil {
+			return Cost{}, err
+		}
+		inner, err := EstimateCost(q.Inner)
+		if err != nil {
+			return Cost{}, err
+		}
+		c := Cost{Work: inner.Work + all + inner.Items}
+		if all > inner.Items {
+			c.Items = all - inner.Items
+		}
+		return c, nil
+	case *eq:
+		n, err := CardinalityOfTerm(q.searcher, Field{
+			Key:  q.Key,
+			Term: bytes.Join(q.Values, nil),
+		})
+		if err != nil {
+			return Cost{}, err
+		}
+		return Cost{Items: n, Work: n}, nil
+	case *rangeOp:
+		n, err := fieldCardinality(q.searcher, q.Key)
+		if err != nil {
+			return Cost{}, err
+		}
+		return Cost{Items: n, Work: n}, nil
+	}
+	return Cost{}, errors.Wrapf(ErrUnsupportedQuery, "%T", query)
+}
+
+func estimateNode(n *node, combine func(a, b uint64) uint64) (Cost, error) {
+	if len(n.SubNodes) < 1 {
+		return Cost{}, ErrEmptyTree
+	}
+	var c Cost
+	for i, sub := range n.SubNodes {
+		sc, err := EstimateCost(sub)
+		if err != nil {
+			return Cost{}, err
+		}
+		c.Work += sc.Work + sc.Items
+		if i == 0 {
+			c.Items = sc.Items
+			continue
+		}
+		c.Items = combine(c.Items, sc.Items)
+	}
+	return c, nil
+}
+
+func fieldCardinality(searcher Searcher, fieldKey FieldKey) (uint64, error) {
+	list, err := searcher.MatchField(fieldKey)
+	if err != nil {
+		return 0, err
+	}
+	if list == nil {
+		return 0, nil
+	}
+	return uint64(list.Len()), nil
+}
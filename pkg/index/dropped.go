@@ -0,0 +1,209 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/index/posting"
+)
+
+// DroppedItems hides the items of the dropped fields from the on-disk tables, which can't remove them in place.
+// The items written after the field is dropped stay visible.
+//
+// An item could be dropped from a single term of a field as well. Such a term is saved along with the fields,
+// whose key is longer than the key of any field.
+type DroppedItems struct {
+	mutex   sync.RWMutex
+	path    string
+	newList posting.Factory
+	repo    map[string]posting.List
+}
+
+// LoadDroppedItems loads the dropped items saved in the file of path. The file is absent if none is dropped.
+func LoadDroppedItems(path string, newList posting.Factory) (*DroppedItems, error) {
+	d := &DroppedItems{
+		path:    path,
+		newList: newList,
+		repo:    make(map[string]posting.List),
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for len(raw) > 0 {
+		var fields [2][]byte
+		for i := range fields {
+			l, n := binary.Uvarint(raw)
+			if n <= 0 || uint64(len(raw)-n) < l {
+				return nil, errors.Wrapf(ErrMalformed, "dropped items %s", path)
+			}
+			fields[i] = raw[n : n+int(l)]
+			raw = raw[n+int(l):]
+		}
+		list := newList()
+		if err = list.Unmarshall(fields[1]); err != nil {
+			return nil, errors.Wrapf(ErrMalformed, "dropped items %s: %v", path, err)
+		}
+		d.repo[string(fields[0])] = list
+	}
+	return d, nil
+}
+
+// Drop hides the items from the field, then saves all the dropped items
+func (d *DroppedItems) Drop(fieldKey FieldKey, items posting.List) error {
+	if items == nil || items.IsEmpty() {
+		return nil
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	key := string(fieldKey.Marshal())
+	if existing, ok := d.repo[key]; ok {
+		if err := existing.Union(items); err != nil {
+			return err
+		}
+	} else {
+		d.repo[key] = items.Clone()
+	}
+	return d.save()
+}
+
+// Sync makes the saved items durable
+func (d *DroppedItems) Sync() error {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return SyncFile(d.path)
+}
+
+// Hide removes the dropped items of the field from the list
+func (d *DroppedItems) Hide(fieldKey FieldKey, list posting.List) error {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if len(d.repo) < 1 {
+		return nil
+	}
+	dropped, ok := d.repo[string(fieldKey.Marshal())]
+	if !ok {
+		return nil
+	}
+	return list.Difference(dropped)
+}
+
+// DropTerm hides the item from the term of the field only, then saves all the dropped items
+func (d *DroppedItems) DropTerm(field Field, itemID common.ItemID) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	key := droppedTermKey(field)
+	list, ok := d.repo[key]
+	if !ok {
+		list = d.newList()
+		d.repo[key] = list
+	}
+	if list.Contains(itemID) {
+		return nil
+	}
+	list.Insert(itemID)
+	return d.save()
+}
+
+// Restore shows the item in the term again, which is written to the term after it's dropped from the term.
+// The item dropped with the whole field stays hidden.
+func (d *DroppedItems) Restore(field Field, itemID common.ItemID) error {
+	key := droppedTermKey(field)
+	d.mutex.RLock()
+	list, ok := d.repo[key]
+	dropped := ok && list.Contains(itemID)
+	d.mutex.RUnlock()
+	if !dropped {
+		return nil
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	list, ok = d.repo[key]
+	if !ok || !list.Contains(itemID) {
+		return nil
+	}
+	if err := list.RemoveRange(itemID, itemID+1); err != nil {
+		return err
+	}
+	if list.IsEmpty() {
+		delete(d.repo, key)
+	}
+	return d.save()
+}
+
+// HideTerm removes the items dropped from the field and the ones dropped from the term from the list
+func (d *DroppedItems) HideTerm(field Field, list posting.List) error {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if len(d.repo) < 1 {
+		return nil
+	}
+	for _, key := range []string{string(field.Key.Marshal()), droppedTermKey(field)} {
+		dropped, ok := d.repo[key]
+		if !ok {
+			continue
+		}
+		if err := list.Difference(dropped); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// droppedTermKey puts a separator between the field key and the term, so that it's longer than the key of a field
+// even if the term is empty
+func droppedTermKey(field Field) string {
+	return string(bytes.Join([][]byte{field.Key.Marshal(), field.Term}, []byte{0}))
+}
+
+func (d *DroppedItems) save() error {
+	keys := make([]string, 0, len(d.repo))
+	for key := range d.repo {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buf []byte
+	var lenBuf [binary.MaxVarintLen64]byte
+	for _, key := range keys {
+		list, err := d.repo[key].Marshall()
+		if err != nil {
+			return err
+		}
+		for _, b := range [][]byte{[]byte(key), list} {
+			n := binary.PutUvarint(lenBuf[:], uint64(len(b)))
+			buf = append(buf, lenBuf[:n]...)
+			buf = append(buf, b...)
+		}
+	}
+	tmp := d.path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, d.path)
+}
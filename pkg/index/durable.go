@@ -0,0 +1,64 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Durable separates moving the buffered writes to a segment from making the segment durable.
+// A high-throughput ingest could Flush frequently to bound the memory, but Sync periodically.
+//
+// The writes not flushed yet are lost if the process crashes, unless the store logs them ahead.
+// The flushed writes survive a crash of the process, because they are in the page cache at least,
+// but they might be lost if the OS crashes or the power is off before a Sync.
+type Durable interface {
+	// Flush moves the in-memory buffer to a segment. The items flushed are searchable after a restart.
+	Flush() error
+	// Sync fsyncs all the flushed segments and their metadata, which survive a crash of the OS then.
+	// It doesn't flush the buffer.
+	Sync() error
+}
+
+// SyncFile fsyncs the file and its directory, which makes a file replaced by a rename durable.
+// An absent file is skipped.
+func SyncFile(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
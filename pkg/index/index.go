@@ -20,6 +20,7 @@ package index
 import (
 	"bytes"
 	"io"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -28,14 +29,46 @@ import (
 	"github.com/apache/skywalking-banyandb/pkg/convert"
 	"github.com/apache/skywalking-banyandb/pkg/index/metadata"
 	"github.com/apache/skywalking-banyandb/pkg/index/posting"
+	"github.com/apache/skywalking-banyandb/pkg/meter"
 )
 
-var ErrMalformed = errors.New("the data is malformed")
+var (
+	ErrMalformed             = errors.New("the data is malformed")
+	ErrPayloadNotFound       = errors.New("the payload of the doc is not found")
+	ErrUnsupportedComparator = errors.New("the comparator doesn't support the operation")
+	ErrTxnDone               = errors.New("the transaction is committed or rolled back")
+	ErrNoTerm                = errors.New("the field has no terms")
+)
+
+const fieldKeyLen = 12
 
 type FieldKey struct {
 	SeriesID    common.SeriesID
 	IndexRuleID uint32
 	EncodeTerm  bool
+	// Comparator is the name of the comparator which orders the terms, see GetComparator
+	Comparator string
+	// AliasOf is the ID of the index rule the field was renamed from. The searches of the field cover
+	// the postings of the same series under that rule as well. The writes never go to the alias.
+	// Once the items are reindexed under this field, clear AliasOf and drop the field of the old rule with DropField.
+	AliasOf uint32
+	// PostingBlockSize encodes the posting lists of the field in blocks of the size when they're flushed,
+	// see posting.MarshalBlocks. Zero follows the store's option.
+	PostingBlockSize int
+}
+
+// AliasKey returns the key of the field the field was renamed from, which shares the series and the term encoding.
+// It's false if the field has no alias.
+func (f FieldKey) AliasKey() (FieldKey, bool) {
+	if f.AliasOf == 0 || f.AliasOf == f.IndexRuleID {
+		return FieldKey{}, false
+	}
+	return FieldKey{
+		SeriesID:    f.SeriesID,
+		IndexRuleID: f.AliasOf,
+		EncodeTerm:  f.EncodeTerm,
+		Comparator:  f.Comparator,
+	}, true
 }
 
 func (f FieldKey) Marshal() []byte {
@@ -67,6 +100,23 @@ type Field struct {
 	Term []byte
 }
 
+// MatchWithAlias unions the items matching the term under the field and the ones under its alias
+func MatchWithAlias(field Field, match func(Field) (posting.List, error)) (posting.List, error) {
+	list, err := match(field)
+	if err != nil {
+		return nil, err
+	}
+	alias, ok := field.Key.AliasKey()
+	if !ok {
+		return list, nil
+	}
+	aliasList, err := match(Field{Key: alias, Term: field.Term})
+	if err != nil {
+		return nil, err
+	}
+	return list, list.Union(aliasList)
+}
+
 func (f Field) MarshalStraight() ([]byte, error) {
 	return bytes.Join([][]byte{f.Key.Marshal(), f.Term}, nil), nil
 }
@@ -101,14 +151,18 @@ func (f *Field) Unmarshal(term metadata.Term, raw []byte) error {
 }
 
 func (f *Field) UnmarshalStraight(raw []byte) error {
+	// The marshaled key always has a fixed width, the term which follows it might be a string in any length
+	if len(raw) < fieldKeyLen {
+		return errors.Wrap(ErrMalformed, "unmarshal a field")
+	}
 	fk := &f.Key
-	err := fk.Unmarshal(raw[:len(raw)-8])
+	err := fk.Unmarshal(raw[:fieldKeyLen])
 	if err != nil {
 		return errors.Wrap(err, "unmarshal a field")
 	}
-	termID := raw[len(raw)-8:]
-	f.Term = make([]byte, len(termID))
-	copy(f.Term, termID)
+	term := raw[fieldKeyLen:]
+	f.Term = make([]byte, len(term))
+	copy(f.Term, term)
 	return nil
 }
 
@@ -120,12 +174,17 @@ type RangeOpts struct {
 }
 
 func (r RangeOpts) Between(value []byte) int {
+	return r.BetweenWith(value, bytes.Compare)
+}
+
+// BetweenWith is identical to Between except that the bounds are compared by compare
+func (r RangeOpts) BetweenWith(value []byte, compare Comparator) int {
 	if r.Upper != nil {
 		var in bool
 		if r.IncludesUpper {
-			in = bytes.Compare(r.Upper, value) >= 0
+			in = compare(r.Upper, value) >= 0
 		} else {
-			in = bytes.Compare(r.Upper, value) > 0
+			in = compare(r.Upper, value) > 0
 		}
 		if !in {
 			return 1
@@ -134,9 +193,9 @@ func (r RangeOpts) Between(value []byte) int {
 	if r.Lower != nil {
 		var in bool
 		if r.IncludesLower {
-			in = bytes.Compare(r.Lower, value) <= 0
+			in = compare(r.Lower, value) <= 0
 		} else {
-			in = bytes.Compare(r.Lower, value) < 0
+			in = compare(r.Lower, value) < 0
 		}
 		if !in {
 			return -1
@@ -145,6 +204,42 @@ func (r RangeOpts) Between(value []byte) int {
 	return 0
 }
 
+// StringRange selects the string terms between lower and upper in lexicographic order.
+// Both bounds are inclusive.
+func StringRange(lower, upper string) RangeOpts {
+	return RangeOpts{
+		Lower:         []byte(lower),
+		Upper:         []byte(upper),
+		IncludesLower: true,
+		IncludesUpper: true,
+	}
+}
+
+// PrefixRange selects the terms starting with prefix. An empty prefix selects all the terms.
+// It only works with the byte-wise comparator, which keeps the terms with the same prefix adjacent.
+func PrefixRange(fieldKey FieldKey, prefix []byte) (RangeOpts, error) {
+	if !byteWise(fieldKey.Comparator) {
+		return RangeOpts{}, errors.Wrapf(ErrUnsupportedComparator, "prefix on comparator %q", fieldKey.Comparator)
+	}
+	if len(prefix) < 1 {
+		return RangeOpts{}, nil
+	}
+	opts := RangeOpts{
+		Lower:         prefix,
+		IncludesLower: true,
+	}
+	// the upper bound is the least term greater than all the ones with the prefix, it's absent if the prefix is all 0xff
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			opts.Upper = make([]byte, i+1)
+			copy(opts.Upper, prefix)
+			opts.Upper[i]++
+			break
+		}
+	}
+	return opts, nil
+}
+
 type FieldIterator interface {
 	Next() bool
 	Val() *PostingValue
@@ -152,6 +247,8 @@ type FieldIterator interface {
 }
 
 type PostingValue struct {
+	// Term is the term as it was written, excluding the field key. The literal is restored if the field key encodes terms.
+	// pbv1.UnmarshalIndexFieldValue decodes it into a tag value.
 	Term  []byte
 	Value posting.List
 }
@@ -160,19 +257,134 @@ type Writer interface {
 	Write(field Field, itemID common.ItemID) error
 }
 
+// DocWriter writes the fields of a doc in a transaction
+type DocWriter interface {
+	BeginDoc(docID common.ItemID) DocTxn
+}
+
+// DocTxn makes all the fields of a doc visible on Commit, or none of them.
+// A search never sees a half-indexed doc, and a crash before Commit leaves nothing of the doc behind.
+type DocTxn interface {
+	// Add buffers the field until Commit
+	Add(field Field) DocTxn
+	// Commit fails with ErrTxnDone if the transaction is committed or rolled back
+	Commit() error
+	// Rollback discards the buffered fields
+	Rollback()
+}
+
+// BulkEntry is a field of a doc to be bulk-loaded
+type BulkEntry struct {
+	Field Field
+	DocID common.ItemID
+}
+
+// BulkLoader indexes lots of fields at once, for example, rebuilding the index of a data segment
+type BulkLoader interface {
+	// BulkLoad is equivalent to writing the entries one by one. It sorts the entries in place by the field, the term
+	// and the doc, then builds the posting list of each term from a run of the sorted entries.
+	BulkLoad(entries []BulkEntry) error
+}
+
+// DocDeleter removes a doc from all the fields it's indexed by
+type DocDeleter interface {
+	// DeleteDoc returns false if the doc is found in none of the fields.
+	// The ID of a deleted doc shouldn't be reused, whose new fields might be hidden as well.
+	DeleteDoc(docID common.ItemID) (bool, error)
+}
+
 type FieldIterable interface {
 	Iterator(fieldKey FieldKey, termRange RangeOpts, order modelv1.Sort) (iter FieldIterator, err error)
 }
 
 type Searcher interface {
 	FieldIterable
+	// MatchField returns the items having any term of the field, which is the union of all the postings of the field.
+	// An item absent from the result never indexed the field, for example, the tag is optional and the item doesn't carry it.
 	MatchField(fieldKey FieldKey) (list posting.List, err error)
 	MatchTerms(field Field) (list posting.List, err error)
 	Range(fieldKey FieldKey, opts RangeOpts) (list posting.List, err error)
+	// RangeWithin only collects the items present in within, which is cheaper than intersecting the whole range
+	RangeWithin(fieldKey FieldKey, opts RangeOpts, within posting.List) (list posting.List, err error)
+	// MatchTermWithinRange returns the items matching the term whose terms of rangeField fall in the range.
+	// The term is matched first, then only its items are checked against the range.
+	MatchTermWithinRange(termField Field, rangeField FieldKey, opts RangeOpts) (list posting.List, err error)
+	// SortedFieldIterator yields the items in within ordered by their terms, which is the primitive of ordering by an indexed field
+	SortedFieldIterator(fieldKey FieldKey, within posting.List, order modelv1.Sort) (iter SortedItemIterator, err error)
+	// PrefixFieldIterator only yields the terms starting with prefix, for example, the paths under a directory.
+	// The terms are ordered by their IDs rather than the literals if the field encodes terms.
+	PrefixFieldIterator(fieldKey FieldKey, prefix []byte, order modelv1.Sort) (iter FieldIterator, err error)
+	// MinTerm returns the smallest term of the field in the order of its comparator, the largest one for MaxTerm.
+	// The terms are ordered by their IDs rather than the literals if the field encodes terms.
+	// Both fail with ErrNoTerm if the field has no terms, including the case that all of its items are dropped.
+	MinTerm(fieldKey FieldKey) ([]byte, error)
+	MaxTerm(fieldKey FieldKey) ([]byte, error)
+	// IndexStats scans the whole index to count the terms and the postings, so it's expensive on a large index
+	IndexStats() (IndexStats, error)
+}
+
+// TailSearcher serves the queries for the latest items, for example, the live tail of a stream
+type TailSearcher interface {
+	// TailN returns at most n items matching the field, the most recently written first
+	TailN(field Field, n int) ([]common.ItemID, error)
+}
+
+// PayloadStore keeps a serialized result of each doc, so that a search could return the results
+// without fetching them from the data store
+type PayloadStore interface {
+	SetDocPayload(docID common.ItemID, payload []byte) error
+	// GetDocPayload fails with ErrPayloadNotFound if the doc carries no payload
+	GetDocPayload(docID common.ItemID) ([]byte, error)
+}
+
+// Stats is the statistics of an index store
+type Stats struct {
+	// SegmentCount is the number of segments, including the in-memory ones
+	SegmentCount int
+	// TotalPostings is the number of posting lists
+	TotalPostings uint64
+	// BytesOnDisk is the size of the on-disk segments
+	BytesOnDisk int64
+	// LastMergeTime is the time when the latest merge happened. It's zero if no merge happened.
+	LastMergeTime time.Time
+	// PrunedSegments is the number of the segments skipped by range queries since the store was opened,
+	// because none of their terms falls in the range
+	PrunedSegments uint64
+	// WALSize is the size of the write-ahead log, which is zero if the store doesn't log the writes
+	WALSize int64
+}
+
+// IndexStats is the size of an index, which helps to find the over-indexed high-cardinality tags.
+// The items hidden by DropField are still counted.
+type IndexStats struct {
+	// TermCount is the number of the distinct terms of all the fields
+	TermCount uint64
+	// PostingCount is the total number of the items in all the posting lists
+	PostingCount uint64
+	// BytesOnDisk is the size of the on-disk segments
+	BytesOnDisk int64
+}
+
+// ObserveStats feeds the statistics into the observer as gauges
+func ObserveStats(observer meter.MetricsObserver, stats Stats, labels meter.Labels) {
+	observer.Gauge("index_segment_count", float64(stats.SegmentCount), labels)
+	observer.Gauge("index_total_postings", float64(stats.TotalPostings), labels)
+	observer.Gauge("index_bytes_on_disk", float64(stats.BytesOnDisk), labels)
+	observer.Gauge("index_pruned_segments", float64(stats.PrunedSegments), labels)
+	if !stats.LastMergeTime.IsZero() {
+		observer.Gauge("index_last_merge_time_seconds", float64(stats.LastMergeTime.Unix()), labels)
+	}
 }
 
 type Store interface {
 	io.Closer
 	Writer
 	Searcher
+	Warmer
+	Stats() Stats
+	// DropField removes all the terms of the field, so that none of the items could be found by it
+	DropField(fieldKey FieldKey) error
+	// DeleteTerm removes the item from the term of the field, while the other terms of the item are kept.
+	// Writing the term of the item again makes the item found by the term.
+	DeleteTerm(field Field, itemID common.ItemID) error
 }
@@ -0,0 +1,61 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix []byte
+		want   RangeOpts
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "plain",
+			prefix: []byte("/a"),
+			want:   RangeOpts{Lower: []byte("/a"), IncludesLower: true, Upper: []byte("/b")},
+		},
+		{
+			name:   "trailing 0xff",
+			prefix: []byte{'a', 0xff, 0xff},
+			want:   RangeOpts{Lower: []byte{'a', 0xff, 0xff}, IncludesLower: true, Upper: []byte("b")},
+		},
+		{
+			name:   "all 0xff",
+			prefix: []byte{0xff},
+			want:   RangeOpts{Lower: []byte{0xff}, IncludesLower: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PrefixRange(FieldKey{}, tt.prefix)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	_, err := PrefixRange(FieldKey{Comparator: ComparatorVersion}, []byte("1."))
+	assert.ErrorIs(t, err, ErrUnsupportedComparator)
+}
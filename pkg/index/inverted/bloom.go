@@ -0,0 +1,222 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package inverted
+
+import (
+	"encoding/binary"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+)
+
+var _ index.DocDeleter = (*store)(nil)
+
+// docBlooms holds a Bloom filter of the docs of each flushed segment.
+// It lets DeleteDoc skip the disk table if none of the segments might contain the doc.
+type docBlooms struct {
+	mutex sync.RWMutex
+	repo  []*index.Bloom
+	// incomplete is true if some segments were flushed before the filters existed, which might contain any doc
+	incomplete bool
+}
+
+func (d *docBlooms) add(bloom *index.Bloom) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.repo = append(d.repo, bloom)
+}
+
+func (d *docBlooms) mightContain(docID common.ItemID) bool {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if d.incomplete {
+		return true
+	}
+	for _, bloom := range d.repo {
+		if bloom.MightContain(docID) {
+			return true
+		}
+	}
+	return false
+}
+
+// save writes the filters to the file at path in a single shot, which replaces the old one atomically
+func (d *docBlooms) save(path string) error {
+	d.mutex.RLock()
+	var buf []byte
+	var lenBuf [binary.MaxVarintLen64]byte
+	for _, bloom := range d.repo {
+		b := bloom.Marshal()
+		n := binary.PutUvarint(lenBuf[:], uint64(len(b)))
+		buf = append(buf, lenBuf[:n]...)
+		buf = append(buf, b...)
+	}
+	d.mutex.RUnlock()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadDocBlooms loads the filters saved at path.
+// The filters are saved before the zones on flushing, so the zones without the filters are left by an older store.
+func loadDocBlooms(path, zonePath string) (*docBlooms, error) {
+	d := &docBlooms{}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if _, err = os.Stat(zonePath); err == nil {
+			d.incomplete = true
+			return d, nil
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			return d, nil
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	for len(raw) > 0 {
+		l, n := binary.Uvarint(raw)
+		if n <= 0 || uint64(len(raw)-n) < l {
+			return nil, errors.Wrapf(index.ErrMalformed, "doc blooms %s", path)
+		}
+		bloom, err := index.UnmarshalBloom(raw[n : n+int(l)])
+		if err != nil {
+			return nil, errors.WithMessagef(err, "doc blooms %s", path)
+		}
+		d.repo = append(d.repo, bloom)
+		raw = raw[n+int(l):]
+	}
+	return d, nil
+}
+
+// DeleteDoc removes the doc from the mem tables which hold it, and hides it in the disk table
+// if any flushed segment might contain it, since the disk table can't remove it in place.
+// Only the disk table is scanned, so the cost of a doc absent from the flushed segments is a few bloom checks.
+func (s *store) DeleteDoc(docID common.ItemID) (bool, error) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	var deleted bool
+	apply := func() error {
+		deleted = s.deleteInMemTables(docID)
+		return nil
+	}
+	if s.wal == nil {
+		_ = apply()
+	} else if err := s.logRecord(marshalWALDelete(docID), apply); err != nil {
+		return false, err
+	}
+	if !s.docBlooms.mightContain(docID) {
+		return deleted, nil
+	}
+	hidden, err := s.hideDocInDiskTable(docID)
+	return deleted || hidden, err
+}
+
+// DeleteTerm removes the doc from the term in the mem tables, and hides it from the term in the disk table
+// if any flushed segment might contain it.
+func (s *store) DeleteTerm(field index.Field, docID common.ItemID) error {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	apply := func() error {
+		s.deleteTermInMemTables(field, docID)
+		return nil
+	}
+	if s.wal == nil {
+		_ = apply()
+	} else if err := s.logRecord(marshalWALDeleteTerm(field, docID), apply); err != nil {
+		return err
+	}
+	if !s.docBlooms.mightContain(docID) {
+		return nil
+	}
+	return s.dropped.DropTerm(field, docID)
+}
+
+// deleteTermInMemTables removes the doc from the term in the mem tables and the tails
+func (s *store) deleteTermInMemTables(field index.Field, docID common.ItemID) {
+	for _, table := range s.memTables() {
+		table.deleteTerm(field, docID)
+	}
+	if s.tails == nil {
+		return
+	}
+	if key, err := tailKey(field); err == nil {
+		s.tails.removeTerm(key, docID)
+	}
+}
+
+// deleteInMemTables removes the doc from the mem tables and the tails
+func (s *store) deleteInMemTables(docID common.ItemID) bool {
+	var deleted bool
+	for _, table := range s.memTables() {
+		if table.deleteDoc(docID) {
+			deleted = true
+		}
+	}
+	if s.tails != nil {
+		s.tails.remove(docID)
+	}
+	return deleted
+}
+
+// hideDocInDiskTable drops the doc from all the fields holding it in the disk table
+func (s *store) hideDocInDiskTable(docID common.ItemID) (bool, error) {
+	fieldKeys := make(map[string]index.FieldKey)
+	err := index.EachTerm(s.diskTable, func(key, value []byte, _ bool) error {
+		var field index.Field
+		if err := field.UnmarshalStraight(key); err != nil {
+			return err
+		}
+		fieldKey := field.Key
+		if _, ok := fieldKeys[string(fieldKey.Marshal())]; ok {
+			return nil
+		}
+		list := s.newList()
+		if err := list.Unmarshall(value); err != nil {
+			return errors.Wrapf(index.ErrMalformed, "the posting list of %x: %v", key, err)
+		}
+		if !list.Contains(docID) {
+			return nil
+		}
+		// the doc might be hidden by a former deletion
+		if err := s.dropped.Hide(fieldKey, list); err != nil {
+			return err
+		}
+		if list.Contains(docID) {
+			fieldKeys[string(fieldKey.Marshal())] = fieldKey
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, fieldKey := range fieldKeys {
+		items := s.newList()
+		items.Insert(docID)
+		err = multierr.Append(err, s.dropped.Drop(fieldKey, items))
+	}
+	return len(fieldKeys) > 0, err
+}
@@ -0,0 +1,107 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package inverted
+
+import (
+	"bytes"
+	"sort"
+
+	"go.uber.org/multierr"
+
+	"github.com/apache/skywalking-banyandb/pkg/index"
+)
+
+var _ index.BulkLoader = (*store)(nil)
+
+func (s *store) BulkLoad(entries []index.BulkEntry) error {
+	if len(entries) < 1 {
+		return nil
+	}
+	s.touch()
+	sort.Sort(bulkEntries(entries))
+	var err error
+	if s.wal == nil {
+		err = s.bulkLoad(entries)
+	} else {
+		err = s.logWrites(entries, func() error {
+			return s.bulkLoad(entries)
+		})
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		err = multierr.Append(err, s.dropped.Restore(e.Field, e.DocID))
+	}
+	return err
+}
+
+func (s *store) bulkLoad(entries []index.BulkEntry) error {
+	if err := s.memTable.bulkLoad(entries, s.sketches.insert); err != nil {
+		return err
+	}
+	if s.tails == nil {
+		return nil
+	}
+	// the tails keep the latest items of a term, which are the largest ones in a run
+	for _, e := range entries {
+		key, err := tailKey(e.Field)
+		if err != nil {
+			return err
+		}
+		s.tails.put(key, e.DocID)
+	}
+	return nil
+}
+
+type bulkEntries []index.BulkEntry
+
+func (b bulkEntries) Len() int           { return len(b) }
+func (b bulkEntries) Less(i, j int) bool { return compareBulkEntry(&b[i], &b[j]) < 0 }
+func (b bulkEntries) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+func compareBulkEntry(a, b *index.BulkEntry) int {
+	if c := compareFieldKey(&a.Field.Key, &b.Field.Key); c != 0 {
+		return c
+	}
+	if c := bytes.Compare(a.Field.Term, b.Field.Term); c != 0 {
+		return c
+	}
+	switch {
+	case a.DocID < b.DocID:
+		return -1
+	case a.DocID > b.DocID:
+		return 1
+	}
+	return 0
+}
+
+// compareFieldKey orders the keys as their marshaled forms do
+func compareFieldKey(a, b *index.FieldKey) int {
+	switch {
+	case a.SeriesID < b.SeriesID:
+		return -1
+	case a.SeriesID > b.SeriesID:
+		return 1
+	case a.IndexRuleID < b.IndexRuleID:
+		return -1
+	case a.IndexRuleID > b.IndexRuleID:
+		return 1
+	}
+	return 0
+}
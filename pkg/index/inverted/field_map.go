@@ -23,27 +23,30 @@ import (
 	"github.com/apache/skywalking-banyandb/api/common"
 	"github.com/apache/skywalking-banyandb/pkg/convert"
 	"github.com/apache/skywalking-banyandb/pkg/index"
+	"github.com/apache/skywalking-banyandb/pkg/index/posting"
 )
 
 type fieldHashID uint64
 
 type fieldMap struct {
-	repo  map[fieldHashID]*termContainer
-	lst   []fieldHashID
-	mutex sync.RWMutex
+	repo    map[fieldHashID]*termContainer
+	lst     []fieldHashID
+	newList posting.Factory
+	mutex   sync.RWMutex
 }
 
-func newFieldMap(initialSize int) *fieldMap {
+func newFieldMap(initialSize int, newList posting.Factory) *fieldMap {
 	return &fieldMap{
-		repo: make(map[fieldHashID]*termContainer, initialSize),
-		lst:  make([]fieldHashID, 0),
+		repo:    make(map[fieldHashID]*termContainer, initialSize),
+		lst:     make([]fieldHashID, 0),
+		newList: newList,
 	}
 }
 
 func (fm *fieldMap) createKey(field index.Field) *termContainer {
 	result := &termContainer{
 		key:   field.Key,
-		value: newPostingMap(),
+		value: newPostingMap(fm.newList),
 	}
 	k := fieldHashID(convert.Hash(field.Key.Marshal()))
 	fm.repo[k] = result
@@ -62,14 +65,59 @@ func (fm *fieldMap) getWithoutLock(key index.FieldKey) (*termContainer, bool) {
 	return v, ok
 }
 
-func (fm *fieldMap) put(fv index.Field, id common.ItemID) error {
+func (fm *fieldMap) put(fv index.Field, id common.ItemID) {
 	fm.mutex.Lock()
 	defer fm.mutex.Unlock()
 	pm, ok := fm.getWithoutLock(fv.Key)
 	if !ok {
 		pm = fm.createKey(fv)
 	}
-	return pm.value.put(fv.Term, id)
+	pm.value.put(fv.Term, id)
+}
+
+func (fm *fieldMap) getOrCreate(key index.FieldKey) *termContainer {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+	if pm, ok := fm.getWithoutLock(key); ok {
+		return pm
+	}
+	return fm.createKey(index.Field{Key: key})
+}
+
+func (fm *fieldMap) remove(key index.FieldKey) {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+	k := fieldHashID(convert.Hash(key.Marshal()))
+	if _, ok := fm.repo[k]; !ok {
+		return
+	}
+	delete(fm.repo, k)
+	for i, id := range fm.lst {
+		if id == k {
+			fm.lst = append(fm.lst[:i], fm.lst[i+1:]...)
+			break
+		}
+	}
+}
+
+func (fm *fieldMap) termCount() (count uint64) {
+	fm.mutex.RLock()
+	defer fm.mutex.RUnlock()
+	for _, tc := range fm.repo {
+		count += tc.value.size()
+	}
+	return count
+}
+
+func (fm *fieldMap) each(fn func(tc *termContainer) error) error {
+	fm.mutex.RLock()
+	defer fm.mutex.RUnlock()
+	for _, tc := range fm.repo {
+		if err := fn(tc); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type termContainer struct {
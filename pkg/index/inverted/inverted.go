@@ -19,7 +19,10 @@ package inverted
 
 import (
 	"bytes"
+	"context"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
@@ -34,14 +37,52 @@ import (
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 )
 
-var _ index.Store = (*store)(nil)
+var (
+	_ index.Store        = (*store)(nil)
+	_ index.TailSearcher = (*store)(nil)
+	_ index.PayloadStore = (*store)(nil)
+
+	_ index.CardinalityEstimator = (*store)(nil)
+	_ index.Durable              = (*store)(nil)
+)
 
 type store struct {
-	termMetadata      metadata.Term
-	diskTable         kv.IndexStore
-	memTable          *memTable
-	immutableMemTable *memTable
-	rwMutex           sync.RWMutex
+	termMetadata metadata.Term
+	diskTable    kv.IndexStore
+	memTable     *memTable
+	// immutables are the frozen mem tables waiting to be merged into the disk table, the oldest first
+	immutables  []*memTable
+	mergePolicy MergePolicy
+	// lastWrite is the unix nanoseconds of the latest write, which tells the compactor if the writes are idle
+	lastWrite     int64
+	stopCompactor chan struct{}
+	compactorDone chan struct{}
+	// diskZones covers all the terms ever flushed to the disk table
+	diskZones *zoneMap
+	zonePath  string
+	// sketches covers all the terms ever written, including the unflushed ones
+	sketches   *sketchMap
+	sketchPath string
+	// docBlooms covers the docs of all the flushed segments
+	docBlooms      *docBlooms
+	docBloomPath   string
+	dropped        *index.DroppedItems
+	prunedSegments uint64
+	lastMergeTime  time.Time
+	rwMutex        sync.RWMutex
+	newList        posting.Factory
+	// postingBlockSize is the default block size of the flushed posting lists
+	postingBlockSize int
+	// tails is nil unless TailSize is positive
+	tails *tailTable
+	// payloads is nil until a payload is set or the table exists
+	payloads     kv.Store
+	payloadPath  string
+	payloadMutex sync.Mutex
+	// wal is nil unless the write-ahead log is enabled
+	wal         *writeAheadLog
+	walFlushing int32
+	walFlushes  sync.WaitGroup
 
 	l *logger.Logger
 }
@@ -49,6 +90,25 @@ type store struct {
 type StoreOpts struct {
 	Path   string
 	Logger *logger.Logger
+	// PostingFactory creates the posting lists of the store. It's roaring.NewPostingList by default.
+	PostingFactory posting.Factory
+	// TailSize is the number of the latest items kept in the written order for each term, which serves TailN.
+	// Zero disables it, then TailN orders the items by their IDs.
+	TailSize int
+	// MergePolicy is MergeEager by default, see MergeLazy for the tradeoff
+	MergePolicy MergePolicy
+	// CompactIdle is how long the writes should be idle before the frozen segments are merged by MergeLazy.
+	// It's a second by default.
+	CompactIdle time.Duration
+	// WAL logs the writes before acknowledging them, so the mem tables lost by a crash are rebuilt on the next open.
+	// A write survives a crash of the process once it's acknowledged, and a crash of the OS after a Sync.
+	WAL bool
+	// WALMaxSize bounds the write-ahead log softly. The mem tables are flushed and merged in the background
+	// once the log outgrows it, which removes their segments. It's 64MB by default.
+	WALMaxSize int64
+	// PostingBlockSize encodes the flushed posting lists in blocks of the size, see posting.MarshalBlocks.
+	// A field overrides it with index.FieldKey.PostingBlockSize. Zero keeps the encoding of PostingFactory.
+	PostingBlockSize int
 }
 
 func NewStore(opts StoreOpts) (index.Store, error) {
@@ -63,48 +123,357 @@ func NewStore(opts StoreOpts) (index.Store, error) {
 	}); err != nil {
 		return nil, err
 	}
-	return &store{
-		memTable:     newMemTable(),
-		diskTable:    diskTable,
-		termMetadata: md,
-		l:            opts.Logger,
-	}, nil
+	zonePath := opts.Path + "/zone"
+	diskZones, err := loadZoneMap(zonePath)
+	if err != nil {
+		return nil, err
+	}
+	docBloomPath := opts.Path + "/docbloom"
+	blooms, err := loadDocBlooms(docBloomPath, zonePath)
+	if err != nil {
+		return nil, err
+	}
+	sketchPath := opts.Path + "/sketch"
+	sketches, err := loadSketchMap(sketchPath)
+	if err != nil {
+		return nil, err
+	}
+	newList := opts.PostingFactory
+	if newList == nil {
+		newList = roaring.NewPostingList
+	}
+	dropped, err := index.LoadDroppedItems(opts.Path+"/dropped", newList)
+	if err != nil {
+		return nil, err
+	}
+	s := &store{
+		dropped:          dropped,
+		payloadPath:      opts.Path + "/payload",
+		memTable:         newMemTable(newList),
+		diskTable:        diskTable,
+		diskZones:        diskZones,
+		zonePath:         zonePath,
+		sketches:         sketches,
+		sketchPath:       sketchPath,
+		docBlooms:        blooms,
+		docBloomPath:     docBloomPath,
+		termMetadata:     md,
+		newList:          newList,
+		mergePolicy:      opts.MergePolicy,
+		l:                opts.Logger,
+		postingBlockSize: opts.PostingBlockSize,
+	}
+	if opts.TailSize > 0 {
+		s.tails = newTailTable(opts.TailSize)
+	}
+	if opts.WAL {
+		if s.wal, err = openWAL(opts.Path+"/wal", opts.WALMaxSize, s.replayer()); err != nil {
+			return nil, err
+		}
+	}
+	if s.mergePolicy == MergeLazy {
+		idle := opts.CompactIdle
+		if idle <= 0 {
+			idle = defaultCompactIdle
+		}
+		s.stopCompactor = make(chan struct{})
+		s.compactorDone = make(chan struct{})
+		go s.compact(idle)
+	}
+	return s, nil
 }
 
+// Close merges the frozen segments before closing the tables.
+// The live mem table isn't flushed, it's rebuilt from the write-ahead log on the next open if the log is enabled.
 func (s *store) Close() error {
-	return multierr.Combine(s.diskTable.Close(), s.termMetadata.Close())
+	var err error
+	s.walFlushes.Wait()
+	if s.stopCompactor != nil {
+		close(s.stopCompactor)
+		<-s.compactorDone
+		err = s.mergeAll()
+	}
+	if s.wal != nil {
+		err = multierr.Append(err, s.wal.close())
+	}
+	return multierr.Combine(err, s.diskTable.Close(), s.termMetadata.Close(), s.closePayloadTable())
 }
 
 func (s *store) Write(field index.Field, chunkID common.ItemID) error {
-	return s.memTable.Write(field, chunkID)
+	s.touch()
+	w, err := s.prepareWrite(field)
+	if err != nil {
+		return err
+	}
+	if s.wal == nil {
+		s.applyWrite(w, chunkID)
+	} else if err = s.logWrites([]index.BulkEntry{{Field: field, DocID: chunkID}}, func() error {
+		s.applyWrite(w, chunkID)
+		return nil
+	}); err != nil {
+		return err
+	}
+	return s.dropped.Restore(field, chunkID)
 }
 
+func (s *store) write(field index.Field, chunkID common.ItemID) error {
+	w, err := s.prepareWrite(field)
+	if err != nil {
+		return err
+	}
+	s.applyWrite(w, chunkID)
+	return nil
+}
+
+// preparedWrite is a field checked and marshaled by prepareWrite, whose applying can't fail
+type preparedWrite struct {
+	field   index.Field
+	tailKey string
+}
+
+// prepareWrite does all that might fail in a write without touching the store
+func (s *store) prepareWrite(field index.Field) (preparedWrite, error) {
+	if _, err := index.GetComparator(field.Key.Comparator); err != nil {
+		return preparedWrite{}, err
+	}
+	w := preparedWrite{field: field}
+	if s.tails == nil {
+		return w, nil
+	}
+	key, err := tailKey(field)
+	if err != nil {
+		return preparedWrite{}, err
+	}
+	w.tailKey = key
+	return w, nil
+}
+
+func (s *store) applyWrite(w preparedWrite, chunkID common.ItemID) {
+	s.memTable.insert(w.field, chunkID)
+	s.sketches.insert(w.field)
+	if s.tails != nil {
+		s.tails.put(w.tailKey, chunkID)
+	}
+}
+
+// Flush hands the mem table over to the disk table, then saves the zones and sketches of the flushed terms.
+// Nothing is fsynced, see Sync.
+// MergeLazy only freezes the mem table unless the frozen ones are more than maxLazySegments.
 func (s *store) Flush() error {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
-	if s.immutableMemTable == nil {
-		s.immutableMemTable = s.memTable
-		s.memTable = newMemTable()
+	if s.mergePolicy == MergeLazy {
+		if s.memTable.termCount() > 0 {
+			if err := s.freeze(); err != nil {
+				return err
+			}
+		}
+		for len(s.immutables) > maxLazySegments {
+			if err := s.mergeOldest(); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
+	// the tables left by a failed flush are merged first
+	if err := s.freeze(); err != nil {
+		return err
+	}
+	for len(s.immutables) > 0 {
+		if err := s.mergeOldest(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeOldest hands the oldest frozen table over to the disk table. The caller should hold the write lock.
+func (s *store) mergeOldest() error {
+	table := s.immutables[0]
 	err := s.diskTable.
-		Handover(s.immutableMemTable.Iter(s.termMetadata))
+		Handover(table.Iter(s.termMetadata, s.postingBlockSize))
 	if err != nil {
 		return err
 	}
-	s.immutableMemTable = nil
+	// the blooms are saved before the zones, see loadDocBlooms
+	if bloom := table.docBloom(); bloom != nil {
+		s.docBlooms.add(bloom)
+	}
+	if err = s.docBlooms.save(s.docBloomPath); err != nil {
+		return err
+	}
+	// the zones are saved after the terms, so they never miss a flushed term
+	if err = s.diskZones.merge(table.zones); err != nil {
+		return err
+	}
+	if err = s.diskZones.save(s.zonePath); err != nil {
+		return err
+	}
+	if err = s.sketches.save(s.sketchPath); err != nil {
+		return err
+	}
+	if s.wal != nil {
+		if err = s.wal.release(); err != nil {
+			return err
+		}
+	}
+	s.immutables[0] = nil
+	s.immutables = s.immutables[1:]
+	s.lastMergeTime = time.Now()
 	return nil
 }
 
+// Sync fsyncs the disk table, the term metadata, the payloads, the write-ahead log and the side files.
+// The files are replaced by renames on Flush or DropField, so their directory is fsynced as well.
+// The segments frozen by MergeLazy are merged beforehand.
+func (s *store) Sync() error {
+	if s.mergePolicy == MergeLazy {
+		if err := s.mergeAll(); err != nil {
+			return err
+		}
+	}
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	err := multierr.Combine(s.diskTable.Sync(), s.termMetadata.Sync(), s.dropped.Sync())
+	for _, path := range []string{s.zonePath, s.sketchPath, s.docBloomPath} {
+		err = multierr.Append(err, index.SyncFile(path))
+	}
+	if s.wal != nil {
+		err = multierr.Append(err, s.wal.sync())
+	}
+	s.payloadMutex.Lock()
+	defer s.payloadMutex.Unlock()
+	if s.payloads != nil {
+		err = multierr.Append(err, s.payloads.Sync())
+	}
+	return err
+}
+
+// DropField removes the field from the mem tables, and hides its items in the disk table.
+// The zones of the field are kept, which only makes the pruning less effective.
+func (s *store) DropField(fieldKey index.FieldKey) error {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	apply := func() error {
+		s.dropInMemTables(fieldKey)
+		return nil
+	}
+	if s.wal == nil {
+		_ = apply()
+	} else if err := s.logRecord(marshalWALDropField(fieldKey), apply); err != nil {
+		return err
+	}
+	if err := s.sketches.save(s.sketchPath); err != nil {
+		return err
+	}
+	iter, err := s.diskIterator(fieldKey, index.RangeOpts{}, modelv1.Sort_SORT_ASC)
+	if err != nil {
+		return err
+	}
+	items := s.newList()
+	for iter.Next() {
+		err = multierr.Append(err, items.Union(iter.Val().Value))
+	}
+	if err = multierr.Append(err, iter.Close()); err != nil {
+		return err
+	}
+	return s.dropped.Drop(fieldKey, items)
+}
+
+// dropInMemTables removes the field from the mem tables, the tails and the sketches
+func (s *store) dropInMemTables(fieldKey index.FieldKey) {
+	for _, table := range s.memTables() {
+		table.fields.remove(fieldKey)
+	}
+	if s.tails != nil {
+		s.tails.drop(fieldKey)
+	}
+	s.sketches.remove(fieldKey)
+}
+
+func (s *store) ApproxDistinctTermCount(fieldKey index.FieldKey) (uint64, error) {
+	return s.sketches.estimate(fieldKey), nil
+}
+
+func (s *store) Stats() index.Stats {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	diskStats := s.diskTable.Stats()
+	stats := index.Stats{
+		SegmentCount:   diskStats.TableCount,
+		TotalPostings:  diskStats.KeyCount,
+		BytesOnDisk:    diskStats.Size,
+		LastMergeTime:  s.lastMergeTime,
+		PrunedSegments: atomic.LoadUint64(&s.prunedSegments),
+	}
+	if s.wal != nil {
+		stats.WALSize = s.wal.totalSize()
+	}
+	for _, table := range s.memTables() {
+		stats.SegmentCount++
+		stats.TotalPostings += table.termCount()
+	}
+	return stats
+}
+
+func (s *store) IndexStats() (index.IndexStats, error) {
+	s.rwMutex.RLock()
+	defer s.rwMutex.RUnlock()
+	stats := index.IndexStats{
+		BytesOnDisk: s.diskTable.Stats().Size,
+	}
+	// a term might be in both the mem tables and the disk table, it's only counted once
+	memTerms := make(map[string]struct{})
+	for _, table := range s.memTables() {
+		err := table.eachTerm(func(field index.Field, list posting.List) error {
+			key, err := field.Marshal(s.termMetadata)
+			if err != nil {
+				return err
+			}
+			memTerms[string(key)] = struct{}{}
+			stats.PostingCount += uint64(list.Len())
+			return nil
+		})
+		if err != nil {
+			return index.IndexStats{}, err
+		}
+	}
+	stats.TermCount = uint64(len(memTerms))
+	err := index.EachTerm(s.diskTable, func(key, value []byte, latest bool) error {
+		// the disk table only serves the latest version of a term
+		if !latest {
+			return nil
+		}
+		if _, ok := memTerms[string(key)]; !ok {
+			stats.TermCount++
+		}
+		list := s.newList()
+		if err := list.Unmarshall(value); err != nil {
+			return errors.Wrapf(index.ErrMalformed, "the posting list of %x: %v", key, err)
+		}
+		stats.PostingCount += uint64(list.Len())
+		return nil
+	})
+	if err != nil {
+		return index.IndexStats{}, err
+	}
+	return stats, nil
+}
+
 func (s *store) MatchField(fieldKey index.FieldKey) (posting.List, error) {
 	return s.Range(fieldKey, index.RangeOpts{})
 }
 
 func (s *store) MatchTerms(field index.Field) (posting.List, error) {
+	return index.MatchWithAlias(field, s.matchTerms)
+}
+
+func (s *store) matchTerms(field index.Field) (posting.List, error) {
 	f, err := field.Marshal(s.termMetadata)
 	if err != nil {
 		return nil, err
 	}
-	result := roaring.NewPostingList()
+	result := s.newList()
 	result, errMem := s.searchInMemTables(result, func(table *memTable) (posting.List, error) {
 		list, errInner := table.MatchTerms(field)
 		if errInner != nil {
@@ -115,16 +484,14 @@ func (s *store) MatchTerms(field index.Field) (posting.List, error) {
 	if errMem != nil {
 		return nil, errors.Wrap(errMem, "mem table of inverted index")
 	}
-	raw, errTable := s.diskTable.Get(f)
-	switch {
-	case errors.Is(errTable, kv.ErrKeyNotFound):
+	list, err := s.diskMatch(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "disk table of inverted index")
+	}
+	if list == nil {
 		return result, nil
-	case errTable != nil:
-		return nil, errors.Wrap(errTable, "disk table of inverted index")
 	}
-	list := roaring.NewPostingList()
-	err = list.Unmarshall(raw)
-	if err != nil {
+	if err = s.dropped.HideTerm(field, list); err != nil {
 		return nil, err
 	}
 	err = result.Union(list)
@@ -134,15 +501,38 @@ func (s *store) MatchTerms(field index.Field) (posting.List, error) {
 	return result, nil
 }
 
+// diskMatch unions all the versions of the term, since each merge of a segment holding the term writes a version.
+// It's nil if the term is absent.
+func (s *store) diskMatch(key []byte) (list posting.List, err error) {
+	iter := s.diskTable.NewIterator(kv.ScanOpts{Prefix: key, PrefetchValues: true})
+	defer func() {
+		err = multierr.Append(err, iter.Close())
+	}()
+	for iter.Seek(key); iter.Valid() && bytes.Equal(iter.Key(), key); iter.Next() {
+		l := s.newList()
+		if err = l.Unmarshall(iter.Val()); err != nil {
+			return nil, err
+		}
+		if list == nil {
+			list = l
+			continue
+		}
+		if err = list.Union(l); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
 func (s *store) Range(fieldKey index.FieldKey, opts index.RangeOpts) (list posting.List, err error) {
 	iter, err := s.Iterator(fieldKey, opts, modelv1.Sort_SORT_ASC)
 	if err != nil {
-		return roaring.EmptyPostingList, err
+		return s.newList(), err
 	}
 	if iter == nil {
-		return roaring.EmptyPostingList, nil
+		return s.newList(), nil
 	}
-	list = roaring.NewPostingList()
+	list = s.newList()
 	for iter.Next() {
 		err = multierr.Append(err, list.Union(iter.Val().Value))
 	}
@@ -150,14 +540,101 @@ func (s *store) Range(fieldKey index.FieldKey, opts index.RangeOpts) (list posti
 	return
 }
 
+func (s *store) RangeWithin(fieldKey index.FieldKey, opts index.RangeOpts, within posting.List) (list posting.List, err error) {
+	if within == nil || within.IsEmpty() {
+		return s.newList(), nil
+	}
+	iter, err := s.Iterator(fieldKey, opts, modelv1.Sort_SORT_ASC)
+	if err != nil {
+		return s.newList(), err
+	}
+	if iter == nil {
+		return s.newList(), nil
+	}
+	list = s.newList()
+	// remaining holds the items which haven't been hit yet, the scan stops once all of them are found
+	remaining := within.Clone()
+	for !remaining.IsEmpty() && iter.Next() {
+		hit := remaining.Clone()
+		err = multierr.Append(err, hit.Intersect(iter.Val().Value))
+		err = multierr.Append(err, list.Union(hit))
+		err = multierr.Append(err, remaining.Difference(hit))
+	}
+	err = multierr.Append(err, iter.Close())
+	return
+}
+
+func (s *store) MatchTermWithinRange(termField index.Field, rangeField index.FieldKey, opts index.RangeOpts) (list posting.List, err error) {
+	within, err := s.MatchTerms(termField)
+	if err != nil {
+		return nil, err
+	}
+	return s.RangeWithin(rangeField, opts, within)
+}
+
+// Warmup only reads the disk table, the mem tables are always in memory
+func (s *store) Warmup(ctx context.Context, fieldKeys []index.FieldKey) (int64, error) {
+	return index.WarmupIterable(ctx, s.diskTable, fieldKeys)
+}
+
+func (s *store) SortedFieldIterator(fieldKey index.FieldKey, within posting.List,
+	order modelv1.Sort) (index.SortedItemIterator, error) {
+	iter, err := s.Iterator(fieldKey, index.RangeOpts{}, order)
+	if err != nil {
+		return nil, err
+	}
+	return index.NewSortedFieldIterator(iter, within), nil
+}
+
+func (s *store) MinTerm(fieldKey index.FieldKey) ([]byte, error) {
+	return index.BoundaryTerm(s, fieldKey, modelv1.Sort_SORT_ASC)
+}
+
+func (s *store) MaxTerm(fieldKey index.FieldKey) ([]byte, error) {
+	return index.BoundaryTerm(s, fieldKey, modelv1.Sort_SORT_DESC)
+}
+
+func (s *store) PrefixFieldIterator(fieldKey index.FieldKey, prefix []byte, order modelv1.Sort) (index.FieldIterator, error) {
+	opts, err := index.PrefixRange(fieldKey, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return s.Iterator(fieldKey, opts, order)
+}
+
+// Iterator merges the terms of the alias into the ones of the field if the field has an alias
 func (s *store) Iterator(fieldKey index.FieldKey, termRange index.RangeOpts,
 	order modelv1.Sort) (index.FieldIterator, error) {
+	iters, err := s.iterators(fieldKey, termRange, order)
+	if err != nil {
+		return nil, err
+	}
+	if alias, ok := fieldKey.AliasKey(); ok {
+		aliasIters, aliasErr := s.iterators(alias, termRange, order)
+		if aliasErr != nil {
+			for _, it := range iters {
+				aliasErr = multierr.Append(aliasErr, it.Close())
+			}
+			return nil, aliasErr
+		}
+		iters = append(iters, aliasIters...)
+	}
+	return s.merge(iters, fieldKey, order)
+}
+
+// iterators returns an iterator per segment overlapping the range
+func (s *store) iterators(fieldKey index.FieldKey, termRange index.RangeOpts,
+	order modelv1.Sort) ([]index.FieldIterator, error) {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
-	tt := []*memTable{s.memTable, s.immutableMemTable}
+	tt := s.memTables()
 	iters := make([]index.FieldIterator, 0, len(tt)+1)
 	for _, table := range tt {
-		if table == nil {
+		ok, err := s.overlaps(table.zones, fieldKey, termRange)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
 			continue
 		}
 		it, err := table.Iterator(fieldKey, termRange, order)
@@ -169,9 +646,24 @@ func (s *store) Iterator(fieldKey index.FieldKey, termRange index.RangeOpts,
 		}
 		iters = append(iters, it)
 	}
-	it, err := index.NewFieldIteratorTemplate(s.l, fieldKey, termRange, order, s.diskTable, s.termMetadata,
+	ok, err := s.overlaps(s.diskZones, fieldKey, termRange)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return iters, nil
+	}
+	it, err := s.diskIterator(fieldKey, termRange, order)
+	if err != nil {
+		return nil, err
+	}
+	return append(iters, it), nil
+}
+
+func (s *store) diskIterator(fieldKey index.FieldKey, termRange index.RangeOpts, order modelv1.Sort) (index.FieldIterator, error) {
+	return index.NewFieldIteratorTemplate(s.l, fieldKey, termRange, order, s.diskTable, s.termMetadata,
 		func(term, val []byte, delegated kv.Iterator) (*index.PostingValue, error) {
-			list := roaring.NewPostingList()
+			list := s.newList()
 			err := list.Unmarshall(val)
 			if err != nil {
 				return nil, err
@@ -193,7 +685,7 @@ func (s *store) Iterator(fieldKey index.FieldKey, termRange index.RangeOpts,
 				if !bytes.Equal(f.Term, term) {
 					break
 				}
-				l := roaring.NewPostingList()
+				l := s.newList()
 				err = l.Unmarshall(delegated.Val())
 				if err != nil {
 					return nil, err
@@ -203,37 +695,32 @@ func (s *store) Iterator(fieldKey index.FieldKey, termRange index.RangeOpts,
 					return nil, err
 				}
 			}
-			return pv, nil
+			return pv, s.dropped.HideTerm(index.Field{Key: fieldKey, Term: term}, pv.Value)
 		})
-	if err != nil {
-		return nil, err
+}
+
+// overlaps counts the segment as pruned if the zone of the field doesn't overlap the range
+func (s *store) overlaps(zones *zoneMap, fieldKey index.FieldKey, termRange index.RangeOpts) (bool, error) {
+	ok, err := zones.overlaps(fieldKey, termRange)
+	if err == nil && !ok {
+		atomic.AddUint64(&s.prunedSegments, 1)
 	}
-	iters = append(iters, it)
+	return ok, err
+}
+
+func (s *store) merge(iters []index.FieldIterator, fieldKey index.FieldKey, order modelv1.Sort) (index.FieldIterator, error) {
 	if len(iters) < 1 {
 		return nil, nil
 	}
-	var fn index.SwitchFn
-	switch order {
-	case modelv1.Sort_SORT_ASC, modelv1.Sort_SORT_UNSPECIFIED:
-		fn = func(a, b []byte) bool {
-			return bytes.Compare(a, b) > 0
-		}
-	case modelv1.Sort_SORT_DESC:
-		fn = func(a, b []byte) bool {
-			return bytes.Compare(a, b) < 0
-		}
-	}
-	return index.NewMergedIterator(iters, fn), nil
+	// The merged iterator follows the byte-wise order, it's reordered by the comparator of the field if necessary
+	return index.SortTerms(index.NewMergedIterator(iters, index.ByteWiseSwitch(order)), fieldKey, order)
 }
 
 func (s *store) searchInMemTables(result posting.List, entityFunc entityFunc) (posting.List, error) {
 	s.rwMutex.RLock()
 	defer s.rwMutex.RUnlock()
-	tt := []*memTable{s.memTable, s.immutableMemTable}
+	tt := s.memTables()
 	for _, table := range tt {
-		if table == nil {
-			continue
-		}
 		list, err := entityFunc(table)
 		if err != nil {
 			return result, err
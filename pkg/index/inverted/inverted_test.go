@@ -18,15 +18,26 @@
 package inverted
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/apache/skywalking-banyandb/api/common"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/banyand/kv"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+	"github.com/apache/skywalking-banyandb/pkg/index"
 	"github.com/apache/skywalking-banyandb/pkg/index/posting"
 	"github.com/apache/skywalking-banyandb/pkg/index/posting/roaring"
 	"github.com/apache/skywalking-banyandb/pkg/index/testcases"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
+	pbv1 "github.com/apache/skywalking-banyandb/pkg/pb/v1"
 	"github.com/apache/skywalking-banyandb/pkg/test"
 )
 
@@ -63,6 +74,51 @@ func TestStore_MatchTerm_AfterFlush(t *testing.T) {
 	testcases.RunServiceName(t, s)
 }
 
+func TestStore_Stats(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUp(tester, s)
+	stats := s.Stats()
+	tester.Equal(1, stats.SegmentCount)
+	tester.Greater(stats.TotalPostings, uint64(0))
+	tester.Zero(stats.BytesOnDisk)
+	tester.True(stats.LastMergeTime.IsZero())
+
+	tester.NoError(s.(*store).Flush())
+	flushed := s.Stats()
+	tester.Greater(flushed.SegmentCount, 1)
+	tester.Equal(stats.TotalPostings, flushed.TotalPostings)
+	tester.Greater(flushed.BytesOnDisk, int64(0))
+	tester.False(flushed.LastMergeTime.IsZero())
+}
+
+func TestStore_MatchField(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUpDBStatement(tester, s)
+	testcases.RunMatchField(t, s)
+	tester.NoError(s.(*store).Flush())
+	testcases.RunMatchField(t, s)
+}
+
 func TestStore_Iterator(t *testing.T) {
 	tester := assert.New(t)
 	path, fn := setUp(require.New(t))
@@ -133,6 +189,848 @@ func TestStore_Iterator_Hybrid(t *testing.T) {
 	testcases.RunDuration(t, data, s)
 }
 
+func TestStore_RangeWithin(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	data := testcases.SetUpDuration(tester, s)
+	testcases.RunDurationRangeWithin(t, data, s)
+	tester.NoError(s.(*store).Flush())
+	testcases.RunDurationRangeWithin(t, data, s)
+}
+
+func TestStore_MatchTermWithinRange(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	data := testcases.SetUpDuration(tester, s)
+	services := testcases.SetUpDurationService(tester, s)
+	testcases.RunDurationMatchTermWithinRange(t, data, services, s)
+	tester.NoError(s.(*store).Flush())
+	testcases.RunDurationMatchTermWithinRange(t, data, services, s)
+}
+
+func TestStore_SortedFieldIterator(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	data := testcases.SetUpDuration(tester, s)
+	testcases.RunDurationSorted(t, data, s)
+	tester.NoError(s.(*store).Flush())
+	testcases.RunDurationSorted(t, data, s)
+}
+
+func TestStore_MatchAnalyzedTerm(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	key := index.FieldKey{IndexRuleID: 10}
+	for i, msg := range []string{"GET /home failed", "POST /login failed", "GET /home"} {
+		for _, term := range index.StandardAnalyzer.Analyze([]byte(msg)) {
+			tester.NoError(s.Write(index.Field{Key: key, Term: term}, common.ItemID(i)))
+		}
+	}
+	tester.NoError(s.(*store).Flush())
+	list, err := s.MatchTerms(index.Field{Key: key, Term: []byte("failed")})
+	tester.NoError(err)
+	tester.Equal([]common.ItemID{0, 1}, list.ToSlice())
+	list, err = s.MatchTerms(index.Field{Key: key, Term: []byte("home")})
+	tester.NoError(err)
+	tester.Equal([]common.ItemID{0, 2}, list.ToSlice())
+}
+
+func TestStore_StringRange_AfterFlush(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUpEndpoint(tester, s)
+	tester.NoError(s.(*store).Flush())
+	testcases.RunEndpointRange(t, s)
+}
+
+func TestStore_PrefixFieldIterator(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUpEndpoint(tester, s)
+	testcases.RunEndpointPrefix(t, s)
+	tester.NoError(s.(*store).Flush())
+	testcases.RunEndpointPrefix(t, s)
+}
+
+func TestStore_IndexStats(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUpEndpoint(tester, s)
+	testcases.RunEndpointIndexStats(t, s)
+	tester.NoError(s.(*store).Flush())
+	testcases.RunEndpointIndexStats(t, s)
+	stats, err := s.IndexStats()
+	tester.NoError(err)
+	tester.Greater(stats.BytesOnDisk, int64(0))
+
+	// the term in both the mem table and the disk table is counted once
+	tester.NoError(s.Write(index.Field{
+		Key:  index.FieldKey{IndexRuleID: 4},
+		Term: []byte("/"),
+	}, 100))
+	hybrid, err := s.IndexStats()
+	tester.NoError(err)
+	tester.Equal(stats.TermCount, hybrid.TermCount)
+	tester.Equal(stats.PostingCount+1, hybrid.PostingCount)
+}
+
+func TestStore_MinMaxTerm(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUpEndpoint(tester, s)
+	testcases.RunEndpointMinMaxTerm(t, s)
+	tester.NoError(s.(*store).Flush())
+	testcases.RunEndpointMinMaxTerm(t, s)
+
+	// a dropped field has no terms
+	tester.NoError(s.DropField(index.FieldKey{IndexRuleID: 4}))
+	_, err = s.MinTerm(index.FieldKey{IndexRuleID: 4})
+	tester.ErrorIs(err, index.ErrNoTerm)
+}
+
+func TestStore_FieldAlias(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUpEndpoint(tester, s)
+	tester.NoError(s.(*store).Flush())
+	testcases.RunEndpointAlias(t, s)
+}
+
+func TestStore_ApproxDistinctTermCount(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	defer fn()
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	tester.NoError(err)
+	fieldKey := index.FieldKey{IndexRuleID: 1}
+	for i := 0; i < 2000; i++ {
+		tester.NoError(s.Write(index.Field{
+			Key:  fieldKey,
+			Term: []byte(fmt.Sprintf("term-%d", i%1000)),
+		}, common.ItemID(i)))
+	}
+	estimator := s.(index.CardinalityEstimator)
+	exact, err := index.DistinctTermCount(s, fieldKey)
+	tester.NoError(err)
+	tester.Equal(uint64(1000), exact)
+	approx, err := estimator.ApproxDistinctTermCount(fieldKey)
+	tester.NoError(err)
+	tester.InDelta(exact, approx, float64(exact)*0.05)
+	absent, err := estimator.ApproxDistinctTermCount(index.FieldKey{IndexRuleID: 2})
+	tester.NoError(err)
+	tester.Zero(absent)
+
+	// the sketches survive the reopening after the flush
+	tester.NoError(s.(*store).Flush())
+	tester.NoError(s.Close())
+	s, err = NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	reopened, err := s.(index.CardinalityEstimator).ApproxDistinctTermCount(fieldKey)
+	tester.NoError(err)
+	tester.Equal(approx, reopened)
+
+	tester.NoError(s.(*store).DropField(fieldKey))
+	dropped, err := s.(index.CardinalityEstimator).ApproxDistinctTermCount(fieldKey)
+	tester.NoError(err)
+	tester.Zero(dropped)
+}
+
+func TestStore_FlushBeforeSync(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	defer fn()
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	tester.NoError(err)
+	fieldKey := index.FieldKey{IndexRuleID: 1}
+	field := func(term string) index.Field {
+		return index.Field{Key: fieldKey, Term: []byte(term)}
+	}
+	tester.NoError(s.Write(field("flushed"), common.ItemID(1)))
+	tester.NoError(s.(*store).Flush())
+	tester.NoError(s.Write(field("buffered"), common.ItemID(2)))
+
+	// simulate a kill after the flush but before the sync. Close drops the buffer without flushing it, just like a kill.
+	tester.NoError(s.Close())
+	s, err = NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	list, err := s.MatchTerms(field("flushed"))
+	tester.NoError(err)
+	tester.Equal([]common.ItemID{1}, list.ToSlice())
+	list, err = s.MatchTerms(field("buffered"))
+	tester.NoError(err)
+	tester.True(list.IsEmpty())
+
+	// the sync doesn't flush the buffer
+	tester.NoError(s.Write(field("buffered"), common.ItemID(2)))
+	before := s.Stats()
+	tester.NoError(s.(index.Durable).Sync())
+	after := s.Stats()
+	tester.Equal(before.SegmentCount, after.SegmentCount)
+	tester.Equal(before.BytesOnDisk, after.BytesOnDisk)
+}
+
+func TestStore_MergeLazy(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	defer fn()
+	opts := StoreOpts{
+		Path:        path,
+		Logger:      logger.GetLogger("test"),
+		MergePolicy: MergeLazy,
+		CompactIdle: time.Hour,
+	}
+	s, err := NewStore(opts)
+	tester.NoError(err)
+	fieldKey := index.FieldKey{IndexRuleID: 1}
+	field := index.Field{Key: fieldKey, Term: []byte("svc")}
+	match := func() []common.ItemID {
+		list, errMatch := s.MatchTerms(field)
+		tester.NoError(errMatch)
+		items := list.ToSlice()
+		sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+		return items
+	}
+	// each flush freezes a segment, the searches merge them
+	for i := 1; i <= 3; i++ {
+		tester.NoError(s.Write(field, common.ItemID(i)))
+		tester.NoError(s.(*store).Flush())
+	}
+	tester.NoError(s.Write(field, common.ItemID(4)))
+	tester.Equal([]common.ItemID{1, 2, 3, 4}, match())
+	list, err := s.MatchField(fieldKey)
+	tester.NoError(err)
+	tester.Equal(4, list.Len())
+	stats := s.Stats()
+	tester.Equal(4, stats.SegmentCount)
+	tester.True(stats.LastMergeTime.IsZero())
+
+	// the oldest segments are merged beyond the limit
+	for i := 0; i < maxLazySegments; i++ {
+		tester.NoError(s.Write(field, common.ItemID(10+i)))
+		tester.NoError(s.(*store).Flush())
+	}
+	tester.Len(s.(*store).immutables, maxLazySegments)
+	tester.False(s.Stats().LastMergeTime.IsZero())
+	tester.Len(match(), 4+maxLazySegments)
+
+	// the sync merges all the frozen segments
+	tester.NoError(s.(index.Durable).Sync())
+	tester.Empty(s.(*store).immutables)
+	tester.Len(match(), 4+maxLazySegments)
+
+	// the frozen segments survive a close
+	tester.NoError(s.Write(field, common.ItemID(100)))
+	tester.NoError(s.(*store).Flush())
+	tester.NoError(s.Close())
+	opts.CompactIdle = 10 * time.Millisecond
+	s, err = NewStore(opts)
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	tester.Len(match(), 5+maxLazySegments)
+
+	// the compactor merges the segments once the writes are idle
+	tester.NoError(s.Write(field, common.ItemID(101)))
+	tester.NoError(s.(*store).Flush())
+	tester.Eventually(func() bool {
+		st := s.(*store)
+		st.rwMutex.RLock()
+		defer st.rwMutex.RUnlock()
+		return len(st.immutables) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	tester.Len(match(), 6+maxLazySegments)
+}
+
+func TestStore_DocTxn(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	defer fn()
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	service := index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte("svc")}
+	endpoint := index.Field{Key: index.FieldKey{IndexRuleID: 2}, Term: []byte("/home")}
+	match := func(field index.Field) []common.ItemID {
+		list, errMatch := s.MatchTerms(field)
+		tester.NoError(errMatch)
+		return list.ToSlice()
+	}
+
+	txn := s.(index.DocWriter).BeginDoc(common.ItemID(1)).Add(service).Add(endpoint)
+	tester.Empty(match(service))
+	tester.NoError(txn.Commit())
+	tester.Equal([]common.ItemID{1}, match(service))
+	tester.Equal([]common.ItemID{1}, match(endpoint))
+	tester.ErrorIs(txn.Commit(), index.ErrTxnDone)
+
+	txn = s.(index.DocWriter).BeginDoc(common.ItemID(2)).Add(service).Add(endpoint)
+	txn.Rollback()
+	tester.ErrorIs(txn.Commit(), index.ErrTxnDone)
+	tester.Equal([]common.ItemID{1}, match(service))
+	tester.Equal([]common.ItemID{1}, match(endpoint))
+}
+
+func TestStore_DeleteDoc(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	defer fn()
+	s, err := NewStore(StoreOpts{
+		Path:     path,
+		Logger:   logger.GetLogger("test"),
+		TailSize: 10,
+	})
+	tester.NoError(err)
+	service := index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte("svc")}
+	endpoint := index.Field{Key: index.FieldKey{IndexRuleID: 2}, Term: []byte("/home")}
+	match := func(field index.Field) []common.ItemID {
+		list, errMatch := s.MatchTerms(field)
+		tester.NoError(errMatch)
+		items := list.ToSlice()
+		sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+		return items
+	}
+	for i := 1; i <= 3; i++ {
+		tester.NoError(s.Write(service, common.ItemID(i)))
+		tester.NoError(s.Write(endpoint, common.ItemID(i)))
+	}
+	tester.NoError(s.(*store).Flush())
+	tester.NoError(s.Write(service, common.ItemID(4)))
+	deleter := s.(index.DocDeleter)
+
+	// the flushed doc is hidden in the disk table
+	deleted, err := deleter.DeleteDoc(common.ItemID(2))
+	tester.NoError(err)
+	tester.True(deleted)
+	// the buffered doc is removed from the mem table
+	deleted, err = deleter.DeleteDoc(common.ItemID(4))
+	tester.NoError(err)
+	tester.True(deleted)
+	tester.Equal([]common.ItemID{1, 3}, match(service))
+	tester.Equal([]common.ItemID{1, 3}, match(endpoint))
+	tail, err := s.(index.TailSearcher).TailN(service, 10)
+	tester.NoError(err)
+	tester.Equal([]common.ItemID{3, 1}, tail)
+
+	// the blooms rule the absent docs out
+	tester.False(s.(*store).docBlooms.mightContain(common.ItemID(100)))
+	deleted, err = deleter.DeleteDoc(common.ItemID(100))
+	tester.NoError(err)
+	tester.False(deleted)
+	deleted, err = deleter.DeleteDoc(common.ItemID(2))
+	tester.NoError(err)
+	tester.False(deleted)
+
+	tester.NoError(s.Close())
+	s, err = NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	tester.Equal([]common.ItemID{1, 3}, match(service))
+	tester.True(s.(*store).docBlooms.mightContain(common.ItemID(1)))
+	tester.False(s.(*store).docBlooms.mightContain(common.ItemID(100)))
+}
+
+func TestStore_DeleteTerm(t *testing.T) {
+	tester := assert.New(t)
+	is := require.New(t)
+	path, fn := setUp(is)
+	defer fn()
+	s, err := NewStore(StoreOpts{
+		Path:     path,
+		Logger:   logger.GetLogger("test"),
+		TailSize: 10,
+	})
+	is.NoError(err)
+	minute := index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte("minute")}
+	hour := index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte("hour")}
+	endpoint := index.Field{Key: index.FieldKey{IndexRuleID: 2}, Term: []byte("/home")}
+	match := func(field index.Field) []common.ItemID {
+		list, errMatch := s.MatchTerms(field)
+		is.NoError(errMatch)
+		items := list.ToSlice()
+		sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+		return items
+	}
+	for i := 1; i <= 3; i++ {
+		is.NoError(s.Write(minute, common.ItemID(i)))
+		is.NoError(s.Write(endpoint, common.ItemID(i)))
+	}
+	is.NoError(s.(*store).Flush())
+	is.NoError(s.Write(minute, common.ItemID(4)))
+
+	// the flushed item is hidden from the term in the disk table, and the buffered one is removed from the mem table
+	is.NoError(s.DeleteTerm(minute, common.ItemID(2)))
+	is.NoError(s.DeleteTerm(minute, common.ItemID(4)))
+	is.NoError(s.Write(hour, common.ItemID(2)))
+	tester.Equal([]common.ItemID{1, 3}, match(minute))
+	tester.Equal([]common.ItemID{2}, match(hour))
+	tester.Equal([]common.ItemID{1, 2, 3}, match(endpoint))
+	list, err := s.MatchField(minute.Key)
+	is.NoError(err)
+	tester.Equal(3, list.Len())
+	tail, err := s.(index.TailSearcher).TailN(minute, 10)
+	is.NoError(err)
+	tester.Equal([]common.ItemID{3, 1}, tail)
+
+	// writing the term again shows the item
+	is.NoError(s.Write(minute, common.ItemID(2)))
+	tester.Equal([]common.ItemID{1, 2, 3}, match(minute))
+	is.NoError(s.DeleteTerm(minute, common.ItemID(3)))
+	is.NoError(s.Close())
+
+	// the deletions in both the disk table and the write-ahead log survive reopening
+	s, err = NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	is.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	tester.Equal([]common.ItemID{1, 2}, match(minute))
+	tester.Equal([]common.ItemID{1, 2, 3}, match(endpoint))
+}
+
+func TestStore_BulkLoad(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	defer fn()
+	s, err := NewStore(StoreOpts{
+		Path:     path,
+		Logger:   logger.GetLogger("test"),
+		TailSize: 10,
+	})
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	service := index.FieldKey{IndexRuleID: 1}
+	duration := index.FieldKey{IndexRuleID: 2}
+	match := func(field index.Field) []common.ItemID {
+		list, errMatch := s.MatchTerms(field)
+		tester.NoError(errMatch)
+		items := list.ToSlice()
+		sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+		return items
+	}
+	// the loaded terms are merged with the written ones
+	tester.NoError(s.Write(index.Field{Key: service, Term: []byte("svc-1")}, common.ItemID(1)))
+	tester.NoError(s.(index.BulkLoader).BulkLoad([]index.BulkEntry{
+		{Field: index.Field{Key: duration, Term: convert.Int64ToBytes(500)}, DocID: 3},
+		{Field: index.Field{Key: service, Term: []byte("svc-2")}, DocID: 3},
+		{Field: index.Field{Key: service, Term: []byte("svc-1")}, DocID: 2},
+		{Field: index.Field{Key: duration, Term: convert.Int64ToBytes(100)}, DocID: 2},
+		{Field: index.Field{Key: service, Term: []byte("svc-2")}, DocID: 4},
+		{Field: index.Field{Key: duration, Term: convert.Int64ToBytes(100)}, DocID: 4},
+	}))
+	tester.Equal([]common.ItemID{1, 2}, match(index.Field{Key: service, Term: []byte("svc-1")}))
+	tester.Equal([]common.ItemID{3, 4}, match(index.Field{Key: service, Term: []byte("svc-2")}))
+	list, err := s.Range(duration, numericRange(0, 200))
+	tester.NoError(err)
+	tester.ElementsMatch([]common.ItemID{2, 4}, list.ToSlice())
+	tail, err := s.(index.TailSearcher).TailN(index.Field{Key: service, Term: []byte("svc-2")}, 10)
+	tester.NoError(err)
+	tester.Equal([]common.ItemID{4, 3}, tail)
+	tester.EqualValues(2, s.(*store).sketches.estimate(service))
+
+	// the loaded docs are flushed and deleted as the written ones
+	tester.NoError(s.(*store).Flush())
+	tester.Equal([]common.ItemID{3, 4}, match(index.Field{Key: service, Term: []byte("svc-2")}))
+	deleted, err := s.(index.DocDeleter).DeleteDoc(common.ItemID(3))
+	tester.NoError(err)
+	tester.True(deleted)
+	tester.Equal([]common.ItemID{4}, match(index.Field{Key: service, Term: []byte("svc-2")}))
+}
+
+// BenchmarkStore_BulkLoad compares loading a million entries at once with writing them one by one
+func BenchmarkStore_BulkLoad(b *testing.B) {
+	const docs = 250_000
+	keys := []index.FieldKey{{IndexRuleID: 1}, {IndexRuleID: 2}, {IndexRuleID: 3}, {IndexRuleID: 4}}
+	entries := make([]index.BulkEntry, 0, docs*len(keys))
+	for i := 0; i < docs; i++ {
+		id := common.ItemID(i)
+		entries = append(entries,
+			index.BulkEntry{Field: index.Field{Key: keys[0], Term: []byte(fmt.Sprintf("svc-%d", i%10))}, DocID: id},
+			index.BulkEntry{Field: index.Field{Key: keys[1], Term: []byte(fmt.Sprintf("/api/%d", i%1000))}, DocID: id},
+			index.BulkEntry{Field: index.Field{Key: keys[2], Term: convert.Int64ToBytes(int64(i / 10))}, DocID: id},
+			index.BulkEntry{Field: index.Field{Key: keys[3], Term: []byte(fmt.Sprintf("trace-%d", i))}, DocID: id},
+		)
+	}
+	for _, bulk := range []bool{false, true} {
+		b.Run(fmt.Sprintf("bulk=%t", bulk), func(b *testing.B) {
+			is := require.New(b)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				path, fn := setUp(is)
+				s, err := NewStore(StoreOpts{
+					Path:   path,
+					Logger: logger.GetLogger("test"),
+				})
+				is.NoError(err)
+				batch := append([]index.BulkEntry{}, entries...)
+				b.StartTimer()
+				if bulk {
+					is.NoError(s.(index.BulkLoader).BulkLoad(batch))
+				} else {
+					for _, e := range batch {
+						is.NoError(s.Write(e.Field, e.DocID))
+					}
+				}
+				b.StopTimer()
+				is.NoError(s.Close())
+				fn()
+			}
+		})
+	}
+}
+
+func TestStore_EstimateCost(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	service, status := index.FieldKey{IndexRuleID: 1}, index.FieldKey{IndexRuleID: 2}
+	for i := 0; i < 100; i++ {
+		svc, code := "a", "200"
+		if i >= 90 {
+			svc = "b"
+		}
+		if i%2 == 1 {
+			code = "500"
+		}
+		tester.NoError(s.Write(index.Field{Key: service, Term: []byte(svc)}, common.ItemID(i)))
+		tester.NoError(s.Write(index.Field{Key: status, Term: []byte(code)}, common.ItemID(i)))
+	}
+	tester.NoError(s.(*store).Flush())
+
+	tests := []struct {
+		name string
+		cond index.Condition
+		want index.Cost
+	}{
+		{
+			name: "intersection",
+			cond: index.Condition{
+				service: {{Op: modelv1.Condition_BINARY_OP_EQ, Values: [][]byte{[]byte("b")}}},
+				status:  {{Op: modelv1.Condition_BINARY_OP_EQ, Values: [][]byte{[]byte("500")}}},
+			},
+			want: index.Cost{Items: 10, Work: 120},
+		},
+		{
+			name: "union",
+			cond: index.Condition{
+				service: {{Op: modelv1.Condition_BINARY_OP_HAVING, Values: [][]byte{[]byte("a"), []byte("b")}}},
+			},
+			want: index.Cost{Items: 100, Work: 300},
+		},
+		{
+			name: "negation",
+			cond: index.Condition{
+				status: {{Op: modelv1.Condition_BINARY_OP_NE, Values: [][]byte{[]byte("200")}}},
+			},
+			want: index.Cost{Items: 50, Work: 250},
+		},
+		{
+			name: "range",
+			cond: index.Condition{
+				status: {{Op: modelv1.Condition_BINARY_OP_GT, Values: [][]byte{[]byte("300")}}},
+			},
+			want: index.Cost{Items: 100, Work: 200},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := require.New(t)
+			tree, err := index.BuildTree(s, tt.cond)
+			is.NoError(err)
+			cost, err := index.EstimateCost(tree)
+			is.NoError(err)
+			is.Equal(tt.want, cost)
+			list, err := tree.Execute()
+			is.NoError(err)
+			is.LessOrEqual(uint64(list.Len()), cost.Items)
+		})
+	}
+	tree, err := index.BuildTree(s, index.Condition{})
+	tester.NoError(err)
+	_, err = index.EstimateCost(tree)
+	tester.ErrorIs(err, index.ErrEmptyTree)
+}
+
+func TestStore_PostingFactory(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	var created int
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+		PostingFactory: func() posting.List {
+			created++
+			return roaring.NewPostingList()
+		},
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUp(tester, s)
+	tester.Positive(created)
+	created = 0
+	tester.NoError(s.(*store).Flush())
+	testcases.RunServiceName(t, s)
+	tester.Positive(created)
+}
+
+func TestStore_PostingBlockSize(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:             path,
+		Logger:           logger.GetLogger("test"),
+		PostingBlockSize: 128,
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUp(tester, s)
+	// the high-cardinality field overrides the block size of the store
+	traceID := index.FieldKey{IndexRuleID: 30, PostingBlockSize: posting.MinBlockSize}
+	for i := 0; i < 3000; i++ {
+		tester.NoError(s.Write(index.Field{Key: traceID, Term: []byte(fmt.Sprintf("trace-%d", i/2))}, common.ItemID(i)))
+	}
+	recommended, err := index.RecommendPostingBlockSize(s.(index.CardinalityEstimator), traceID)
+	tester.NoError(err)
+	tester.Equal(posting.MinBlockSize, recommended)
+	tester.NoError(s.(*store).Flush())
+
+	iter := s.(*store).diskTable.NewIterator(kv.ScanOpts{PrefetchValues: true})
+	var count int
+	for iter.Rewind(); iter.Valid(); iter.Next() {
+		tester.True(posting.IsBlockEncoded(iter.Val()))
+		count++
+	}
+	tester.NoError(iter.Close())
+	tester.Positive(count)
+	testcases.RunServiceName(t, s)
+	list, err := s.MatchTerms(index.Field{Key: traceID, Term: []byte("trace-42")})
+	tester.NoError(err)
+	tester.Equal([]common.ItemID{84, 85}, list.ToSlice())
+}
+
+func BenchmarkStore(b *testing.B) {
+	for name, factory := range testcases.PostingFactories {
+		for _, flushed := range []bool{false, true} {
+			b.Run(fmt.Sprintf("%s/flushed=%t", name, flushed), func(b *testing.B) {
+				is := require.New(b)
+				path, fn := setUp(is)
+				s, err := NewStore(StoreOpts{
+					Path:           path,
+					Logger:         logger.GetLogger("test"),
+					PostingFactory: factory,
+				})
+				is.NoError(err)
+				defer func() {
+					is.NoError(s.Close())
+					fn()
+				}()
+				testcases.SetUpBenchmark(b, s)
+				if flushed {
+					is.NoError(s.(*store).Flush())
+				}
+				b.ResetTimer()
+				testcases.RunBenchmark(b, s)
+			})
+		}
+	}
+}
+
+// BenchmarkStore_MergePolicy shows the tradeoff of the merge policies. MergeLazy takes the merges off the ingest,
+// while a search visits up to maxLazySegments more mem tables.
+func BenchmarkStore_MergePolicy(b *testing.B) {
+	const docsPerSegment = 10_000
+	field := func(i int) index.Field {
+		return index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte(fmt.Sprintf("/api/%d", i%100))}
+	}
+	for name, policy := range map[string]MergePolicy{"eager": MergeEager, "lazy": MergeLazy} {
+		b.Run(name, func(b *testing.B) {
+			is := require.New(b)
+			path, fn := setUp(is)
+			defer fn()
+			s, err := NewStore(StoreOpts{
+				Path:        path,
+				Logger:      logger.GetLogger("test"),
+				MergePolicy: policy,
+				CompactIdle: time.Hour,
+			})
+			is.NoError(err)
+			defer func() {
+				is.NoError(s.Close())
+			}()
+			st := s.(*store)
+			var id common.ItemID
+			writeSegment := func() {
+				for i := 0; i < docsPerSegment; i++ {
+					id++
+					is.NoError(s.Write(field(int(id)), id))
+				}
+			}
+			// an ingest cycle writes a segment and flushes it
+			b.Run("WriteAndFlush", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					// MergeLazy merges on Flush beyond the limit, which isn't measured here
+					if len(st.immutables) >= maxLazySegments {
+						b.StopTimer()
+						is.NoError(st.Sync())
+						b.StartTimer()
+					}
+					writeSegment()
+					is.NoError(st.Flush())
+				}
+			})
+			// the searches run against as many frozen segments as MergeLazy keeps at most
+			is.NoError(st.Sync())
+			for i := 0; i < maxLazySegments; i++ {
+				writeSegment()
+				is.NoError(st.Flush())
+			}
+			b.Run("MatchTerms", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					_, err := s.MatchTerms(field(i))
+					is.NoError(err)
+				}
+			})
+			b.Run("Range", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					_, err := s.MatchField(index.FieldKey{IndexRuleID: 1})
+					is.NoError(err)
+				}
+			})
+		})
+	}
+}
+
 func setUp(t *require.Assertions) (tempDir string, deferFunc func()) {
 	t.NoError(logger.Init(logger.Logging{
 		Env:   "dev",
@@ -141,3 +1039,371 @@ func setUp(t *require.Assertions) (tempDir string, deferFunc func()) {
 	tempDir, deferFunc = test.Space(t)
 	return tempDir, deferFunc
 }
+
+func TestStore_VersionComparator(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUpVersion(tester, s)
+	testcases.RunVersionRange(t, s)
+	tester.NoError(s.(*store).Flush())
+	testcases.RunVersionRange(t, s)
+}
+
+func TestStore_Warmup(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	hot, cold := index.FieldKey{IndexRuleID: 20}, index.FieldKey{IndexRuleID: 21}
+	for i := 0; i < 100; i++ {
+		tester.NoError(s.Write(index.Field{Key: hot, Term: []byte(fmt.Sprintf("term-%d", i%10))}, common.ItemID(i)))
+	}
+	loaded, err := s.Warmup(context.Background(), []index.FieldKey{hot})
+	tester.NoError(err)
+	tester.Zero(loaded)
+
+	tester.NoError(s.(*store).Flush())
+	loaded, err = s.Warmup(context.Background(), []index.FieldKey{hot})
+	tester.NoError(err)
+	tester.Greater(loaded, int64(0))
+	all, err := s.Warmup(context.Background(), []index.FieldKey{hot, cold})
+	tester.NoError(err)
+	tester.Equal(loaded, all)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = s.Warmup(ctx, []index.FieldKey{hot})
+	tester.ErrorIs(err, context.Canceled)
+}
+
+func TestStore_TailN(t *testing.T) {
+	tester := assert.New(t)
+	is := require.New(t)
+	field := index.Field{
+		Key:  index.FieldKey{SeriesID: 1, IndexRuleID: 1},
+		Term: []byte("GET"),
+	}
+	for _, tailSize := range []int{0, 3} {
+		path, fn := setUp(is)
+		s, err := NewStore(StoreOpts{
+			Path:     path,
+			Logger:   logger.GetLogger("test"),
+			TailSize: tailSize,
+		})
+		is.NoError(err)
+		for _, id := range []common.ItemID{5, 1, 9, 3, 9} {
+			is.NoError(s.Write(field, id))
+		}
+		is.NoError(s.Write(index.Field{Key: field.Key, Term: []byte("POST")}, 7))
+		tail := s.(index.TailSearcher)
+		for _, flushed := range []bool{false, true} {
+			if flushed {
+				is.NoError(s.(*store).Flush())
+			}
+			got, err := tail.TailN(field, 2)
+			is.NoError(err)
+			if tailSize > 0 {
+				tester.Equal([]common.ItemID{9, 3}, got, "flushed=%t", flushed)
+			} else {
+				tester.Equal([]common.ItemID{9, 5}, got, "flushed=%t", flushed)
+			}
+			got, err = tail.TailN(field, 10)
+			is.NoError(err)
+			if tailSize > 0 {
+				// the items out of the tail table are ordered by their IDs
+				tester.Equal([]common.ItemID{9, 3, 5, 1}, got, "flushed=%t", flushed)
+			} else {
+				tester.Equal([]common.ItemID{9, 5, 3, 1}, got, "flushed=%t", flushed)
+			}
+		}
+		tester.NoError(s.Close())
+		fn()
+	}
+}
+
+func numericRange(lower, upper int64) index.RangeOpts {
+	return index.RangeOpts{
+		Lower:         convert.Int64ToBytes(lower),
+		Upper:         convert.Int64ToBytes(upper),
+		IncludesLower: true,
+		IncludesUpper: true,
+	}
+}
+
+func TestStore_ZoneMap(t *testing.T) {
+	tester := assert.New(t)
+	is := require.New(t)
+	path, fn := setUp(is)
+	defer fn()
+	fieldKey := index.FieldKey{SeriesID: 1, IndexRuleID: 3, Comparator: index.ComparatorNumeric}
+	write := func(s index.Store, from, to int64) {
+		for v := from; v <= to; v++ {
+			is.NoError(s.Write(index.Field{Key: fieldKey, Term: convert.Int64ToBytes(v)}, common.ItemID(v)))
+		}
+	}
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	is.NoError(err)
+	// the disk table holds [100,199], and the mem table holds [300,399]
+	write(s, 100, 199)
+	is.NoError(s.(*store).Flush())
+	write(s, 300, 399)
+
+	tests := []struct {
+		name   string
+		opts   index.RangeOpts
+		count  int
+		pruned uint64
+	}{
+		{name: "in the disk table", opts: numericRange(150, 160), count: 11, pruned: 1},
+		{name: "in the mem table", opts: numericRange(350, 360), count: 11, pruned: 1},
+		{name: "across both", opts: numericRange(190, 310), count: 21, pruned: 0},
+		{name: "out of both", opts: numericRange(200, 299), count: 0, pruned: 2},
+		{name: "the open range", opts: index.RangeOpts{}, count: 200, pruned: 0},
+	}
+	for _, tt := range tests {
+		before := s.Stats().PrunedSegments
+		list, err := s.Range(fieldKey, tt.opts)
+		is.NoError(err)
+		tester.Equal(tt.count, list.Len(), tt.name)
+		tester.Equal(tt.pruned, s.Stats().PrunedSegments-before, tt.name)
+	}
+	is.NoError(s.Close())
+
+	// the zones of the disk table survive reopening
+	s, err = NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	is.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	list, err := s.Range(fieldKey, numericRange(350, 360))
+	is.NoError(err)
+	tester.True(list.IsEmpty())
+	tester.Equal(uint64(1), s.Stats().PrunedSegments)
+	list, err = s.Range(fieldKey, numericRange(150, 160))
+	is.NoError(err)
+	tester.Equal(11, list.Len())
+}
+
+// BenchmarkStore_RangePruning queries a selective range over the stores of many blocks,
+// each of which holds a distinct range of the terms. Most of the segments are skipped.
+func BenchmarkStore_RangePruning(b *testing.B) {
+	const (
+		storeCount    = 16
+		termsPerStore = 1000
+	)
+	is := require.New(b)
+	fieldKey := index.FieldKey{IndexRuleID: 3, Comparator: index.ComparatorNumeric}
+	stores := make([]index.Store, 0, storeCount)
+	for i := 0; i < storeCount; i++ {
+		path, fn := setUp(is)
+		s, err := NewStore(StoreOpts{
+			Path:   path,
+			Logger: logger.GetLogger("test"),
+		})
+		is.NoError(err)
+		defer func() {
+			is.NoError(s.Close())
+			fn()
+		}()
+		for v := int64(i * termsPerStore); v < int64((i+1)*termsPerStore); v++ {
+			is.NoError(s.Write(index.Field{Key: fieldKey, Term: convert.Int64ToBytes(v)}, common.ItemID(v)))
+		}
+		is.NoError(s.(*store).Flush())
+		stores = append(stores, s)
+	}
+	opts := numericRange(5*termsPerStore+100, 5*termsPerStore+200)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, s := range stores {
+			if _, err := s.Range(fieldKey, opts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.StopTimer()
+	var pruned uint64
+	for _, s := range stores {
+		pruned += s.Stats().PrunedSegments
+	}
+	b.ReportMetric(float64(pruned)/float64(b.N), "pruned/op")
+}
+
+func TestStore_FloatRange(t *testing.T) {
+	tester := assert.New(t)
+	is := require.New(t)
+	path, fn := setUp(is)
+	defer fn()
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	is.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	fieldKey := index.FieldKey{SeriesID: 1, IndexRuleID: 4}
+	for i, v := range []float64{-100.5, -1, -0.001, 0, 0.5, 3, 1e10, math.NaN()} {
+		is.NoError(s.Write(index.Field{Key: fieldKey, Term: pbv1.MarshalFloat(v)}, common.ItemID(i)))
+	}
+	tests := []struct {
+		name string
+		opts index.RangeOpts
+		want []common.ItemID
+	}{
+		{
+			name: "[-1, 1]",
+			opts: index.RangeOpts{
+				Lower: pbv1.MarshalFloat(-1), Upper: pbv1.MarshalFloat(1),
+				IncludesLower: true, IncludesUpper: true,
+			},
+			want: []common.ItemID{1, 2, 3, 4},
+		},
+		{
+			name: "less than zero",
+			opts: index.RangeOpts{Upper: pbv1.MarshalFloat(math.Copysign(0, -1))},
+			want: []common.ItemID{0, 1, 2},
+		},
+		{
+			name: "greater than 1 excluding NaN",
+			opts: index.RangeOpts{
+				Lower: pbv1.MarshalFloat(1), Upper: pbv1.MarshalFloat(math.Inf(1)),
+				IncludesUpper: true,
+			},
+			want: []common.ItemID{5, 6},
+		},
+	}
+	for _, flushed := range []bool{false, true} {
+		if flushed {
+			is.NoError(s.(*store).Flush())
+		}
+		for _, tt := range tests {
+			list, err := s.Range(fieldKey, tt.opts)
+			is.NoError(err)
+			got := list.ToSlice()
+			sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+			tester.Equal(tt.want, got, "%s flushed=%t", tt.name, flushed)
+		}
+	}
+}
+
+func TestStore_DropField(t *testing.T) {
+	tester := assert.New(t)
+	is := require.New(t)
+	path, fn := setUp(is)
+	defer fn()
+	dropped := index.FieldKey{SeriesID: 1, IndexRuleID: 3, Comparator: index.ComparatorNumeric}
+	kept := index.FieldKey{SeriesID: 2, IndexRuleID: 3, Comparator: index.ComparatorNumeric}
+	s, err := NewStore(StoreOpts{
+		Path:     path,
+		Logger:   logger.GetLogger("test"),
+		TailSize: 4,
+	})
+	is.NoError(err)
+	write := func(from, to int64) {
+		for v := from; v <= to; v++ {
+			for _, key := range []index.FieldKey{dropped, kept} {
+				is.NoError(s.Write(index.Field{Key: key, Term: convert.Int64ToBytes(v)}, common.ItemID(v)))
+			}
+		}
+	}
+	// the fields live in both the disk table and the mem table
+	write(1, 10)
+	is.NoError(s.(*store).Flush())
+	write(11, 20)
+
+	is.NoError(s.DropField(dropped))
+	list, err := s.MatchField(dropped)
+	is.NoError(err)
+	tester.True(list.IsEmpty())
+	items, err := s.(index.TailSearcher).TailN(index.Field{Key: dropped, Term: convert.Int64ToBytes(15)}, 1)
+	is.NoError(err)
+	tester.Empty(items)
+	list, err = s.MatchField(kept)
+	is.NoError(err)
+	tester.Equal(20, list.Len())
+	// the field is written again after it's dropped
+	is.NoError(s.Write(index.Field{Key: dropped, Term: convert.Int64ToBytes(30)}, common.ItemID(30)))
+	list, err = s.MatchField(dropped)
+	is.NoError(err)
+	tester.Equal(1, list.Len())
+	is.NoError(s.(*store).Flush())
+	is.NoError(s.Close())
+
+	// the dropped items are still hidden after reopening
+	s, err = NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	is.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	list, err = s.MatchField(dropped)
+	is.NoError(err)
+	tester.Equal([]common.ItemID{30}, list.ToSlice())
+	list, err = s.MatchTerms(index.Field{Key: dropped, Term: convert.Int64ToBytes(5)})
+	is.NoError(err)
+	tester.True(list.IsEmpty())
+	list, err = s.MatchField(kept)
+	is.NoError(err)
+	tester.Equal(20, list.Len())
+}
+
+func TestStore_DocPayload(t *testing.T) {
+	tester := assert.New(t)
+	is := require.New(t)
+	path, fn := setUp(is)
+	defer fn()
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	is.NoError(err)
+	ps := s.(index.PayloadStore)
+	_, err = ps.GetDocPayload(common.ItemID(1))
+	tester.ErrorIs(err, index.ErrPayloadNotFound)
+	is.NoError(ps.SetDocPayload(common.ItemID(1), []byte("foo")))
+	is.NoError(ps.SetDocPayload(common.ItemID(2), []byte("bar")))
+	payload, err := ps.GetDocPayload(common.ItemID(1))
+	is.NoError(err)
+	tester.Equal([]byte("foo"), payload)
+	is.NoError(s.Close())
+
+	// the payloads persist across reopening
+	s, err = NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	is.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	ps = s.(index.PayloadStore)
+	payload, err = ps.GetDocPayload(common.ItemID(2))
+	is.NoError(err)
+	tester.Equal([]byte("bar"), payload)
+	_, err = ps.GetDocPayload(common.ItemID(3))
+	tester.ErrorIs(err, index.ErrPayloadNotFound)
+}
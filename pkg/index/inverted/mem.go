@@ -20,6 +20,7 @@ package inverted
 import (
 	"bytes"
 	"sort"
+	"sync"
 
 	"go.uber.org/multierr"
 
@@ -29,7 +30,6 @@ import (
 	"github.com/apache/skywalking-banyandb/pkg/index"
 	"github.com/apache/skywalking-banyandb/pkg/index/metadata"
 	"github.com/apache/skywalking-banyandb/pkg/index/posting"
-	"github.com/apache/skywalking-banyandb/pkg/index/posting/roaring"
 )
 
 var (
@@ -39,16 +39,114 @@ var (
 
 type memTable struct {
 	fields *fieldMap
+	zones  *zoneMap
+	// docs are all the items written to the table, whose Bloom filter is built on flushing
+	docs      posting.List
+	docsMutex sync.RWMutex
+	newList   posting.Factory
 }
 
-func newMemTable() *memTable {
+func newMemTable(newList posting.Factory) *memTable {
 	return &memTable{
-		fields: newFieldMap(1000),
+		fields:  newFieldMap(1000, newList),
+		zones:   newZoneMap(),
+		docs:    newList(),
+		newList: newList,
 	}
 }
 
 func (m *memTable) Write(field index.Field, itemID common.ItemID) error {
-	return m.fields.put(field, itemID)
+	m.insert(field, itemID)
+	return nil
+}
+
+// insert never fails, so a doc's fields are either all in the table or none of them
+func (m *memTable) insert(field index.Field, itemID common.ItemID) {
+	m.fields.put(field, itemID)
+	m.zones.put(field.Key, field.Term)
+	m.docsMutex.Lock()
+	m.docs.Insert(itemID)
+	m.docsMutex.Unlock()
+}
+
+// bulkLoad expects the entries sorted by compareBulkEntry. The terms of a field and the items of a term are both runs,
+// so a field is looked up once per run, and a posting list is built before it's merged into the table.
+// onTerm is called once per distinct term.
+func (m *memTable) bulkLoad(entries []index.BulkEntry, onTerm func(field index.Field)) error {
+	docs := m.newList()
+	for start := 0; start < len(entries); {
+		key := entries[start].Field.Key
+		tc := m.fields.getOrCreate(key)
+		for start < len(entries) && compareFieldKey(&entries[start].Field.Key, &key) == 0 {
+			term := entries[start].Field.Term
+			list := m.newList()
+			for ; start < len(entries) && compareFieldKey(&entries[start].Field.Key, &key) == 0 &&
+				bytes.Equal(entries[start].Field.Term, term); start++ {
+				list.Insert(entries[start].DocID)
+				docs.Insert(entries[start].DocID)
+			}
+			if err := tc.value.merge(term, list); err != nil {
+				return err
+			}
+			m.zones.put(key, term)
+			onTerm(index.Field{Key: key, Term: term})
+		}
+	}
+	m.docsMutex.Lock()
+	defer m.docsMutex.Unlock()
+	return m.docs.Union(docs)
+}
+
+// deleteDoc removes the item from all the terms, and the terms left empty
+func (m *memTable) deleteDoc(itemID common.ItemID) bool {
+	m.docsMutex.Lock()
+	defer m.docsMutex.Unlock()
+	if !m.docs.Contains(itemID) {
+		return false
+	}
+	_ = m.docs.RemoveRange(itemID, itemID+1)
+	_ = m.fields.each(func(tc *termContainer) error {
+		tc.value.removeItem(itemID)
+		return nil
+	})
+	return true
+}
+
+// deleteTerm removes the item from the term, which is dropped if it's left empty.
+// The item stays in docs, since it might be indexed by the other terms.
+func (m *memTable) deleteTerm(field index.Field, itemID common.ItemID) {
+	tc, ok := m.fields.get(field.Key)
+	if !ok {
+		return
+	}
+	tc.value.removeTermItem(field.Term, itemID)
+}
+
+// docBloom builds the Bloom filter of the items written to the table. It's nil if the table is empty.
+func (m *memTable) docBloom() *index.Bloom {
+	m.docsMutex.RLock()
+	defer m.docsMutex.RUnlock()
+	if m.docs.IsEmpty() {
+		return nil
+	}
+	bloom := index.NewBloom(m.docs.Len())
+	for _, id := range m.docs.ToSlice() {
+		bloom.Insert(id)
+	}
+	return bloom
+}
+
+func (m *memTable) termCount() uint64 {
+	return m.fields.termCount()
+}
+
+// eachTerm visits all the terms along with their postings
+func (m *memTable) eachTerm(fn func(field index.Field, list posting.List) error) error {
+	return m.fields.each(func(tc *termContainer) error {
+		return tc.value.each(func(value *index.PostingValue) error {
+			return fn(index.Field{Key: tc.key, Term: value.Term}, value.Value)
+		})
+	})
 }
 
 var _ index.FieldIterator = (*fIterator)(nil)
@@ -99,13 +197,17 @@ func (m *memTable) Iterator(fieldKey index.FieldKey, rangeOpts index.RangeOpts,
 	if !ok {
 		return nil, nil
 	}
+	compare, err := index.GetComparator(fieldKey.Comparator)
+	if err != nil {
+		return nil, err
+	}
 	fValue := fieldsValues.value
 	var terms [][]byte
 	{
 		fValue.mutex.RLock()
 		defer fValue.mutex.RUnlock()
 		for _, value := range fValue.repo {
-			if rangeOpts.Between(value.Term) == 0 {
+			if rangeOpts.BetweenWith(value.Term, compare) == 0 {
 				terms = append(terms, value.Term)
 			}
 		}
@@ -129,55 +231,40 @@ func (m *memTable) Iterator(fieldKey index.FieldKey, rangeOpts index.RangeOpts,
 func (m *memTable) MatchTerms(field index.Field) (posting.List, error) {
 	fieldsValues, ok := m.fields.get(field.Key)
 	if !ok {
-		return roaring.EmptyPostingList, nil
+		return m.newList(), nil
 	}
 	list := fieldsValues.value.get(field.Term)
 	if list == nil {
-		return roaring.EmptyPostingList, nil
+		return m.newList(), nil
 	}
 	return list, nil
 }
 
 var _ kv.Iterator = (*flushIterator)(nil)
 
+type flushEntry struct {
+	key   []byte
+	value []byte
+}
+
+// flushIterator emits the entries sorted by their keys, which is required by the handover
 type flushIterator struct {
-	fieldIdx     int
-	termIdx      int
-	key          []byte
-	value        []byte
-	fields       *fieldMap
-	valid        bool
-	err          error
-	termMetadata metadata.Term
+	idx              int
+	entries          []flushEntry
+	fields           *fieldMap
+	err              error
+	termMetadata     metadata.Term
+	postingBlockSize int
 }
 
 func (i *flushIterator) Next() {
-	if i.fieldIdx >= len(i.fields.lst) {
-		i.valid = false
-		return
-	}
-	fieldID := i.fields.lst[i.fieldIdx]
-	terms := i.fields.repo[fieldID]
-	if i.termIdx < len(terms.value.lst) {
-		i.termIdx++
-		if !i.setCurr() {
-			i.Next()
-		}
-		return
-	}
-	i.fieldIdx++
-	i.termIdx = 0
-	if !i.setCurr() {
-		i.Next()
-	}
+	i.idx++
 }
 
 func (i *flushIterator) Rewind() {
-	i.fieldIdx = 0
-	i.termIdx = 0
-	i.valid = true
-	if !i.setCurr() {
-		i.valid = false
+	i.idx = 0
+	if i.entries == nil {
+		i.load()
 	}
 }
 
@@ -186,53 +273,59 @@ func (i *flushIterator) Seek(_ []byte) {
 }
 
 func (i *flushIterator) Key() []byte {
-	return i.key
+	return i.entries[i.idx].key
 }
 
 func (i *flushIterator) Val() []byte {
-	return i.value
+	return i.entries[i.idx].value
 }
 
 func (i *flushIterator) Valid() bool {
-	return i.valid
+	return i.idx < len(i.entries)
 }
 
 func (i *flushIterator) Close() error {
 	return i.err
 }
 
-func (i *flushIterator) setCurr() bool {
-	if i.fieldIdx >= len(i.fields.lst) {
-		return false
-	}
-	fieldID := i.fields.lst[i.fieldIdx]
-	term := i.fields.repo[fieldID]
-	if i.termIdx >= len(term.value.lst) {
-		return false
-	}
-	valueID := term.value.lst[i.termIdx]
-	value := term.value.repo[valueID]
-	v, err := value.Value.Marshall()
-	if err != nil {
-		i.err = multierr.Append(i.err, err)
-		return false
-	}
-	i.value = v
-	f := index.Field{
-		Key:  term.key,
-		Term: value.Term,
-	}
-	i.key, err = f.Marshal(i.termMetadata)
-	if err != nil {
-		i.err = multierr.Append(i.err, err)
-		return false
+func (i *flushIterator) load() {
+	i.entries = make([]flushEntry, 0)
+	for _, fieldID := range i.fields.lst {
+		term := i.fields.repo[fieldID]
+		for _, valueID := range term.value.lst {
+			value := term.value.repo[valueID]
+			blockSize := term.key.PostingBlockSize
+			if blockSize == 0 {
+				blockSize = i.postingBlockSize
+			}
+			v, err := posting.Marshal(value.Value, blockSize)
+			if err != nil {
+				i.err = multierr.Append(i.err, err)
+				continue
+			}
+			f := index.Field{
+				Key:  term.key,
+				Term: value.Term,
+			}
+			k, err := f.Marshal(i.termMetadata)
+			if err != nil {
+				i.err = multierr.Append(i.err, err)
+				continue
+			}
+			i.entries = append(i.entries, flushEntry{key: k, value: v})
+		}
 	}
-	return true
+	sort.Slice(i.entries, func(a, b int) bool {
+		return bytes.Compare(i.entries[a].key, i.entries[b].key) < 0
+	})
 }
 
-func (m *memTable) Iter(termMetadata metadata.Term) kv.Iterator {
+// Iter encodes the posting lists of the fields without their own block size in blocks of postingBlockSize.
+// Zero keeps the encoding of the lists.
+func (m *memTable) Iter(termMetadata metadata.Term, postingBlockSize int) kv.Iterator {
 	return &flushIterator{
-		fields:       m.fields,
-		termMetadata: termMetadata,
+		fields:           m.fields,
+		termMetadata:     termMetadata,
+		postingBlockSize: postingBlockSize,
 	}
 }
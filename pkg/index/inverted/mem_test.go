@@ -22,17 +22,24 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/apache/skywalking-banyandb/pkg/index/posting/roaring"
 	"github.com/apache/skywalking-banyandb/pkg/index/testcases"
 )
 
 func TestMemTable_MatchTerm(t *testing.T) {
-	mt := newMemTable()
+	mt := newMemTable(roaring.NewPostingList)
 	testcases.SetUp(assert.New(t), mt)
 	testcases.RunServiceName(t, mt)
 }
 
 func TestMemTable_Iterator(t *testing.T) {
-	mt := newMemTable()
+	mt := newMemTable(roaring.NewPostingList)
 	data := testcases.SetUpDuration(assert.New(t), mt)
 	testcases.RunDuration(t, data, mt)
 }
+
+func TestMemTable_StringRange(t *testing.T) {
+	mt := newMemTable(roaring.NewPostingList)
+	testcases.SetUpEndpoint(assert.New(t), mt)
+	testcases.RunEndpointRange(t, mt)
+}
@@ -0,0 +1,122 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package inverted
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// MergePolicy decides when a flushed mem table is merged into the disk table
+type MergePolicy int
+
+const (
+	// MergeEager merges the mem table into the disk table on Flush. The searches and the doc transactions
+	// wait for the merge, but a search only visits the live mem table and the disk table.
+	MergeEager MergePolicy = iota
+	// MergeLazy freezes the mem table as an in-memory segment on Flush, which takes no time.
+	// A search unions the posting lists of all the frozen segments, so it slows down as they pile up.
+	// A background compactor merges them one by one once the writes are idle, and Sync or Close merges the rest.
+	// The frozen segments are lost if the process crashes before they are merged, unless StoreOpts.WAL is on.
+	MergeLazy
+)
+
+const (
+	defaultCompactIdle = time.Second
+	// maxLazySegments bounds the memory and the fan-out of a search. The oldest segments are merged on Flush
+	// beyond it, as if the policy were MergeEager.
+	maxLazySegments = 8
+)
+
+// memTables lists the live mem table and the frozen ones, the newest first
+func (s *store) memTables() []*memTable {
+	tt := make([]*memTable, 0, len(s.immutables)+1)
+	tt = append(tt, s.memTable)
+	for i := len(s.immutables) - 1; i >= 0; i-- {
+		tt = append(tt, s.immutables[i])
+	}
+	return tt
+}
+
+// freeze replaces the live mem table with an empty one. The caller should hold the write lock.
+// The live segments of the write-ahead log are sealed along with the frozen table.
+func (s *store) freeze() error {
+	swap := func() {
+		s.immutables = append(s.immutables, s.memTable)
+		s.memTable = newMemTable(s.newList)
+	}
+	if s.wal == nil {
+		swap()
+		return nil
+	}
+	return s.wal.rotate(swap)
+}
+
+// mergeAll merges all the frozen segments, the oldest first
+func (s *store) mergeAll() error {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	for len(s.immutables) > 0 {
+		if err := s.mergeOldest(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeOne merges the oldest frozen segment, it returns false if there is none
+func (s *store) mergeOne() (bool, error) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	if len(s.immutables) < 1 {
+		return false, nil
+	}
+	return true, s.mergeOldest()
+}
+
+func (s *store) touch() {
+	atomic.StoreInt64(&s.lastWrite, time.Now().UnixNano())
+}
+
+func (s *store) idleFor() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&s.lastWrite)))
+}
+
+// compact merges a segment at a time, so the searches get the lock in between
+func (s *store) compact(idle time.Duration) {
+	defer close(s.compactorDone)
+	ticker := time.NewTicker(idle)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCompactor:
+			return
+		case <-ticker.C:
+		}
+		for s.idleFor() >= idle {
+			merged, err := s.mergeOne()
+			if err != nil {
+				s.l.Warn().Err(err).Msg("failed to merge a frozen segment")
+				break
+			}
+			if !merged {
+				break
+			}
+		}
+	}
+}
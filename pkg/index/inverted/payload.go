@@ -0,0 +1,82 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package inverted
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/banyand/kv"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+)
+
+// SetDocPayload opens the payload table on the first write, which costs nothing if none of the rules stores payloads
+func (s *store) SetDocPayload(docID common.ItemID, payload []byte) error {
+	table, err := s.payloadTable(true)
+	if err != nil {
+		return err
+	}
+	return table.Put(convert.Uint64ToBytes(uint64(docID)), payload)
+}
+
+func (s *store) GetDocPayload(docID common.ItemID) ([]byte, error) {
+	table, err := s.payloadTable(false)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return nil, errors.Wrapf(index.ErrPayloadNotFound, "doc %d", docID)
+	}
+	payload, err := table.Get(convert.Uint64ToBytes(uint64(docID)))
+	if errors.Is(err, kv.ErrKeyNotFound) {
+		return nil, errors.Wrapf(index.ErrPayloadNotFound, "doc %d", docID)
+	}
+	return payload, err
+}
+
+// payloadTable returns nil if the table is absent and create is false
+func (s *store) payloadTable(create bool) (kv.Store, error) {
+	s.payloadMutex.Lock()
+	defer s.payloadMutex.Unlock()
+	if s.payloads != nil {
+		return s.payloads, nil
+	}
+	if !create {
+		if _, err := os.Stat(s.payloadPath); errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+	}
+	table, err := kv.OpenStore(0, s.payloadPath, kv.StoreWithNamedLogger("payload", s.l))
+	if err != nil {
+		return nil, err
+	}
+	s.payloads = table
+	return table, nil
+}
+
+func (s *store) closePayloadTable() error {
+	s.payloadMutex.Lock()
+	defer s.payloadMutex.Unlock()
+	if s.payloads == nil {
+		return nil
+	}
+	return s.payloads.Close()
+}
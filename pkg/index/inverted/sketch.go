@@ -0,0 +1,123 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package inverted
+
+import (
+	"encoding/binary"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/apache/skywalking-banyandb/pkg/index"
+)
+
+// sketchMap holds a HyperLogLog sketch for each field, which is updated on writing
+// and estimates the distinct terms of the field in constant time
+type sketchMap struct {
+	mutex sync.RWMutex
+	repo  map[string]*index.Sketch
+}
+
+func newSketchMap() *sketchMap {
+	return &sketchMap{
+		repo: make(map[string]*index.Sketch),
+	}
+}
+
+func (m *sketchMap) insert(field index.Field) {
+	key := string(field.Key.Marshal())
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	sketch, ok := m.repo[key]
+	if !ok {
+		sketch = index.NewSketch()
+		m.repo[key] = sketch
+	}
+	sketch.Insert(field.Term)
+}
+
+func (m *sketchMap) estimate(fieldKey index.FieldKey) uint64 {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	sketch, ok := m.repo[string(fieldKey.Marshal())]
+	if !ok {
+		return 0
+	}
+	return sketch.Estimate()
+}
+
+func (m *sketchMap) remove(fieldKey index.FieldKey) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.repo, string(fieldKey.Marshal()))
+}
+
+// save writes the sketches to the file at path in a single shot, which replaces the old one atomically
+func (m *sketchMap) save(path string) error {
+	m.mutex.RLock()
+	keys := make([]string, 0, len(m.repo))
+	for key := range m.repo {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buf []byte
+	var lenBuf [binary.MaxVarintLen64]byte
+	for _, key := range keys {
+		for _, b := range [][]byte{[]byte(key), m.repo[key].Marshal()} {
+			n := binary.PutUvarint(lenBuf[:], uint64(len(b)))
+			buf = append(buf, lenBuf[:n]...)
+			buf = append(buf, b...)
+		}
+	}
+	m.mutex.RUnlock()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func loadSketchMap(path string) (*sketchMap, error) {
+	m := newSketchMap()
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for len(raw) > 0 {
+		var fields [2][]byte
+		for i := range fields {
+			l, n := binary.Uvarint(raw)
+			if n <= 0 || uint64(len(raw)-n) < l {
+				return nil, errors.Wrapf(index.ErrMalformed, "sketch map %s", path)
+			}
+			fields[i] = raw[n : n+int(l)]
+			raw = raw[n+int(l):]
+		}
+		sketch, err := index.UnmarshalSketch(fields[1])
+		if err != nil {
+			return nil, errors.WithMessagef(err, "sketch map %s", path)
+		}
+		m.repo[string(fields[0])] = sketch
+	}
+	return m, nil
+}
@@ -0,0 +1,184 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package inverted
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+)
+
+// tailTable keeps the latest items of each term in the order they are written.
+// It lives in memory only and survives the flushes of the mem tables.
+type tailTable struct {
+	mutex sync.RWMutex
+	size  int
+	repo  map[string]*tailRing
+}
+
+// tailRing is a ring buffer holding at most size items
+type tailRing struct {
+	items []common.ItemID
+	next  int
+}
+
+func newTailTable(size int) *tailTable {
+	return &tailTable{
+		size: size,
+		repo: make(map[string]*tailRing),
+	}
+}
+
+func tailKey(field index.Field) (string, error) {
+	key, err := field.MarshalStraight()
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
+
+// put appends the item to the ring of the key, see tailKey
+func (t *tailTable) put(key string, itemID common.ItemID) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	r, ok := t.repo[key]
+	if !ok {
+		r = &tailRing{items: make([]common.ItemID, 0, 1)}
+		t.repo[key] = r
+	}
+	if len(r.items) < t.size {
+		r.items = append(r.items, itemID)
+		return
+	}
+	r.items[r.next] = itemID
+	r.next = (r.next + 1) % t.size
+}
+
+func (t *tailTable) drop(fieldKey index.FieldKey) {
+	prefix := string(fieldKey.Marshal())
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for key := range t.repo {
+		if strings.HasPrefix(key, prefix) {
+			delete(t.repo, key)
+		}
+	}
+}
+
+// remove drops the item from all the rings, which keep the order of the rest
+func (t *tailTable) remove(itemID common.ItemID) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for key := range t.repo {
+		t.removeFrom(key, itemID)
+	}
+}
+
+// removeTerm removes the item from the ring of the term only
+func (t *tailTable) removeTerm(key string, itemID common.ItemID) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.removeFrom(key, itemID)
+}
+
+func (t *tailTable) removeFrom(key string, itemID common.ItemID) {
+	r, ok := t.repo[key]
+	if !ok {
+		return
+	}
+	items := make([]common.ItemID, 0, len(r.items))
+	// the oldest one is at next
+	for i := 0; i < len(r.items); i++ {
+		if id := r.items[(r.next+i)%len(r.items)]; id != itemID {
+			items = append(items, id)
+		}
+	}
+	if len(items) == len(r.items) {
+		return
+	}
+	if len(items) < 1 {
+		delete(t.repo, key)
+		return
+	}
+	t.repo[key] = &tailRing{items: items}
+}
+
+// tail returns at most n items of the term, the latest first.
+// An item written more than once is placed by its latest write.
+func (t *tailTable) tail(field index.Field, n int, seen map[common.ItemID]struct{}) ([]common.ItemID, error) {
+	key, err := field.MarshalStraight()
+	if err != nil {
+		return nil, err
+	}
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	r, ok := t.repo[string(key)]
+	if !ok {
+		return nil, nil
+	}
+	var result []common.ItemID
+	for i := 0; i < len(r.items) && len(result) < n; i++ {
+		// the latest one is right before next
+		id := r.items[(r.next-1-i+len(r.items))%len(r.items)]
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+// TailN returns at most n items matching the field, the most recently written first.
+// The items come from the tail table if it holds enough of them, which doesn't touch the posting lists.
+// Otherwise, for example, TailSize is less than n or the items were written before the store was opened,
+// the rest are picked from the posting list in the descending order of their IDs.
+func (s *store) TailN(field index.Field, n int) ([]common.ItemID, error) {
+	if n < 1 {
+		return nil, nil
+	}
+	var result []common.ItemID
+	seen := make(map[common.ItemID]struct{})
+	if s.tails != nil {
+		var err error
+		if result, err = s.tails.tail(field, n, seen); err != nil {
+			return nil, err
+		}
+		if len(result) >= n {
+			return result, nil
+		}
+	}
+	list, err := s.MatchTerms(field)
+	if err != nil {
+		return nil, err
+	}
+	items := list.ToSlice()
+	sort.Slice(items, func(i, j int) bool {
+		return items[i] < items[j]
+	})
+	for i := len(items) - 1; i >= 0 && len(result) < n; i-- {
+		if _, ok := seen[items[i]]; ok {
+			continue
+		}
+		result = append(result, items[i])
+	}
+	return result, nil
+}
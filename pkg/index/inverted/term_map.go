@@ -24,27 +24,27 @@ import (
 	"github.com/apache/skywalking-banyandb/pkg/convert"
 	"github.com/apache/skywalking-banyandb/pkg/index"
 	"github.com/apache/skywalking-banyandb/pkg/index/posting"
-	"github.com/apache/skywalking-banyandb/pkg/index/posting/roaring"
 )
 
 type termHashID uint64
 
 type termMap struct {
-	repo  map[termHashID]*index.PostingValue
-	lst   []termHashID
-	mutex sync.RWMutex
+	repo    map[termHashID]*index.PostingValue
+	lst     []termHashID
+	newList posting.Factory
+	mutex   sync.RWMutex
 }
 
-func newPostingMap() *termMap {
+func newPostingMap(newList posting.Factory) *termMap {
 	return &termMap{
-		repo: make(map[termHashID]*index.PostingValue),
+		repo:    make(map[termHashID]*index.PostingValue),
+		newList: newList,
 	}
 }
 
-func (p *termMap) put(key []byte, id common.ItemID) error {
+func (p *termMap) put(key []byte, id common.ItemID) {
 	list := p.getOrCreate(key)
 	list.Insert(id)
-	return nil
 }
 
 func (p *termMap) getOrCreate(key []byte) posting.List {
@@ -57,13 +57,46 @@ func (p *termMap) getOrCreate(key []byte) posting.List {
 	hashedKey := termHashID(convert.Hash(key))
 	v := &index.PostingValue{
 		Term:  key,
-		Value: roaring.NewPostingList(),
+		Value: p.newList(),
 	}
 	p.repo[hashedKey] = v
 	p.lst = append(p.lst, hashedKey)
 	return v.Value
 }
 
+// merge takes the list over if the term is absent, otherwise the list is added into the term's
+func (p *termMap) merge(key []byte, list posting.List) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	hashedKey := termHashID(convert.Hash(key))
+	if v, ok := p.repo[hashedKey]; ok {
+		return v.Value.Union(list)
+	}
+	p.repo[hashedKey] = &index.PostingValue{
+		Term:  key,
+		Value: list,
+	}
+	p.lst = append(p.lst, hashedKey)
+	return nil
+}
+
+func (p *termMap) size() uint64 {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return uint64(len(p.repo))
+}
+
+func (p *termMap) each(fn func(value *index.PostingValue) error) error {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	for _, v := range p.repo {
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *termMap) get(key []byte) posting.List {
 	e := p.getEntry(key)
 	if e == nil {
@@ -82,3 +115,44 @@ func (p *termMap) getEntry(key []byte) *index.PostingValue {
 	}
 	return v
 }
+
+// removeItem removes the item from all the terms, and drops the terms left empty
+func (p *termMap) removeItem(id common.ItemID) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	lst := p.lst[:0]
+	for _, hashedKey := range p.lst {
+		v := p.repo[hashedKey]
+		if v.Value.Contains(id) {
+			_ = v.Value.RemoveRange(id, id+1)
+		}
+		if v.Value.IsEmpty() {
+			delete(p.repo, hashedKey)
+			continue
+		}
+		lst = append(lst, hashedKey)
+	}
+	p.lst = lst
+}
+
+// removeTermItem removes the item from the term, and drops the term if it's left empty
+func (p *termMap) removeTermItem(key []byte, id common.ItemID) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	hashedKey := termHashID(convert.Hash(key))
+	v, ok := p.repo[hashedKey]
+	if !ok || !v.Value.Contains(id) {
+		return
+	}
+	_ = v.Value.RemoveRange(id, id+1)
+	if !v.Value.IsEmpty() {
+		return
+	}
+	delete(p.repo, hashedKey)
+	for i, k := range p.lst {
+		if k == hashedKey {
+			p.lst = append(p.lst[:i], p.lst[i+1:]...)
+			break
+		}
+	}
+}
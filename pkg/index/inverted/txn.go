@@ -0,0 +1,92 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package inverted
+
+import (
+	"go.uber.org/multierr"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+)
+
+var _ index.DocWriter = (*store)(nil)
+
+type docTxn struct {
+	s      *store
+	docID  common.ItemID
+	fields []index.Field
+	done   bool
+}
+
+func (s *store) BeginDoc(docID common.ItemID) index.DocTxn {
+	return &docTxn{s: s, docID: docID}
+}
+
+func (t *docTxn) Add(field index.Field) index.DocTxn {
+	t.fields = append(t.fields, field)
+	return t
+}
+
+// Commit writes the fields while holding the searches and the flush off,
+// so neither of them could observe a part of the fields.
+// All the fields are prepared before any of them is logged or applied, so a bad field fails the doc as a whole.
+func (t *docTxn) Commit() error {
+	if t.done {
+		return index.ErrTxnDone
+	}
+	t.done = true
+	t.s.rwMutex.Lock()
+	defer t.s.rwMutex.Unlock()
+	t.s.touch()
+	writes := make([]preparedWrite, 0, len(t.fields))
+	for _, field := range t.fields {
+		w, err := t.s.prepareWrite(field)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, w)
+	}
+	apply := func() error {
+		for _, w := range writes {
+			t.s.applyWrite(w, t.docID)
+		}
+		return nil
+	}
+	if t.s.wal == nil {
+		_ = apply()
+	} else {
+		// the fields are logged in a single record, so a crash never leaves a part of them
+		entries := make([]index.BulkEntry, 0, len(t.fields))
+		for _, field := range t.fields {
+			entries = append(entries, index.BulkEntry{Field: field, DocID: t.docID})
+		}
+		if err := t.s.logWrites(entries, apply); err != nil {
+			return err
+		}
+	}
+	var err error
+	for _, field := range t.fields {
+		err = multierr.Append(err, t.s.dropped.Restore(field, t.docID))
+	}
+	return err
+}
+
+func (t *docTxn) Rollback() {
+	t.done = true
+	t.fields = nil
+}
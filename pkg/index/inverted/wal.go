@@ -0,0 +1,529 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package inverted
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+)
+
+const (
+	defaultWALMaxSize = 64 << 20
+
+	walSuffix           = ".wal"
+	walHeaderLen        = 8
+	walRecordWrite      = byte(1)
+	walRecordDelete     = byte(2)
+	walRecordDropField  = byte(3)
+	walRecordDeleteTerm = byte(4)
+)
+
+// WALReplayer receives the records of the write-ahead log in the order they are acknowledged.
+// A nil handler skips the records of its kind.
+type WALReplayer struct {
+	OnWrites     func(entries []index.BulkEntry) error
+	OnDelete     func(docID common.ItemID) error
+	OnDropField  func(fieldKey index.FieldKey) error
+	OnDeleteTerm func(field index.Field, docID common.ItemID) error
+}
+
+// writeAheadLog records the writes and the deletions before they are applied to the live mem table,
+// so that the mem tables lost by a crash could be rebuilt on the next open.
+//
+// The log is split into segments. Each frozen mem table owns the segments which were live when it's frozen,
+// they are removed once the table is merged into the disk table.
+//
+// A record isn't fsynced on each write. Acknowledged means it's in the page cache, which survives a crash
+// of the process but not of the host. Sync makes the acknowledged records durable.
+type writeAheadLog struct {
+	mutex   sync.Mutex
+	dir     string
+	maxSize int64
+	file    walFile
+	// err fails the following records once a torn record can't be removed from the active segment
+	err     error
+	nextSeq uint64
+	// live are the segments of the live mem table, the active one is the last
+	live []uint64
+	// sealed are the segments of the frozen mem tables, the oldest first
+	sealed [][]uint64
+	sizes  map[uint64]int64
+	size   int64
+}
+
+// openWAL replays the segments left in dir, which belong to the live mem table afterwards,
+// then opens a new active segment.
+func openWAL(dir string, maxSize int64, r WALReplayer) (*writeAheadLog, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if maxSize <= 0 {
+		maxSize = defaultWALMaxSize
+	}
+	w := &writeAheadLog{
+		dir:     dir,
+		maxSize: maxSize,
+		sizes:   make(map[uint64]int64),
+	}
+	seqs, err := listWALSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i, seq := range seqs {
+		size, errReplay := replayWALSegment(w.segmentPath(seq), i == len(seqs)-1, r)
+		if errReplay != nil {
+			return nil, errReplay
+		}
+		w.live = append(w.live, seq)
+		w.sizes[seq] = size
+		w.size += size
+		w.nextSeq = seq + 1
+	}
+	if err = w.openSegment(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// ReplayWAL reads the write-ahead log of the store in path without opening the store, which is the recovery
+// NewStore runs on opening. A torn record at the tail, which a crash leaves, ends the replay.
+func ReplayWAL(path string, r WALReplayer) error {
+	dir := path + "/wal"
+	seqs, err := listWALSegments(dir)
+	if err != nil {
+		return err
+	}
+	for i, seq := range seqs {
+		if _, err = replayWALSegment(filepath.Join(dir, segmentName(seq)), false, r); err != nil {
+			if i == len(seqs)-1 && errors.Is(err, errTornRecord) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+var errTornRecord = errors.Wrap(index.ErrMalformed, "torn record of the write-ahead log")
+
+func listWALSegments(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	seqs := make([]uint64, 0, len(entries))
+	for _, e := range entries {
+		var seq uint64
+		if e.IsDir() || !strings.HasSuffix(e.Name(), walSuffix) {
+			continue
+		}
+		if _, errScan := fmt.Sscanf(e.Name(), "%016x"+walSuffix, &seq); errScan != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+// replayWALSegment returns the size of the valid records. The torn tail of the last segment is truncated,
+// since it's never acknowledged.
+func replayWALSegment(path string, last bool, r WALReplayer) (int64, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var offset int64
+	for len(raw) > 0 {
+		payload, n, errRecord := readWALRecord(raw)
+		if errRecord != nil {
+			if !last {
+				return 0, errors.WithMessagef(errRecord, "segment %s at %d", path, offset)
+			}
+			if errTruncate := os.Truncate(path, offset); errTruncate != nil {
+				return 0, errTruncate
+			}
+			return offset, nil
+		}
+		if err = applyWALRecord(payload, r); err != nil {
+			return 0, errors.WithMessagef(err, "segment %s at %d", path, offset)
+		}
+		raw = raw[n:]
+		offset += int64(n)
+	}
+	return offset, nil
+}
+
+// readWALRecord reads a record framed by the length and the checksum of its payload
+func readWALRecord(raw []byte) ([]byte, int, error) {
+	if len(raw) < walHeaderLen {
+		return nil, 0, errTornRecord
+	}
+	l := binary.BigEndian.Uint32(raw[:4])
+	if uint64(len(raw)-walHeaderLen) < uint64(l) {
+		return nil, 0, errTornRecord
+	}
+	payload := raw[walHeaderLen : walHeaderLen+int(l)]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(raw[4:8]) {
+		return nil, 0, errTornRecord
+	}
+	return payload, walHeaderLen + int(l), nil
+}
+
+func applyWALRecord(payload []byte, r WALReplayer) error {
+	if len(payload) < 1 {
+		return errors.Wrap(index.ErrMalformed, "empty record")
+	}
+	switch payload[0] {
+	case walRecordWrite:
+		entries, err := unmarshalWALWrites(payload[1:])
+		if err != nil || r.OnWrites == nil {
+			return err
+		}
+		return r.OnWrites(entries)
+	case walRecordDelete:
+		if len(payload) != 9 {
+			return errors.Wrap(index.ErrMalformed, "deletion record")
+		}
+		if r.OnDelete == nil {
+			return nil
+		}
+		return r.OnDelete(common.ItemID(convert.BytesToUint64(payload[1:])))
+	case walRecordDropField:
+		var fieldKey index.FieldKey
+		if err := fieldKey.Unmarshal(payload[1:]); err != nil {
+			return errors.Wrapf(index.ErrMalformed, "dropping record: %v", err)
+		}
+		if r.OnDropField == nil {
+			return nil
+		}
+		return r.OnDropField(fieldKey)
+	case walRecordDeleteTerm:
+		// the record is a write record of a single entry except its type
+		entries, err := unmarshalWALWrites(payload[1:])
+		if err != nil {
+			return err
+		}
+		if len(entries) != 1 {
+			return errors.Wrap(index.ErrMalformed, "term deletion record")
+		}
+		if r.OnDeleteTerm == nil {
+			return nil
+		}
+		return r.OnDeleteTerm(entries[0].Field, entries[0].DocID)
+	}
+	return errors.Wrapf(index.ErrMalformed, "unknown record type %d", payload[0])
+}
+
+func marshalWALDelete(docID common.ItemID) []byte {
+	return append([]byte{walRecordDelete}, convert.Uint64ToBytes(uint64(docID))...)
+}
+
+func marshalWALDropField(fieldKey index.FieldKey) []byte {
+	return append([]byte{walRecordDropField}, fieldKey.Marshal()...)
+}
+
+func marshalWALDeleteTerm(field index.Field, docID common.ItemID) []byte {
+	record := marshalWALWrites([]index.BulkEntry{{Field: field, DocID: docID}})
+	record[0] = walRecordDeleteTerm
+	return record
+}
+
+func marshalWALWrites(entries []index.BulkEntry) []byte {
+	buf := []byte{walRecordWrite}
+	buf = appendUvarint(buf, uint64(len(entries)))
+	for _, e := range entries {
+		key := e.Field.Key
+		buf = append(buf, key.Marshal()...)
+		encodeTerm := byte(0)
+		if key.EncodeTerm {
+			encodeTerm = 1
+		}
+		buf = append(buf, encodeTerm)
+		buf = appendUvarint(buf, uint64(key.AliasOf))
+		buf = appendWALBytes(buf, []byte(key.Comparator))
+		buf = appendUvarint(buf, uint64(key.PostingBlockSize))
+		buf = appendWALBytes(buf, e.Field.Term)
+		buf = append(buf, convert.Uint64ToBytes(uint64(e.DocID))...)
+	}
+	return buf
+}
+
+func unmarshalWALWrites(raw []byte) ([]index.BulkEntry, error) {
+	malformed := errors.Wrap(index.ErrMalformed, "write record")
+	count, n := binary.Uvarint(raw)
+	if n <= 0 {
+		return nil, malformed
+	}
+	raw = raw[n:]
+	entries := make([]index.BulkEntry, 0, count)
+	for i := uint64(0); i < count; i++ {
+		// the marshaled key has a fixed width
+		if len(raw) < 13 {
+			return nil, malformed
+		}
+		var key index.FieldKey
+		if err := key.Unmarshal(raw[:12]); err != nil {
+			return nil, err
+		}
+		key.EncodeTerm = raw[12] == 1
+		raw = raw[13:]
+		aliasOf, n := binary.Uvarint(raw)
+		if n <= 0 {
+			return nil, malformed
+		}
+		key.AliasOf = uint32(aliasOf)
+		raw = raw[n:]
+		comparator, rest, ok := readWALBytes(raw)
+		if !ok {
+			return nil, malformed
+		}
+		key.Comparator = string(comparator)
+		blockSize, n := binary.Uvarint(rest)
+		if n <= 0 {
+			return nil, malformed
+		}
+		key.PostingBlockSize = int(blockSize)
+		term, rest, ok := readWALBytes(rest[n:])
+		if !ok || len(rest) < 8 {
+			return nil, malformed
+		}
+		entries = append(entries, index.BulkEntry{
+			Field: index.Field{Key: key, Term: append([]byte(nil), term...)},
+			DocID: common.ItemID(convert.BytesToUint64(rest[:8])),
+		})
+		raw = rest[8:]
+	}
+	return entries, nil
+}
+
+func appendWALBytes(buf, b []byte) []byte {
+	buf = appendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], v)
+	return append(buf, lenBuf[:n]...)
+}
+
+func readWALBytes(raw []byte) ([]byte, []byte, bool) {
+	l, n := binary.Uvarint(raw)
+	if n <= 0 || uint64(len(raw)-n) < l {
+		return nil, nil, false
+	}
+	return raw[n : n+int(l)], raw[n+int(l):], true
+}
+
+// logWrites logs the entries in a single record before apply writes them
+func (s *store) logWrites(entries []index.BulkEntry, apply func() error) error {
+	return s.logRecord(marshalWALWrites(entries), apply)
+}
+
+func (s *store) logRecord(payload []byte, apply func() error) error {
+	full, err := s.wal.log(payload, apply)
+	if full {
+		s.flushWAL()
+	}
+	return err
+}
+
+// flushWAL flushes and merges the mem tables in the background, which removes their segments of the log.
+// The caller might hold the lock, so it doesn't wait.
+func (s *store) flushWAL() {
+	if !atomic.CompareAndSwapInt32(&s.walFlushing, 0, 1) {
+		return
+	}
+	s.walFlushes.Add(1)
+	go func() {
+		defer s.walFlushes.Done()
+		defer atomic.StoreInt32(&s.walFlushing, 0)
+		err := s.Flush()
+		if err == nil && s.mergePolicy == MergeLazy {
+			err = s.mergeAll()
+		}
+		if err != nil {
+			s.l.Warn().Err(err).Msg("failed to flush the mem tables to truncate the write-ahead log")
+		}
+	}()
+}
+
+// replayer rebuilds the live mem table from the log without logging the records again
+func (s *store) replayer() WALReplayer {
+	return WALReplayer{
+		OnWrites: func(entries []index.BulkEntry) error {
+			for _, e := range entries {
+				if err := s.write(e.Field, e.DocID); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		OnDelete: func(docID common.ItemID) error {
+			s.deleteInMemTables(docID)
+			return nil
+		},
+		OnDropField: func(fieldKey index.FieldKey) error {
+			s.dropInMemTables(fieldKey)
+			return nil
+		},
+		OnDeleteTerm: func(field index.Field, docID common.ItemID) error {
+			s.deleteTermInMemTables(field, docID)
+			return nil
+		},
+	}
+}
+
+// walFile is the active segment
+type walFile interface {
+	Write(b []byte) (int, error)
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// log appends the record to the active segment, then applies it. A record is never applied without being logged,
+// and the rotation doesn't come in between, so the record goes to a segment of the table it's applied to.
+// It returns true if the log outgrows the max size.
+//
+// A failed write or apply truncates the record away. Otherwise the records after a torn one would be dropped
+// by the replay, and a rejected record would be replayed.
+func (w *writeAheadLog) log(payload []byte, apply func() error) (bool, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.err != nil {
+		return false, w.err
+	}
+	record := make([]byte, walHeaderLen, walHeaderLen+len(payload))
+	binary.BigEndian.PutUint32(record[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	record = append(record, payload...)
+	active := w.live[len(w.live)-1]
+	offset := w.sizes[active]
+	if _, err := w.file.Write(record); err != nil {
+		return false, w.undo(offset, err)
+	}
+	if err := apply(); err != nil {
+		return false, w.undo(offset, err)
+	}
+	w.sizes[active] += int64(len(record))
+	w.size += int64(len(record))
+	return w.size > w.maxSize, nil
+}
+
+// undo truncates the active segment to the offset of the failed record. If the truncation fails, the log
+// refuses the following records, since they would be appended after the torn one.
+func (w *writeAheadLog) undo(offset int64, cause error) error {
+	if err := w.file.Truncate(offset); err != nil {
+		w.err = errors.WithMessage(err, "failed to remove a torn record of the write-ahead log")
+		return multierr.Append(cause, w.err)
+	}
+	return cause
+}
+
+// rotate seals the live segments along with the mem table frozen by swap, and opens a new active segment
+func (w *writeAheadLog) rotate(swap func()) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	swap()
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.sealed = append(w.sealed, w.live)
+	w.live = nil
+	return w.openSegment()
+}
+
+// release removes the segments of the oldest frozen mem table, which is merged into the disk table
+func (w *writeAheadLog) release() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if len(w.sealed) < 1 {
+		return nil
+	}
+	for _, seq := range w.sealed[0] {
+		if err := os.Remove(w.segmentPath(seq)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		w.size -= w.sizes[seq]
+		delete(w.sizes, seq)
+	}
+	w.sealed[0] = nil
+	w.sealed = w.sealed[1:]
+	return nil
+}
+
+func (w *writeAheadLog) sync() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+	return index.SyncFile(w.dir)
+}
+
+func (w *writeAheadLog) totalSize() int64 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.size
+}
+
+func (w *writeAheadLog) close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.file.Close()
+}
+
+func (w *writeAheadLog) openSegment() error {
+	seq := w.nextSeq
+	f, err := os.OpenFile(w.segmentPath(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	w.file = f
+	w.nextSeq++
+	w.live = append(w.live, seq)
+	w.sizes[seq] = 0
+	return nil
+}
+
+func (w *writeAheadLog) segmentPath(seq uint64) string {
+	return filepath.Join(w.dir, segmentName(seq))
+}
+
+func segmentName(seq uint64) string {
+	return fmt.Sprintf("%016x", seq) + walSuffix
+}
@@ -0,0 +1,299 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package inverted
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+)
+
+// walState reads the postings of the fields, which the replay of the write-ahead log should rebuild as they were
+func walState(tester *assert.Assertions, s index.Store, fields []index.Field) [][]common.ItemID {
+	state := make([][]common.ItemID, 0, len(fields))
+	for _, field := range fields {
+		list, err := s.MatchTerms(field)
+		tester.NoError(err)
+		items := list.ToSlice()
+		sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+		state = append(state, items)
+	}
+	return state
+}
+
+func TestStore_WALDocFailure(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	defer fn()
+	opts := StoreOpts{
+		Path:     path,
+		Logger:   logger.GetLogger("test"),
+		TailSize: 10,
+		WAL:      true,
+	}
+	s, err := NewStore(opts)
+	tester.NoError(err)
+	service := index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte("svc")}
+	unknown := index.Field{Key: index.FieldKey{IndexRuleID: 2, Comparator: "unknown"}, Term: []byte("/home")}
+	fields := []index.Field{service, unknown}
+
+	// the bad field comes last, the ones before it are neither applied nor logged
+	err = s.(index.DocWriter).BeginDoc(common.ItemID(1)).Add(service).Add(unknown).Commit()
+	tester.ErrorIs(err, index.ErrUnknownComparator)
+	tester.ErrorIs(s.Write(unknown, common.ItemID(2)), index.ErrUnknownComparator)
+	tester.Equal([][]common.ItemID{{}, {}}, walState(tester, s, fields))
+	tester.Zero(s.Stats().WALSize)
+	tail, err := s.(index.TailSearcher).TailN(service, 10)
+	tester.NoError(err)
+	tester.Empty(tail)
+
+	tester.NoError(s.Close())
+	s, err = NewStore(opts)
+	tester.NoError(err)
+	tester.Equal([][]common.ItemID{{}, {}}, walState(tester, s, fields))
+	tester.NoError(s.Close())
+}
+
+func TestStore_WAL(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	defer fn()
+	opts := StoreOpts{
+		Path:     path,
+		Logger:   logger.GetLogger("test"),
+		TailSize: 10,
+		WAL:      true,
+	}
+	s, err := NewStore(opts)
+	tester.NoError(err)
+	service := index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte("svc")}
+	endpoint := index.Field{Key: index.FieldKey{IndexRuleID: 2}, Term: []byte("/home")}
+	encoded := index.Field{Key: index.FieldKey{IndexRuleID: 3, EncodeTerm: true}, Term: []byte("10.0.0.1")}
+	dropped := index.Field{Key: index.FieldKey{IndexRuleID: 4}, Term: []byte("gone")}
+	fields := []index.Field{service, endpoint, encoded, dropped}
+
+	tester.NoError(s.Write(service, common.ItemID(1)))
+	tester.NoError(s.(*store).Flush())
+	tester.Zero(s.Stats().WALSize, "the flush removes the segments of the merged table")
+	tester.NoError(s.Write(service, common.ItemID(2)))
+	tester.NoError(s.Write(dropped, common.ItemID(2)))
+	tester.NoError(s.(index.DocWriter).BeginDoc(common.ItemID(3)).Add(service).Add(endpoint).Add(encoded).Commit())
+	tester.NoError(s.(index.BulkLoader).BulkLoad([]index.BulkEntry{
+		{Field: endpoint, DocID: common.ItemID(5)},
+		{Field: service, DocID: common.ItemID(4)},
+		{Field: endpoint, DocID: common.ItemID(4)},
+	}))
+	deleted, err := s.(index.DocDeleter).DeleteDoc(common.ItemID(4))
+	tester.NoError(err)
+	tester.True(deleted)
+	tester.NoError(s.DropField(dropped.Key))
+	tester.Positive(s.Stats().WALSize)
+	want := walState(tester, s, fields)
+	tester.Equal([][]common.ItemID{{1, 2, 3}, {3, 5}, {3}, {}}, want)
+	wantTail, err := s.(index.TailSearcher).TailN(service, 10)
+	tester.NoError(err)
+
+	// Close drops the buffer without flushing it, just like a kill
+	tester.NoError(s.Close())
+	s, err = NewStore(opts)
+	tester.NoError(err)
+	tester.Equal(want, walState(tester, s, fields))
+	tail, err := s.(index.TailSearcher).TailN(service, 10)
+	tester.NoError(err)
+	tester.Equal(wantTail, tail)
+
+	// a torn record at the tail is never acknowledged, it's truncated on replaying
+	tester.NoError(s.Write(service, common.ItemID(6)))
+	tester.NoError(s.Close())
+	seqs, err := listWALSegments(path + "/wal")
+	tester.NoError(err)
+	last := filepath.Join(path+"/wal", segmentName(seqs[len(seqs)-1]))
+	f, err := os.OpenFile(last, os.O_WRONLY|os.O_APPEND, 0o600)
+	tester.NoError(err)
+	_, err = f.Write(marshalWALDelete(common.ItemID(6))[:5])
+	tester.NoError(err)
+	tester.NoError(f.Close())
+	var replayed []common.ItemID
+	tester.NoError(ReplayWAL(path, WALReplayer{
+		OnWrites: func(entries []index.BulkEntry) error {
+			for _, e := range entries {
+				replayed = append(replayed, e.DocID)
+			}
+			return nil
+		},
+	}))
+	tester.Contains(replayed, common.ItemID(6))
+	s, err = NewStore(opts)
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	want[0] = append(want[0], 6)
+	tester.Equal(want, walState(tester, s, fields))
+	entries, err := os.ReadDir(path + "/wal")
+	tester.NoError(err)
+	var size int64
+	for _, e := range entries {
+		info, errInfo := e.Info()
+		tester.NoError(errInfo)
+		size += info.Size()
+	}
+	tester.Equal(size, s.Stats().WALSize)
+}
+
+func TestStore_WAL_MergeLazy(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	defer fn()
+	opts := StoreOpts{
+		Path:        path,
+		Logger:      logger.GetLogger("test"),
+		MergePolicy: MergeLazy,
+		CompactIdle: time.Hour,
+		WAL:         true,
+	}
+	s, err := NewStore(opts)
+	tester.NoError(err)
+	field := index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte("svc")}
+	for i := 1; i <= 3; i++ {
+		tester.NoError(s.Write(field, common.ItemID(i)))
+		tester.NoError(s.(*store).Flush())
+	}
+	tester.NoError(s.Write(field, common.ItemID(4)))
+	// a merge removes the segments of the merged table only
+	_, err = s.(*store).mergeOne()
+	tester.NoError(err)
+	tester.Len(s.(*store).wal.sealed, 2)
+
+	// simulate a kill, which loses the frozen tables unless they are logged
+	st := s.(*store)
+	close(st.stopCompactor)
+	<-st.compactorDone
+	tester.NoError(st.wal.close())
+	tester.NoError(st.diskTable.Close())
+	tester.NoError(st.termMetadata.Close())
+	s, err = NewStore(opts)
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	tester.Equal([][]common.ItemID{{1, 2, 3, 4}}, walState(tester, s, []index.Field{field}))
+}
+
+func TestStore_WALMaxSize(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	defer fn()
+	s, err := NewStore(StoreOpts{
+		Path:       path,
+		Logger:     logger.GetLogger("test"),
+		WAL:        true,
+		WALMaxSize: 1024,
+	})
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	field := index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte("svc")}
+	for i := 1; i <= 100; i++ {
+		tester.NoError(s.Write(field, common.ItemID(i)))
+	}
+	// the background flush truncates the log
+	tester.Eventually(func() bool {
+		return s.Stats().WALSize <= 1024
+	}, 5*time.Second, 10*time.Millisecond)
+	list, err := s.MatchTerms(field)
+	tester.NoError(err)
+	tester.Equal(100, list.Len())
+}
+
+// tornFile writes half of the next record, then fails as a full disk does
+type tornFile struct {
+	walFile
+	torn bool
+}
+
+func (f *tornFile) Write(b []byte) (int, error) {
+	if f.torn {
+		return f.walFile.Write(b)
+	}
+	f.torn = true
+	n, err := f.walFile.Write(b[:len(b)/2])
+	if err != nil {
+		return n, err
+	}
+	return n, errors.New("no space left on device")
+}
+
+func TestStore_WALTornRecord(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	defer fn()
+	opts := StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+		WAL:    true,
+	}
+	s, err := NewStore(opts)
+	tester.NoError(err)
+	service := index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte("svc")}
+	fields := []index.Field{service}
+
+	tester.NoError(s.Write(service, common.ItemID(1)))
+	size := s.Stats().WALSize
+	wal := s.(*store).wal
+	wal.file = &tornFile{walFile: wal.file}
+	tester.Error(s.Write(service, common.ItemID(2)))
+	tester.Equal(size, s.Stats().WALSize)
+	// the record after the torn one is acknowledged, so it must survive the replay
+	tester.NoError(s.Write(service, common.ItemID(3)))
+	// a record failing to apply isn't replayed
+	tester.Error(s.(*store).logRecord(marshalWALDelete(common.ItemID(1)), func() error {
+		return errors.New("rejected")
+	}))
+	tester.Equal([][]common.ItemID{{1, 3}}, walState(tester, s, fields))
+	tester.NoError(s.Close())
+
+	var replayed []common.ItemID
+	tester.NoError(ReplayWAL(path, WALReplayer{
+		OnWrites: func(entries []index.BulkEntry) error {
+			for _, e := range entries {
+				replayed = append(replayed, e.DocID)
+			}
+			return nil
+		},
+		OnDelete: func(docID common.ItemID) error {
+			return errors.Errorf("the rejected deletion of %d is replayed", docID)
+		},
+	}))
+	tester.Equal([]common.ItemID{1, 3}, replayed)
+	s, err = NewStore(opts)
+	tester.NoError(err)
+	tester.Equal([][]common.ItemID{{1, 3}}, walState(tester, s, fields))
+	tester.NoError(s.Close())
+}
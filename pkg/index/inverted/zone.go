@@ -0,0 +1,177 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package inverted
+
+import (
+	"encoding/binary"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/apache/skywalking-banyandb/pkg/index"
+)
+
+// zone is the min and max terms of a field in a segment
+type zone struct {
+	comparator string
+	min        []byte
+	max        []byte
+}
+
+// zoneMap holds the zones of all the fields in a segment. It lets a range query skip the segment
+// if none of the terms of the field falls in the range.
+type zoneMap struct {
+	mutex sync.RWMutex
+	repo  map[string]*zone
+}
+
+func newZoneMap() *zoneMap {
+	return &zoneMap{
+		repo: make(map[string]*zone),
+	}
+}
+
+// put widens the zone of the field to cover the term.
+// The field is never pruned if its comparator is unknown, which fails the range queries instead of the writes.
+func (z *zoneMap) put(fieldKey index.FieldKey, term []byte) {
+	compare, err := index.GetComparator(fieldKey.Comparator)
+	if err != nil {
+		return
+	}
+	key := string(fieldKey.Marshal())
+	z.mutex.Lock()
+	defer z.mutex.Unlock()
+	zn, ok := z.repo[key]
+	if !ok {
+		z.repo[key] = &zone{
+			comparator: fieldKey.Comparator,
+			min:        cloneTerm(term),
+			max:        cloneTerm(term),
+		}
+		return
+	}
+	if compare(term, zn.min) < 0 {
+		zn.min = cloneTerm(term)
+	}
+	if compare(term, zn.max) > 0 {
+		zn.max = cloneTerm(term)
+	}
+}
+
+// merge widens the zones to cover the ones of other
+func (z *zoneMap) merge(other *zoneMap) error {
+	other.mutex.RLock()
+	defer other.mutex.RUnlock()
+	z.mutex.Lock()
+	defer z.mutex.Unlock()
+	for key, o := range other.repo {
+		zn, ok := z.repo[key]
+		if !ok {
+			z.repo[key] = &zone{comparator: o.comparator, min: o.min, max: o.max}
+			continue
+		}
+		compare, err := index.GetComparator(o.comparator)
+		if err != nil {
+			return err
+		}
+		if compare(o.min, zn.min) < 0 {
+			zn.min = o.min
+		}
+		if compare(o.max, zn.max) > 0 {
+			zn.max = o.max
+		}
+	}
+	return nil
+}
+
+// overlaps tells whether any term of the field in the segment might fall in the range.
+// It's true if the zone of the field is unknown.
+func (z *zoneMap) overlaps(fieldKey index.FieldKey, opts index.RangeOpts) (bool, error) {
+	z.mutex.RLock()
+	zn, ok := z.repo[string(fieldKey.Marshal())]
+	z.mutex.RUnlock()
+	if !ok {
+		return true, nil
+	}
+	compare, err := index.GetComparator(fieldKey.Comparator)
+	if err != nil {
+		return false, err
+	}
+	// the whole zone is either below the lower bound or above the upper bound
+	return opts.BetweenWith(zn.max, compare) >= 0 && opts.BetweenWith(zn.min, compare) <= 0, nil
+}
+
+// save writes the zones to the file at path in a single shot, which replaces the old one atomically
+func (z *zoneMap) save(path string) error {
+	z.mutex.RLock()
+	keys := make([]string, 0, len(z.repo))
+	for key := range z.repo {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buf []byte
+	var lenBuf [binary.MaxVarintLen64]byte
+	for _, key := range keys {
+		zn := z.repo[key]
+		for _, b := range [][]byte{[]byte(key), []byte(zn.comparator), zn.min, zn.max} {
+			n := binary.PutUvarint(lenBuf[:], uint64(len(b)))
+			buf = append(buf, lenBuf[:n]...)
+			buf = append(buf, b...)
+		}
+	}
+	z.mutex.RUnlock()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func loadZoneMap(path string) (*zoneMap, error) {
+	z := newZoneMap()
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return z, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for len(raw) > 0 {
+		var fields [4][]byte
+		for i := range fields {
+			l, n := binary.Uvarint(raw)
+			if n <= 0 || uint64(len(raw)-n) < l {
+				return nil, errors.Wrapf(index.ErrMalformed, "zone map %s", path)
+			}
+			fields[i] = raw[n : n+int(l)]
+			raw = raw[n+int(l):]
+		}
+		z.repo[string(fields[0])] = &zone{
+			comparator: string(fields[1]),
+			min:        fields[2],
+			max:        fields[3],
+		}
+	}
+	return z, nil
+}
+
+func cloneTerm(term []byte) []byte {
+	return append([]byte{}, term...)
+}
@@ -23,13 +23,36 @@ import (
 
 	"go.uber.org/multierr"
 
+	"github.com/apache/skywalking-banyandb/api/common"
 	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
 	"github.com/apache/skywalking-banyandb/banyand/kv"
 	"github.com/apache/skywalking-banyandb/pkg/convert"
 	"github.com/apache/skywalking-banyandb/pkg/index/metadata"
+	"github.com/apache/skywalking-banyandb/pkg/index/posting"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 )
 
+// BoundaryTerm returns the first term of the field in the order, which serves MinTerm and MaxTerm.
+// The terms whose items are all hidden are skipped.
+func BoundaryTerm(iterable FieldIterable, fieldKey FieldKey, order modelv1.Sort) (term []byte, err error) {
+	iter, err := iterable.Iterator(fieldKey, RangeOpts{}, order)
+	if err != nil {
+		return nil, err
+	}
+	if iter == nil {
+		return nil, ErrNoTerm
+	}
+	defer func() {
+		err = multierr.Append(err, iter.Close())
+	}()
+	for iter.Next() {
+		if v := iter.Val(); v.Value != nil && !v.Value.IsEmpty() {
+			return v.Term, nil
+		}
+	}
+	return nil, ErrNoTerm
+}
+
 type CompositePostingValueFn = func(term, value []byte, delegated kv.Iterator) (*PostingValue, error)
 
 var _ FieldIterator = (*FieldIteratorTemplate)(nil)
@@ -43,8 +66,10 @@ type FieldIteratorTemplate struct {
 	cur       *PostingValue
 	err       error
 	termRange RangeOpts
+	compare   Comparator
 	fn        CompositePostingValueFn
 	reverse   bool
+	fullScan  bool
 	seekKey   []byte
 }
 
@@ -53,29 +78,23 @@ func (f *FieldIteratorTemplate) Next() bool {
 		f.init = true
 		f.delegated.Seek(f.seekKey)
 	}
-	if !f.delegated.Valid() {
-		return false
-	}
-	pv, err := f.fn(f.delegated.Field().Term, f.delegated.Val(), f.delegated)
-	if err != nil {
-		f.err = err
-		return false
-	}
-	in := f.termRange.Between(pv.Term)
-	switch {
-	case in > 0:
-		if f.reverse {
-			return f.Next()
+	for f.delegated.Valid() {
+		pv, err := f.fn(f.delegated.Field().Term, f.delegated.Val(), f.delegated)
+		if err != nil {
+			f.err = err
+			return false
 		}
-		return false
-	case in < 0:
-		if f.reverse {
+		in := f.termRange.BetweenWith(pv.Term, f.compare)
+		switch {
+		case in == 0:
+			f.cur = pv
+			return true
+		case f.fullScan:
+		case in > 0 && !f.reverse, in < 0 && f.reverse:
 			return false
 		}
-		return f.Next()
 	}
-	f.cur = pv
-	return true
+	return false
 }
 
 func (f *FieldIteratorTemplate) Val() *PostingValue {
@@ -88,42 +107,48 @@ func (f *FieldIteratorTemplate) Close() error {
 
 func NewFieldIteratorTemplate(l *logger.Logger, fieldKey FieldKey, termRange RangeOpts, order modelv1.Sort, iterable kv.Iterable,
 	metadata metadata.Term, fn CompositePostingValueFn) (*FieldIteratorTemplate, error) {
-	if termRange.Upper == nil {
-		termRange.Upper = DefaultUpper
-	}
-	if termRange.Lower == nil {
-		termRange.Lower = DefaultLower
-	}
-	var reverse bool
-	var term []byte
-	switch order {
-	case modelv1.Sort_SORT_ASC, modelv1.Sort_SORT_UNSPECIFIED:
-		term = termRange.Lower
-		reverse = false
-	case modelv1.Sort_SORT_DESC:
-		term = termRange.Upper
-		reverse = true
-	}
-	if order == modelv1.Sort_SORT_DESC {
-		reverse = true
+	compare, err := GetComparator(fieldKey.Comparator)
+	if err != nil {
+		return nil, err
 	}
+	reverse := order == modelv1.Sort_SORT_DESC
 	iter := iterable.NewIterator(kv.ScanOpts{
 		Prefix:  fieldKey.Marshal(),
 		Reverse: reverse,
 	})
 	field := Field{
-		Key:  fieldKey,
-		Term: term,
+		Key: fieldKey,
+	}
+	var seekKey []byte
+	// The encoded terms are sorted by their ids rather than the literals, and a custom comparator
+	// doesn't follow the byte-wise order. Both force the iterator to scan the whole field and filter terms one by one.
+	fullScan := fieldKey.EncodeTerm || !byteWise(fieldKey.Comparator)
+	if fullScan {
+		field.Term = DefaultLower
+		if reverse {
+			field.Term = DefaultUpper
+		}
+		seekKey, err = field.MarshalStraight()
+	} else {
+		field.Term = termRange.Lower
+		if reverse {
+			field.Term = termRange.Upper
+			if field.Term == nil {
+				field.Term = DefaultUpper
+			}
+		}
+		seekKey, err = field.Marshal(metadata)
 	}
-	seekKey, err := field.Marshal(metadata)
 	if err != nil {
 		return nil, err
 	}
 	return &FieldIteratorTemplate{
 		delegated: newDelegateIterator(iter, fieldKey, metadata, l),
 		termRange: termRange,
+		compare:   compare,
 		fn:        fn,
 		reverse:   reverse,
+		fullScan:  fullScan,
 		seekKey:   seekKey,
 	}, nil
 }
@@ -141,6 +166,18 @@ func parseKey(fieldKey FieldKey, metadata metadata.Term, key []byte) (Field, err
 
 type SwitchFn = func(a, b []byte) bool
 
+// ByteWiseSwitch makes the merged iterator yield the terms in byte-wise order
+func ByteWiseSwitch(order modelv1.Sort) SwitchFn {
+	if order == modelv1.Sort_SORT_DESC {
+		return func(a, b []byte) bool {
+			return bytes.Compare(a, b) < 0
+		}
+	}
+	return func(a, b []byte) bool {
+		return bytes.Compare(a, b) > 0
+	}
+}
+
 var _ FieldIterator = (*mergedIterator)(nil)
 
 type mergedIterator struct {
@@ -187,6 +224,7 @@ func (m *mergedIterator) Next() bool {
 		}
 		if head == nil {
 			head = iterator
+			headIndex = i
 			continue
 		}
 		if m.switchFn(head.Val().Term, iterator.Val().Term) {
@@ -300,3 +338,105 @@ func (di *delegateIterator) Close() error {
 	di.closed = true
 	return nil
 }
+
+// SortedItemIterator iterates items in the order of their terms
+type SortedItemIterator interface {
+	Next() bool
+	Val() common.ItemID
+	Term() []byte
+	Close() error
+}
+
+var _ SortedItemIterator = (*sortedFieldIterator)(nil)
+
+type sortedFieldIterator struct {
+	delegated FieldIterator
+	remaining posting.List
+	items     posting.Iterator
+	term      []byte
+	err       error
+}
+
+// NewSortedFieldIterator yields the items of the field ordered by their terms.
+// The items are restricted to the ones in within unless it's nil.
+// delegated might be nil, which means the field is absent.
+func NewSortedFieldIterator(delegated FieldIterator, within posting.List) SortedItemIterator {
+	it := &sortedFieldIterator{
+		delegated: delegated,
+	}
+	if within != nil {
+		it.remaining = within.Clone()
+	}
+	return it
+}
+
+func (s *sortedFieldIterator) Next() bool {
+	for {
+		if s.items != nil && s.items.Next() {
+			return true
+		}
+		if s.delegated == nil || (s.remaining != nil && s.remaining.IsEmpty()) {
+			return false
+		}
+		if !s.delegated.Next() {
+			return false
+		}
+		pv := s.delegated.Val()
+		hit := pv.Value.Clone()
+		if s.remaining != nil {
+			if err := hit.Intersect(s.remaining); err != nil {
+				s.err = err
+				return false
+			}
+			if err := s.remaining.Difference(hit); err != nil {
+				s.err = err
+				return false
+			}
+		}
+		if s.items != nil {
+			s.err = multierr.Append(s.err, s.items.Close())
+		}
+		s.term = pv.Term
+		s.items = hit.Iterator()
+	}
+}
+
+func (s *sortedFieldIterator) Val() common.ItemID {
+	return s.items.Current()
+}
+
+func (s *sortedFieldIterator) Term() []byte {
+	return s.term
+}
+
+func (s *sortedFieldIterator) Close() error {
+	err := s.err
+	if s.items != nil {
+		err = multierr.Append(err, s.items.Close())
+	}
+	if s.delegated != nil {
+		err = multierr.Append(err, s.delegated.Close())
+	}
+	return err
+}
+
+// EachTerm visits all the entries of the iterable in the key order.
+// The versions of a key are visited consecutively, the latest one first, which is denoted by latest.
+func EachTerm(iterable kv.Iterable, fn func(key, value []byte, latest bool) error) (err error) {
+	iter := iterable.NewIterator(kv.ScanOpts{PrefetchValues: true})
+	defer func() {
+		err = multierr.Append(err, iter.Close())
+	}()
+	var last []byte
+	for iter.Rewind(); iter.Valid(); iter.Next() {
+		key := iter.Key()
+		latest := last == nil || !bytes.Equal(last, key)
+		if latest {
+			last = append(last[:0], key...)
+		}
+		if err = fn(key, iter.Val(), latest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -25,6 +25,8 @@ import (
 	"github.com/apache/skywalking-banyandb/pkg/convert"
 	"github.com/apache/skywalking-banyandb/pkg/index"
 	"github.com/apache/skywalking-banyandb/pkg/index/metadata"
+	"github.com/apache/skywalking-banyandb/pkg/index/posting"
+	"github.com/apache/skywalking-banyandb/pkg/index/posting/roaring"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 )
 
@@ -33,6 +35,8 @@ var _ index.Store = (*store)(nil)
 type store struct {
 	lsm          kv.Store
 	termMetadata metadata.Term
+	dropped      *index.DroppedItems
+	newList      posting.Factory
 	l            *logger.Logger
 }
 
@@ -46,12 +50,58 @@ func (s *store) Write(field index.Field, itemID common.ItemID) error {
 		return err
 	}
 	itemIDInt := uint64(itemID)
-	return s.lsm.PutWithVersion(f, convert.Uint64ToBytes(itemIDInt), itemIDInt)
+	if err = s.lsm.PutWithVersion(f, convert.Uint64ToBytes(itemIDInt), itemIDInt); err != nil {
+		return err
+	}
+	return s.dropped.Restore(field, itemID)
+}
+
+// DeleteTerm hides the item from the term, because the lsm tree doesn't delete the keys
+func (s *store) DeleteTerm(field index.Field, itemID common.ItemID) error {
+	return s.dropped.DropTerm(field, itemID)
+}
+
+// DropField hides the items of the field, because the lsm tree doesn't delete the keys
+func (s *store) DropField(fieldKey index.FieldKey) error {
+	items, err := s.MatchField(fieldKey)
+	if err != nil {
+		return err
+	}
+	return s.dropped.Drop(fieldKey, items)
+}
+
+func (s *store) Stats() index.Stats {
+	kvStats := s.lsm.Stats()
+	return index.Stats{
+		SegmentCount:  kvStats.TableCount,
+		TotalPostings: kvStats.KeyCount,
+		BytesOnDisk:   kvStats.Size,
+	}
+}
+
+// IndexStats counts each version of a term as a posting, because every item is stored as a version of its term
+func (s *store) IndexStats() (index.IndexStats, error) {
+	stats := index.IndexStats{
+		BytesOnDisk: s.lsm.Stats().Size,
+	}
+	err := index.EachTerm(s.lsm, func(_, _ []byte, latest bool) error {
+		if latest {
+			stats.TermCount++
+		}
+		stats.PostingCount++
+		return nil
+	})
+	if err != nil {
+		return index.IndexStats{}, err
+	}
+	return stats, nil
 }
 
 type StoreOpts struct {
 	Path   string
 	Logger *logger.Logger
+	// PostingFactory creates the posting lists of the store. It's roaring.NewPostingList by default.
+	PostingFactory posting.Factory
 }
 
 func NewStore(opts StoreOpts) (index.Store, error) {
@@ -67,9 +117,19 @@ func NewStore(opts StoreOpts) (index.Store, error) {
 	}); err != nil {
 		return nil, err
 	}
+	newList := opts.PostingFactory
+	if newList == nil {
+		newList = roaring.NewPostingList
+	}
+	dropped, err := index.LoadDroppedItems(opts.Path+"/dropped", newList)
+	if err != nil {
+		return nil, err
+	}
 	return &store{
+		dropped:      dropped,
 		lsm:          lsm,
 		termMetadata: md,
+		newList:      newList,
 		l:            opts.Logger,
 	}, nil
 }
@@ -18,11 +18,14 @@
 package lsm
 
 import (
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/index"
 	"github.com/apache/skywalking-banyandb/pkg/index/testcases"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 	"github.com/apache/skywalking-banyandb/pkg/test"
@@ -44,6 +47,22 @@ func TestStore_MatchTerm(t *testing.T) {
 	testcases.RunServiceName(t, s)
 }
 
+func TestStore_MatchField(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUpDBStatement(tester, s)
+	testcases.RunMatchField(t, s)
+}
+
 func TestStore_Iterator(t *testing.T) {
 	tester := assert.New(t)
 	path, fn := setUp(require.New(t))
@@ -60,6 +79,138 @@ func TestStore_Iterator(t *testing.T) {
 	testcases.RunDuration(t, data, s)
 }
 
+func TestStore_MatchTermWithinRange(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	data := testcases.SetUpDuration(tester, s)
+	services := testcases.SetUpDurationService(tester, s)
+	testcases.RunDurationMatchTermWithinRange(t, data, services, s)
+}
+
+func TestStore_IndexStats(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUpEndpoint(tester, s)
+	testcases.RunEndpointIndexStats(t, s)
+}
+
+func TestStore_MinMaxTerm(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUpEndpoint(tester, s)
+	testcases.RunEndpointMinMaxTerm(t, s)
+}
+
+func TestStore_FieldAlias(t *testing.T) {
+	tester := assert.New(t)
+	path, fn := setUp(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	defer func() {
+		tester.NoError(s.Close())
+		fn()
+	}()
+	tester.NoError(err)
+	testcases.SetUpEndpoint(tester, s)
+	testcases.RunEndpointAlias(t, s)
+}
+
+func TestStore_DeleteTerm(t *testing.T) {
+	tester := assert.New(t)
+	is := require.New(t)
+	path, fn := setUp(is)
+	defer fn()
+	s, err := NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	is.NoError(err)
+	minute := index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte("minute")}
+	hour := index.Field{Key: index.FieldKey{IndexRuleID: 1}, Term: []byte("hour")}
+	match := func(field index.Field) []common.ItemID {
+		list, errMatch := s.MatchTerms(field)
+		is.NoError(errMatch)
+		items := list.ToSlice()
+		sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+		return items
+	}
+	for i := 1; i <= 3; i++ {
+		is.NoError(s.Write(minute, common.ItemID(i)))
+	}
+	is.NoError(s.DeleteTerm(minute, common.ItemID(2)))
+	is.NoError(s.Write(hour, common.ItemID(2)))
+	tester.Equal([]common.ItemID{1, 3}, match(minute))
+	tester.Equal([]common.ItemID{2}, match(hour))
+	list, err := s.MatchField(minute.Key)
+	is.NoError(err)
+	tester.Equal(3, list.Len())
+	is.NoError(s.DeleteTerm(minute, common.ItemID(3)))
+	// writing the term again shows the item
+	is.NoError(s.Write(minute, common.ItemID(2)))
+	is.NoError(s.Close())
+
+	s, err = NewStore(StoreOpts{
+		Path:   path,
+		Logger: logger.GetLogger("test"),
+	})
+	is.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	tester.Equal([]common.ItemID{1, 2}, match(minute))
+}
+
+func BenchmarkStore(b *testing.B) {
+	for name, factory := range testcases.PostingFactories {
+		b.Run(name, func(b *testing.B) {
+			is := require.New(b)
+			path, fn := setUp(is)
+			s, err := NewStore(StoreOpts{
+				Path:           path,
+				Logger:         logger.GetLogger("test"),
+				PostingFactory: factory,
+			})
+			is.NoError(err)
+			defer func() {
+				is.NoError(s.Close())
+				fn()
+			}()
+			testcases.SetUpBenchmark(b, s)
+			b.ResetTimer()
+			testcases.RunBenchmark(b, s)
+		})
+	}
+}
+
 func setUp(t *require.Assertions) (tempDir string, deferFunc func()) {
 	t.NoError(logger.Init(logger.Logging{
 		Env:   "dev",
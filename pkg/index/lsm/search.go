@@ -19,6 +19,7 @@ package lsm
 
 import (
 	"bytes"
+	"context"
 
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
@@ -29,35 +30,41 @@ import (
 	"github.com/apache/skywalking-banyandb/pkg/convert"
 	"github.com/apache/skywalking-banyandb/pkg/index"
 	"github.com/apache/skywalking-banyandb/pkg/index/posting"
-	"github.com/apache/skywalking-banyandb/pkg/index/posting/roaring"
 )
 
 func (s *store) MatchField(fieldKey index.FieldKey) (list posting.List, err error) {
 	return s.Range(fieldKey, index.RangeOpts{})
 }
 
-func (s *store) MatchTerms(field index.Field) (list posting.List, err error) {
+func (s *store) MatchTerms(field index.Field) (posting.List, error) {
+	return index.MatchWithAlias(field, s.matchTerms)
+}
+
+func (s *store) matchTerms(field index.Field) (list posting.List, err error) {
 	f, err := field.Marshal(s.termMetadata)
 	if err != nil {
 		return nil, err
 	}
-	list = roaring.NewPostingList()
+	list = s.newList()
 	err = s.lsm.GetAll(f, func(itemID []byte) error {
 		list.Insert(common.ItemID(convert.BytesToUint64(itemID)))
 		return nil
 	})
 	if errors.Is(err, kv.ErrKeyNotFound) {
-		return roaring.EmptyPostingList, nil
+		return s.newList(), nil
 	}
-	return
+	if err != nil {
+		return
+	}
+	return list, s.dropped.HideTerm(field, list)
 }
 
 func (s *store) Range(fieldKey index.FieldKey, opts index.RangeOpts) (list posting.List, err error) {
 	iter, err := s.Iterator(fieldKey, opts, modelv1.Sort_SORT_ASC)
 	if err != nil {
-		return roaring.EmptyPostingList, err
+		return s.newList(), err
 	}
-	list = roaring.NewPostingList()
+	list = s.newList()
 	for iter.Next() {
 		err = multierr.Append(err, list.Union(iter.Val().Value))
 	}
@@ -65,13 +72,89 @@ func (s *store) Range(fieldKey index.FieldKey, opts index.RangeOpts) (list posti
 	return
 }
 
+func (s *store) RangeWithin(fieldKey index.FieldKey, opts index.RangeOpts, within posting.List) (list posting.List, err error) {
+	if within == nil || within.IsEmpty() {
+		return s.newList(), nil
+	}
+	iter, err := s.Iterator(fieldKey, opts, modelv1.Sort_SORT_ASC)
+	if err != nil {
+		return s.newList(), err
+	}
+	list = s.newList()
+	// remaining holds the items which haven't been hit yet, the scan stops once all of them are found
+	remaining := within.Clone()
+	for !remaining.IsEmpty() && iter.Next() {
+		hit := remaining.Clone()
+		err = multierr.Append(err, hit.Intersect(iter.Val().Value))
+		err = multierr.Append(err, list.Union(hit))
+		err = multierr.Append(err, remaining.Difference(hit))
+	}
+	err = multierr.Append(err, iter.Close())
+	return
+}
+
+func (s *store) MatchTermWithinRange(termField index.Field, rangeField index.FieldKey, opts index.RangeOpts) (list posting.List, err error) {
+	within, err := s.MatchTerms(termField)
+	if err != nil {
+		return nil, err
+	}
+	return s.RangeWithin(rangeField, opts, within)
+}
+
+func (s *store) Warmup(ctx context.Context, fieldKeys []index.FieldKey) (int64, error) {
+	return index.WarmupIterable(ctx, s.lsm, fieldKeys)
+}
+
+func (s *store) SortedFieldIterator(fieldKey index.FieldKey, within posting.List,
+	order modelv1.Sort) (index.SortedItemIterator, error) {
+	iter, err := s.Iterator(fieldKey, index.RangeOpts{}, order)
+	if err != nil {
+		return nil, err
+	}
+	return index.NewSortedFieldIterator(iter, within), nil
+}
+
+func (s *store) MinTerm(fieldKey index.FieldKey) ([]byte, error) {
+	return index.BoundaryTerm(s, fieldKey, modelv1.Sort_SORT_ASC)
+}
+
+func (s *store) MaxTerm(fieldKey index.FieldKey) ([]byte, error) {
+	return index.BoundaryTerm(s, fieldKey, modelv1.Sort_SORT_DESC)
+}
+
+func (s *store) PrefixFieldIterator(fieldKey index.FieldKey, prefix []byte, order modelv1.Sort) (index.FieldIterator, error) {
+	opts, err := index.PrefixRange(fieldKey, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return s.Iterator(fieldKey, opts, order)
+}
+
+// Iterator merges the terms of the alias into the ones of the field if the field has an alias
 func (s *store) Iterator(fieldKey index.FieldKey, termRange index.RangeOpts, order modelv1.Sort) (index.FieldIterator, error) {
+	iter, err := s.iterator(fieldKey, termRange, order)
+	if err != nil {
+		return nil, err
+	}
+	if alias, ok := fieldKey.AliasKey(); ok {
+		aliasIter, aliasErr := s.iterator(alias, termRange, order)
+		if aliasErr != nil {
+			return nil, multierr.Append(aliasErr, iter.Close())
+		}
+		iter = index.NewMergedIterator([]index.FieldIterator{iter, aliasIter}, index.ByteWiseSwitch(order))
+	}
+	return index.SortTerms(iter, fieldKey, order)
+}
+
+// iterator yields the terms of the field in byte-wise order
+func (s *store) iterator(fieldKey index.FieldKey, termRange index.RangeOpts, order modelv1.Sort) (index.FieldIterator, error) {
 	return index.NewFieldIteratorTemplate(s.l, fieldKey, termRange, order, s.lsm, s.termMetadata,
 		func(term, value []byte, delegated kv.Iterator) (*index.PostingValue, error) {
 			pv := &index.PostingValue{
 				Term:  term,
-				Value: roaring.NewPostingListWithInitialData(convert.BytesToUint64(value)),
+				Value: s.newList(),
 			}
+			pv.Value.Insert(common.ItemID(convert.BytesToUint64(value)))
 
 			for ; delegated.Valid(); delegated.Next() {
 				f := index.Field{}
@@ -86,6 +169,6 @@ func (s *store) Iterator(fieldKey index.FieldKey, termRange index.RangeOpts, ord
 				s.l.Debug().Uint64("item_id", itemID).Msg("add item id")
 				pv.Value.Insert(common.ItemID(itemID))
 			}
-			return pv, nil
+			return pv, s.dropped.HideTerm(index.Field{Key: fieldKey, Term: term}, pv.Value)
 		})
 }
@@ -30,6 +30,7 @@ import (
 type Term interface {
 	ID(term []byte) (id []byte, err error)
 	Literal(id []byte) (term []byte, err error)
+	kv.Syncer
 	io.Closer
 }
 
@@ -68,6 +69,10 @@ func (t *term) Literal(id []byte) (term []byte, err error) {
 	return t.store.Get(id)
 }
 
+func (t *term) Sync() error {
+	return t.store.Sync()
+}
+
 func (t *term) Close() error {
 	return t.store.Close()
 }
@@ -0,0 +1,214 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+package posting
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/pkg/errors"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/bit"
+)
+
+// the block size of MarshalBlocks is clamped within them
+const (
+	MinBlockSize = 16
+	MaxBlockSize = 4096
+)
+
+var ErrMalformedBlocks = errors.New("the block-encoded posting list is malformed")
+
+// blockMagic leads the block encoding. A serialized roaring64 bitmap starts with its container count
+// as a little-endian uint64, whose upper bytes are never set, so the two encodings are told apart.
+var blockMagic = []byte{'p', 'o', 's', 't', 'b', 'l', 'k', 0xff}
+
+// RecommendBlockSize suggests the block size of the posting lists of a field with the number of the distinct terms.
+// A field of a few terms, whose lists cover a large share of the items, keeps the encoding of the list, a bitmap
+// packs such a list in a few bits per item, fewer than the gaps take. A high-cardinality field, like the trace ID,
+// gets the smallest blocks because the items of a term come in bursts, a block spanning the jump between two bursts
+// widens all its gaps. The others get large blocks, which spare the headers.
+func RecommendBlockSize(distinctTerms uint64) int {
+	switch {
+	case distinctTerms <= 4:
+		return 0
+	case distinctTerms > 1024:
+		return MinBlockSize
+	default:
+		return 1024
+	}
+}
+
+// Marshal encodes the list in blocks of blockSize items by MarshalBlocks.
+// A zero blockSize falls back to the encoding of the list itself.
+func Marshal(list List, blockSize int) ([]byte, error) {
+	if blockSize == 0 {
+		return list.Marshall()
+	}
+	return MarshalBlocks(list, blockSize)
+}
+
+// MarshalBlocks encodes the list in blocks of blockSize items, the last one may be shorter.
+// A block holds its first item and the gaps between the rest, which are packed in the bits the widest gap needs.
+// The gaps of a dense list cost a few bits, even zero in a run of consecutive items, so large blocks spare the headers.
+// Small blocks fit the sparse lists better, whose outliers only widen the gaps of their own block.
+// blockSize is clamped within [MinBlockSize, MaxBlockSize].
+func MarshalBlocks(list List, blockSize int) ([]byte, error) {
+	switch {
+	case blockSize < MinBlockSize:
+		blockSize = MinBlockSize
+	case blockSize > MaxBlockSize:
+		blockSize = MaxBlockSize
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, len(blockMagic)+2*binary.MaxVarintLen64))
+	buf.Write(blockMagic)
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	putUvarint := func(v uint64) {
+		buf.Write(lenBuf[:binary.PutUvarint(lenBuf, v)])
+	}
+	putUvarint(uint64(blockSize))
+	putUvarint(uint64(list.Len()))
+	w := bit.NewWriter(buf)
+	block := make([]uint64, 0, blockSize)
+	var last uint64
+	flush := func() {
+		if len(block) < 1 {
+			return
+		}
+		putUvarint(block[0] - last)
+		var maxGap uint64
+		for i := 1; i < len(block); i++ {
+			if gap := block[i] - block[i-1] - 1; gap > maxGap {
+				maxGap = gap
+			}
+		}
+		width := bits.Len64(maxGap)
+		buf.WriteByte(byte(width))
+		if width > 0 {
+			for i := 1; i < len(block); i++ {
+				w.WriteBits(block[i]-block[i-1]-1, width)
+			}
+			w.Flush()
+		}
+		last = block[len(block)-1]
+		block = block[:0]
+	}
+	iter := list.Iterator()
+	defer iter.Close()
+	for iter.Next() {
+		block = append(block, uint64(iter.Current()))
+		if len(block) == blockSize {
+			flush()
+		}
+	}
+	flush()
+	return buf.Bytes(), nil
+}
+
+// IsBlockEncoded tells whether the data comes from MarshalBlocks
+func IsBlockEncoded(data []byte) bool {
+	return bytes.HasPrefix(data, blockMagic)
+}
+
+// UnmarshalBlocks inserts the items encoded by MarshalBlocks into the list
+func UnmarshalBlocks(list List, data []byte) error {
+	ids, err := DecodeBlocks(data)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		list.Insert(common.ItemID(id))
+	}
+	return nil
+}
+
+// DecodeBlocks returns the items encoded by MarshalBlocks in the ascending order
+func DecodeBlocks(data []byte) ([]uint64, error) {
+	if !IsBlockEncoded(data) {
+		return nil, errors.Wrap(ErrMalformedBlocks, "missing the magic")
+	}
+	data = data[len(blockMagic):]
+	readUvarint := func() (uint64, bool) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, false
+		}
+		data = data[n:]
+		return v, true
+	}
+	blockSize, ok := readUvarint()
+	if !ok || blockSize < 1 {
+		return nil, errors.Wrap(ErrMalformedBlocks, "read the block size")
+	}
+	count, ok := readUvarint()
+	if !ok {
+		return nil, errors.Wrap(ErrMalformedBlocks, "read the item count")
+	}
+	// every item takes a bit at least unless it's in a run, so the count doesn't bound the allocation
+	ids := make([]uint64, 0, minUint64(count, uint64(len(data))*8))
+	var last uint64
+	for count > 0 {
+		n := minUint64(blockSize, count)
+		first, ok := readUvarint()
+		if !ok || len(data) < 1 {
+			return nil, errors.Wrap(ErrMalformedBlocks, "read the block header")
+		}
+		width := int(data[0])
+		data = data[1:]
+		packed := (uint64(width)*(n-1) + 7) / 8
+		if width > 64 || uint64(len(data)) < packed {
+			return nil, errors.Wrap(ErrMalformedBlocks, "read the gaps")
+		}
+		id := last + first
+		ids = append(ids, id)
+		var pos uint
+		for i := uint64(1); i < n; i++ {
+			id += readBits(data, &pos, width) + 1
+			ids = append(ids, id)
+		}
+		data = data[packed:]
+		last = id
+		count -= n
+	}
+	return ids, nil
+}
+
+// readBits reads the width bits at pos of the data packed by bit.Writer, which puts the most significant bit first
+func readBits(data []byte, pos *uint, width int) uint64 {
+	var v uint64
+	for need := uint(width); need > 0; {
+		avail := 8 - *pos&7
+		take := avail
+		if need < take {
+			take = need
+		}
+		b := data[*pos>>3] >> (avail - take) & (1<<take - 1)
+		v = v<<take | uint64(b)
+		*pos += take
+		need -= take
+	}
+	return v
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -25,6 +25,10 @@ import (
 
 var ErrListEmpty = errors.New("postings list is empty")
 
+// Factory creates an empty List. The lists of an index should come from the same factory
+// because the operations between lists, for example, Intersect, might not work across the implementations.
+type Factory func() List
+
 // List is a collection of common.ItemID.
 type List interface {
 	Contains(id common.ItemID) bool
@@ -47,6 +51,8 @@ type List interface {
 
 	Difference(other List) error
 
+	SymmetricDifference(other List) error
+
 	Union(other List) error
 
 	UnionMany(others []List) error
@@ -66,6 +72,24 @@ type List interface {
 	Unmarshall(data []byte) error
 }
 
+// Difference returns a new list of the items in a but not in b. Neither a nor b is mutated.
+func Difference(a, b List) (List, error) {
+	l := a.Clone()
+	if err := l.Difference(b); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// SymmetricDifference returns a new list of the items in either a or b but not in both. Neither a nor b is mutated.
+func SymmetricDifference(a, b List) (List, error) {
+	l := a.Clone()
+	if err := l.SymmetricDifference(b); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
 type Iterator interface {
 	Next() bool
 
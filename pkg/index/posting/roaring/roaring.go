@@ -28,9 +28,10 @@ import (
 var (
 	EmptyPostingList = NewPostingList()
 
-	ErrIntersectRoaringOnly  = errors.New("Intersect only supported between roaringDocId sets")
-	ErrUnionRoaringOnly      = errors.New("Union only supported between roaringDocId sets")
-	ErrDifferenceRoaringOnly = errors.New("Difference only supported between roaringDocId sets")
+	ErrIntersectRoaringOnly           = errors.New("Intersect only supported between roaringDocId sets")
+	ErrUnionRoaringOnly               = errors.New("Union only supported between roaringDocId sets")
+	ErrDifferenceRoaringOnly          = errors.New("Difference only supported between roaringDocId sets")
+	ErrSymmetricDifferenceRoaringOnly = errors.New("SymmetricDifference only supported between roaringDocId sets")
 )
 
 var _ posting.List = (*postingsList)(nil)
@@ -44,7 +45,17 @@ func (p *postingsList) Marshall() ([]byte, error) {
 	return p.bitmap.MarshalBinary()
 }
 
+// Unmarshall decodes the data from Marshall or posting.MarshalBlocks
 func (p *postingsList) Unmarshall(data []byte) error {
+	if posting.IsBlockEncoded(data) {
+		ids, err := posting.DecodeBlocks(data)
+		if err != nil {
+			return err
+		}
+		p.bitmap.Clear()
+		p.bitmap.AddMany(ids)
+		return nil
+	}
 	return p.bitmap.UnmarshalBinary(data)
 }
 
@@ -143,6 +154,15 @@ func (p *postingsList) Difference(other posting.List) error {
 	return nil
 }
 
+func (p *postingsList) SymmetricDifference(other posting.List) error {
+	o, ok := other.(*postingsList)
+	if !ok {
+		return ErrSymmetricDifferenceRoaringOnly
+	}
+	p.bitmap.Xor(o.bitmap)
+	return nil
+}
+
 func (p *postingsList) Union(other posting.List) error {
 	o, ok := other.(*postingsList)
 	if !ok {
@@ -0,0 +1,162 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package roaring
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/index/posting"
+)
+
+func TestDifference(t *testing.T) {
+	tests := []struct {
+		name          string
+		a, b          []uint64
+		difference    []common.ItemID
+		symmetricDiff []common.ItemID
+	}{
+		{
+			name:          "disjoint",
+			a:             []uint64{1, 2, 3},
+			b:             []uint64{4, 5},
+			difference:    []common.ItemID{1, 2, 3},
+			symmetricDiff: []common.ItemID{1, 2, 3, 4, 5},
+		},
+		{
+			name:          "overlapping",
+			a:             []uint64{1, 2, 3},
+			b:             []uint64{2, 3, 4},
+			difference:    []common.ItemID{1},
+			symmetricDiff: []common.ItemID{1, 4},
+		},
+		{
+			name:          "identical",
+			a:             []uint64{1, 2, 3},
+			b:             []uint64{1, 2, 3},
+			difference:    []common.ItemID{},
+			symmetricDiff: []common.ItemID{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewPostingListWithInitialData(tt.a...)
+			b := NewPostingListWithInitialData(tt.b...)
+			difference, err := posting.Difference(a, b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.difference, difference.ToSlice())
+			symmetricDiff, err := posting.SymmetricDifference(a, b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.symmetricDiff, symmetricDiff.ToSlice())
+			// the inputs stay intact
+			assert.True(t, a.Equal(NewPostingListWithInitialData(tt.a...)))
+			assert.True(t, b.Equal(NewPostingListWithInitialData(tt.b...)))
+		})
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []uint64
+		blockSize int
+	}{
+		{name: "empty", blockSize: 128},
+		{name: "single", data: []uint64{42}, blockSize: 128},
+		{name: "consecutive", data: rangeOf(1000, 3000, 1), blockSize: 128},
+		{name: "partial last block", data: rangeOf(0, 1000, 7), blockSize: 64},
+		{name: "wide gaps", data: []uint64{0, 1, 1 << 20, 1<<40 + 3, 1<<63 + 5}, blockSize: 16},
+		{name: "clamped block size", data: rangeOf(0, 100, 3), blockSize: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := NewPostingListWithInitialData(tt.data...)
+			data, err := posting.Marshal(list, tt.blockSize)
+			require.NoError(t, err)
+			assert.True(t, posting.IsBlockEncoded(data))
+			got := NewPostingListWithInitialData(7)
+			require.NoError(t, got.Unmarshall(data))
+			assert.True(t, list.Equal(got))
+		})
+	}
+	data, err := posting.Marshal(NewPostingListWithInitialData(1, 2, 3), 0)
+	require.NoError(t, err)
+	assert.False(t, posting.IsBlockEncoded(data))
+	data, err = posting.MarshalBlocks(NewPostingListWithInitialData(rangeOf(0, 100, 3)...), 16)
+	require.NoError(t, err)
+	assert.ErrorIs(t, NewPostingList().Unmarshall(data[:len(data)-4]), posting.ErrMalformedBlocks)
+}
+
+func rangeOf(start, end, step uint64) []uint64 {
+	s := make([]uint64, 0, (end-start)/step+1)
+	for i := start; i < end; i += step {
+		s = append(s, i)
+	}
+	return s
+}
+
+// BenchmarkBlockSize compares the size and the decoding time of the encodings on a dense list and a sparse one.
+// A dense list comes from a low-cardinality field, like the status code, a sparse one from a high-cardinality field.
+// A bursty one comes from a high-cardinality field whose items of a term are written together, like the trace ID.
+func BenchmarkBlockSize(b *testing.B) {
+	const items = 1_000_000
+	r := rand.New(rand.NewSource(1))
+	lists := map[string]posting.List{
+		"dense":  NewPostingList(),
+		"sparse": NewPostingList(),
+		"bursty": NewPostingList(),
+	}
+	for i := uint64(0); i < items; i++ {
+		if r.Intn(3) == 0 {
+			lists["dense"].Insert(common.ItemID(i))
+		}
+		if r.Intn(1000) == 0 {
+			lists["sparse"].Insert(common.ItemID(i))
+		}
+		// the items of a trace are written together
+		if i%10_000 < 50 && r.Intn(2) == 0 {
+			lists["bursty"].Insert(common.ItemID(i))
+		}
+	}
+	for _, name := range []string{"dense", "sparse", "bursty"} {
+		list := lists[name]
+		for _, blockSize := range []int{0, 16, 128, 1024, 4096} {
+			data, err := posting.Marshal(list, blockSize)
+			require.NoError(b, err)
+			b.Run(fmt.Sprintf("%s/block=%d", name, blockSize), func(b *testing.B) {
+				other := NewPostingListWithInitialData(rangeOf(0, items, 7)...)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					l := NewPostingList()
+					if err := l.Unmarshall(data); err != nil {
+						b.Fatal(err)
+					}
+					if err := l.Intersect(other); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(len(data)*8)/float64(list.Len()), "bits/item")
+			})
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"math"
+
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+)
+
+// SamplingRate returns the effective fraction of the values the rule indexes.
+// It's 1 if the rule doesn't sample. The registry rejects a rate out of [0, 1], so the rest only come from
+// the rules stored before the check.
+func SamplingRate(rule *databasev1.IndexRule) float64 {
+	rate := rule.GetSamplingRate()
+	if rate <= 0 || rate >= 1 || math.IsNaN(rate) {
+		return 1
+	}
+	return rate
+}
+
+// Sampled tells whether the rule indexes the value. The decision is deterministic,
+// the same value is always either indexed or skipped.
+func Sampled(rule *databasev1.IndexRule, value []byte) bool {
+	rate := SamplingRate(rule)
+	if rate >= 1 {
+		return true
+	}
+	return float64(convert.Hash(value)) < rate*math.MaxUint64
+}
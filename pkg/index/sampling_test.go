@@ -0,0 +1,49 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+)
+
+func TestSamplingRate(t *testing.T) {
+	assert.Equal(t, 1.0, SamplingRate(&databasev1.IndexRule{}))
+	assert.Equal(t, 1.0, SamplingRate(&databasev1.IndexRule{SamplingRate: -0.5}))
+	assert.Equal(t, 1.0, SamplingRate(&databasev1.IndexRule{SamplingRate: 2}))
+	assert.Equal(t, 0.1, SamplingRate(&databasev1.IndexRule{SamplingRate: 0.1}))
+}
+
+func TestSampled(t *testing.T) {
+	rule := &databasev1.IndexRule{SamplingRate: 0.1}
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		value := []byte(fmt.Sprintf("trace-%d", i))
+		s := Sampled(rule, value)
+		assert.Equal(t, s, Sampled(rule, value), "the decision isn't deterministic")
+		assert.True(t, Sampled(&databasev1.IndexRule{}, value))
+		if s {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 1000, sampled, 200)
+}
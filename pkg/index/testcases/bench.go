@@ -0,0 +1,129 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package testcases
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+	"github.com/apache/skywalking-banyandb/pkg/index/posting"
+	"github.com/apache/skywalking-banyandb/pkg/index/posting/roaring"
+)
+
+// PostingFactories are the implementations of posting.List to be benchmarked
+var PostingFactories = map[string]posting.Factory{
+	"roaring": roaring.NewPostingList,
+}
+
+const benchItems = 100_000
+
+var benchStatusCodes = []string{"200", "201", "404", "500", "503"}
+
+var (
+	// benchStatus has a few terms shared by lots of items, like the status code
+	benchStatus = index.FieldKey{IndexRuleID: 20}
+	// benchEndpoint has a moderate number of terms, like the endpoint
+	benchEndpoint = index.FieldKey{IndexRuleID: 21}
+	// benchLatency has a numeric term per 10 items, like the duration
+	benchLatency = index.FieldKey{IndexRuleID: 22}
+)
+
+// SetUpBenchmark writes benchItems items into the store
+func SetUpBenchmark(b *testing.B, store index.Writer) {
+	is := require.New(b)
+	for i := 0; i < benchItems; i++ {
+		id := common.ItemID(i)
+		is.NoError(store.Write(index.Field{Key: benchStatus, Term: []byte(benchStatusCodes[i%len(benchStatusCodes)])}, id))
+		is.NoError(store.Write(index.Field{Key: benchEndpoint, Term: []byte(fmt.Sprintf("/api/%d", i%1000))}, id))
+		is.NoError(store.Write(index.Field{Key: benchLatency, Term: convert.Int64ToBytes(int64(i / 10))}, id))
+	}
+}
+
+// RunBenchmark exercises the searcher filled by SetUpBenchmark
+func RunBenchmark(b *testing.B, searcher index.Searcher) {
+	latencyRange := func(lower, upper int64) index.RangeOpts {
+		return index.RangeOpts{
+			Lower:         convert.Int64ToBytes(lower),
+			Upper:         convert.Int64ToBytes(upper),
+			IncludesLower: true,
+		}
+	}
+	benchmarks := []struct {
+		name string
+		fn   func() (posting.List, error)
+	}{
+		{
+			name: "MatchTerms/low-cardinality",
+			fn: func() (posting.List, error) {
+				return searcher.MatchTerms(index.Field{Key: benchStatus, Term: []byte("200")})
+			},
+		},
+		{
+			name: "MatchTerms/high-cardinality",
+			fn: func() (posting.List, error) {
+				return searcher.MatchTerms(index.Field{Key: benchEndpoint, Term: []byte("/api/42")})
+			},
+		},
+		{
+			name: "Range/narrow",
+			fn: func() (posting.List, error) {
+				return searcher.Range(benchLatency, latencyRange(100, 200))
+			},
+		},
+		{
+			name: "Range/wide",
+			fn: func() (posting.List, error) {
+				return searcher.Range(benchLatency, latencyRange(0, benchItems/20))
+			},
+		},
+		{
+			name: "Intersect",
+			fn: func() (posting.List, error) {
+				status, err := searcher.MatchTerms(index.Field{Key: benchStatus, Term: []byte("500")})
+				if err != nil {
+					return nil, err
+				}
+				latency, err := searcher.Range(benchLatency, latencyRange(0, benchItems/20))
+				if err != nil {
+					return nil, err
+				}
+				list := status.Clone()
+				return list, list.Intersect(latency)
+			},
+		},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				list, err := bm.fn()
+				if err != nil {
+					b.Fatal(err)
+				}
+				if list.IsEmpty() {
+					b.Fatal("the result is empty")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package testcases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+	"github.com/apache/skywalking-banyandb/pkg/index/posting/roaring"
+)
+
+var dbStatement = index.FieldKey{
+	// db.statement is an optional tag
+	IndexRuleID: 7,
+}
+
+// RunMatchField verifies MatchField hits the items having any term of the field
+func RunMatchField(t *testing.T, store index.Searcher) {
+	tester := assert.New(t)
+	is := require.New(t)
+	withStatement := roaring.NewPostingListWithInitialData(0, 2, 4, 6, 8)
+
+	list, err := store.MatchField(dbStatement)
+	is.NoError(err)
+	tester.True(withStatement.Equal(list))
+
+	list, err = store.MatchTerms(index.Field{Key: dbStatement, Term: []byte("select")})
+	is.NoError(err)
+	tester.True(roaring.NewPostingListWithInitialData(0, 4, 8).Equal(list))
+
+	list, err = store.MatchField(index.FieldKey{IndexRuleID: 8})
+	is.NoError(err)
+	tester.True(list.IsEmpty())
+}
+
+func SetUpDBStatement(t *assert.Assertions, store index.Writer) {
+	for i := 0; i < 10; i += 2 {
+		term := "select"
+		if i%4 != 0 {
+			term = "insert"
+		}
+		t.NoError(store.Write(index.Field{
+			Key:  dbStatement,
+			Term: []byte(term),
+		}, common.ItemID(i)))
+	}
+}
@@ -37,6 +37,10 @@ var (
 		//duration
 		IndexRuleID: 3,
 	}
+	durationService = index.FieldKey{
+		//service of the duration
+		IndexRuleID: 9,
+	}
 )
 
 type SimpleStore interface {
@@ -343,3 +347,190 @@ func SetUpPartialDuration(t *assert.Assertions, store index.Writer, r map[int]po
 	}
 	return r
 }
+
+func RunDurationRangeWithin(t *testing.T, data map[int]posting.List, store index.Searcher) {
+	tester := assert.New(t)
+	is := require.New(t)
+	within := roaring.NewPostingList()
+	is.NoError(within.Union(data[200]))
+	is.NoError(within.Union(data[2000]))
+	within.Insert(common.ItemID(1))
+	tests := []struct {
+		name   string
+		opts   index.RangeOpts
+		within posting.List
+		want   posting.List
+	}{
+		{
+			name: "only the items within",
+			opts: index.RangeOpts{
+				Lower:         convert.Int64ToBytes(200),
+				IncludesLower: true,
+				Upper:         convert.Int64ToBytes(1000),
+				IncludesUpper: true,
+			},
+			within: within,
+			want:   data[200],
+		},
+		{
+			name:   "the whole range",
+			within: within,
+			want: func() posting.List {
+				l := data[200].Clone()
+				is.NoError(l.Union(data[2000]))
+				return l
+			}(),
+		},
+		{
+			name:   "empty within",
+			within: roaring.NewPostingList(),
+			want:   roaring.EmptyPostingList,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := store.RangeWithin(duration, tt.opts, tt.within)
+			is.NoError(err)
+			tester.True(tt.want.Equal(list))
+		})
+	}
+}
+
+// SetUpDurationService writes the service of the items set up by SetUpDuration,
+// the items whose IDs are even belong to svc-even, the others belong to svc-odd
+func SetUpDurationService(t *assert.Assertions, store index.Writer) map[string]posting.List {
+	r := map[string]posting.List{
+		"svc-even": roaring.NewPostingList(),
+		"svc-odd":  roaring.NewPostingList(),
+	}
+	for i := 100; i < 200; i++ {
+		svc := "svc-even"
+		if i%2 == 1 {
+			svc = "svc-odd"
+		}
+		t.NoError(store.Write(index.Field{
+			Key:  durationService,
+			Term: []byte(svc),
+		}, common.ItemID(i)))
+		r[svc].Insert(common.ItemID(i))
+	}
+	return r
+}
+
+func RunDurationMatchTermWithinRange(t *testing.T, data map[int]posting.List, services map[string]posting.List, store index.Searcher) {
+	tester := assert.New(t)
+	is := require.New(t)
+	intersect := func(svc string, terms ...int) posting.List {
+		l := roaring.NewPostingList()
+		for _, term := range terms {
+			is.NoError(l.Union(data[term]))
+		}
+		is.NoError(l.Intersect(services[svc]))
+		return l
+	}
+	tests := []struct {
+		name string
+		svc  string
+		opts index.RangeOpts
+		want posting.List
+	}{
+		{
+			name: "the items of the service in the range",
+			svc:  "svc-even",
+			opts: index.RangeOpts{
+				Lower:         convert.Int64ToBytes(200),
+				IncludesLower: true,
+				Upper:         convert.Int64ToBytes(1000),
+				IncludesUpper: true,
+			},
+			want: intersect("svc-even", 200, 500, 1000),
+		},
+		{
+			name: "the whole range",
+			svc:  "svc-odd",
+			want: services["svc-odd"],
+		},
+		{
+			name: "unknown service",
+			svc:  "svc-unknown",
+			want: roaring.EmptyPostingList,
+		},
+		{
+			name: "out of the range",
+			svc:  "svc-odd",
+			opts: index.RangeOpts{
+				Lower: convert.Int64ToBytes(2000),
+			},
+			want: roaring.EmptyPostingList,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := store.MatchTermWithinRange(index.Field{Key: durationService, Term: []byte(tt.svc)}, duration, tt.opts)
+			is.NoError(err)
+			tester.True(tt.want.Equal(list))
+		})
+	}
+}
+
+func RunDurationSorted(t *testing.T, data map[int]posting.List, store index.Searcher) {
+	tester := assert.New(t)
+	is := require.New(t)
+	within := roaring.NewPostingList()
+	is.NoError(within.Union(data[200]))
+	is.NoError(within.Union(data[1000]))
+	within.Insert(common.ItemID(1))
+	concat := func(terms ...int) []int {
+		var r []int
+		for _, term := range terms {
+			r = append(r, toArray(data[term])...)
+		}
+		return r
+	}
+	tests := []struct {
+		name   string
+		within posting.List
+		order  modelv1.Sort
+		want   []int
+	}{
+		{
+			name:  "all in asc order",
+			order: modelv1.Sort_SORT_ASC,
+			want:  concat(50, 200, 500, 1000, 2000),
+		},
+		{
+			name:  "all in desc order",
+			order: modelv1.Sort_SORT_DESC,
+			want:  concat(2000, 1000, 500, 200, 50),
+		},
+		{
+			name:   "within in asc order",
+			within: within,
+			order:  modelv1.Sort_SORT_ASC,
+			want:   concat(200, 1000),
+		},
+		{
+			name:   "within in desc order",
+			within: within,
+			order:  modelv1.Sort_SORT_DESC,
+			want:   concat(1000, 200),
+		},
+		{
+			name:   "empty within",
+			within: roaring.NewPostingList(),
+			order:  modelv1.Sort_SORT_ASC,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iter, err := store.SortedFieldIterator(duration, tt.within, tt.order)
+			is.NoError(err)
+			var got []int
+			for iter.Next() {
+				got = append(got, int(iter.Val()))
+			}
+			tester.NoError(iter.Close())
+			tester.Equal(tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package testcases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+)
+
+var endpoint = index.FieldKey{
+	// endpoint
+	IndexRuleID: 4,
+}
+
+var endpoints = []string{"/", "/a", "/a/b", "/home", "/m", "/m/n", "/z"}
+
+func RunEndpointRange(t *testing.T, store SimpleStore) {
+	tester := assert.New(t)
+	is := require.New(t)
+	tests := []struct {
+		name      string
+		fieldKey  index.FieldKey
+		termRange index.RangeOpts
+		want      []string
+	}{
+		{
+			name:      "between /a and /m",
+			fieldKey:  endpoint,
+			termRange: index.StringRange("/a", "/m"),
+			want:      []string{"/a", "/a/b", "/home", "/m"},
+		},
+		{
+			name:     "exclude both bounds",
+			fieldKey: endpoint,
+			termRange: index.RangeOpts{
+				Lower: []byte("/a"),
+				Upper: []byte("/m"),
+			},
+			want: []string{"/a/b", "/home"},
+		},
+		{
+			name:      "bounds are absent",
+			fieldKey:  endpoint,
+			termRange: index.StringRange("/b", "/c"),
+		},
+		{
+			name:     "unbounded",
+			fieldKey: endpoint,
+			want:     endpoints,
+		},
+		{
+			name:      "int terms in another field",
+			fieldKey:  duration,
+			termRange: index.StringRange("/a", "/m"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iter, err := store.Iterator(tt.fieldKey, tt.termRange, modelv1.Sort_SORT_ASC)
+			is.NoError(err)
+			if iter == nil {
+				tester.Empty(tt.want)
+				return
+			}
+			defer func() {
+				tester.NoError(iter.Close())
+			}()
+			var got []string
+			for iter.Next() {
+				got = append(got, string(iter.Val().Term))
+			}
+			tester.Equal(tt.want, got)
+		})
+	}
+}
+
+func RunEndpointPrefix(t *testing.T, store index.Searcher) {
+	tester := assert.New(t)
+	is := require.New(t)
+	tests := []struct {
+		name   string
+		prefix string
+		order  modelv1.Sort
+		want   []string
+	}{
+		{
+			name:   "under /a in asc order",
+			prefix: "/a",
+			order:  modelv1.Sort_SORT_ASC,
+			want:   []string{"/a", "/a/b"},
+		},
+		{
+			name:   "under /m in desc order",
+			prefix: "/m",
+			order:  modelv1.Sort_SORT_DESC,
+			want:   []string{"/m/n", "/m"},
+		},
+		{
+			name:   "the leaf",
+			prefix: "/a/",
+			order:  modelv1.Sort_SORT_ASC,
+			want:   []string{"/a/b"},
+		},
+		{
+			name:   "absent prefix",
+			prefix: "/b",
+			order:  modelv1.Sort_SORT_ASC,
+		},
+		{
+			name:  "all the terms",
+			order: modelv1.Sort_SORT_ASC,
+			want:  endpoints,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iter, err := store.PrefixFieldIterator(endpoint, []byte(tt.prefix), tt.order)
+			is.NoError(err)
+			if iter == nil {
+				tester.Empty(tt.want)
+				return
+			}
+			defer func() {
+				tester.NoError(iter.Close())
+			}()
+			var got []string
+			for iter.Next() {
+				got = append(got, string(iter.Val().Term))
+			}
+			tester.Equal(tt.want, got)
+		})
+	}
+	_, err := store.PrefixFieldIterator(duration, []byte("/a"), modelv1.Sort_SORT_ASC)
+	tester.NoError(err)
+	_, err = store.PrefixFieldIterator(index.FieldKey{IndexRuleID: 4, Comparator: index.ComparatorNumeric}, []byte("/a"), modelv1.Sort_SORT_ASC)
+	tester.ErrorIs(err, index.ErrUnsupportedComparator)
+}
+
+// RunEndpointIndexStats expects the terms written by SetUpEndpoint, each of which holds a single item
+func RunEndpointIndexStats(t *testing.T, store index.Searcher) {
+	is := require.New(t)
+	stats, err := store.IndexStats()
+	is.NoError(err)
+	is.Equal(uint64(2*len(endpoints)), stats.TermCount)
+	is.Equal(uint64(2*len(endpoints)), stats.PostingCount)
+}
+
+// RunEndpointMinMaxTerm expects the terms written by SetUpEndpoint
+func RunEndpointMinMaxTerm(t *testing.T, store index.Searcher) {
+	is := require.New(t)
+	term, err := store.MinTerm(endpoint)
+	is.NoError(err)
+	is.Equal([]byte(endpoints[0]), term)
+	term, err = store.MaxTerm(endpoint)
+	is.NoError(err)
+	is.Equal([]byte(endpoints[len(endpoints)-1]), term)
+	term, err = store.MinTerm(duration)
+	is.NoError(err)
+	is.Equal(convert.Int64ToBytes(0), term)
+	term, err = store.MaxTerm(duration)
+	is.NoError(err)
+	is.Equal(convert.Int64ToBytes(int64(len(endpoints)-1)), term)
+
+	absent := index.FieldKey{IndexRuleID: 100}
+	_, err = store.MinTerm(absent)
+	is.ErrorIs(err, index.ErrNoTerm)
+	_, err = store.MaxTerm(absent)
+	is.ErrorIs(err, index.ErrNoTerm)
+}
+
+// RunEndpointAlias expects the terms written by SetUpEndpoint. It renames the endpoint field,
+// then writes the new items under the renamed one.
+func RunEndpointAlias(t *testing.T, store index.Store) {
+	is := require.New(t)
+	renamed := index.FieldKey{IndexRuleID: 40}
+	aliased := index.FieldKey{IndexRuleID: 40, AliasOf: endpoint.IndexRuleID}
+	is.NoError(store.Write(index.Field{Key: renamed, Term: []byte("/a")}, common.ItemID(100)))
+	is.NoError(store.Write(index.Field{Key: renamed, Term: []byte("/new")}, common.ItemID(101)))
+
+	list, err := store.MatchTerms(index.Field{Key: aliased, Term: []byte("/a")})
+	is.NoError(err)
+	is.Equal([]common.ItemID{1, 100}, list.ToSlice())
+	list, err = store.MatchTerms(index.Field{Key: renamed, Term: []byte("/a")})
+	is.NoError(err)
+	is.Equal([]common.ItemID{100}, list.ToSlice())
+
+	list, err = store.Range(aliased, index.StringRange("/m", "/new"))
+	is.NoError(err)
+	is.Equal([]common.ItemID{4, 5, 101}, list.ToSlice())
+	list, err = store.MatchField(renamed)
+	is.NoError(err)
+	is.Equal([]common.ItemID{100, 101}, list.ToSlice())
+
+	// the terms of both fields are merged in order
+	iter, err := store.Iterator(aliased, index.RangeOpts{}, modelv1.Sort_SORT_DESC)
+	is.NoError(err)
+	var terms []string
+	for iter.Next() {
+		terms = append(terms, string(iter.Val().Term))
+	}
+	is.NoError(iter.Close())
+	is.Equal([]string{"/z", "/new", "/m/n", "/m", "/home", "/a/b", "/a", "/a", "/"}, terms)
+}
+
+func SetUpEndpoint(t *assert.Assertions, store SimpleStore) {
+	for i, e := range endpoints {
+		t.NoError(store.Write(index.Field{
+			Key:  endpoint,
+			Term: []byte(e),
+		}, common.ItemID(i)))
+		t.NoError(store.Write(index.Field{
+			Key:  duration,
+			Term: convert.Int64ToBytes(int64(i)),
+		}, common.ItemID(i)))
+	}
+}
@@ -0,0 +1,99 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package testcases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/pkg/index"
+)
+
+var version = index.FieldKey{
+	// service_version
+	IndexRuleID: 5,
+	Comparator:  index.ComparatorVersion,
+}
+
+// versions are written in neither the byte-wise order nor the semantic one
+var versions = []string{"1.10.0", "1.2.0", "1.9.2", "2.0.0-rc.1", "1.9.2-rc.1", "2.0.0"}
+
+func RunVersionRange(t *testing.T, store SimpleStore) {
+	tester := assert.New(t)
+	is := require.New(t)
+	tests := []struct {
+		name      string
+		termRange index.RangeOpts
+		order     modelv1.Sort
+		want      []string
+	}{
+		{
+			name:  "all in asc order",
+			order: modelv1.Sort_SORT_ASC,
+			want:  []string{"1.2.0", "1.9.2-rc.1", "1.9.2", "1.10.0", "2.0.0-rc.1", "2.0.0"},
+		},
+		{
+			name:  "all in desc order",
+			order: modelv1.Sort_SORT_DESC,
+			want:  []string{"2.0.0", "2.0.0-rc.1", "1.10.0", "1.9.2", "1.9.2-rc.1", "1.2.0"},
+		},
+		{
+			name:      "between 1.9.0 and 2.0.0-rc.1",
+			termRange: index.StringRange("1.9.0", "2.0.0-rc.1"),
+			order:     modelv1.Sort_SORT_ASC,
+			want:      []string{"1.9.2-rc.1", "1.9.2", "1.10.0", "2.0.0-rc.1"},
+		},
+		{
+			name: "exclude both bounds",
+			termRange: index.RangeOpts{
+				Lower: []byte("1.2.0"),
+				Upper: []byte("1.10.0"),
+			},
+			order: modelv1.Sort_SORT_DESC,
+			want:  []string{"1.9.2", "1.9.2-rc.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iter, err := store.Iterator(version, tt.termRange, tt.order)
+			is.NoError(err)
+			is.NotNil(iter)
+			defer func() {
+				tester.NoError(iter.Close())
+			}()
+			var got []string
+			for iter.Next() {
+				got = append(got, string(iter.Val().Term))
+			}
+			tester.Equal(tt.want, got)
+		})
+	}
+}
+
+func SetUpVersion(t *assert.Assertions, store SimpleStore) {
+	for i, v := range versions {
+		t.NoError(store.Write(index.Field{
+			Key:  version,
+			Term: []byte(v),
+		}, common.ItemID(i)))
+	}
+}
@@ -0,0 +1,58 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"bytes"
+	"context"
+
+	"go.uber.org/multierr"
+
+	"github.com/apache/skywalking-banyandb/banyand/kv"
+)
+
+// Warmer preloads the postings of hot fields, which spares the first queries after a restart from reading the disk
+type Warmer interface {
+	// Warmup reads the postings of the fields one by one. It returns the number of bytes loaded.
+	// It stops once the ctx is done, and the bytes loaded so far are returned along with the error.
+	Warmup(ctx context.Context, fieldKeys []FieldKey) (loaded int64, err error)
+}
+
+// WarmupIterable reads all the entries of the fields in the iterable
+func WarmupIterable(ctx context.Context, iterable kv.Iterable, fieldKeys []FieldKey) (loaded int64, err error) {
+	for _, fieldKey := range fieldKeys {
+		if err = ctx.Err(); err != nil {
+			return loaded, err
+		}
+		prefix := fieldKey.Marshal()
+		iter := iterable.NewIterator(kv.ScanOpts{
+			Prefix:         prefix,
+			PrefetchValues: true,
+		})
+		for iter.Seek(prefix); iter.Valid() && bytes.HasPrefix(iter.Key(), prefix); iter.Next() {
+			if err = ctx.Err(); err != nil {
+				break
+			}
+			loaded += int64(len(iter.Key()) + len(iter.Val()))
+		}
+		if err = multierr.Append(err, iter.Close()); err != nil {
+			return loaded, err
+		}
+	}
+	return loaded, nil
+}
@@ -0,0 +1,50 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package meter defines the way components expose their measurements to a metrics backend.
+package meter
+
+import "context"
+
+// ContextKey carries the MetricsObserver through the context, like the one of the logger
+var ContextKey = contextKey{}
+
+type contextKey struct{}
+
+// Labels are the dimensions of a measurement
+type Labels map[string]string
+
+// MetricsObserver receives measurements from components
+type MetricsObserver interface {
+	// Gauge records the latest value of a metric
+	Gauge(name string, value float64, labels Labels)
+}
+
+// NoopObserver drops all measurements
+var NoopObserver MetricsObserver = noopObserver{}
+
+type noopObserver struct{}
+
+func (noopObserver) Gauge(string, float64, Labels) {}
+
+// Fetch returns the observer carried by the context, or nil if there is none
+func Fetch(ctx context.Context) MetricsObserver {
+	if observer, ok := ctx.Value(ContextKey).(MetricsObserver); ok {
+		return observer
+	}
+	return nil
+}
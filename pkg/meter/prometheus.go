@@ -0,0 +1,71 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package meter
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type prometheusObserver struct {
+	reg    prometheus.Registerer
+	mu     sync.Mutex
+	gauges map[string]*prometheus.GaugeVec
+}
+
+// NewPrometheusObserver exports the measurements to the registerer.
+// The label names of a metric are fixed by its first measurement, the measurements with other labels are dropped.
+func NewPrometheusObserver(reg prometheus.Registerer) MetricsObserver {
+	return &prometheusObserver{
+		reg:    reg,
+		gauges: make(map[string]*prometheus.GaugeVec),
+	}
+}
+
+func (p *prometheusObserver) Gauge(name string, value float64, labels Labels) {
+	gauge, err := p.gaugeVec(name, labels).GetMetricWith(prometheus.Labels(labels))
+	if err != nil {
+		return
+	}
+	gauge.Set(value)
+}
+
+func (p *prometheusObserver) gaugeVec(name string, labels Labels) *prometheus.GaugeVec {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if g, ok := p.gauges[name]; ok {
+		return g
+	}
+	labelNames := make([]string, 0, len(labels))
+	for l := range labels {
+		labelNames = append(labelNames, l)
+	}
+	sort.Strings(labelNames)
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name}, labelNames)
+	if err := p.reg.Register(g); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if existing, ok := are.ExistingCollector.(*prometheus.GaugeVec); ok {
+				g = existing
+			}
+		}
+	}
+	p.gauges[name] = g
+	return g
+}
@@ -0,0 +1,52 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package meter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusObserver(t *testing.T) {
+	req := require.New(t)
+	reg := prometheus.NewRegistry()
+	observer := NewPrometheusObserver(reg)
+	observer.Gauge("index_segment_count", 1, Labels{"shard": "0", "index": "inverted"})
+	observer.Gauge("index_segment_count", 2, Labels{"shard": "1", "index": "inverted"})
+	observer.Gauge("index_segment_count", 3, Labels{"shard": "0", "index": "inverted"})
+	// the labels mismatching the first measurement are dropped
+	observer.Gauge("index_segment_count", 4, Labels{"shard": "0"})
+	n, err := testutil.GatherAndCount(reg, "index_segment_count")
+	req.NoError(err)
+	req.Equal(2, n)
+
+	gauges := NewPrometheusObserver(reg).(*prometheusObserver).gaugeVec("index_segment_count", Labels{"shard": "0", "index": "inverted"})
+	req.Equal(float64(3), testutil.ToFloat64(gauges.WithLabelValues("inverted", "0")))
+	req.Equal(float64(2), testutil.ToFloat64(gauges.WithLabelValues("inverted", "1")))
+}
+
+func TestFetch(t *testing.T) {
+	req := require.New(t)
+	req.Nil(Fetch(context.Background()))
+	ctx := context.WithValue(context.Background(), ContextKey, NoopObserver)
+	req.Equal(NoopObserver, Fetch(ctx))
+}
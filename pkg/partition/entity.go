@@ -18,6 +18,8 @@
 package partition
 
 import (
+	"fmt"
+
 	"github.com/pkg/errors"
 
 	"github.com/apache/skywalking-banyandb/api/common"
@@ -29,6 +31,7 @@ import (
 
 var (
 	ErrMalformedElement = errors.New("element is malformed")
+	ErrMissingEntityTag = errors.New("entity tag is missing")
 )
 
 type EntityLocator []TagLocator
@@ -36,6 +39,8 @@ type EntityLocator []TagLocator
 type TagLocator struct {
 	FamilyOffset int
 	TagOffset    int
+	// Name is only used to report the errors, it's empty if the locator is received from an entity event
+	Name string
 }
 
 func NewEntityLocator(families []*databasev1.TagFamilySpec, entity *databasev1.Entity) EntityLocator {
@@ -43,23 +48,30 @@ func NewEntityLocator(families []*databasev1.TagFamilySpec, entity *databasev1.E
 	for _, tagInEntity := range entity.GetTagNames() {
 		fIndex, tIndex, tag := pbv1.FindTagByName(families, tagInEntity)
 		if tag != nil {
-			locator = append(locator, TagLocator{FamilyOffset: fIndex, TagOffset: tIndex})
+			locator = append(locator, TagLocator{FamilyOffset: fIndex, TagOffset: tIndex, Name: tagInEntity})
 		}
 	}
 	return locator
 }
 
+// Find fails with ErrMissingEntityTag if any of the entity tags is absent or null,
+// so a short element never ends up in a degenerate series.
+// The entries are encoded by pbv1.MarshalEntityValue, which also rejects the empty arrays.
 func (e EntityLocator) Find(subject string, value []*modelv1.TagFamilyForWrite) (tsdb.Entity, error) {
 	entity := make(tsdb.Entity, len(e)+1)
 	entity[0] = []byte(subject)
 	for i, index := range e {
 		tag, err := GetTagByOffset(value, index.FamilyOffset, index.TagOffset)
 		if err != nil {
-			return nil, err
+			return nil, errors.Wrapf(ErrMissingEntityTag, "%s: %v", index.name(), err)
+		}
+		// a series is never identified by a null tag
+		if _, isNull := pbv1.TagValueTypeConv(tag); isNull || tag.GetValue() == nil {
+			return nil, errors.Wrapf(ErrMissingEntityTag, "%s is null", index.name())
 		}
-		entry, errMarshal := pbv1.MarshalIndexFieldValue(tag)
+		entry, errMarshal := pbv1.MarshalEntityValue(tag)
 		if errMarshal != nil {
-			return nil, errMarshal
+			return nil, errors.Wrapf(errMarshal, "entity tag %s", index.name())
 		}
 		entity[i+1] = entry
 	}
@@ -78,6 +90,13 @@ func (e EntityLocator) Locate(subject string, value []*modelv1.TagFamilyForWrite
 	return entity, common.ShardID(id), nil
 }
 
+func (l TagLocator) name() string {
+	if l.Name != "" {
+		return l.Name
+	}
+	return fmt.Sprintf("tag at %d/%d", l.FamilyOffset, l.TagOffset)
+}
+
 func GetTagByOffset(value []*modelv1.TagFamilyForWrite, fIndex, tIndex int) (*modelv1.TagValue, error) {
 	if fIndex >= len(value) {
 		return nil, errors.Wrap(ErrMalformedElement, "tag family offset is invalid")
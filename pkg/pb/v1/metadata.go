@@ -45,6 +45,8 @@ func TagValueTypeConv(tagValue *modelv1.TagValue) (tagType databasev1.TagType, i
 		return databasev1.TagType_TAG_TYPE_STRING_ARRAY, false
 	case *modelv1.TagValue_BinaryData:
 		return databasev1.TagType_TAG_TYPE_DATA_BINARY, false
+	case *modelv1.TagValue_Float:
+		return databasev1.TagType_TAG_TYPE_FLOAT, false
 	case *modelv1.TagValue_Null:
 		return databasev1.TagType_TAG_TYPE_UNSPECIFIED, true
 	}
@@ -59,6 +61,8 @@ func FieldValueTypeConv(tagValue *modelv1.FieldValue) (tagType databasev1.FieldT
 		return databasev1.FieldType_FIELD_TYPE_STRING, false
 	case *modelv1.FieldValue_BinaryData:
 		return databasev1.FieldType_FIELD_TYPE_DATA_BINARY, false
+	case *modelv1.FieldValue_Float:
+		return databasev1.FieldType_FIELD_TYPE_FLOAT, false
 	case *modelv1.FieldValue_Null:
 		return databasev1.FieldType_FIELD_TYPE_UNSPECIFIED, true
 	}
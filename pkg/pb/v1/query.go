@@ -137,6 +137,10 @@ func buildTagValue(value interface{}) *modelv1.TagValue {
 		return &modelv1.TagValue{
 			Value: &modelv1.TagValue_IntArray{IntArray: &modelv1.IntArray{Value: v}},
 		}
+	case float64:
+		return &modelv1.TagValue{
+			Value: &modelv1.TagValue_Float{Float: &modelv1.Float{Value: v}},
+		}
 	case string:
 		return &modelv1.TagValue{
 			Value: &modelv1.TagValue_Str{Str: &modelv1.Str{Value: v}},
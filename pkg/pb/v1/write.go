@@ -19,13 +19,18 @@ package v1
 
 import (
 	"bytes"
+	"math"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	measurev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/measure/v1"
 	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
 	streamv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/stream/v1"
 	"github.com/apache/skywalking-banyandb/pkg/convert"
@@ -33,10 +38,37 @@ import (
 
 const strDelimiter = "\n"
 
-var ErrUnsupportedTagForIndexField = errors.New("the tag type(for example, null) can not be as the index field value")
+var (
+	ErrUnsupportedTagForIndexField = errors.New("the tag type(for example, null) can not be as the index field value")
+	ErrNullTagSkipped              = errors.New("the null tag is skipped by the null policy")
+	ErrInvalidUTF8                 = errors.New("the string tag is not valid UTF-8")
+	ErrMalformedIndexFieldValue    = errors.New("the index field value is malformed")
+	ErrTooManyTagFamilies          = errors.New("the tag families are more than the schema defines")
+	ErrFieldCountMismatch          = errors.New("the fields don't match the schema in number")
+	ErrFieldTypeMismatch           = errors.New("the field type doesn't match the schema")
+	ErrUnsupportedFieldValue       = errors.New("the native type has no field value")
+	ErrEmptyEntityArray            = errors.New("the array tag of an entity is empty")
+	ErrEntityFamilySkipped         = errors.New("the tag family holding an entity tag is skipped")
+)
+
+const utf8Replacement = "\uFFFD"
+
+// NullTerm is the term of a null tag indexed by IndexRule_NULL_POLICY_SENTINEL.
+// Its length differs from the terms of the int and float tags, so it never matches them.
+var NullTerm = []byte("\x00null\x00")
 
-func MarshalIndexFieldValue(tagValue *modelv1.TagValue) ([]byte, error) {
+// MarshalIndexFieldValue encodes the tag as an index term. The nullPolicy decides the result of a null tag:
+// ErrUnsupportedTagForIndexField by default, ErrNullTagSkipped if the caller should leave the item out of the index,
+// or NullTerm.
+func MarshalIndexFieldValue(tagValue *modelv1.TagValue, nullPolicy databasev1.IndexRule_NullPolicy) ([]byte, error) {
 	switch x := tagValue.GetValue().(type) {
+	case *modelv1.TagValue_Null:
+		switch nullPolicy {
+		case databasev1.IndexRule_NULL_POLICY_SKIP:
+			return nil, ErrNullTagSkipped
+		case databasev1.IndexRule_NULL_POLICY_SENTINEL:
+			return NullTerm, nil
+		}
 	case *modelv1.TagValue_Str:
 		return []byte(x.Str.GetValue()), nil
 	case *modelv1.TagValue_Int:
@@ -51,10 +83,188 @@ func MarshalIndexFieldValue(tagValue *modelv1.TagValue) ([]byte, error) {
 		return buf.Bytes(), nil
 	case *modelv1.TagValue_BinaryData:
 		return x.BinaryData, nil
+	case *modelv1.TagValue_Float:
+		return MarshalFloat(x.Float.GetValue()), nil
 	}
 	return nil, ErrUnsupportedTagForIndexField
 }
 
+// MarshalEntityValue encodes a tag of an entity as an entry of the series key.
+// The encoding is the same as MarshalIndexFieldValue's, so a query condition on an entity tag
+// can locate the series through this function as well:
+//   - StrArray: the elements are joined by "\n" in order
+//   - IntArray: the elements are encoded by convert.Int64ToBytes and concatenated in order
+//
+// The order of the elements matters, ["a", "b"] and ["b", "a"] are two series.
+// An empty array fails with ErrEmptyEntityArray, since it would collide with an empty string.
+func MarshalEntityValue(tagValue *modelv1.TagValue) ([]byte, error) {
+	switch x := tagValue.GetValue().(type) {
+	case *modelv1.TagValue_StrArray:
+		if len(x.StrArray.GetValue()) == 0 {
+			return nil, ErrEmptyEntityArray
+		}
+	case *modelv1.TagValue_IntArray:
+		if len(x.IntArray.GetValue()) == 0 {
+			return nil, ErrEmptyEntityArray
+		}
+	}
+	return MarshalIndexFieldValue(tagValue, databasev1.IndexRule_NULL_POLICY_ERROR)
+}
+
+// MarshalFloat encodes a float term whose byte-wise order is the numeric order.
+// The zeros are stored as +0, so -0 and +0 match each other.
+// All the NaNs are stored as a single one placed above +Inf.
+func MarshalFloat(f float64) []byte {
+	switch {
+	case math.IsNaN(f):
+		f = math.NaN()
+	case f == 0:
+		f = 0
+	}
+	return convert.Float64ToBytes(f)
+}
+
+// UnmarshalIndexFieldValue is the inverse of MarshalIndexFieldValue.
+// It decodes a term, for example, index.PostingValue.Term, into a readable value of the tag type.
+// NullTerm is decoded as a null tag regardless of the tag type.
+func UnmarshalIndexFieldValue(term []byte, tagType databasev1.TagType) (*modelv1.TagValue, error) {
+	if bytes.Equal(term, NullTerm) {
+		return &modelv1.TagValue{Value: &modelv1.TagValue_Null{}}, nil
+	}
+	switch tagType {
+	case databasev1.TagType_TAG_TYPE_STRING:
+		return &modelv1.TagValue{Value: &modelv1.TagValue_Str{Str: &modelv1.Str{Value: string(term)}}}, nil
+	case databasev1.TagType_TAG_TYPE_INT:
+		if len(term) != 8 {
+			return nil, errors.Wrapf(ErrMalformedIndexFieldValue, "int term has %d bytes", len(term))
+		}
+		return &modelv1.TagValue{Value: &modelv1.TagValue_Int{Int: &modelv1.Int{Value: convert.BytesToInt64(term)}}}, nil
+	case databasev1.TagType_TAG_TYPE_STRING_ARRAY:
+		return &modelv1.TagValue{Value: &modelv1.TagValue_StrArray{StrArray: &modelv1.StrArray{
+			Value: strings.Split(string(term), strDelimiter),
+		}}}, nil
+	case databasev1.TagType_TAG_TYPE_INT_ARRAY:
+		if len(term)%8 != 0 {
+			return nil, errors.Wrapf(ErrMalformedIndexFieldValue, "int array term has %d bytes", len(term))
+		}
+		values := make([]int64, 0, len(term)/8)
+		for i := 0; i < len(term); i += 8 {
+			values = append(values, convert.BytesToInt64(term[i:i+8]))
+		}
+		return &modelv1.TagValue{Value: &modelv1.TagValue_IntArray{IntArray: &modelv1.IntArray{Value: values}}}, nil
+	case databasev1.TagType_TAG_TYPE_DATA_BINARY:
+		return &modelv1.TagValue{Value: &modelv1.TagValue_BinaryData{BinaryData: term}}, nil
+	case databasev1.TagType_TAG_TYPE_FLOAT:
+		if len(term) != 8 {
+			return nil, errors.Wrapf(ErrMalformedIndexFieldValue, "float term has %d bytes", len(term))
+		}
+		return &modelv1.TagValue{Value: &modelv1.TagValue_Float{Float: &modelv1.Float{Value: convert.BytesToFloat64(term)}}}, nil
+	}
+	return nil, ErrUnsupportedTagForIndexField
+}
+
+// CheckTagFamilyCount makes sure that the tag families match the specs by position.
+// An extra family, for example, a family added twice by accident, is reported with its index.
+func CheckTagFamilyCount(tagFamilies []*modelv1.TagFamilyForWrite, specs []*databasev1.TagFamilySpec) error {
+	if len(tagFamilies) > len(specs) {
+		return errors.Wrapf(ErrTooManyTagFamilies, "tag family #%d is beyond the %d families of the schema", len(specs), len(specs))
+	}
+	return nil
+}
+
+// ValidateStreamWrite checks the request against the schema before it's sent.
+// The tags of the entity are never absent, so a family skipped by SkipTagFamily or cut short
+// mustn't end before any of them.
+func ValidateStreamWrite(req *streamv1.WriteRequest, schema *databasev1.Stream) error {
+	tagFamilies := req.GetElement().GetTagFamilies()
+	if err := CheckTagFamilyCount(tagFamilies, schema.GetTagFamilies()); err != nil {
+		return err
+	}
+	for _, name := range schema.GetEntity().GetTagNames() {
+		fi, ti, tag := FindTagByName(schema.GetTagFamilies(), name)
+		if tag == nil || fi >= len(tagFamilies) {
+			continue
+		}
+		if len(tagFamilies[fi].GetTags()) <= ti {
+			return errors.Wrapf(ErrEntityFamilySkipped, "tag family #%d %s misses the entity tag %s at #%d",
+				fi, schema.GetTagFamilies()[fi].GetName(), name, ti)
+		}
+	}
+	return nil
+}
+
+// ValidateMeasureWrite checks the request against the schema before it's sent.
+// The fields are matched with the specs by position, so every field of the schema should be present.
+// A null field is still allowed in its position. All the mismatches are reported in a multi-error.
+func ValidateMeasureWrite(req *measurev1.WriteRequest, schema *databasev1.Measure) error {
+	err := CheckTagFamilyCount(req.GetDataPoint().GetTagFamilies(), schema.GetTagFamilies())
+	fields, specs := req.GetDataPoint().GetFields(), schema.GetFields()
+	if len(fields) != len(specs) {
+		err = multierr.Append(err, errors.Wrapf(ErrFieldCountMismatch, "got %d fields, the schema defines %d", len(fields), len(specs)))
+	}
+	for i, spec := range specs {
+		if i >= len(fields) {
+			err = multierr.Append(err, errors.Wrapf(ErrFieldCountMismatch, "field #%d %s is absent", i, spec.GetName()))
+			continue
+		}
+		fType, isNull := FieldValueTypeConv(fields[i])
+		if isNull {
+			continue
+		}
+		if fType != spec.GetFieldType() {
+			err = multierr.Append(err, errors.Wrapf(ErrFieldTypeMismatch, "field #%d %s expects %s, got %s",
+				i, spec.GetName(), spec.GetFieldType(), fType))
+		}
+	}
+	return err
+}
+
+// ApplyUTF8Policy checks the string tags against the policy before they're stored and indexed.
+// The invalid strings are fixed in place if the policy is UTF8_POLICY_REPLACE.
+func ApplyUTF8Policy(tagFamilies []*modelv1.TagFamilyForWrite, policy commonv1.ResourceOpts_UTF8Policy) error {
+	if policy == commonv1.ResourceOpts_UTF8_POLICY_UNSPECIFIED {
+		return nil
+	}
+	apply := func(str string) (string, error) {
+		if utf8.ValidString(str) {
+			return str, nil
+		}
+		if policy == commonv1.ResourceOpts_UTF8_POLICY_REJECT {
+			return "", errors.Wrapf(ErrInvalidUTF8, "%q", str)
+		}
+		return strings.ToValidUTF8(str, utf8Replacement), nil
+	}
+	for fi, tf := range tagFamilies {
+		for ti, tag := range tf.GetTags() {
+			switch x := tag.GetValue().(type) {
+			case *modelv1.TagValue_Str:
+				str, err := apply(x.Str.GetValue())
+				if err != nil {
+					return errors.WithMessagef(err, "tag family:%d tag:%d", fi, ti)
+				}
+				x.Str.Value = str
+			case *modelv1.TagValue_StrArray:
+				for i := range x.StrArray.GetValue() {
+					str, err := apply(x.StrArray.Value[i])
+					if err != nil {
+						return errors.WithMessagef(err, "tag family:%d tag:%d", fi, ti)
+					}
+					x.StrArray.Value[i] = str
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// StreamWriteRequestBuilder builds a stream write request.
+// It could be reused by Reset to avoid allocating a request per element,
+// for example, by getting builders from a sync.Pool:
+//
+//	b := pool.Get().(*StreamWriteRequestBuilder)
+//	defer pool.Put(b)
+//	b.Reset().Metadata("default", "sw").ID("1").Timestamp(time.Now()).TagFamily("trace_id")
+//	err := client.Send(b.Build())
 type StreamWriteRequestBuilder struct {
 	ec *streamv1.WriteRequest
 }
@@ -69,11 +279,31 @@ func NewStreamWriteRequestBuilder() *StreamWriteRequestBuilder {
 	}
 }
 
+// Reset clears the request to build another one. It keeps the allocated memory for reuse.
+func (b *StreamWriteRequestBuilder) Reset() *StreamWriteRequestBuilder {
+	if b.ec.Metadata != nil {
+		b.ec.Metadata.Reset()
+	}
+	e := b.ec.Element
+	e.ElementId = ""
+	e.Timestamp = nil
+	e.Compression = commonv1.Compression_COMPRESSION_UNSPECIFIED
+	for _, tf := range e.TagFamilies {
+		for i := range tf.Tags {
+			tf.Tags[i] = nil
+		}
+		tf.Tags = tf.Tags[:0]
+	}
+	e.TagFamilies = e.TagFamilies[:0]
+	return b
+}
+
 func (b *StreamWriteRequestBuilder) Metadata(group, name string) *StreamWriteRequestBuilder {
-	b.ec.Metadata = &commonv1.Metadata{
-		Group: group,
-		Name:  name,
+	if b.ec.Metadata == nil {
+		b.ec.Metadata = &commonv1.Metadata{}
 	}
+	b.ec.Metadata.Group = group
+	b.ec.Metadata.Name = name
 	return b
 }
 
@@ -87,19 +317,91 @@ func (b *StreamWriteRequestBuilder) Timestamp(t time.Time) *StreamWriteRequestBu
 	return b
 }
 
+// ServerTimestamp leaves the timestamp absent, which lets the server stamp the element on receipt.
+func (b *StreamWriteRequestBuilder) ServerTimestamp() *StreamWriteRequestBuilder {
+	b.ec.Element.Timestamp = nil
+	return b
+}
+
 func (b *StreamWriteRequestBuilder) TagFamily(tags ...interface{}) *StreamWriteRequestBuilder {
-	tagFamily := &modelv1.TagFamilyForWrite{}
+	tagFamilies := b.ec.Element.TagFamilies
+	var tagFamily *modelv1.TagFamilyForWrite
+	// The tag families truncated by Reset are reused
+	if len(tagFamilies) < cap(tagFamilies) {
+		tagFamily = tagFamilies[:len(tagFamilies)+1][len(tagFamilies)]
+	}
+	if tagFamily == nil {
+		tagFamily = &modelv1.TagFamilyForWrite{}
+	}
 	for _, tag := range tags {
 		tagFamily.Tags = append(tagFamily.Tags, getTag(tag))
 	}
-	b.ec.Element.TagFamilies = append(b.ec.Element.TagFamilies, tagFamily)
+	b.ec.Element.TagFamilies = append(tagFamilies, tagFamily)
+	return b
+}
+
+// SkipTagFamily appends an empty tag family, so the next family goes to the following position of the schema.
+// All the tags of a skipped family are absent, which a query reads as null.
+func (b *StreamWriteRequestBuilder) SkipTagFamily() *StreamWriteRequestBuilder {
+	return b.TagFamily()
+}
+
+// Validate checks the request against the stream. See ValidateStreamWrite.
+func (b *StreamWriteRequestBuilder) Validate(stream *databasev1.Stream) error {
+	return ValidateStreamWrite(b.ec, stream)
+}
+
+// DataBinary appends a tag family holding the raw binary data, for example, a serialized span.
+// It's stored compressed if the compression of the element or the group is specified.
+func (b *StreamWriteRequestBuilder) DataBinary(data []byte) *StreamWriteRequestBuilder {
+	return b.TagFamily(data)
+}
+
+// Compression overrides the binary compression of the group for the element
+func (b *StreamWriteRequestBuilder) Compression(compression commonv1.Compression) *StreamWriteRequestBuilder {
+	b.ec.Element.Compression = compression
 	return b
 }
 
+// Build returns the request held by the builder rather than a copy.
+// The request should be consumed, for example, sent out, before the next Reset.
 func (b *StreamWriteRequestBuilder) Build() *streamv1.WriteRequest {
 	return b.ec
 }
 
+// StreamWriteBatchBuilder builds a batch of stream write requests. The elements might target different groups and streams.
+//
+//	b := NewStreamWriteBatchBuilder()
+//	b.Element("sw_hot", "sw").ID("1").Timestamp(time.Now()).TagFamily("trace_id")
+//	b.Element("sw_cold", "sw").ID("2").Timestamp(time.Now()).TagFamily("trace_id")
+//	err := writer.WriteBatch(b.Build())
+type StreamWriteBatchBuilder struct {
+	requests []*streamv1.WriteRequest
+}
+
+func NewStreamWriteBatchBuilder() *StreamWriteBatchBuilder {
+	return &StreamWriteBatchBuilder{}
+}
+
+// Element appends an element of the stream to the batch, and returns the builder to fill it in.
+func (b *StreamWriteBatchBuilder) Element(group, name string) *StreamWriteRequestBuilder {
+	eb := NewStreamWriteRequestBuilder().Metadata(group, name)
+	b.requests = append(b.requests, eb.Build())
+	return eb
+}
+
+// Add appends a request built elsewhere to the batch
+func (b *StreamWriteBatchBuilder) Add(request *streamv1.WriteRequest) *StreamWriteBatchBuilder {
+	b.requests = append(b.requests, request)
+	return b
+}
+
+// Build returns the requests in the order they're appended.
+// The position of a request is the index to which its failure is attributed.
+func (b *StreamWriteBatchBuilder) Build() []*streamv1.WriteRequest {
+	return b.requests
+}
+
 func getTag(tag interface{}) *modelv1.TagValue {
 	if tag == nil {
 		return &modelv1.TagValue{
@@ -123,6 +425,14 @@ func getTag(tag interface{}) *modelv1.TagValue {
 				},
 			},
 		}
+	case float64:
+		return &modelv1.TagValue{
+			Value: &modelv1.TagValue_Float{
+				Float: &modelv1.Float{
+					Value: t,
+				},
+			},
+		}
 	case []byte:
 		return &modelv1.TagValue{
 			Value: &modelv1.TagValue_BinaryData{
@@ -132,3 +442,142 @@ func getTag(tag interface{}) *modelv1.TagValue {
 	}
 	return nil
 }
+
+// MeasureWriteRequestBuilder builds a measure write request.
+// Build validates the request if the schema of the measure is supplied.
+type MeasureWriteRequestBuilder struct {
+	ec     *measurev1.WriteRequest
+	schema *databasev1.Measure
+	// err collects the fields of unsupported types, which Build reports
+	err error
+}
+
+func NewMeasureWriteRequestBuilder() *MeasureWriteRequestBuilder {
+	return &MeasureWriteRequestBuilder{
+		ec: &measurev1.WriteRequest{
+			DataPoint: &measurev1.DataPointValue{
+				TagFamilies: make([]*modelv1.TagFamilyForWrite, 0),
+				Fields:      make([]*modelv1.FieldValue, 0),
+			},
+		},
+	}
+}
+
+func (b *MeasureWriteRequestBuilder) Metadata(group, name string) *MeasureWriteRequestBuilder {
+	b.ec.Metadata = &commonv1.Metadata{
+		Group: group,
+		Name:  name,
+	}
+	return b
+}
+
+func (b *MeasureWriteRequestBuilder) Timestamp(t time.Time) *MeasureWriteRequestBuilder {
+	b.ec.DataPoint.Timestamp = timestamppb.New(t)
+	return b
+}
+
+func (b *MeasureWriteRequestBuilder) TagFamily(tags ...interface{}) *MeasureWriteRequestBuilder {
+	tagFamily := &modelv1.TagFamilyForWrite{}
+	for _, tag := range tags {
+		tagFamily.Tags = append(tagFamily.Tags, getTag(tag))
+	}
+	b.ec.DataPoint.TagFamilies = append(b.ec.DataPoint.TagFamilies, tagFamily)
+	return b
+}
+
+// Fields appends the field values. Build fails with ErrUnsupportedFieldValue if any of them isn't
+// nil, an int, an int64, a float64, a string or a []byte.
+func (b *MeasureWriteRequestBuilder) Fields(fields ...interface{}) *MeasureWriteRequestBuilder {
+	for _, field := range fields {
+		value, err := getField(field)
+		if err != nil {
+			// the position counts the unsupported fields before it as well
+			pos := len(b.ec.DataPoint.Fields) + len(multierr.Errors(b.err))
+			b.err = multierr.Append(b.err, errors.WithMessagef(err, "field #%d", pos))
+			continue
+		}
+		b.ec.DataPoint.Fields = append(b.ec.DataPoint.Fields, value)
+	}
+	return b
+}
+
+// Version sets the version which deduplicates the data points of the same series and timestamp
+func (b *MeasureWriteRequestBuilder) Version(version int64) *MeasureWriteRequestBuilder {
+	b.ec.DataPoint.Version = version
+	return b
+}
+
+// Schema lets Build validate the request against the measure
+func (b *MeasureWriteRequestBuilder) Schema(measure *databasev1.Measure) *MeasureWriteRequestBuilder {
+	b.schema = measure
+	return b
+}
+
+// Validate checks the request against the measure. See ValidateMeasureWrite.
+func (b *MeasureWriteRequestBuilder) Validate(measure *databasev1.Measure) error {
+	return ValidateMeasureWrite(b.ec, measure)
+}
+
+// Build returns the request. It fails if any field is unsupported, or the request doesn't match the schema supplied by Schema.
+func (b *MeasureWriteRequestBuilder) Build() (*measurev1.WriteRequest, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.schema != nil {
+		if err := b.Validate(b.schema); err != nil {
+			return nil, err
+		}
+	}
+	return b.ec, nil
+}
+
+// getField converts a native value into a FieldValue. Only nil maps to the null value,
+// and the types without a FieldValue variant fail with ErrUnsupportedFieldValue.
+func getField(field interface{}) (*modelv1.FieldValue, error) {
+	if field == nil {
+		return &modelv1.FieldValue{
+			Value: &modelv1.FieldValue_Null{},
+		}, nil
+	}
+	switch t := field.(type) {
+	case int:
+		return &modelv1.FieldValue{
+			Value: &modelv1.FieldValue_Int{
+				Int: &modelv1.Int{
+					Value: int64(t),
+				},
+			},
+		}, nil
+	case int64:
+		return &modelv1.FieldValue{
+			Value: &modelv1.FieldValue_Int{
+				Int: &modelv1.Int{
+					Value: t,
+				},
+			},
+		}, nil
+	case float64:
+		return &modelv1.FieldValue{
+			Value: &modelv1.FieldValue_Float{
+				Float: &modelv1.Float{
+					Value: t,
+				},
+			},
+		}, nil
+	case string:
+		return &modelv1.FieldValue{
+			Value: &modelv1.FieldValue_Str{
+				Str: &modelv1.Str{
+					Value: t,
+				},
+			},
+		}, nil
+	case []byte:
+		return &modelv1.FieldValue{
+			Value: &modelv1.FieldValue_BinaryData{
+				BinaryData: t,
+			},
+		}, nil
+	}
+	return nil, errors.Wrapf(ErrUnsupportedFieldValue, "%T", field)
+}
@@ -0,0 +1,442 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v1
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+)
+
+func TestApplyUTF8Policy(t *testing.T) {
+	strTag := func(s string) *modelv1.TagValue {
+		return &modelv1.TagValue{Value: &modelv1.TagValue_Str{Str: &modelv1.Str{Value: s}}}
+	}
+	strArrayTag := func(s ...string) *modelv1.TagValue {
+		return &modelv1.TagValue{Value: &modelv1.TagValue_StrArray{StrArray: &modelv1.StrArray{Value: s}}}
+	}
+	tests := []struct {
+		name    string
+		policy  commonv1.ResourceOpts_UTF8Policy
+		tag     *modelv1.TagValue
+		want    *modelv1.TagValue
+		wantErr bool
+	}{
+		{
+			name:   "keep invalid bytes",
+			tag:    strTag("a\xffb"),
+			want:   strTag("a\xffb"),
+			policy: commonv1.ResourceOpts_UTF8_POLICY_UNSPECIFIED,
+		},
+		{
+			name:   "valid string",
+			tag:    strTag("トレース"),
+			want:   strTag("トレース"),
+			policy: commonv1.ResourceOpts_UTF8_POLICY_REJECT,
+		},
+		{
+			name:    "reject an invalid byte",
+			tag:     strTag("a\xffb"),
+			want:    strTag("a\xffb"),
+			policy:  commonv1.ResourceOpts_UTF8_POLICY_REJECT,
+			wantErr: true,
+		},
+		{
+			name:    "reject a truncated sequence in an array",
+			tag:     strArrayTag("ok", "\xe3\x83"),
+			want:    strArrayTag("ok", "\xe3\x83"),
+			policy:  commonv1.ResourceOpts_UTF8_POLICY_REJECT,
+			wantErr: true,
+		},
+		{
+			name:   "replace an invalid byte",
+			tag:    strTag("a\xffb"),
+			want:   strTag("a�b"),
+			policy: commonv1.ResourceOpts_UTF8_POLICY_REPLACE,
+		},
+		{
+			name:   "replace a run of invalid bytes once",
+			tag:    strArrayTag("\xc0\xafx", "ok"),
+			want:   strArrayTag("�x", "ok"),
+			policy: commonv1.ResourceOpts_UTF8_POLICY_REPLACE,
+		},
+		{
+			name:   "replace a surrogate half",
+			tag:    strTag("\xed\xa0\x80"),
+			want:   strTag("�"),
+			policy: commonv1.ResourceOpts_UTF8_POLICY_REPLACE,
+		},
+		{
+			name:   "ignore binary data",
+			tag:    &modelv1.TagValue{Value: &modelv1.TagValue_BinaryData{BinaryData: []byte("\xff")}},
+			want:   &modelv1.TagValue{Value: &modelv1.TagValue_BinaryData{BinaryData: []byte("\xff")}},
+			policy: commonv1.ResourceOpts_UTF8_POLICY_REJECT,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tagFamilies := []*modelv1.TagFamilyForWrite{{Tags: []*modelv1.TagValue{strTag("trace"), tt.tag}}}
+			err := ApplyUTF8Policy(tagFamilies, tt.policy)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidUTF8)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want.String(), tagFamilies[0].Tags[1].String())
+		})
+	}
+}
+
+func TestUnmarshalIndexFieldValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		tagType databasev1.TagType
+		tag     *modelv1.TagValue
+	}{
+		{
+			name:    "string",
+			tagType: databasev1.TagType_TAG_TYPE_STRING,
+			tag:     &modelv1.TagValue{Value: &modelv1.TagValue_Str{Str: &modelv1.Str{Value: "GET /home"}}},
+		},
+		{
+			name:    "negative int",
+			tagType: databasev1.TagType_TAG_TYPE_INT,
+			tag:     &modelv1.TagValue{Value: &modelv1.TagValue_Int{Int: &modelv1.Int{Value: -500}}},
+		},
+		{
+			name:    "string array",
+			tagType: databasev1.TagType_TAG_TYPE_STRING_ARRAY,
+			tag:     &modelv1.TagValue{Value: &modelv1.TagValue_StrArray{StrArray: &modelv1.StrArray{Value: []string{"a", "b"}}}},
+		},
+		{
+			name:    "int array",
+			tagType: databasev1.TagType_TAG_TYPE_INT_ARRAY,
+			tag:     &modelv1.TagValue{Value: &modelv1.TagValue_IntArray{IntArray: &modelv1.IntArray{Value: []int64{-1, 0, 1}}}},
+		},
+		{
+			name:    "binary",
+			tagType: databasev1.TagType_TAG_TYPE_DATA_BINARY,
+			tag:     &modelv1.TagValue{Value: &modelv1.TagValue_BinaryData{BinaryData: []byte{0, 1, 2}}},
+		},
+		{
+			name:    "negative float",
+			tagType: databasev1.TagType_TAG_TYPE_FLOAT,
+			tag:     &modelv1.TagValue{Value: &modelv1.TagValue_Float{Float: &modelv1.Float{Value: -12.5}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term, err := MarshalIndexFieldValue(tt.tag, databasev1.IndexRule_NULL_POLICY_UNSPECIFIED)
+			assert.NoError(t, err)
+			got, err := UnmarshalIndexFieldValue(term, tt.tagType)
+			assert.NoError(t, err)
+			assert.True(t, proto.Equal(tt.tag, got), "got %v", got)
+		})
+	}
+	_, err := UnmarshalIndexFieldValue([]byte{1, 2, 3}, databasev1.TagType_TAG_TYPE_INT)
+	assert.ErrorIs(t, err, ErrMalformedIndexFieldValue)
+	_, err = UnmarshalIndexFieldValue(nil, databasev1.TagType_TAG_TYPE_UNSPECIFIED)
+	assert.ErrorIs(t, err, ErrUnsupportedTagForIndexField)
+}
+
+func TestMarshalIndexFieldValue_NullPolicy(t *testing.T) {
+	null := &modelv1.TagValue{Value: &modelv1.TagValue_Null{}}
+	for _, policy := range []databasev1.IndexRule_NullPolicy{
+		databasev1.IndexRule_NULL_POLICY_UNSPECIFIED,
+		databasev1.IndexRule_NULL_POLICY_ERROR,
+	} {
+		_, err := MarshalIndexFieldValue(null, policy)
+		assert.ErrorIs(t, err, ErrUnsupportedTagForIndexField)
+	}
+	_, err := MarshalIndexFieldValue(null, databasev1.IndexRule_NULL_POLICY_SKIP)
+	assert.ErrorIs(t, err, ErrNullTagSkipped)
+	term, err := MarshalIndexFieldValue(null, databasev1.IndexRule_NULL_POLICY_SENTINEL)
+	assert.NoError(t, err)
+	assert.Equal(t, NullTerm, term)
+	got, err := UnmarshalIndexFieldValue(term, databasev1.TagType_TAG_TYPE_INT)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(null, got), "got %v", got)
+
+	// the policy never applies to the other tags
+	term, err = MarshalIndexFieldValue(&modelv1.TagValue{Value: &modelv1.TagValue_Str{Str: &modelv1.Str{Value: "trace"}}},
+		databasev1.IndexRule_NULL_POLICY_SENTINEL)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("trace"), term)
+}
+
+func TestMarshalEntityValue(t *testing.T) {
+	strArray := func(s ...string) *modelv1.TagValue {
+		return &modelv1.TagValue{Value: &modelv1.TagValue_StrArray{StrArray: &modelv1.StrArray{Value: s}}}
+	}
+	intArray := func(i ...int64) *modelv1.TagValue {
+		return &modelv1.TagValue{Value: &modelv1.TagValue_IntArray{IntArray: &modelv1.IntArray{Value: i}}}
+	}
+	entry, err := MarshalEntityValue(strArray("a", "b"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a\nb"), entry)
+	reversed, err := MarshalEntityValue(strArray("b", "a"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, entry, reversed)
+
+	entry, err = MarshalEntityValue(intArray(1, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, append(convert.Int64ToBytes(1), convert.Int64ToBytes(2)...), entry)
+	// the entry of a query condition is the same as the index term
+	term, err := MarshalIndexFieldValue(intArray(1, 2), databasev1.IndexRule_NULL_POLICY_ERROR)
+	assert.NoError(t, err)
+	assert.Equal(t, term, entry)
+
+	_, err = MarshalEntityValue(strArray())
+	assert.ErrorIs(t, err, ErrEmptyEntityArray)
+	_, err = MarshalEntityValue(intArray())
+	assert.ErrorIs(t, err, ErrEmptyEntityArray)
+	_, err = MarshalEntityValue(&modelv1.TagValue{Value: &modelv1.TagValue_Null{}})
+	assert.ErrorIs(t, err, ErrUnsupportedTagForIndexField)
+}
+
+func TestValidateStreamWrite(t *testing.T) {
+	schema := &databasev1.Stream{
+		TagFamilies: []*databasev1.TagFamilySpec{
+			{Name: "data", Tags: []*databasev1.TagSpec{{Name: "data_binary", Type: databasev1.TagType_TAG_TYPE_DATA_BINARY}}},
+			{Name: "searchable", Tags: []*databasev1.TagSpec{{Name: "trace_id", Type: databasev1.TagType_TAG_TYPE_STRING}}},
+		},
+	}
+	builder := func() *StreamWriteRequestBuilder {
+		return NewStreamWriteRequestBuilder().
+			Metadata("default", "sw").
+			ID("1").
+			TagFamily([]byte{0x1}).
+			TagFamily("trace-1")
+	}
+	assert.NoError(t, ValidateStreamWrite(builder().Build(), schema))
+	err := ValidateStreamWrite(builder().TagFamily("trace-1").Build(), schema)
+	assert.ErrorIs(t, err, ErrTooManyTagFamilies)
+	assert.Contains(t, err.Error(), "#2")
+}
+
+func TestValidateStreamWrite_SkipTagFamily(t *testing.T) {
+	schema := &databasev1.Stream{
+		TagFamilies: []*databasev1.TagFamilySpec{
+			{Name: "data", Tags: []*databasev1.TagSpec{{Name: "data_binary", Type: databasev1.TagType_TAG_TYPE_DATA_BINARY}}},
+			{Name: "searchable", Tags: []*databasev1.TagSpec{{Name: "trace_id", Type: databasev1.TagType_TAG_TYPE_STRING}}},
+		},
+		Entity: &databasev1.Entity{TagNames: []string{"trace_id"}},
+	}
+	builder := NewStreamWriteRequestBuilder().
+		Metadata("default", "sw").
+		ID("1").
+		SkipTagFamily().
+		TagFamily("trace-1")
+	tagFamilies := builder.Build().GetElement().GetTagFamilies()
+	assert.Len(t, tagFamilies, 2)
+	assert.Empty(t, tagFamilies[0].GetTags())
+	assert.Len(t, tagFamilies[1].GetTags(), 1)
+	assert.NoError(t, builder.Validate(schema))
+
+	err := NewStreamWriteRequestBuilder().
+		Metadata("default", "sw").
+		ID("1").
+		TagFamily([]byte{0x1}).
+		SkipTagFamily().
+		Validate(schema)
+	assert.ErrorIs(t, err, ErrEntityFamilySkipped)
+	assert.Contains(t, err.Error(), "trace_id")
+
+	// the family ends before the entity tag, which isn't its first one
+	schema.TagFamilies[1].Tags = append(schema.TagFamilies[1].Tags, &databasev1.TagSpec{
+		Name: "service_id",
+		Type: databasev1.TagType_TAG_TYPE_STRING,
+	})
+	schema.Entity.TagNames = []string{"service_id"}
+	builder = NewStreamWriteRequestBuilder().
+		Metadata("default", "sw").
+		ID("1").
+		TagFamily([]byte{0x1})
+	err = builder.TagFamily("trace-1").Validate(schema)
+	assert.ErrorIs(t, err, ErrEntityFamilySkipped)
+	assert.Contains(t, err.Error(), "service_id")
+	assert.NoError(t, NewStreamWriteRequestBuilder().
+		Metadata("default", "sw").
+		ID("1").
+		TagFamily([]byte{0x1}).
+		TagFamily("trace-1", "service-1").
+		Validate(schema))
+}
+
+func TestStreamWriteBatchBuilder(t *testing.T) {
+	b := NewStreamWriteBatchBuilder()
+	b.Element("sw_hot", "sw").ID("1").TagFamily("trace-1")
+	b.Add(NewStreamWriteRequestBuilder().Metadata("sw_cold", "sw").ID("2").Build())
+	b.Element("sw_cold", "sw").ID("3").TagFamily("trace-3")
+	requests := b.Build()
+	assert.Len(t, requests, 3)
+	assert.Equal(t, "sw_hot", requests[0].GetMetadata().GetGroup())
+	assert.Equal(t, "1", requests[0].GetElement().GetElementId())
+	assert.Len(t, requests[0].GetElement().GetTagFamilies(), 1)
+	assert.Equal(t, "sw_cold", requests[1].GetMetadata().GetGroup())
+	assert.Equal(t, "2", requests[1].GetElement().GetElementId())
+	assert.Equal(t, "sw_cold", requests[2].GetMetadata().GetGroup())
+	assert.Equal(t, "3", requests[2].GetElement().GetElementId())
+}
+
+func TestGetField(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   interface{}
+		want    *modelv1.FieldValue
+		wantErr bool
+	}{
+		{name: "nil", field: nil, want: &modelv1.FieldValue{Value: &modelv1.FieldValue_Null{}}},
+		{name: "int", field: 1, want: &modelv1.FieldValue{Value: &modelv1.FieldValue_Int{Int: &modelv1.Int{Value: 1}}}},
+		{name: "int64", field: int64(math.MaxInt64), want: &modelv1.FieldValue{
+			Value: &modelv1.FieldValue_Int{Int: &modelv1.Int{Value: math.MaxInt64}},
+		}},
+		{name: "string", field: "svc", want: &modelv1.FieldValue{Value: &modelv1.FieldValue_Str{Str: &modelv1.Str{Value: "svc"}}}},
+		{name: "bytes", field: []byte{1, 2}, want: &modelv1.FieldValue{Value: &modelv1.FieldValue_BinaryData{BinaryData: []byte{1, 2}}}},
+		{name: "float64", field: 1.5, want: &modelv1.FieldValue{Value: &modelv1.FieldValue_Float{Float: &modelv1.Float{Value: 1.5}}}},
+		{name: "float32", field: float32(1.5), wantErr: true},
+		{name: "int32", field: int32(1), wantErr: true},
+		{name: "bool", field: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getField(tt.field)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnsupportedFieldValue)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+		})
+	}
+}
+
+func TestMeasureWriteRequestBuilder_UnsupportedField(t *testing.T) {
+	req, err := NewMeasureWriteRequestBuilder().
+		Metadata("default", "service_cpm_minute").
+		Fields(100, float32(1.5)).
+		Fields(true).
+		Build()
+	assert.Nil(t, req)
+	assert.ErrorIs(t, err, ErrUnsupportedFieldValue)
+	assert.Len(t, multierr.Errors(err), 2)
+	assert.Contains(t, err.Error(), "field #1: float32")
+	assert.Contains(t, err.Error(), "field #2: bool")
+}
+
+func TestMeasureWriteRequestBuilder_FloatField(t *testing.T) {
+	req, err := NewMeasureWriteRequestBuilder().
+		Metadata("default", "service_latency_minute").
+		Fields(int64(100), 0.95).
+		Build()
+	assert.NoError(t, err)
+	fields := req.GetDataPoint().GetFields()
+	assert.Len(t, fields, 2)
+	assert.Equal(t, int64(100), fields[0].GetInt().GetValue())
+	assert.Equal(t, 0.95, fields[1].GetFloat().GetValue())
+	fieldType, isNull := FieldValueTypeConv(fields[1])
+	assert.Equal(t, databasev1.FieldType_FIELD_TYPE_FLOAT, fieldType)
+	assert.False(t, isNull)
+}
+
+func TestValidateMeasureWrite(t *testing.T) {
+	schema := &databasev1.Measure{
+		TagFamilies: []*databasev1.TagFamilySpec{
+			{Name: "default", Tags: []*databasev1.TagSpec{{Name: "id", Type: databasev1.TagType_TAG_TYPE_STRING}}},
+		},
+		Fields: []*databasev1.FieldSpec{
+			{Name: "total", FieldType: databasev1.FieldType_FIELD_TYPE_INT},
+			{Name: "name", FieldType: databasev1.FieldType_FIELD_TYPE_STRING},
+		},
+	}
+	builder := func(fields ...interface{}) *MeasureWriteRequestBuilder {
+		return NewMeasureWriteRequestBuilder().
+			Metadata("default", "service_cpm_minute").
+			TagFamily("1").
+			Fields(fields...).
+			Schema(schema)
+	}
+	req, err := builder(100, "svc").Build()
+	assert.NoError(t, err)
+	assert.Len(t, req.GetDataPoint().GetFields(), 2)
+	_, err = builder(nil, "svc").Build()
+	assert.NoError(t, err)
+
+	// the fields are in the wrong position
+	req, err = builder("svc", 100).Build()
+	assert.Nil(t, req)
+	assert.ErrorIs(t, err, ErrFieldTypeMismatch)
+	assert.Len(t, multierr.Errors(err), 2)
+	assert.Contains(t, err.Error(), "field #0 total")
+	assert.Contains(t, err.Error(), "field #1 name")
+
+	_, err = builder(100).Build()
+	assert.ErrorIs(t, err, ErrFieldCountMismatch)
+	assert.Contains(t, err.Error(), "field #1 name is absent")
+	_, err = builder(100, "svc", 1).Build()
+	assert.ErrorIs(t, err, ErrFieldCountMismatch)
+
+	err = builder(100, "svc").TagFamily("2").Validate(schema)
+	assert.ErrorIs(t, err, ErrTooManyTagFamilies)
+}
+
+func TestMarshalFloat_Order(t *testing.T) {
+	// in the ascending order
+	values := []float64{
+		math.Inf(-1),
+		-math.MaxFloat64,
+		-1.5,
+		-math.SmallestNonzeroFloat64,
+		0,
+		math.SmallestNonzeroFloat64,
+		// the largest subnormal
+		math.Float64frombits(0x000fffffffffffff),
+		// the smallest normal
+		math.Float64frombits(0x0010000000000000),
+		1,
+		1.5,
+		math.MaxFloat64,
+		math.Inf(1),
+		math.NaN(),
+	}
+	for i := 1; i < len(values); i++ {
+		assert.Negative(t, bytes.Compare(MarshalFloat(values[i-1]), MarshalFloat(values[i])),
+			"%v should be less than %v", values[i-1], values[i])
+	}
+	assert.Equal(t, MarshalFloat(0), MarshalFloat(math.Copysign(0, -1)))
+	assert.Equal(t, MarshalFloat(math.NaN()), MarshalFloat(math.Float64frombits(0xfff8000000000001)))
+	for _, v := range values {
+		got, err := UnmarshalIndexFieldValue(MarshalFloat(v), databasev1.TagType_TAG_TYPE_FLOAT)
+		assert.NoError(t, err)
+		if math.IsNaN(v) {
+			assert.True(t, math.IsNaN(got.GetFloat().GetValue()))
+			continue
+		}
+		assert.Equal(t, v, got.GetFloat().GetValue())
+	}
+}
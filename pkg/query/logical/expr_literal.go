@@ -18,6 +18,7 @@
 package logical
 
 import (
+	"bytes"
 	"fmt"
 	"strconv"
 
@@ -25,6 +26,7 @@ import (
 
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
 	"github.com/apache/skywalking-banyandb/pkg/convert"
+	pbv1 "github.com/apache/skywalking-banyandb/pkg/pb/v1"
 )
 
 var _ LiteralExpr = (*int64Literal)(nil)
@@ -57,6 +59,36 @@ func (i *int64Literal) String() string {
 	return strconv.FormatInt(i.int64, 10)
 }
 
+var _ LiteralExpr = (*float64Literal)(nil)
+
+type float64Literal struct {
+	float64
+}
+
+func (f *float64Literal) Bytes() [][]byte {
+	return [][]byte{pbv1.MarshalFloat(f.float64)}
+}
+
+func (f *float64Literal) Equal(expr Expr) bool {
+	if other, ok := expr.(*float64Literal); ok {
+		return bytes.Equal(pbv1.MarshalFloat(other.float64), pbv1.MarshalFloat(f.float64))
+	}
+
+	return false
+}
+
+func Float(num float64) Expr {
+	return &float64Literal{num}
+}
+
+func (f *float64Literal) FieldType() databasev1.TagType {
+	return databasev1.TagType_TAG_TYPE_FLOAT
+}
+
+func (f *float64Literal) String() string {
+	return strconv.FormatFloat(f.float64, 'g', -1, 64)
+}
+
 var _ LiteralExpr = (*int64ArrLiteral)(nil)
 
 type int64ArrLiteral struct {
@@ -20,12 +20,15 @@ package logical
 import (
 	"context"
 
+	"github.com/pkg/errors"
+
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
 	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
 	streamv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/stream/v1"
 	"github.com/apache/skywalking-banyandb/banyand/metadata"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/pkg/convert"
+	pbv1 "github.com/apache/skywalking-banyandb/pkg/pb/v1"
 )
 
 var (
@@ -184,8 +187,16 @@ func parseStreamFields(criteria *streamv1.QueryRequest, metadata *commonv1.Metad
 					}
 				}
 			case *modelv1.TagValue_StrArray:
-				e = &strArrLiteral{
-					arr: v.StrArray.GetValue(),
+				if entityIdx, ok := entityMap[pairQuery.GetName()]; ok {
+					entry, err := pbv1.MarshalEntityValue(typedTagValue)
+					if err != nil {
+						return nil, errors.Wrapf(err, "entity tag %s", pairQuery.GetName())
+					}
+					entity[entityIdx] = entry
+				} else {
+					e = &strArrLiteral{
+						arr: v.StrArray.GetValue(),
+					}
 				}
 			case *modelv1.TagValue_Int:
 				if entityIdx, ok := entityMap[pairQuery.GetName()]; ok {
@@ -196,8 +207,20 @@ func parseStreamFields(criteria *streamv1.QueryRequest, metadata *commonv1.Metad
 					}
 				}
 			case *modelv1.TagValue_IntArray:
-				e = &int64ArrLiteral{
-					arr: v.IntArray.GetValue(),
+				if entityIdx, ok := entityMap[pairQuery.GetName()]; ok {
+					entry, err := pbv1.MarshalEntityValue(typedTagValue)
+					if err != nil {
+						return nil, errors.Wrapf(err, "entity tag %s", pairQuery.GetName())
+					}
+					entity[entityIdx] = entry
+				} else {
+					e = &int64ArrLiteral{
+						arr: v.IntArray.GetValue(),
+					}
+				}
+			case *modelv1.TagValue_Float:
+				e = &float64Literal{
+					float64: v.Float.GetValue(),
 				}
 			default:
 				return nil, ErrInvalidConditionType
@@ -44,6 +44,8 @@ type EventType uint8
 const (
 	EventAddOrUpdate EventType = iota
 	EventDelete
+	// EventResync reloads the groups and resources from the registry, regardless of the kind
+	EventResync
 )
 
 type EventKind uint8
@@ -72,6 +74,12 @@ type ResourceSchema interface {
 type ResourceSpec struct {
 	Schema     ResourceSchema
 	IndexRules []*databasev1.IndexRule
+	// StrictIndexing rejects the data which fails to be indexed
+	StrictIndexing bool
+	// UTF8Policy applies to the string tags before they're stored and indexed
+	UTF8Policy commonv1.ResourceOpts_UTF8Policy
+	// BinaryCompression applies to the tag families which only hold binary tags
+	BinaryCompression commonv1.Compression
 }
 
 type Resource interface {
@@ -85,6 +93,7 @@ type Resource interface {
 type ResourceSupplier interface {
 	OpenResource(shardNum uint32, db tsdb.Supplier, spec ResourceSpec) (Resource, error)
 	ResourceSchema(repo metadata.Repo, metdata *commonv1.Metadata) (ResourceSchema, error)
+	ResourceSchemas(repo metadata.Repo, group string) ([]ResourceSchema, error)
 	OpenDB(groupSchema *commonv1.Group) (tsdb.Database, error)
 }
 
@@ -94,7 +103,10 @@ type Repository interface {
 	StoreGroup(groupMeta *commonv1.Metadata) (*group, error)
 	LoadGroup(name string) (Group, bool)
 	LoadResource(metadata *commonv1.Metadata) (Resource, bool)
+	FetchResource(metadata *commonv1.Metadata) (Resource, error)
 	NotifyAll() (err error)
+	// OnResync lets the repository catch up with the registry after the events are suppressed by a maintenance
+	OnResync()
 	Close()
 }
 
@@ -106,6 +118,7 @@ type schemaRepo struct {
 	repo             discovery.ServiceRepo
 	l                *logger.Logger
 	resourceSupplier ResourceSupplier
+	catalog          commonv1.Catalog
 	shardTopic       bus.Topic
 	entityTopic      bus.Topic
 	data             map[string]*group
@@ -120,6 +133,7 @@ func NewRepository(
 	repo discovery.ServiceRepo,
 	l *logger.Logger,
 	resourceSupplier ResourceSupplier,
+	catalog commonv1.Catalog,
 	shardTopic bus.Topic,
 	entityTopic bus.Topic,
 ) Repository {
@@ -128,6 +142,7 @@ func NewRepository(
 		repo:             repo,
 		l:                l,
 		resourceSupplier: resourceSupplier,
+		catalog:          catalog,
 		shardTopic:       shardTopic,
 		entityTopic:      entityTopic,
 		data:             make(map[string]*group),
@@ -140,6 +155,10 @@ func (sr *schemaRepo) SendMetadataEvent(event MetadataEvent) {
 	sr.eventCh <- event
 }
 
+func (sr *schemaRepo) OnResync() {
+	sr.SendMetadataEvent(MetadataEvent{Typ: EventResync})
+}
+
 func (sr *schemaRepo) Watcher() {
 	defer func() {
 		if err := recover(); err != nil {
@@ -165,6 +184,8 @@ func (sr *schemaRepo) Watcher() {
 				case EventKindResource:
 					err = sr.deleteResource(evt.Metadata)
 				}
+			case EventResync:
+				err = sr.resync()
 			}
 			if err != nil {
 				sr.l.Err(err).Interface("event", evt).Msg("fail to handle the metadata event. retry...")
@@ -241,6 +262,58 @@ func (sr *schemaRepo) deleteGroup(groupMeta *commonv1.Metadata) error {
 	return nil
 }
 
+// resync stores the groups of the catalog and their resources listed in the registry,
+// then deletes the ones missing there
+func (sr *schemaRepo) resync() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	groups, err := sr.metadata.GroupRegistry().ListGroup(ctx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	listed := make(map[string]struct{}, len(groups))
+	for _, groupSchema := range groups {
+		if groupSchema.GetCatalog() != sr.catalog {
+			continue
+		}
+		listed[groupSchema.GetMetadata().GetName()] = struct{}{}
+		g, errGroup := sr.StoreGroup(groupSchema.GetMetadata())
+		if errGroup != nil {
+			return errGroup
+		}
+		resourceSchemas, errGroup := sr.resourceSupplier.ResourceSchemas(sr.metadata, groupSchema.GetMetadata().GetName())
+		if errGroup != nil {
+			return errGroup
+		}
+		listedResources := make(map[string]struct{}, len(resourceSchemas))
+		for _, resourceSchema := range resourceSchemas {
+			listedResources[resourceSchema.GetMetadata().GetName()] = struct{}{}
+			if _, errGroup = g.StoreResource(resourceSchema); errGroup != nil {
+				return errGroup
+			}
+		}
+		for _, md := range g.unlisted(listedResources) {
+			if errGroup = g.deleteResource(md); errGroup != nil {
+				return errGroup
+			}
+		}
+	}
+	sr.RLock()
+	unlisted := make([]*commonv1.Metadata, 0)
+	for name, g := range sr.data {
+		if _, ok := listed[name]; !ok {
+			unlisted = append(unlisted, g.groupSchema.GetMetadata())
+		}
+	}
+	sr.RUnlock()
+	for _, md := range unlisted {
+		if err = sr.deleteGroup(md); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (sr *schemaRepo) getGroup(name string) (*group, bool) {
 	g := sr.data[name]
 	if g == nil {
@@ -250,6 +323,10 @@ func (sr *schemaRepo) getGroup(name string) (*group, bool) {
 }
 
 func (sr *schemaRepo) LoadGroup(name string) (Group, bool) {
+	return sr.getGroupWithLock(name)
+}
+
+func (sr *schemaRepo) getGroupWithLock(name string) (*group, bool) {
 	sr.RLock()
 	defer sr.RUnlock()
 	return sr.getGroup(name)
@@ -263,6 +340,27 @@ func (sr *schemaRepo) LoadResource(metadata *commonv1.Metadata) (Resource, bool)
 	return g.LoadResource(metadata.Name)
 }
 
+// FetchResource stores the resource from the registry if it isn't loaded yet, for example,
+// it's registered but the event of the registration isn't handled yet. So is its group.
+// The error wraps the one of the registry, which is schema.ErrEntityNotFound if either of them isn't registered.
+func (sr *schemaRepo) FetchResource(metadata *commonv1.Metadata) (Resource, error) {
+	if r, ok := sr.LoadResource(metadata); ok {
+		return r, nil
+	}
+	// the resource goes first, which keeps the groups of the other catalogs out
+	resourceSchema, err := sr.resourceSupplier.ResourceSchema(sr.metadata, metadata)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "fetch the resource %s/%s", metadata.GetGroup(), metadata.GetName())
+	}
+	g, ok := sr.getGroupWithLock(metadata.GetGroup())
+	if !ok {
+		if g, err = sr.StoreGroup(&commonv1.Metadata{Name: metadata.GetGroup()}); err != nil {
+			return nil, errors.WithMessagef(err, "fetch the group %s", metadata.GetGroup())
+		}
+	}
+	return g.StoreResource(resourceSchema)
+}
+
 func (sr *schemaRepo) storeResource(metadata *commonv1.Metadata) (Resource, error) {
 	group, ok := sr.LoadGroup(metadata.Group)
 	if !ok {
@@ -429,8 +527,11 @@ func (g *group) StoreResource(resourceSchema ResourceSchema) (Resource, error) {
 		return nil, errIndexRules
 	}
 	sm, errTS := g.resourceSupplier.OpenResource(g.groupSchema.GetResourceOpts().ShardNum, g, ResourceSpec{
-		Schema:     resourceSchema,
-		IndexRules: idxRules,
+		Schema:            resourceSchema,
+		IndexRules:        idxRules,
+		StrictIndexing:    g.groupSchema.GetResourceOpts().GetStrictIndexing(),
+		UTF8Policy:        g.groupSchema.GetResourceOpts().GetUtf8Policy(),
+		BinaryCompression: g.groupSchema.GetResourceOpts().GetBinaryCompression(),
 	})
 	if errTS != nil {
 		return nil, errTS
@@ -461,6 +562,19 @@ func (g *group) deleteResource(metadata *commonv1.Metadata) error {
 	return nil
 }
 
+// unlisted returns the metadata of the resources missing in the listed names
+func (g *group) unlisted(listed map[string]struct{}) []*commonv1.Metadata {
+	g.mapMutex.RLock()
+	defer g.mapMutex.RUnlock()
+	mm := make([]*commonv1.Metadata, 0)
+	for name, r := range g.schemaMap {
+		if _, ok := listed[name]; !ok {
+			mm = append(mm, r.GetMetadata())
+		}
+	}
+	return mm
+}
+
 func (g *group) LoadResource(name string) (Resource, bool) {
 	data := g.getMap()
 	s := data[name]